@@ -0,0 +1,283 @@
+/*
+Package accounts offers an account store for B2B use cases. It remembers the
+traits of the groups received in Group events, merged over time, and the users
+and anonymous users who are members of these groups.
+*/
+package accounts
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/helper/logger"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Membership is the membership of a user or an anonymous user in an account.
+*/
+type Membership struct {
+	Type        string     `json:"type"`
+	Value       string     `json:"id"`
+	FirstSeenAt *time.Time `json:"first_seen_at,omitempty"`
+	LastSeenAt  *time.Time `json:"last_seen_at,omitempty"`
+}
+
+/*
+Store is the account store.
+*/
+type Store struct {
+	env *Options
+}
+
+/*
+New returns a valid account store.
+*/
+func New(env *Options) *Store {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &Store{
+		env: env,
+	}
+}
+
+/*
+members returns the memberships of a Group event.
+*/
+func members(msg analytics.Group) []Membership {
+	m := []Membership{}
+	if msg.UserId != "" {
+		m = append(m, Membership{Type: "user_id", Value: msg.UserId})
+	}
+
+	if msg.AnonymousId != "" {
+		m = append(m, Membership{Type: "anonymous_id", Value: msg.AnonymousId})
+	}
+
+	return m
+}
+
+/*
+Group updates an account given a Group event. A trait is only updated if the event
+is more recent than the one which last set it, so events received out of order
+do not override more recent values. The user of the event is added as a member
+of the account.
+*/
+func (s *Store) Group(msg analytics.Group) error {
+	at := msg.Timestamp
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+
+	if msg.Traits == nil {
+		msg.Traits = analytics.Traits{}
+	}
+
+	traits, err := json.Marshal(msg.Traits)
+	if err != nil {
+		return failed("Failed to update account", err)
+	}
+
+	tx, err := s.env.DB.Begin()
+	if err != nil {
+		return failed("Failed to update account", err)
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO fragment_accounts.accounts (group_id, created_at, updated_at)
+		VALUES ($1, $2, $2)
+		ON CONFLICT (group_id) DO UPDATE
+		SET updated_at = GREATEST(accounts.updated_at, EXCLUDED.updated_at);
+	`, msg.GroupId, at)
+	if err != nil {
+		return failed("Failed to update account", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO fragment_accounts.traits (group_id, key, value, updated_at)
+		SELECT $1, t.key, t.value, $3 FROM jsonb_each($2::JSONB) AS t
+		ON CONFLICT (group_id, key) DO UPDATE
+		SET value = EXCLUDED.value, updated_at = EXCLUDED.updated_at
+		WHERE traits.updated_at <= EXCLUDED.updated_at;
+	`, msg.GroupId, string(traits), at)
+	if err != nil {
+		return failed("Failed to update traits", err)
+	}
+
+	for _, m := range members(msg) {
+		_, err = tx.Exec(`
+			INSERT INTO fragment_accounts.memberships (group_id, type, value, first_seen_at, last_seen_at)
+			VALUES ($1, $2, $3, $4, $4)
+			ON CONFLICT (group_id, type, value) DO UPDATE
+			SET first_seen_at = LEAST(memberships.first_seen_at, EXCLUDED.first_seen_at),
+				last_seen_at = GREATEST(memberships.last_seen_at, EXCLUDED.last_seen_at);
+		`, msg.GroupId, m.Type, m.Value, at)
+		if err != nil {
+			return failed("Failed to update membership", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return failed("Failed to update account", err)
+	}
+
+	return nil
+}
+
+/*
+Traits returns the traits of an account.
+*/
+func (s *Store) Traits(groupID string) (analytics.Traits, error) {
+	rows, err := s.env.DB.Query(`
+		SELECT key, value FROM fragment_accounts.traits
+		WHERE group_id = $1;
+	`, groupID)
+	if err != nil {
+		return nil, failed("Failed to find traits", err)
+	}
+
+	defer rows.Close()
+	traits := analytics.Traits{}
+	for rows.Next() {
+		var key string
+		var value []byte
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, failed("Failed to find traits", err)
+		}
+
+		var v interface{}
+		if err := json.Unmarshal(value, &v); err != nil {
+			return nil, failed("Failed to find traits", err)
+		}
+
+		traits[key] = v
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, failed("Failed to find traits", err)
+	}
+
+	return traits, nil
+}
+
+/*
+Memberships returns the members of an account.
+*/
+func (s *Store) Memberships(groupID string) ([]*Membership, error) {
+	rows, err := s.env.DB.Query(`
+		SELECT type, value, first_seen_at, last_seen_at
+		FROM fragment_accounts.memberships
+		WHERE group_id = $1
+		ORDER BY first_seen_at ASC;
+	`, groupID)
+	if err != nil {
+		return nil, failed("Failed to find memberships", err)
+	}
+
+	defer rows.Close()
+	memberships := []*Membership{}
+	for rows.Next() {
+		m := &Membership{}
+		if err := rows.Scan(&m.Type, &m.Value, &m.FirstSeenAt, &m.LastSeenAt); err != nil {
+			return nil, failed("Failed to find memberships", err)
+		}
+
+		memberships = append(memberships, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, failed("Failed to find memberships", err)
+	}
+
+	return memberships, nil
+}
+
+/*
+Current returns the group ID of the current account of a user, which is the last
+one the user has been seen in. The user ID has precedence over the anonymous ID.
+It returns an empty string if the user is not a member of any account.
+*/
+func (s *Store) Current(userID string, anonymousID string) (string, error) {
+	var groupID string
+	err := s.env.DB.QueryRow(`
+		SELECT group_id FROM fragment_accounts.memberships
+		WHERE (type = 'user_id' AND value = $1)
+		OR (type = 'anonymous_id' AND value = $2)
+		ORDER BY type = 'user_id' DESC, last_seen_at DESC
+		LIMIT 1;
+	`, userID, anonymousID).Scan(&groupID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	} else if err != nil {
+		return "", failed("Failed to find account", err)
+	}
+
+	return groupID, nil
+}
+
+/*
+Attach returns the context of an event including the traits of the current
+account of a user under the key "groupTraits", if enabled in the options. The
+context is left untouched if the user is not a member of any account or if the
+event already holds group traits.
+*/
+func (s *Store) Attach(ctx *analytics.Context, userID string, anonymousID string) (*analytics.Context, error) {
+	if !s.env.GroupTraits {
+		return ctx, nil
+	}
+
+	if ctx != nil {
+		if _, exists := ctx.Extra["groupTraits"]; exists {
+			return ctx, nil
+		}
+	}
+
+	groupID, err := s.Current(userID, anonymousID)
+	if err != nil || groupID == "" {
+		return ctx, err
+	}
+
+	traits, err := s.Traits(groupID)
+	if err != nil {
+		return ctx, err
+	}
+
+	if ctx == nil {
+		ctx = &analytics.Context{}
+	}
+
+	extra := make(map[string]interface{}, len(ctx.Extra)+1)
+	for key, value := range ctx.Extra {
+		extra[key] = value
+	}
+
+	extra["groupTraits"] = traits
+	ctx.Extra = extra
+
+	return ctx, nil
+}
+
+/*
+failed returns a normalized error for the account store.
+*/
+func failed(message string, err error) error {
+	return &errors.Error{
+		Message: "accounts: " + message,
+		Validations: []errors.Validation{
+			{
+				Message: err.Error(),
+			},
+		},
+	}
+}
@@ -0,0 +1,92 @@
+package accounts
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+func TestMembers(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  analytics.Group
+		want []Membership
+	}{
+		{
+			name: "WithUserID",
+			msg: analytics.Group{
+				GroupId: "acme",
+				UserId:  "u1",
+			},
+			want: []Membership{
+				{Type: "user_id", Value: "u1"},
+			},
+		},
+		{
+			name: "WithBothIDs",
+			msg: analytics.Group{
+				GroupId:     "acme",
+				UserId:      "u1",
+				AnonymousId: "a1",
+			},
+			want: []Membership{
+				{Type: "user_id", Value: "u1"},
+				{Type: "anonymous_id", Value: "a1"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := members(tt.msg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("members() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStore_AttachDisabled(t *testing.T) {
+	s := &Store{
+		env: &Options{
+			GroupTraits: false,
+		},
+	}
+
+	ctx := &analytics.Context{
+		IP: []byte{127, 0, 0, 1},
+	}
+
+	got, err := s.Attach(ctx, "u1", "a1")
+	if err != nil {
+		t.Fatalf("Store.Attach() error = %v", err)
+	}
+
+	if got != ctx || got.Extra != nil {
+		t.Errorf("Store.Attach() = %v, want %v", got, ctx)
+	}
+}
+
+func TestStore_AttachExisting(t *testing.T) {
+	s := &Store{
+		env: &Options{
+			GroupTraits: true,
+		},
+	}
+
+	ctx := &analytics.Context{
+		Extra: map[string]interface{}{
+			"groupTraits": map[string]interface{}{
+				"plan": "enterprise",
+			},
+		},
+	}
+
+	got, err := s.Attach(ctx, "u1", "a1")
+	if err != nil {
+		t.Fatalf("Store.Attach() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, ctx) {
+		t.Errorf("Store.Attach() = %v, want %v", got, ctx)
+	}
+}
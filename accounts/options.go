@@ -0,0 +1,57 @@
+package accounts
+
+import (
+	"database/sql"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+)
+
+/*
+Options is the options the account store can take as an input to be configured.
+*/
+type Options struct {
+
+	// DB is the PostgreSQL database connection where the accounts are stored. The
+	// tables are created by the migration "init_accounts".
+	//
+	// Required.
+	DB *sql.DB
+
+	// GroupTraits allows to attach the traits of the current account of a user
+	// in the context of its subsequent "track" and "page" events, under the key
+	// "groupTraits". The current account is the last one the user has been seen
+	// in with a "group" event.
+	GroupTraits bool
+}
+
+/*
+validate ensures the options passed to initialize the account store are valid.
+*/
+func (env *Options) validate() error {
+	fail := &errors.Error{
+		Message:     "accounts: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Accounts"},
+		})
+
+		return fail
+	}
+
+	if env.DB == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Database connection must be set",
+			Path:    []string{"Options", "Accounts", "DB"},
+		})
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package accounts
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestOptions_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  *Options
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: true,
+		},
+		{
+			name: "WithDB",
+			fields: &Options{
+				DB:          &sql.DB{},
+				GroupTraits: true,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Package attribution records the first and last marketing touchpoints of the users
+and exposes them as traits of their profile:
+
+  - initial_utm_source, initial_utm_medium, initial_utm_campaign,
+    initial_utm_term, initial_utm_content, initial_referrer, and
+    initial_landing_page for the first touch;
+  - latest_utm_source, latest_utm_medium, latest_utm_campaign, latest_utm_term,
+    latest_utm_content, latest_referrer, and latest_landing_page for the last
+    touch attributed to a campaign or to another website.
+
+Touches are stored per profile, so the touches of anonymous sessions are carried
+into the known profile once the profiles are merged on "identify" or "alias".
+*/
+package attribution
+
+import (
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/helper/logger"
+
+	"github.com/lib/pq"
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+KindFirst is the kind of the first touch of a profile.
+*/
+var KindFirst = "first"
+
+/*
+KindLast is the kind of the last touch of a profile.
+*/
+var KindLast = "last"
+
+/*
+Store holds the touches of the profiles.
+*/
+type Store struct {
+	env *Options
+}
+
+/*
+New returns a valid attribution store.
+*/
+func New(env *Options) *Store {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &Store{
+		env: env,
+	}
+}
+
+/*
+Touch records the touch found in the context of an event received for a profile.
+It returns an Identify event for the attribution traits which changed, or nil if
+none changed.
+*/
+func (s *Store) Touch(profileID string, userID string, anonymousID string, at time.Time, ctx *analytics.Context) (*analytics.Identify, error) {
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+
+	t := fromContext(ctx, at)
+	if t == nil {
+		return nil, nil
+	}
+
+	if err := s.record(profileID, KindFirst, t); err != nil {
+		return nil, err
+	}
+
+	if t.attributed() {
+		if err := s.record(profileID, KindLast, t); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.Refresh(profileID, userID, anonymousID)
+}
+
+/*
+Refresh computes the attribution traits of a profile given the touches of every
+profiles merged into it. It must be called after profiles have been merged, such
+as on "identify" and "alias" events. It returns an Identify event for the traits
+which changed, or nil if none changed.
+*/
+func (s *Store) Refresh(profileID string, userID string, anonymousID string) (*analytics.Identify, error) {
+	members, err := s.env.Identity.Members(profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.env.DB.Query(`
+		SELECT DISTINCT ON (kind) kind, source, medium, campaign, term, content, referrer, landing_page, touched_at
+		FROM fragment_attribution.touches
+		WHERE profile_id = ANY($1)
+		ORDER BY kind, CASE WHEN kind = $2 THEN touched_at END ASC, touched_at DESC;
+	`, pq.Array(members), KindFirst)
+	if err != nil {
+		return nil, failed("Failed to find touches", err)
+	}
+
+	defer rows.Close()
+	traits := analytics.Traits{}
+	for rows.Next() {
+		var kind string
+		t := &Touch{}
+		err := rows.Scan(&kind, &t.Source, &t.Medium, &t.Campaign, &t.Term, &t.Content, &t.Referrer, &t.LandingPage, &t.At)
+		if err != nil {
+			return nil, failed("Failed to find touches", err)
+		}
+
+		prefix := "latest_"
+		if kind == KindFirst {
+			prefix = "initial_"
+		}
+
+		for key, value := range t.traits(prefix) {
+			traits[key] = value
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, failed("Failed to find touches", err)
+	}
+
+	if len(traits) == 0 {
+		return nil, nil
+	}
+
+	return s.env.Profiles.Update(profileID, userID, anonymousID, traits, time.Now().UTC())
+}
+
+/*
+record stores a touch of a profile. The first touch is only replaced by an older
+one, and the last touch by a more recent one.
+*/
+func (s *Store) record(profileID string, kind string, t *Touch) error {
+	_, err := s.env.DB.Exec(`
+		INSERT INTO fragment_attribution.touches (profile_id, kind, source, medium, campaign, term, content, referrer, landing_page, touched_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (profile_id, kind) DO UPDATE SET
+			source = EXCLUDED.source,
+			medium = EXCLUDED.medium,
+			campaign = EXCLUDED.campaign,
+			term = EXCLUDED.term,
+			content = EXCLUDED.content,
+			referrer = EXCLUDED.referrer,
+			landing_page = EXCLUDED.landing_page,
+			touched_at = EXCLUDED.touched_at
+		WHERE (EXCLUDED.kind = $11 AND EXCLUDED.touched_at < touches.touched_at)
+		OR (EXCLUDED.kind <> $11 AND EXCLUDED.touched_at >= touches.touched_at);
+	`, profileID, kind, t.Source, t.Medium, t.Campaign, t.Term, t.Content, t.Referrer, t.LandingPage, t.At, KindFirst)
+	if err != nil {
+		return failed("Failed to record touch", err)
+	}
+
+	return nil
+}
+
+/*
+failed returns a normalized error for the attribution.
+*/
+func failed(message string, err error) error {
+	return &errors.Error{
+		Message: "attribution: " + message,
+		Validations: []errors.Validation{
+			{
+				Message: err.Error(),
+			},
+		},
+	}
+}
@@ -0,0 +1,78 @@
+package attribution
+
+import (
+	"database/sql"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/nunchistudio/fragment/identity"
+	"github.com/nunchistudio/fragment/profiles"
+)
+
+/*
+Options is the options the attribution can take as an input to be configured.
+*/
+type Options struct {
+
+	// DB is the PostgreSQL database connection where the touches are stored. The
+	// tables are created by the migration "init_attribution".
+	//
+	// Required.
+	DB *sql.DB
+
+	// Identity is the identity graph used to find the profiles merged together.
+	//
+	// Required.
+	Identity *identity.Graph
+
+	// Profiles is the profile store where the attribution traits are saved.
+	//
+	// Required.
+	Profiles *profiles.Store
+}
+
+/*
+validate ensures the options passed to initialize the attribution are valid.
+*/
+func (env *Options) validate() error {
+	fail := &errors.Error{
+		Message:     "attribution: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Attribution"},
+		})
+
+		return fail
+	}
+
+	if env.DB == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Database connection must be set",
+			Path:    []string{"Options", "Attribution", "DB"},
+		})
+	}
+
+	if env.Identity == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Identity graph must be set",
+			Path:    []string{"Options", "Attribution", "Identity"},
+		})
+	}
+
+	if env.Profiles == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Profile store must be set",
+			Path:    []string{"Options", "Attribution", "Profiles"},
+		})
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
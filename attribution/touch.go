@@ -0,0 +1,117 @@
+package attribution
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Touch is a marketing touchpoint of a user, as found in the context of an event.
+*/
+type Touch struct {
+	Source      string
+	Medium      string
+	Campaign    string
+	Term        string
+	Content     string
+	Referrer    string
+	LandingPage string
+	At          time.Time
+}
+
+/*
+fromContext returns the touch found in the context of an event. It returns nil if
+the context holds neither a campaign nor a page.
+*/
+func fromContext(ctx *analytics.Context, at time.Time) *Touch {
+	if ctx == nil {
+		return nil
+	}
+
+	t := &Touch{
+		Source:      ctx.Campaign.Source,
+		Medium:      ctx.Campaign.Medium,
+		Campaign:    ctx.Campaign.Name,
+		Term:        ctx.Campaign.Term,
+		Content:     ctx.Campaign.Content,
+		Referrer:    ctx.Page.Referrer,
+		LandingPage: ctx.Page.URL,
+		At:          at,
+	}
+
+	if t.Referrer == "" {
+		t.Referrer = ctx.Referrer.URL
+	}
+
+	if !t.hasCampaign() && t.LandingPage == "" {
+		return nil
+	}
+
+	return t
+}
+
+/*
+hasCampaign returns if the touch holds campaign details.
+*/
+func (t *Touch) hasCampaign() bool {
+	return t.Source != "" || t.Medium != "" || t.Campaign != "" || t.Term != "" || t.Content != ""
+}
+
+/*
+attributed returns if the touch can be attributed to a marketing channel, which
+is the case if it holds campaign details or if the user comes from another
+website. Navigating within the same website is not a touch.
+*/
+func (t *Touch) attributed() bool {
+	if t.hasCampaign() {
+		return true
+	}
+
+	if t.Referrer == "" {
+		return false
+	}
+
+	referrer, err := url.Parse(t.Referrer)
+	if err != nil || referrer.Host == "" {
+		return false
+	}
+
+	landing, err := url.Parse(t.LandingPage)
+	if err != nil {
+		return true
+	}
+
+	return !strings.EqualFold(strings.TrimPrefix(referrer.Host, "www."), strings.TrimPrefix(landing.Host, "www."))
+}
+
+/*
+traits returns the traits of the touch, prefixed by "initial_" for the first
+touch or by "latest_" for the last touch. Values not set are nil so they replace
+the ones of a previous touch.
+*/
+func (t *Touch) traits(prefix string) analytics.Traits {
+	values := map[string]string{
+		"utm_source":   t.Source,
+		"utm_medium":   t.Medium,
+		"utm_campaign": t.Campaign,
+		"utm_term":     t.Term,
+		"utm_content":  t.Content,
+		"referrer":     t.Referrer,
+		"landing_page": t.LandingPage,
+	}
+
+	traits := analytics.Traits{}
+	for key, value := range values {
+		if value == "" {
+			traits[prefix+key] = nil
+			continue
+		}
+
+		traits[prefix+key] = value
+	}
+
+	return traits
+}
@@ -0,0 +1,97 @@
+package attribution
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+func TestFromContext(t *testing.T) {
+	at := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		ctx            *analytics.Context
+		wantNil        bool
+		wantAttributed bool
+	}{
+		{
+			name:    "WithoutContext",
+			ctx:     nil,
+			wantNil: true,
+		},
+		{
+			name:    "WithoutPageNorCampaign",
+			ctx:     &analytics.Context{},
+			wantNil: true,
+		},
+		{
+			name: "WithCampaign",
+			ctx: &analytics.Context{
+				Campaign: analytics.CampaignInfo{Source: "newsletter", Medium: "email"},
+			},
+			wantAttributed: true,
+		},
+		{
+			name: "WithExternalReferrer",
+			ctx: &analytics.Context{
+				Page: analytics.PageInfo{
+					URL:      "https://www.example.com/pricing",
+					Referrer: "https://www.google.com/",
+				},
+			},
+			wantAttributed: true,
+		},
+		{
+			name: "WithInternalReferrer",
+			ctx: &analytics.Context{
+				Page: analytics.PageInfo{
+					URL:      "https://www.example.com/pricing",
+					Referrer: "https://example.com/",
+				},
+			},
+			wantAttributed: false,
+		},
+		{
+			name: "WithDirectVisit",
+			ctx: &analytics.Context{
+				Page: analytics.PageInfo{
+					URL: "https://www.example.com/",
+				},
+			},
+			wantAttributed: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fromContext(tt.ctx, at)
+			if (got == nil) != tt.wantNil {
+				t.Fatalf("fromContext() = %v, wantNil %v", got, tt.wantNil)
+			}
+
+			if got != nil && got.attributed() != tt.wantAttributed {
+				t.Errorf("Touch.attributed() = %v, want %v", got.attributed(), tt.wantAttributed)
+			}
+		})
+	}
+}
+
+func TestTouch_traits(t *testing.T) {
+	touch := &Touch{
+		Source:      "newsletter",
+		LandingPage: "https://www.example.com/",
+	}
+
+	traits := touch.traits("initial_")
+	if traits["initial_utm_source"] != "newsletter" {
+		t.Errorf("Touch.traits() initial_utm_source = %v, want %v", traits["initial_utm_source"], "newsletter")
+	}
+
+	if value, exists := traits["initial_utm_medium"]; !exists || value != nil {
+		t.Errorf("Touch.traits() initial_utm_medium = %v, want nil", value)
+	}
+
+	if len(traits) != 7 {
+		t.Errorf("Touch.traits() has %d traits, want 7", len(traits))
+	}
+}
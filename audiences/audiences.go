@@ -0,0 +1,802 @@
+/*
+Package audiences offers rule-based audiences, which are segments of profiles
+satisfying boolean conditions over their traits, computed traits, and events.
+
+Audiences are evaluated in near-real time every time an event is received for a
+profile, and on a schedule so conditions over rolling windows stay accurate. An
+"Audience Entered" or "Audience Exited" event is returned every time a profile
+enters or exits an audience.
+
+Named lists, defined by a trait condition or by uploaded identifiers, can also be
+stored in the database at runtime. They are evaluated alongside the audiences.
+*/
+package audiences
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/helper/logger"
+
+	"github.com/lib/pq"
+	"github.com/segmentio/ksuid"
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+EventEntered is the name of the "track" event emitted when a profile enters an
+audience.
+*/
+var EventEntered = "Audience Entered"
+
+/*
+EventExited is the name of the "track" event emitted when a profile exits an
+audience.
+*/
+var EventExited = "Audience Exited"
+
+/*
+retention is the time during which the IDs of the messages evaluated are kept,
+so a message retried by a client is not recorded twice.
+*/
+var retention = 24 * time.Hour
+
+/*
+Store holds the audiences and their memberships.
+*/
+type Store struct {
+	env *Options
+
+	// events holds the events used by the conditions of the audiences, alongside
+	// the largest number of days they are looked up. A value of 0 means the
+	// occurrences must be kept forever.
+	events map[string]int
+
+	// lists holds the lists loaded from the database.
+	lists *lists
+}
+
+/*
+New returns a valid store of audiences.
+*/
+func New(env *Options) *Store {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	events := map[string]int{}
+	for _, audience := range env.Audiences {
+		audience.Condition.events(events)
+	}
+
+	return &Store{
+		env:    env,
+		events: events,
+		lists:  &lists{},
+	}
+}
+
+/*
+Audiences returns the audiences defined in the options.
+*/
+func (s *Store) Audiences() []*Audience {
+	return s.env.Audiences
+}
+
+/*
+Track records the occurrence of a Track event if it is used by an audience, and
+evaluates the audiences for the user's profile. It returns the events to emit
+for the memberships which changed.
+
+Messages are recorded once given their message ID. When a message is received
+again, such as when a client retries a message after an error, the occurrence is
+not counted twice and the events of the memberships changed by the message are
+returned again, so the ones of the failed attempt are not lost.
+*/
+func (s *Store) Track(profileID string, msg analytics.Track) ([]analytics.Track, error) {
+	tx, err := s.env.DB.Begin()
+	if err != nil {
+		return nil, failed("Failed to record event", err)
+	}
+
+	defer tx.Rollback()
+	claimed, err := claim(tx, msg.MessageId)
+	if err != nil {
+		return nil, failed("Failed to record event", err)
+	}
+
+	if _, exists := s.events[msg.Event]; exists && claimed {
+		at := msg.Timestamp
+		if at.IsZero() {
+			at = time.Now().UTC()
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO fragment_audiences.occurrences (profile_id, event, day, count)
+			VALUES ($1, $2, $3::DATE, 1)
+			ON CONFLICT (profile_id, event, day) DO UPDATE
+			SET count = occurrences.count + 1;
+		`, profileID, msg.Event, day(at))
+		if err != nil {
+			return nil, failed("Failed to record event", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, failed("Failed to record event", err)
+	}
+
+	return s.message(profileID, msg.MessageId, msg.UserId, msg.AnonymousId, !claimed)
+}
+
+/*
+Identify evaluates the audiences for the user's profile after its traits have
+been updated. It returns the events to emit for the memberships which changed.
+Like Track, the events of a message received again are returned again.
+*/
+func (s *Store) Identify(profileID string, msg analytics.Identify) ([]analytics.Track, error) {
+	tx, err := s.env.DB.Begin()
+	if err != nil {
+		return nil, failed("Failed to record event", err)
+	}
+
+	defer tx.Rollback()
+	claimed, err := claim(tx, msg.MessageId)
+	if err != nil {
+		return nil, failed("Failed to record event", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, failed("Failed to record event", err)
+	}
+
+	return s.message(profileID, msg.MessageId, msg.UserId, msg.AnonymousId, !claimed)
+}
+
+/*
+claim records the ID of a message as evaluated. It returns false if the message
+has already been evaluated. Messages without ID are always evaluated.
+*/
+func claim(tx *sql.Tx, messageID string) (bool, error) {
+	if messageID == "" {
+		return true, nil
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO fragment_audiences.messages (message_id, received_at)
+		VALUES ($1, $2)
+		ON CONFLICT (message_id) DO NOTHING;
+	`, messageID, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+/*
+message evaluates every audiences and lists for a profile on behalf of a message.
+When the message has been received before, the events of the memberships it
+changed are returned alongside the ones of the memberships which changed since.
+*/
+func (s *Store) message(profileID string, messageID string, userID string, anonymousID string, retried bool) ([]analytics.Track, error) {
+	definitions, err := s.definitions()
+	if err != nil || len(definitions) == 0 {
+		return nil, err
+	}
+
+	return s.evaluate(profileID, userID, anonymousID, definitions, evaluation{
+		messageID: messageID,
+		retried:   retried,
+	})
+}
+
+/*
+Refresh evaluates the audiences for every profile which is a member of at least
+one audience or which has events within the rolling windows of the conditions.
+It also removes the occurrences no longer needed. It is meant to be called
+periodically so conditions over rolling windows stay accurate.
+*/
+func (s *Store) Refresh() ([]analytics.Track, error) {
+	now := time.Now().UTC()
+	_, err := s.env.DB.Exec(`
+		DELETE FROM fragment_audiences.messages
+		WHERE received_at < $1;
+	`, now.Add(-retention))
+	if err != nil {
+		return nil, failed("Failed to remove expired messages", err)
+	}
+
+	// Only keep the occurrences of the events within the largest window they are
+	// looked up.
+	for event, days := range s.events {
+		if days == 0 {
+			continue
+		}
+
+		_, err := s.env.DB.Exec(`
+			DELETE FROM fragment_audiences.occurrences
+			WHERE event = $1 AND day < $2::DATE;
+		`, event, day(now.AddDate(0, 0, -days)))
+		if err != nil {
+			return nil, failed("Failed to remove expired events", err)
+		}
+	}
+
+	rows, err := s.env.DB.Query(`
+		SELECT profile_id FROM fragment_audiences.memberships WHERE is_member = TRUE
+		UNION
+		SELECT profile_id FROM fragment_audiences.occurrences;
+	`)
+	if err != nil {
+		return nil, failed("Failed to find profiles", err)
+	}
+
+	profileIDs := []string{}
+	for rows.Next() {
+		var profileID string
+		if err := rows.Scan(&profileID); err != nil {
+			rows.Close()
+			return nil, failed("Failed to find profiles", err)
+		}
+
+		profileIDs = append(profileIDs, profileID)
+	}
+
+	rows.Close()
+	tracks := []analytics.Track{}
+	seen := map[string]bool{}
+	for _, profileID := range profileIDs {
+		root, err := s.env.Identity.Root(profileID)
+		if err != nil {
+			return nil, err
+		} else if root == "" || seen[root] {
+			continue
+		}
+
+		seen[root] = true
+		userID, anonymousID, err := s.env.Identity.Primary(root)
+		if err != nil {
+			return nil, err
+		}
+
+		changes, err := s.Evaluate(root, userID, anonymousID)
+		if err != nil {
+			return nil, err
+		}
+
+		tracks = append(tracks, changes...)
+	}
+
+	return tracks, nil
+}
+
+/*
+Evaluate evaluates every audiences and lists for a profile and stores the
+memberships which changed. It returns the events to emit for these changes.
+*/
+func (s *Store) Evaluate(profileID string, userID string, anonymousID string) ([]analytics.Track, error) {
+	definitions, err := s.definitions()
+	if err != nil || len(definitions) == 0 {
+		return nil, err
+	}
+
+	return s.evaluate(profileID, userID, anonymousID, definitions, evaluation{})
+}
+
+/*
+Resync requests an audience or a list to be evaluated again for every profiles.
+Unlike Evaluate, an "Audience Entered" event is returned for every member of the
+audience even if its membership did not change, so the destinations can be
+synchronized again when the definition of an audience changed.
+
+The request is stored in the database and the profiles are evaluated page by
+page with ResyncNext, so an audience is never synchronized in a single HTTP
+request nor held in memory at once. Requesting a resync already in progress
+starts it over.
+*/
+func (s *Store) Resync(key string) error {
+	s.lists.expire()
+	found, err := s.definition(key)
+	if err != nil {
+		return err
+	} else if found == nil {
+		return &errors.Error{
+			StatusCode: 404,
+			Message:    "audiences: Audience '" + key + "' does not exist",
+		}
+	}
+
+	_, err = s.env.DB.Exec(`
+		INSERT INTO fragment_audiences.resyncs (key, cursor, requested_at)
+		VALUES ($1, '', $2)
+		ON CONFLICT (key) DO UPDATE SET
+			cursor = EXCLUDED.cursor,
+			requested_at = EXCLUDED.requested_at;
+	`, key, time.Now().UTC())
+	if err != nil {
+		return failed("Failed to request resync", err)
+	}
+
+	return nil
+}
+
+/*
+ResyncPage is a page of profiles evaluated for a resync.
+*/
+type ResyncPage struct {
+
+	// Key is the key of the audience or list being synchronized.
+	Key string
+
+	// Tracks are the events to emit for the profiles of the page.
+	Tracks []analytics.Track
+
+	cursor      string
+	requestedAt time.Time
+	last        bool
+}
+
+/*
+ResyncNext evaluates the next page of at most limit profiles for the oldest
+resync requested, and returns the events to emit for these profiles. Nil is
+returned when no resync is pending.
+
+The progress of the resync is not saved until ResyncSave is called with the page,
+so a page is evaluated again if its events could not be emitted.
+*/
+func (s *Store) ResyncNext(limit int) (*ResyncPage, error) {
+	page := &ResyncPage{
+		Tracks: []analytics.Track{},
+	}
+
+	var cursor string
+	err := s.env.DB.QueryRow(`
+		SELECT key, cursor, requested_at
+		FROM fragment_audiences.resyncs
+		ORDER BY requested_at ASC
+		LIMIT 1;
+	`).Scan(&page.Key, &cursor, &page.requestedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, failed("Failed to find resync", err)
+	}
+
+	s.lists.expire()
+	found, err := s.definition(page.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	// The list may have been removed since the resync was requested.
+	profileIDs := []string{}
+	if found != nil {
+		profileIDs, err = s.env.Identity.Profiles(cursor, limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, profileID := range profileIDs {
+		userID, anonymousID, err := s.env.Identity.Primary(profileID)
+		if err != nil {
+			return nil, err
+		}
+
+		changes, err := s.evaluate(profileID, userID, anonymousID, []*definition{found}, evaluation{
+			resync: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		page.Tracks = append(page.Tracks, changes...)
+	}
+
+	page.last = len(profileIDs) < limit
+	if !page.last {
+		page.cursor = profileIDs[len(profileIDs)-1]
+	}
+
+	return page, nil
+}
+
+/*
+ResyncSave saves the progress of a resync once the events of a page have been
+emitted. The resync is removed once its last page is saved. When the resync has
+been requested again in the meantime, the progress is not saved so the resync
+starts over.
+*/
+func (s *Store) ResyncSave(page *ResyncPage) error {
+	var err error
+	if page.last {
+		_, err = s.env.DB.Exec(`
+			DELETE FROM fragment_audiences.resyncs
+			WHERE key = $1 AND requested_at = $2;
+		`, page.Key, page.requestedAt)
+	} else {
+		_, err = s.env.DB.Exec(`
+			UPDATE fragment_audiences.resyncs SET cursor = $3
+			WHERE key = $1 AND requested_at = $2;
+		`, page.Key, page.requestedAt, page.cursor)
+	}
+
+	if err != nil {
+		return failed("Failed to save resync", err)
+	}
+
+	return nil
+}
+
+/*
+definition returns the audience or the list given its key. Nil is returned if it
+does not exist.
+*/
+func (s *Store) definition(key string) (*definition, error) {
+	definitions, err := s.definitions()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, definition := range definitions {
+		if definition.key == key {
+			return definition, nil
+		}
+	}
+
+	return nil, nil
+}
+
+/*
+definition is an audience or a list which can be evaluated for a profile.
+*/
+type definition struct {
+	key    string
+	name   string
+	member func(f *profileFacts) (bool, error)
+}
+
+/*
+definitions returns the audiences defined in the options followed by the lists
+stored in the database.
+*/
+func (s *Store) definitions() ([]*definition, error) {
+	definitions := []*definition{}
+	for _, audience := range s.env.Audiences {
+		condition := audience.Condition
+		definitions = append(definitions, &definition{
+			key:  audience.Key,
+			name: audience.Name,
+			member: func(f *profileFacts) (bool, error) {
+				return condition.evaluate(f)
+			},
+		})
+	}
+
+	lists, err := s.Lists()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, list := range lists {
+		definitions = append(definitions, &definition{
+			key:    list.Key,
+			name:   list.Name,
+			member: list.member,
+		})
+	}
+
+	return definitions, nil
+}
+
+/*
+evaluation holds the details of why the definitions are evaluated for a profile.
+*/
+type evaluation struct {
+
+	// messageID is the ID of the message evaluated, if any. It is stored alongside
+	// the memberships changed by the message.
+	messageID string
+
+	// retried indicates the message has already been evaluated. The events of the
+	// memberships it changed are returned again.
+	retried bool
+
+	// resync indicates events must also be returned for the members whose
+	// membership did not change.
+	resync bool
+}
+
+/*
+evaluate evaluates the definitions for a profile and stores the memberships
+which changed.
+*/
+func (s *Store) evaluate(profileID string, userID string, anonymousID string, definitions []*definition, e evaluation) ([]analytics.Track, error) {
+	now := time.Now().UTC()
+	members, err := s.env.Identity.Members(profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.memberships(profileID, members)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &profileFacts{
+		store:   s,
+		members: members,
+		profile: profileID,
+		now:     now,
+		counts:  map[string]uint64{},
+	}
+
+	tracks := []analytics.Track{}
+	if e.retried && e.messageID != "" {
+		tracks, err = s.replay(members, e.messageID, userID, anonymousID, definitions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, definition := range definitions {
+		member, err := definition.member(f)
+		if err != nil {
+			return nil, err
+		}
+
+		changed := member != current[definition.key]
+		if !changed && !(e.resync && member) {
+			continue
+		}
+
+		var changeID string
+		if changed {
+			changeID, err = s.record(profileID, members, definition.key, member, e.messageID, now)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		tracks = append(tracks, membershipEvent(definition, member, changeID, userID, anonymousID, now))
+	}
+
+	// Add the email of the user to the context of the events so destinations
+	// identifying users by email can be synchronized.
+	if len(tracks) > 0 {
+		value, _, err := f.trait("email")
+		if err != nil {
+			return nil, err
+		}
+
+		if email, ok := value.(string); ok && email != "" {
+			for i := range tracks {
+				tracks[i].Context = &analytics.Context{
+					Traits: analytics.Traits{
+						"email": email,
+					},
+				}
+			}
+		}
+	}
+
+	return tracks, nil
+}
+
+/*
+membershipEvent returns the event to emit for the membership of a user in an
+audience. The ID of the change, if any, is used as message ID so a change emitted
+again can be deduplicated by the destinations.
+*/
+func membershipEvent(d *definition, member bool, changeID string, userID string, anonymousID string, at time.Time) analytics.Track {
+	event := EventExited
+	if member {
+		event = EventEntered
+	}
+
+	return analytics.Track{
+		MessageId:   changeID,
+		Event:       event,
+		UserId:      userID,
+		AnonymousId: anonymousID,
+		Timestamp:   at,
+		Properties: analytics.Properties{
+			"audience_key":  d.key,
+			"audience_name": d.name,
+		},
+	}
+}
+
+/*
+replay returns the events of the memberships changed by a message for a set of
+profiles merged together. Changes of audiences or lists no longer defined are
+ignored.
+*/
+func (s *Store) replay(members []string, messageID string, userID string, anonymousID string, definitions []*definition) ([]analytics.Track, error) {
+	rows, err := s.env.DB.Query(`
+		SELECT id, audience, change, created_at
+		FROM fragment_audiences.changes
+		WHERE message_id = $1 AND profile_id = ANY($2)
+		ORDER BY id ASC;
+	`, messageID, pq.Array(members))
+	if err != nil {
+		return nil, failed("Failed to find membership changes", err)
+	}
+
+	defined := map[string]*definition{}
+	for _, d := range definitions {
+		defined[d.key] = d
+	}
+
+	defer rows.Close()
+	tracks := []analytics.Track{}
+	for rows.Next() {
+		var id, audience, kind string
+		var at time.Time
+		if err := rows.Scan(&id, &audience, &kind, &at); err != nil {
+			return nil, failed("Failed to find membership changes", err)
+		}
+
+		if d, exists := defined[audience]; exists {
+			tracks = append(tracks, membershipEvent(d, kind == "entered", id, userID, anonymousID, at))
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, failed("Failed to find membership changes", err)
+	}
+
+	return tracks, nil
+}
+
+/*
+membership is the membership of a profile in an audience, as stored.
+*/
+type membership struct {
+	audience  string
+	profileID string
+	member    bool
+}
+
+/*
+memberships returns the audiences a set of profiles merged together is a member
+of, given the root profile of the set.
+*/
+func (s *Store) memberships(profileID string, members []string) (map[string]bool, error) {
+	rows, err := s.env.DB.Query(`
+		SELECT audience, profile_id, is_member
+		FROM fragment_audiences.memberships
+		WHERE profile_id = ANY($1);
+	`, pq.Array(members))
+	if err != nil {
+		return nil, failed("Failed to find memberships", err)
+	}
+
+	defer rows.Close()
+	stored := []membership{}
+	for rows.Next() {
+		var m membership
+		if err := rows.Scan(&m.audience, &m.profileID, &m.member); err != nil {
+			return nil, failed("Failed to find memberships", err)
+		}
+
+		stored = append(stored, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, failed("Failed to find memberships", err)
+	}
+
+	return current(profileID, stored), nil
+}
+
+/*
+current returns the current memberships of a set of profiles merged together.
+The membership of the root profile is the one last evaluated, so it always takes
+precedence. The memberships of the other profiles are only inherited by the root
+profile for the audiences it has not been evaluated against yet, so a merge does
+not emit "Audience Entered" again for an audience a merged profile was already
+a member of.
+*/
+func current(root string, stored []membership) map[string]bool {
+	memberships := map[string]bool{}
+	evaluated := map[string]bool{}
+	for _, m := range stored {
+		if m.profileID == root {
+			memberships[m.audience] = m.member
+			evaluated[m.audience] = true
+		}
+	}
+
+	for _, m := range stored {
+		if !evaluated[m.audience] && m.member {
+			memberships[m.audience] = true
+		}
+	}
+
+	return memberships
+}
+
+/*
+record stores the membership of a profile in an audience, and keeps track of the
+change alongside the ID of the message which caused it, if any. The memberships
+of the other profiles merged into the profile are removed, so the stored
+memberships match the one evaluated. It returns the ID of the change.
+*/
+func (s *Store) record(profileID string, members []string, audience string, member bool, messageID string, at time.Time) (string, error) {
+	tx, err := s.env.DB.Begin()
+	if err != nil {
+		return "", failed("Failed to update membership", err)
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		DELETE FROM fragment_audiences.memberships
+		WHERE audience = $1 AND profile_id = ANY($2) AND profile_id <> $3;
+	`, audience, pq.Array(members), profileID)
+	if err != nil {
+		return "", failed("Failed to update membership", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO fragment_audiences.memberships (audience, profile_id, is_member, entered_at, exited_at, updated_at)
+		VALUES ($1, $2, $3, CASE WHEN $3 THEN $4::TIMESTAMP END, CASE WHEN NOT $3 THEN $4::TIMESTAMP END, $4)
+		ON CONFLICT (audience, profile_id) DO UPDATE SET
+			is_member = EXCLUDED.is_member,
+			entered_at = COALESCE(EXCLUDED.entered_at, memberships.entered_at),
+			exited_at = COALESCE(EXCLUDED.exited_at, memberships.exited_at),
+			updated_at = EXCLUDED.updated_at;
+	`, audience, profileID, member, at)
+	if err != nil {
+		return "", failed("Failed to update membership", err)
+	}
+
+	change := "exited"
+	if member {
+		change = "entered"
+	}
+
+	id := ksuid.New().String()
+	_, err = tx.Exec(`
+		INSERT INTO fragment_audiences.changes (id, audience, profile_id, change, message_id, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6);
+	`, id, audience, profileID, change, messageID, at)
+	if err != nil {
+		return "", failed("Failed to log membership change", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", failed("Failed to update membership", err)
+	}
+
+	return id, nil
+}
+
+/*
+day returns the day of a timestamp in UTC, as expected by PostgreSQL for dates.
+*/
+func day(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+/*
+failed returns a normalized error for the audiences.
+*/
+func failed(message string, err error) error {
+	return &errors.Error{
+		Message: "audiences: " + message,
+		Validations: []errors.Validation{
+			{
+				Message: err.Error(),
+			},
+		},
+	}
+}
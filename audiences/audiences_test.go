@@ -0,0 +1,110 @@
+package audiences
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+func TestCurrent(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored []membership
+		want   map[string]bool
+	}{
+		{
+			name: "WithRootOnly",
+			stored: []membership{
+				{audience: "cart_abandoners", profileID: "root", member: true},
+			},
+			want: map[string]bool{
+				"cart_abandoners": true,
+			},
+		},
+		{
+			name: "WithMergedProfileNotEvaluated",
+			stored: []membership{
+				{audience: "cart_abandoners", profileID: "merged", member: true},
+			},
+			want: map[string]bool{
+				"cart_abandoners": true,
+			},
+		},
+		{
+			name: "WithMergedProfilesAndRootExited",
+			stored: []membership{
+				{audience: "cart_abandoners", profileID: "merged", member: true},
+				{audience: "cart_abandoners", profileID: "root", member: false},
+				{audience: "big_spenders", profileID: "merged", member: false},
+				{audience: "big_spenders", profileID: "other", member: true},
+			},
+			want: map[string]bool{
+				"cart_abandoners": false,
+				"big_spenders":    true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := current("root", tt.stored); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("current() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMembershipEvent(t *testing.T) {
+	at := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	d := &definition{
+		key:  "cart_abandoners",
+		name: "Cart Abandoners",
+	}
+
+	tests := []struct {
+		name     string
+		member   bool
+		changeID string
+		want     analytics.Track
+	}{
+		{
+			name:     "WithEntered",
+			member:   true,
+			changeID: "2GwBOsRflvHgMm3Hv4E1MGUYDkV",
+			want: analytics.Track{
+				MessageId:   "2GwBOsRflvHgMm3Hv4E1MGUYDkV",
+				Event:       EventEntered,
+				UserId:      "user",
+				AnonymousId: "anonymous",
+				Timestamp:   at,
+				Properties: analytics.Properties{
+					"audience_key":  "cart_abandoners",
+					"audience_name": "Cart Abandoners",
+				},
+			},
+		},
+		{
+			name:   "WithExitedWithoutChange",
+			member: false,
+			want: analytics.Track{
+				Event:       EventExited,
+				UserId:      "user",
+				AnonymousId: "anonymous",
+				Timestamp:   at,
+				Properties: analytics.Properties{
+					"audience_key":  "cart_abandoners",
+					"audience_name": "Cart Abandoners",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := membershipEvent(d, tt.member, tt.changeID, "user", "anonymous", at)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("membershipEvent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,403 @@
+package audiences
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+)
+
+/*
+Operator is a custom type allowing the user to only pass supported operators
+when comparing traits.
+*/
+type Operator string
+
+/*
+OperatorExists is used to check if a trait is set.
+*/
+var OperatorExists Operator = "exists"
+
+/*
+OperatorEqual is used to check if a trait is equal to a value.
+*/
+var OperatorEqual Operator = "eq"
+
+/*
+OperatorNotEqual is used to check if a trait is not equal to a value.
+*/
+var OperatorNotEqual Operator = "neq"
+
+/*
+OperatorGreater is used to check if a numeric trait is greater than a value.
+*/
+var OperatorGreater Operator = "gt"
+
+/*
+OperatorGreaterOrEqual is used to check if a numeric trait is greater than or
+equal to a value.
+*/
+var OperatorGreaterOrEqual Operator = "gte"
+
+/*
+OperatorLess is used to check if a numeric trait is less than a value.
+*/
+var OperatorLess Operator = "lt"
+
+/*
+OperatorLessOrEqual is used to check if a numeric trait is less than or equal to
+a value.
+*/
+var OperatorLessOrEqual Operator = "lte"
+
+/*
+OperatorContains is used to check if a string trait contains a value.
+*/
+var OperatorContains Operator = "contains"
+
+/*
+Condition is a boolean condition over the traits, the computed traits, and the
+events of a profile. Exactly one of its fields must be set.
+
+Example for users who viewed their cart in the last 7 days without completing
+an order:
+
+	&audiences.Condition{
+	  And: []*audiences.Condition{
+	    {Event: &audiences.EventCondition{Name: "Cart Viewed", Within: 7 * 24 * time.Hour}},
+	    {Not: &audiences.Condition{
+	      Event: &audiences.EventCondition{Name: "Order Completed", Within: 7 * 24 * time.Hour},
+	    }},
+	  },
+	}
+*/
+type Condition struct {
+
+	// And is satisfied when all of its conditions are satisfied.
+	And []*Condition
+
+	// Or is satisfied when at least one of its conditions is satisfied.
+	Or []*Condition
+
+	// Not is satisfied when its condition is not satisfied.
+	Not *Condition
+
+	// Trait is a condition over a trait or a computed trait of the profile.
+	Trait *TraitCondition
+
+	// Event is a condition over the occurrences of an event of the profile.
+	Event *EventCondition
+}
+
+/*
+TraitCondition is a condition over a trait or a computed trait of a profile.
+*/
+type TraitCondition struct {
+
+	// Name is the key of the trait.
+	//
+	// Required.
+	Name string `json:"name"`
+
+	// Operator is the operator used to compare the trait with the value.
+	//
+	// Required.
+	Operator Operator `json:"operator"`
+
+	// Value is the value to compare the trait with. It is not used by the operator
+	// OperatorExists.
+	Value interface{} `json:"value,omitempty"`
+}
+
+/*
+EventCondition is a condition over the occurrences of a "track" event of a
+profile.
+*/
+type EventCondition struct {
+
+	// Name is the name of the "track" event.
+	//
+	// Required.
+	Name string
+
+	// Within is the rolling window of the event occurrences. It is rounded up to
+	// days. When zero, every occurrences since the profile creation are counted.
+	Within time.Duration
+
+	// AtLeast is the minimum number of occurrences for the condition to be
+	// satisfied.
+	//
+	// Defaults to 1.
+	AtLeast uint64
+}
+
+/*
+days returns the number of days covered by the rolling window of the condition.
+It returns 0 if the condition has no window.
+*/
+func (c *EventCondition) days() int {
+	if c.Within <= 0 {
+		return 0
+	}
+
+	days := int(c.Within / (24 * time.Hour))
+	if c.Within%(24*time.Hour) != 0 {
+		days++
+	}
+
+	return days
+}
+
+/*
+facts gives access to the data of a profile needed to evaluate a condition.
+*/
+type facts interface {
+	trait(name string) (interface{}, bool, error)
+	occurrences(event string, days int) (uint64, error)
+}
+
+/*
+evaluate returns if the condition is satisfied given the facts of a profile.
+*/
+func (c *Condition) evaluate(f facts) (bool, error) {
+	switch {
+	case c.And != nil:
+		for _, sub := range c.And {
+			ok, err := sub.evaluate(f)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+
+		return true, nil
+
+	case c.Or != nil:
+		for _, sub := range c.Or {
+			ok, err := sub.evaluate(f)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+
+		return false, nil
+
+	case c.Not != nil:
+		ok, err := c.Not.evaluate(f)
+		return !ok, err
+
+	case c.Trait != nil:
+		value, exists, err := f.trait(c.Trait.Name)
+		if err != nil {
+			return false, err
+		}
+
+		return c.Trait.compare(value, exists), nil
+
+	case c.Event != nil:
+		count, err := f.occurrences(c.Event.Name, c.Event.days())
+		if err != nil {
+			return false, err
+		}
+
+		atLeast := c.Event.AtLeast
+		if atLeast == 0 {
+			atLeast = 1
+		}
+
+		return count >= atLeast, nil
+	}
+
+	return false, nil
+}
+
+/*
+compare returns if a trait satisfies the condition.
+*/
+func (c *TraitCondition) compare(value interface{}, exists bool) bool {
+	exists = exists && value != nil
+	switch c.Operator {
+	case OperatorExists:
+		return exists
+
+	case OperatorEqual:
+		return exists && fmt.Sprint(value) == fmt.Sprint(c.Value)
+
+	case OperatorNotEqual:
+		return !exists || fmt.Sprint(value) != fmt.Sprint(c.Value)
+
+	case OperatorContains:
+		s, ok := value.(string)
+		return exists && ok && strings.Contains(s, fmt.Sprint(c.Value))
+
+	case OperatorGreater, OperatorGreaterOrEqual, OperatorLess, OperatorLessOrEqual:
+		a, ok := number(value)
+		if !exists || !ok {
+			return false
+		}
+
+		b, ok := number(c.Value)
+		if !ok {
+			return false
+		}
+
+		switch c.Operator {
+		case OperatorGreater:
+			return a > b
+		case OperatorGreaterOrEqual:
+			return a >= b
+		case OperatorLess:
+			return a < b
+		case OperatorLessOrEqual:
+			return a <= b
+		}
+	}
+
+	return false
+}
+
+/*
+number returns the numeric representation of a value. Numbers sent as strings are
+also supported.
+*/
+func number(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+
+	return 0, false
+}
+
+/*
+events returns the events used by the condition, alongside the largest number of
+days they are looked up.
+*/
+func (c *Condition) events(events map[string]int) {
+	switch {
+	case c.And != nil:
+		for _, sub := range c.And {
+			sub.events(events)
+		}
+
+	case c.Or != nil:
+		for _, sub := range c.Or {
+			sub.events(events)
+		}
+
+	case c.Not != nil:
+		c.Not.events(events)
+
+	case c.Event != nil:
+		days, exists := events[c.Event.Name]
+		if !exists || (days != 0 && (c.Event.days() == 0 || c.Event.days() > days)) {
+			events[c.Event.Name] = c.Event.days()
+		}
+	}
+}
+
+/*
+validate ensures the condition is valid.
+*/
+func (c *Condition) validate(path []string) []errors.Validation {
+	validations := []errors.Validation{}
+	if c == nil {
+		return append(validations, errors.Validation{
+			Message: "Condition must not be nil",
+			Path:    path,
+		})
+	}
+
+	set := 0
+	if c.And != nil {
+		set++
+		for i, sub := range c.And {
+			validations = append(validations, sub.validate(at(path, "And", strconv.Itoa(i)))...)
+		}
+	}
+
+	if c.Or != nil {
+		set++
+		for i, sub := range c.Or {
+			validations = append(validations, sub.validate(at(path, "Or", strconv.Itoa(i)))...)
+		}
+	}
+
+	if c.Not != nil {
+		set++
+		validations = append(validations, c.Not.validate(at(path, "Not"))...)
+	}
+
+	if c.Trait != nil {
+		set++
+		if c.Trait.Name == "" {
+			validations = append(validations, errors.Validation{
+				Message: "Trait name must be set",
+				Path:    at(path, "Trait", "Name"),
+			})
+		}
+
+		switch c.Trait.Operator {
+		case OperatorExists, OperatorEqual, OperatorNotEqual, OperatorContains:
+		case OperatorGreater, OperatorGreaterOrEqual, OperatorLess, OperatorLessOrEqual:
+			if _, ok := number(c.Trait.Value); !ok {
+				validations = append(validations, errors.Validation{
+					Message: "Value must be a number for operator '" + string(c.Trait.Operator) + "'",
+					Path:    at(path, "Trait", "Value"),
+				})
+			}
+
+		default:
+			validations = append(validations, errors.Validation{
+				Message: "Operator must be one of 'exists', 'eq', 'neq', 'gt', 'gte', 'lt', 'lte', 'contains'",
+				Path:    at(path, "Trait", "Operator"),
+			})
+		}
+	}
+
+	if c.Event != nil {
+		set++
+		if c.Event.Name == "" {
+			validations = append(validations, errors.Validation{
+				Message: "Event name must be set",
+				Path:    at(path, "Event", "Name"),
+			})
+		}
+
+		if c.Event.Within < 0 {
+			validations = append(validations, errors.Validation{
+				Message: "Window must not be negative",
+				Path:    at(path, "Event", "Within"),
+			})
+		}
+	}
+
+	if set != 1 {
+		validations = append(validations, errors.Validation{
+			Message: "Condition must have exactly one of 'And', 'Or', 'Not', 'Trait', 'Event' set",
+			Path:    path,
+		})
+	}
+
+	return validations
+}
+
+/*
+at returns a copy of a path with the keys appended, so paths of sibling
+conditions never share the same underlying array.
+*/
+func at(path []string, keys ...string) []string {
+	p := make([]string, 0, len(path)+len(keys))
+	p = append(p, path...)
+	return append(p, keys...)
+}
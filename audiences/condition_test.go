@@ -0,0 +1,184 @@
+package audiences
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeFacts struct {
+	traits map[string]interface{}
+	counts map[string]uint64
+}
+
+func (f *fakeFacts) trait(name string) (interface{}, bool, error) {
+	value, exists := f.traits[name]
+	return value, exists, nil
+}
+
+func (f *fakeFacts) occurrences(event string, days int) (uint64, error) {
+	return f.counts[event], nil
+}
+
+func TestCondition_evaluate(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	abandoners := &Condition{
+		And: []*Condition{
+			{Event: &EventCondition{Name: "Cart Viewed", Within: week}},
+			{Not: &Condition{
+				Event: &EventCondition{Name: "Order Completed", Within: week},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		condition *Condition
+		facts     *fakeFacts
+		want      bool
+	}{
+		{
+			name:      "WithCartViewedOnly",
+			condition: abandoners,
+			facts: &fakeFacts{
+				counts: map[string]uint64{"Cart Viewed": 2},
+			},
+			want: true,
+		},
+		{
+			name:      "WithOrderCompleted",
+			condition: abandoners,
+			facts: &fakeFacts{
+				counts: map[string]uint64{"Cart Viewed": 2, "Order Completed": 1},
+			},
+			want: false,
+		},
+		{
+			name: "WithAtLeast",
+			condition: &Condition{
+				Event: &EventCondition{Name: "Order Completed", AtLeast: 3},
+			},
+			facts: &fakeFacts{
+				counts: map[string]uint64{"Order Completed": 2},
+			},
+			want: false,
+		},
+		{
+			name: "WithTraitOr",
+			condition: &Condition{
+				Or: []*Condition{
+					{Trait: &TraitCondition{Name: "plan", Operator: OperatorEqual, Value: "enterprise"}},
+					{Trait: &TraitCondition{Name: "lifetime_revenue", Operator: OperatorGreaterOrEqual, Value: 1000}},
+				},
+			},
+			facts: &fakeFacts{
+				traits: map[string]interface{}{"plan": "free", "lifetime_revenue": float64(1200)},
+			},
+			want: true,
+		},
+		{
+			name: "WithMissingTrait",
+			condition: &Condition{
+				Trait: &TraitCondition{Name: "plan", Operator: OperatorNotEqual, Value: "free"},
+			},
+			facts: &fakeFacts{},
+			want:  true,
+		},
+		{
+			name: "WithContains",
+			condition: &Condition{
+				Trait: &TraitCondition{Name: "email", Operator: OperatorContains, Value: "@example.com"},
+			},
+			facts: &fakeFacts{
+				traits: map[string]interface{}{"email": "johndoe@example.com"},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.condition.evaluate(tt.facts)
+			if err != nil {
+				t.Fatalf("Condition.evaluate() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Condition.evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCondition_events(t *testing.T) {
+	condition := &Condition{
+		Or: []*Condition{
+			{Event: &EventCondition{Name: "Cart Viewed", Within: 7 * 24 * time.Hour}},
+			{Event: &EventCondition{Name: "Cart Viewed", Within: 36 * time.Hour}},
+			{Event: &EventCondition{Name: "Order Completed", Within: 24 * time.Hour}},
+			{Event: &EventCondition{Name: "Order Completed"}},
+		},
+	}
+
+	want := map[string]int{
+		"Cart Viewed":     7,
+		"Order Completed": 0,
+	}
+
+	events := map[string]int{}
+	condition.events(events)
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("Condition.events() = %v, want %v", events, want)
+	}
+}
+
+func TestCondition_validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition *Condition
+		wantErr   bool
+	}{
+		{
+			name:      "WithNilCondition",
+			condition: nil,
+			wantErr:   true,
+		},
+		{
+			name:      "WithEmptyCondition",
+			condition: &Condition{},
+			wantErr:   true,
+		},
+		{
+			name: "WithSeveralFields",
+			condition: &Condition{
+				Not:   &Condition{Event: &EventCondition{Name: "Order Completed"}},
+				Event: &EventCondition{Name: "Cart Viewed"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithNonNumericValue",
+			condition: &Condition{
+				Trait: &TraitCondition{Name: "age", Operator: OperatorGreater, Value: "old"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithValidTree",
+			condition: &Condition{
+				And: []*Condition{
+					{Trait: &TraitCondition{Name: "plan", Operator: OperatorExists}},
+					{Event: &EventCondition{Name: "Cart Viewed", Within: time.Hour}},
+				},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validations := tt.condition.validate([]string{"Condition"})
+			if (len(validations) > 0) != tt.wantErr {
+				t.Errorf("Condition.validate() = %v, wantErr %v", validations, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package audiences
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/nunchistudio/fragment/identity"
+	"github.com/nunchistudio/fragment/profiles"
+
+	"github.com/lib/pq"
+)
+
+/*
+profileFacts gives access to the facts of a profile stored in the database. The
+traits and event occurrences are only loaded when needed by a condition, and are
+cached for evaluating every audiences.
+*/
+type profileFacts struct {
+	store    *Store
+	members  []string
+	profile  string
+	now      time.Time
+	traits   map[string]*profiles.Trait
+	counts   map[string]uint64
+	hasCache bool
+	ids      []*identity.Identifier
+}
+
+/*
+trait returns the value of a trait of the profile.
+*/
+func (f *profileFacts) trait(name string) (interface{}, bool, error) {
+	if !f.hasCache {
+		traits, err := f.store.env.Profiles.Traits(f.profile)
+		if err != nil {
+			return nil, false, err
+		}
+
+		f.traits = traits
+		f.hasCache = true
+	}
+
+	trait, exists := f.traits[name]
+	if !exists {
+		return nil, false, nil
+	}
+
+	return trait.Value, true, nil
+}
+
+/*
+occurrences returns the number of occurrences of an event for the profile within
+a number of days, including the current one. When days is 0, every occurrences
+are counted.
+*/
+func (f *profileFacts) occurrences(event string, days int) (uint64, error) {
+	key := event + ":" + strconv.Itoa(days)
+	if count, exists := f.counts[key]; exists {
+		return count, nil
+	}
+
+	var since interface{}
+	if days > 0 {
+		since = day(f.now.AddDate(0, 0, 1-days))
+	}
+
+	var count uint64
+	err := f.store.env.DB.QueryRow(`
+		SELECT COALESCE(SUM(count), 0) FROM fragment_audiences.occurrences
+		WHERE profile_id = ANY($1) AND event = $2
+		AND ($3::DATE IS NULL OR day >= $3::DATE);
+	`, pq.Array(f.members), event, since).Scan(&count)
+	if err != nil {
+		return 0, failed("Failed to count events", err)
+	}
+
+	f.counts[key] = count
+	return count, nil
+}
+
+/*
+identifiers returns the identifiers of the profile and of every profiles merged
+into it.
+*/
+func (f *profileFacts) identifiers() ([]*identity.Identifier, error) {
+	if f.ids != nil {
+		return f.ids, nil
+	}
+
+	ids := []*identity.Identifier{}
+	for _, member := range f.members {
+		found, err := f.store.env.Identity.Identifiers(member)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, found...)
+	}
+
+	f.ids = ids
+	return ids, nil
+}
@@ -0,0 +1,327 @@
+package audiences
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/nunchistudio/fragment/identity"
+
+	"github.com/lib/pq"
+)
+
+/*
+ListsTTL is the duration the lists are kept in memory before being loaded again
+from the database.
+*/
+var ListsTTL = time.Minute
+
+/*
+List is a named list of users stored in the database. Unlike audiences defined in
+the options, lists can be created and updated at runtime. A list is either defined
+by a condition over a trait, or by a list of uploaded identifiers.
+
+Memberships of lists are handled the same way as memberships of audiences: an
+"Audience Entered" or "Audience Exited" event is returned every time a profile
+enters or exits a list.
+*/
+type List struct {
+
+	// Key is the unique key of the list. It must not be used by an audience.
+	//
+	// Required.
+	Key string `json:"key"`
+
+	// Name is the human readable name of the list.
+	//
+	// Defaults to the key.
+	Name string `json:"name,omitempty"`
+
+	// Trait is the condition over a trait or a computed trait a profile must
+	// satisfy to be part of the list.
+	Trait *TraitCondition `json:"condition,omitempty"`
+
+	// IDs is the list of identifiers, such as user IDs or emails, of the users
+	// part of the list.
+	IDs []identity.Identifier `json:"ids,omitempty"`
+
+	// ids is the set of identifiers built from IDs, used to check the membership
+	// of a profile.
+	ids map[string]bool
+}
+
+/*
+lists holds the lists loaded from the database.
+*/
+type lists struct {
+	sync.Mutex
+	loaded   []*List
+	loadedAt time.Time
+}
+
+/*
+expire ensures the lists are loaded again from the database on next use.
+*/
+func (l *lists) expire() {
+	l.Lock()
+	defer l.Unlock()
+
+	l.loadedAt = time.Time{}
+}
+
+/*
+validate ensures the list is valid.
+*/
+func (l *List) validate(audiences []*Audience) error {
+	fail := &errors.Error{
+		StatusCode:  400,
+		Message:     "audiences: Failed to save list",
+		Validations: []errors.Validation{},
+	}
+
+	path := []string{"List"}
+	if l.Key == "" {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "List key must be set",
+			Path:    at(path, "Key"),
+		})
+	}
+
+	for _, audience := range audiences {
+		if audience.Key == l.Key {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Key '" + l.Key + "' is already used by an audience",
+				Path:    at(path, "Key"),
+			})
+		}
+	}
+
+	if l.Name == "" {
+		l.Name = l.Key
+	}
+
+	switch {
+	case l.Trait != nil && len(l.IDs) > 0:
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "List must either have a condition or identifiers",
+			Path:    path,
+		})
+
+	case l.Trait != nil:
+		condition := &Condition{Trait: l.Trait}
+		fail.Validations = append(fail.Validations, condition.validate(at(path, "Condition"))...)
+
+	case len(l.IDs) == 0:
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "List must have a condition or identifiers",
+			Path:    path,
+		})
+	}
+
+	for _, id := range l.IDs {
+		if id.Type == "" || id.Value == "" {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Identifiers must have a type and an ID",
+				Path:    at(path, "IDs"),
+			})
+
+			break
+		}
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
+
+/*
+index builds the set of identifiers of the list.
+*/
+func (l *List) index() {
+	l.ids = map[string]bool{}
+	for _, id := range l.IDs {
+		l.ids[id.Type+":"+id.Value] = true
+	}
+}
+
+/*
+member returns if a profile is part of the list given its facts.
+*/
+func (l *List) member(f *profileFacts) (bool, error) {
+	if l.Trait != nil {
+		value, exists, err := f.trait(l.Trait.Name)
+		if err != nil {
+			return false, err
+		}
+
+		return l.Trait.compare(value, exists), nil
+	}
+
+	ids, err := f.identifiers()
+	if err != nil {
+		return false, err
+	}
+
+	for _, id := range ids {
+		if l.ids[id.Type+":"+id.Value] {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+/*
+SaveList creates or replaces a list. The memberships are not updated: Resync must
+be called for the changes to apply to every profiles.
+*/
+func (s *Store) SaveList(list *List) error {
+	if err := list.validate(s.env.Audiences); err != nil {
+		return err
+	}
+
+	var condition []byte
+	if list.Trait != nil {
+		condition, _ = json.Marshal(list.Trait)
+	}
+
+	types := make([]string, len(list.IDs))
+	values := make([]string, len(list.IDs))
+	for i, id := range list.IDs {
+		types[i] = id.Type
+		values[i] = id.Value
+	}
+
+	tx, err := s.env.DB.Begin()
+	if err != nil {
+		return failed("Failed to save list", err)
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO fragment_audiences.lists (key, name, condition, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		ON CONFLICT (key) DO UPDATE SET
+			name = EXCLUDED.name,
+			condition = EXCLUDED.condition,
+			updated_at = EXCLUDED.updated_at;
+	`, list.Key, list.Name, nullable(condition))
+	if err != nil {
+		return failed("Failed to save list", err)
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM fragment_audiences.list_ids WHERE list = $1;
+	`, list.Key)
+	if err != nil {
+		return failed("Failed to save list", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO fragment_audiences.list_ids (list, type, value)
+		SELECT $1, UNNEST($2::TEXT[]), UNNEST($3::TEXT[])
+		ON CONFLICT DO NOTHING;
+	`, list.Key, pq.Array(types), pq.Array(values))
+	if err != nil {
+		return failed("Failed to save list", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return failed("Failed to save list", err)
+	}
+
+	s.lists.expire()
+	return nil
+}
+
+/*
+Lists returns the lists stored in the database. They are kept in memory for the
+duration of ListsTTL.
+*/
+func (s *Store) Lists() ([]*List, error) {
+	s.lists.Lock()
+	defer s.lists.Unlock()
+
+	if time.Since(s.lists.loadedAt) < ListsTTL {
+		return s.lists.loaded, nil
+	}
+
+	rows, err := s.env.DB.Query(`
+		SELECT key, name, condition FROM fragment_audiences.lists
+		ORDER BY key ASC;
+	`)
+	if err != nil {
+		return nil, failed("Failed to find lists", err)
+	}
+
+	loaded := []*List{}
+	keys := map[string]*List{}
+	for rows.Next() {
+		list := &List{}
+		var condition []byte
+		if err := rows.Scan(&list.Key, &list.Name, &condition); err != nil {
+			rows.Close()
+			return nil, failed("Failed to find lists", err)
+		}
+
+		if condition != nil {
+			list.Trait = &TraitCondition{}
+			if err := json.Unmarshal(condition, list.Trait); err != nil {
+				rows.Close()
+				return nil, failed("Failed to decode list condition", err)
+			}
+		}
+
+		loaded = append(loaded, list)
+		keys[list.Key] = list
+	}
+
+	rows.Close()
+	rows, err = s.env.DB.Query(`
+		SELECT list, type, value FROM fragment_audiences.list_ids;
+	`)
+	if err != nil {
+		return nil, failed("Failed to find list identifiers", err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var key string
+		id := identity.Identifier{}
+		if err := rows.Scan(&key, &id.Type, &id.Value); err != nil {
+			return nil, failed("Failed to find list identifiers", err)
+		}
+
+		if list, exists := keys[key]; exists {
+			list.IDs = append(list.IDs, id)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, failed("Failed to find list identifiers", err)
+	}
+
+	for _, list := range loaded {
+		list.index()
+	}
+
+	s.lists.loaded = loaded
+	s.lists.loadedAt = time.Now()
+	return loaded, nil
+}
+
+/*
+nullable returns nil for an empty JSON value so it is stored as NULL.
+*/
+func nullable(value []byte) interface{} {
+	if len(value) == 0 {
+		return nil
+	}
+
+	return value
+}
@@ -0,0 +1,133 @@
+package audiences
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/fragment/identity"
+)
+
+func TestList_validate(t *testing.T) {
+	audiences := []*Audience{
+		{Key: "cart_abandoners"},
+	}
+
+	tests := []struct {
+		name    string
+		list    *List
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyList",
+			list:    &List{},
+			wantErr: true,
+		},
+		{
+			name: "WithTraitCondition",
+			list: &List{
+				Key:   "enterprise",
+				Trait: &TraitCondition{Name: "plan", Operator: OperatorEqual, Value: "enterprise"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithIDs",
+			list: &List{
+				Key: "beta_testers",
+				IDs: []identity.Identifier{
+					{Type: "email", Value: "john@example.com"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithTraitConditionAndIDs",
+			list: &List{
+				Key:   "beta_testers",
+				Trait: &TraitCondition{Name: "plan", Operator: OperatorExists},
+				IDs: []identity.Identifier{
+					{Type: "email", Value: "john@example.com"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithInvalidID",
+			list: &List{
+				Key: "beta_testers",
+				IDs: []identity.Identifier{
+					{Value: "john@example.com"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithAudienceKey",
+			list: &List{
+				Key: "cart_abandoners",
+				IDs: []identity.Identifier{
+					{Type: "email", Value: "john@example.com"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.list.validate(audiences); (err != nil) != tt.wantErr {
+				t.Errorf("List.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestList_member(t *testing.T) {
+	tests := []struct {
+		name  string
+		list  *List
+		facts *profileFacts
+		want  bool
+	}{
+		{
+			name: "WithIDFound",
+			list: &List{
+				IDs: []identity.Identifier{
+					{Type: "email", Value: "john@example.com"},
+				},
+			},
+			facts: &profileFacts{
+				ids: []*identity.Identifier{
+					{Type: identity.TypeUserID, Value: "42"},
+					{Type: "email", Value: "john@example.com"},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "WithIDNotFound",
+			list: &List{
+				IDs: []identity.Identifier{
+					{Type: identity.TypeUserID, Value: "john@example.com"},
+				},
+			},
+			facts: &profileFacts{
+				ids: []*identity.Identifier{
+					{Type: "email", Value: "john@example.com"},
+				},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.list.index()
+			got, err := tt.list.member(tt.facts)
+			if err != nil {
+				t.Fatalf("List.member() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("List.member() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package audiences
+
+import (
+	"database/sql"
+	"strconv"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/nunchistudio/fragment/identity"
+	"github.com/nunchistudio/fragment/profiles"
+)
+
+/*
+Audience is the definition of an audience.
+*/
+type Audience struct {
+
+	// Key is the unique key of the audience. It is sent in the properties of the
+	// "Audience Entered" and "Audience Exited" events as "audience_key".
+	//
+	// Example: "cart_abandoners"
+	//
+	// Required.
+	Key string
+
+	// Name is the human readable name of the audience. It is sent in the properties
+	// of the "Audience Entered" and "Audience Exited" events as "audience_name".
+	//
+	// Example: "Cart Abandoners"
+	Name string
+
+	// Condition is the condition a profile must satisfy to be part of the audience.
+	//
+	// Required.
+	Condition *Condition
+}
+
+/*
+Options is the options the audiences can take as an input to be configured.
+*/
+type Options struct {
+
+	// DB is the PostgreSQL database connection where the memberships and event
+	// occurrences are stored. The tables are created by the migration
+	// "init_audiences".
+	//
+	// Required.
+	DB *sql.DB
+
+	// Identity is the identity graph used to find the profiles.
+	//
+	// Required.
+	Identity *identity.Graph
+
+	// Profiles is the profile store used to read the traits and computed traits
+	// of the profiles.
+	//
+	// Required.
+	Profiles *profiles.Store
+
+	// Audiences is the list of audiences to evaluate.
+	Audiences []*Audience
+}
+
+/*
+validate ensures the options passed to initialize the audiences are valid.
+*/
+func (env *Options) validate() error {
+	fail := &errors.Error{
+		Message:     "audiences: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Audiences"},
+		})
+
+		return fail
+	}
+
+	if env.DB == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Database connection must be set",
+			Path:    []string{"Options", "Audiences", "DB"},
+		})
+	}
+
+	if env.Identity == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Identity graph must be set",
+			Path:    []string{"Options", "Audiences", "Identity"},
+		})
+	}
+
+	if env.Profiles == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Profile store must be set",
+			Path:    []string{"Options", "Audiences", "Profiles"},
+		})
+	}
+
+	keys := map[string]bool{}
+	for i, audience := range env.Audiences {
+		path := []string{"Options", "Audiences", "Audiences", strconv.Itoa(i)}
+		if audience == nil {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Audience must not be nil",
+				Path:    path,
+			})
+
+			continue
+		}
+
+		if audience.Key == "" {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Audience key must be set",
+				Path:    at(path, "Key"),
+			})
+		} else if keys[audience.Key] {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Audience '" + audience.Key + "' is defined more than once",
+				Path:    at(path, "Key"),
+			})
+		}
+
+		keys[audience.Key] = true
+		if audience.Name == "" {
+			audience.Name = audience.Key
+		}
+
+		fail.Validations = append(fail.Validations, audience.Condition.validate(at(path, "Condition"))...)
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package audiences
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/nunchistudio/fragment/identity"
+	"github.com/nunchistudio/fragment/profiles"
+)
+
+func TestOptions_validate(t *testing.T) {
+	condition := &Condition{
+		Event: &EventCondition{Name: "Cart Viewed"},
+	}
+
+	tests := []struct {
+		name    string
+		fields  *Options
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: true,
+		},
+		{
+			name: "WithRequiredOptions",
+			fields: &Options{
+				DB:       &sql.DB{},
+				Identity: &identity.Graph{},
+				Profiles: &profiles.Store{},
+				Audiences: []*Audience{
+					{Key: "cart_viewers", Condition: condition},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithDuplicateAudiences",
+			fields: &Options{
+				DB:       &sql.DB{},
+				Identity: &identity.Graph{},
+				Profiles: &profiles.Store{},
+				Audiences: []*Audience{
+					{Key: "cart_viewers", Condition: condition},
+					{Key: "cart_viewers", Condition: condition},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithoutCondition",
+			fields: &Options{
+				DB:       &sql.DB{},
+				Identity: &identity.Graph{},
+				Profiles: &profiles.Store{},
+				Audiences: []*Audience{
+					{Key: "cart_viewers"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
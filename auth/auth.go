@@ -0,0 +1,80 @@
+/*
+Package auth holds the helpers used to authenticate the requests made against
+the endpoints exposed by Fragment, such as the profile API or the ingestion
+endpoints.
+
+Keys are compared in constant time, so they can not be guessed by measuring the
+time taken to reject a request.
+*/
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+)
+
+/*
+Valid returns if a key is one of the keys allowed. An empty key is never valid.
+*/
+func Valid(key string, keys []string) bool {
+	if key == "" {
+		return false
+	}
+
+	valid := false
+	for _, k := range keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(k)) == 1 {
+			valid = true
+		}
+	}
+
+	return valid
+}
+
+/*
+Bearer returns the token passed in the "Authorization" header of a request with
+the "Bearer" scheme. An empty string is returned if no token is present.
+*/
+func Bearer(req *http.Request) string {
+	return Token(req.Header.Get("Authorization"))
+}
+
+/*
+Token returns the token of an authorization value with the "Bearer" scheme, such
+as the value of an HTTP header or of gRPC metadata. An empty string is returned
+if no token is present.
+*/
+func Token(header string) string {
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return ""
+	}
+
+	return strings.TrimSpace(header[7:])
+}
+
+/*
+Unauthorized returns the error returned when a request is not authenticated, such
+as by the triggers of the sources.
+*/
+func Unauthorized() *errors.Error {
+	return &errors.Error{
+		StatusCode: 401,
+		Message:    "Unauthorized",
+	}
+}
+
+/*
+ErrorUnauthorized handles HTTP 401 error responses.
+*/
+func ErrorUnauthorized(res http.ResponseWriter) {
+	body, _ := json.Marshal(Unauthorized())
+
+	res.Header().Set("Content-Type", "application/json")
+	res.Header().Set("WWW-Authenticate", "Bearer")
+	res.WriteHeader(http.StatusUnauthorized)
+	res.Write(body)
+}
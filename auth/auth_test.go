@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		keys []string
+		want bool
+	}{
+		{
+			name: "WithValidKey",
+			key:  "secret",
+			keys: []string{"other", "secret"},
+			want: true,
+		},
+		{
+			name: "WithUnknownKey",
+			key:  "unknown",
+			keys: []string{"secret"},
+			want: false,
+		},
+		{
+			name: "WithEmptyKey",
+			key:  "",
+			keys: []string{""},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Valid(tt.key, tt.keys); got != tt.want {
+				t.Errorf("Valid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBearer(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{
+			name:   "WithBearer",
+			header: "Bearer secret",
+			want:   "secret",
+		},
+		{
+			name:   "WithLowerCaseScheme",
+			header: "bearer secret",
+			want:   "secret",
+		},
+		{
+			name:   "WithBasic",
+			header: "Basic c2VjcmV0Og==",
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", tt.header)
+			if got := Bearer(req); got != tt.want {
+				t.Errorf("Bearer() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
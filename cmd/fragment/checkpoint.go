@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+/*
+checkpoint holds the progress of an import, so an interrupted import can be
+resumed without sending the same events twice. It is saved as a JSON file after
+every batch accepted by the gateway.
+*/
+type checkpoint struct {
+	path  string
+	Files map[string]*progress `json:"files"`
+}
+
+/*
+progress is the progress of the import of a file. Records is the number of
+records handled, either imported or rejected.
+*/
+type progress struct {
+	Records  int  `json:"records"`
+	Imported int  `json:"imported"`
+	Rejected int  `json:"rejected"`
+	Done     bool `json:"done"`
+}
+
+/*
+loadCheckpoint reads a checkpoint file. An empty checkpoint is returned if the
+file does not exist yet.
+*/
+func loadCheckpoint(path string) (*checkpoint, error) {
+	c := &checkpoint{
+		path:  path,
+		Files: map[string]*progress{},
+	}
+
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, err
+	}
+
+	if c.Files == nil {
+		c.Files = map[string]*progress{}
+	}
+
+	return c, nil
+}
+
+/*
+file returns the progress of a file, given its absolute path.
+*/
+func (c *checkpoint) file(path string) *progress {
+	p, exists := c.Files[path]
+	if !exists {
+		p = &progress{}
+		c.Files[path] = p
+	}
+
+	return p
+}
+
+/*
+save writes the checkpoint to a temporary file and renames it, so the checkpoint
+is never left half written.
+*/
+func (c *checkpoint) save() error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), c.path)
+}
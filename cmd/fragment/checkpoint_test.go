@@ -0,0 +1,29 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "import.checkpoint")
+
+	c, err := loadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("loadCheckpoint() error = %v", err)
+	}
+
+	c.file("/data/events.ndjson").Records = 42
+	if err := c.save(); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	c, err = loadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("loadCheckpoint() error = %v", err)
+	}
+
+	if got := c.file("/data/events.ndjson").Records; got != 42 {
+		t.Errorf("checkpoint records = %v, want %v", got, 42)
+	}
+}
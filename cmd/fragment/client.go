@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+/*
+gateway returns the URL of the gateway of the Fragment application.
+*/
+func gateway() string {
+	if url := os.Getenv("FRAGMENT_URL"); url != "" {
+		return strings.TrimSuffix(url, "/")
+	}
+
+	return "http://localhost:9090"
+}
+
+/*
+response is the response returned by the gateway.
+*/
+type response struct {
+	StatusCode  int          `json:"statusCode"`
+	Message     string       `json:"message"`
+	Validations []validation `json:"validations"`
+}
+
+/*
+validation is a validation error of a response.
+*/
+type validation struct {
+	Message string   `json:"message"`
+	Path    []string `json:"path"`
+}
+
+/*
+send sends a JSON payload to an endpoint of the gateway. It returns an error if
+the gateway did not accept the payload.
+*/
+func send(method string, url string, payload interface{}) (*response, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if secret := os.Getenv("FRAGMENT_API_SECRET"); secret != "" {
+		req.Header.Set("Authorization", "Bearer "+secret)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+	r := &response{
+		StatusCode: res.StatusCode,
+	}
+	json.NewDecoder(res.Body).Decode(r)
+
+	if res.StatusCode >= 300 {
+		return r, fmt.Errorf("%d %s", res.StatusCode, r.describe())
+	}
+
+	return r, nil
+}
+
+/*
+describe returns the message of a response alongside its validations, if any.
+*/
+func (r *response) describe() string {
+	message := r.Message
+	for _, validation := range r.Validations {
+		message += "\n  - " + validation.Message
+		if len(validation.Path) > 0 {
+			message += " (" + strings.Join(validation.Path, ".") + ")"
+		}
+	}
+
+	return message
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr bool
+	}{
+		{
+			name:    "WithSuccess",
+			status:  202,
+			body:    `{"statusCode":202,"message":"Accepted"}`,
+			wantErr: false,
+		},
+		{
+			name:    "WithValidations",
+			status:  400,
+			body:    `{"statusCode":400,"message":"Bad Request","validations":[{"message":"List key must be set","path":["List","Key"]}]}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				res.WriteHeader(tt.status)
+				res.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			r, err := send("PUT", server.URL, map[string]string{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if r.StatusCode != tt.status {
+				t.Errorf("send() status = %v, want %v", r.StatusCode, tt.status)
+			}
+		})
+	}
+}
+
+func TestSendWithSecret(t *testing.T) {
+	os.Setenv("FRAGMENT_API_SECRET", "secret")
+	defer os.Unsetenv("FRAGMENT_API_SECRET")
+
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer secret" {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		res.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	if _, err := send("POST", server.URL, map[string]string{}); err != nil {
+		t.Errorf("send() error = %v", err)
+	}
+}
@@ -0,0 +1,509 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nunchistudio/fragment/sources/rest"
+)
+
+/*
+library is the library set in the context of every imported message, so the
+imported events can be told apart from the ones sent live.
+*/
+var library = map[string]interface{}{
+	"name":    rest.ImportLibrary,
+	"version": "1",
+}
+
+/*
+realtime holds the destinations disabled by default for the imported messages,
+since historical events must not trigger alerts, messages to users, or real-time
+workflows.
+*/
+var realtime = []string{"Customer.io", "NATS", "Slack", "Webhook"}
+
+/*
+integrations is the integrations set on every imported message. It is a flag
+holding a comma-separated list of destinations to enable or disable, such as
+"Webhook=true,Mixpanel=false". Destinations not listed keep the value of the
+message.
+*/
+type integrations map[string]bool
+
+/*
+String returns the integrations as set with the flag.
+*/
+func (i integrations) String() string {
+	names := make([]string, 0, len(i))
+	for name := range i {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	for n, name := range names {
+		names[n] = name + "=" + strconv.FormatBool(i[name])
+	}
+
+	return strings.Join(names, ",")
+}
+
+/*
+Set sets the integrations listed in the value of the flag.
+*/
+func (i integrations) Set(value string) error {
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || name == "" {
+			return fmt.Errorf("%q must be set as <destination>=<true|false>", pair)
+		}
+
+		b, err := strconv.ParseBool(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return fmt.Errorf("%q must be set as <destination>=<true|false>", pair)
+		}
+
+		i[name] = b
+	}
+
+	return nil
+}
+
+/*
+bulkResponse is the response returned by the NDJSON endpoint of the gateway.
+*/
+type bulkResponse struct {
+	StatusCode int    `json:"statusCode"`
+	Message    string `json:"message"`
+	Data       *struct {
+		Lines    int `json:"lines"`
+		Accepted int `json:"accepted"`
+		Rejected int `json:"rejected"`
+		Results  []struct {
+			Line  int       `json:"line"`
+			Error *response `json:"error"`
+		} `json:"results"`
+		Truncated bool `json:"truncated"`
+	} `json:"data"`
+}
+
+/*
+importer imports the records of files into the gateway, in batches sent to the
+NDJSON endpoint of the source "rest".
+*/
+type importer struct {
+	url          string
+	key          string
+	batchSize    int
+	dryRun       bool
+	integrations integrations
+	checkpoint   *checkpoint
+	out          io.Writer
+
+	lines   [][]byte
+	records []int
+}
+
+/*
+importEvents imports historical events from CSV, NDJSON, or Segment archive
+files. Every record is validated the same way as the source "rest" before being
+sent to the gateway. The progress is saved in a checkpoint file, so running the
+same command again resumes an interrupted import.
+
+The real-time destinations are disabled for the imported messages, unless enabled
+with the flag -integrations. The gateway does not attach the imported messages
+to sessions nor evaluate audiences, and never sends them to Slack.
+*/
+func importEvents(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "Format of the files: csv, ndjson, or segment. Defaults to the extension of the files.")
+	mappingFile := flags.String("mapping", "", "Path to the JSON file mapping the CSV columns to the messages.")
+	checkpointFile := flags.String("checkpoint", "fragment-import.checkpoint", "Path to the checkpoint file saving the progress.")
+	batchSize := flags.Int("batch", 1000, "Number of records sent to the gateway per request.")
+	dryRun := flags.Bool("dry-run", false, "Validate the records without sending them.")
+	enabled := integrations{}
+	for _, name := range realtime {
+		enabled[name] = false
+	}
+
+	flags.Var(enabled, "integrations", "Comma-separated destinations to enable or disable for every record, such as \"Webhook=true\".")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: fragment import [flags] <file or directory>...")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return errors.New("import: at least one file or directory must be set")
+	}
+
+	if *batchSize <= 0 {
+		return errors.New("import: -batch must be positive")
+	}
+
+	var m *mapping
+	if *mappingFile != "" {
+		var err error
+		m, err = readMapping(*mappingFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	files, err := expand(flags.Args(), *format)
+	if err != nil {
+		return err
+	}
+
+	cp, err := loadCheckpoint(*checkpointFile)
+	if err != nil {
+		return fmt.Errorf("import: invalid checkpoint: %s", err)
+	}
+
+	imp := &importer{
+		url:          gateway() + "/api/v1/bulk",
+		key:          os.Getenv("FRAGMENT_IMPORT_KEY"),
+		batchSize:    *batchSize,
+		dryRun:       *dryRun,
+		integrations: enabled,
+		checkpoint:   cp,
+		out:          os.Stderr,
+	}
+
+	var imported, rejected int
+	for _, file := range files {
+		if file.format == "csv" && m == nil {
+			return fmt.Errorf("import: %s: -mapping must be set for CSV files", file.path)
+		}
+
+		p, err := imp.file(file.path, file.format, m)
+		if err != nil {
+			return err
+		}
+
+		imported += p.Imported
+		rejected += p.Rejected
+	}
+
+	if *dryRun {
+		fmt.Printf("Validated %d events from %d files, %d rejected.\n", imported, len(files), rejected)
+		return nil
+	}
+
+	fmt.Printf("Imported %d events from %d files, %d rejected.\n", imported, len(files), rejected)
+	return nil
+}
+
+/*
+input is a file to import alongside its format.
+*/
+type input struct {
+	path   string
+	format string
+}
+
+/*
+expand returns the files to import given the paths passed to the command, with
+their absolute path. Directories are walked, and their files are imported in
+lexical order. When no format is set, it is guessed from the extension of the
+files, and files with an unknown extension are skipped within directories.
+*/
+func expand(paths []string, format string) ([]input, error) {
+	files := []input{}
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			f := formatOf(abs, format)
+			if f == "" {
+				return nil, fmt.Errorf("import: %s: -format must be set for this extension", path)
+			}
+
+			files = append(files, input{path: abs, format: f})
+			continue
+		}
+
+		found := []input{}
+		err = filepath.Walk(abs, func(p string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+
+			if f := formatOf(p, format); f != "" {
+				found = append(found, input{path: p, format: f})
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		sort.Slice(found, func(i, j int) bool {
+			return found[i].path < found[j].path
+		})
+
+		files = append(files, found...)
+	}
+
+	return files, nil
+}
+
+/*
+formatOf returns the format of a file, either the one set or the one guessed
+from its extension. An empty string is returned if it can not be guessed.
+*/
+func formatOf(path string, format string) string {
+	if format != "" {
+		return format
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return "csv"
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	case ".gz":
+		return "segment"
+	}
+
+	return ""
+}
+
+/*
+file imports the records of a file, starting after the records already handled
+according to the checkpoint. It returns the progress of the file.
+*/
+func (imp *importer) file(path string, format string, m *mapping) (*progress, error) {
+	p := imp.checkpoint.file(path)
+	if p.Done {
+		fmt.Fprintf(imp.out, "%s: already imported, skipping.\n", path)
+		return p, nil
+	}
+
+	r, closer, err := open(path, format, m)
+	if err != nil {
+		return nil, err
+	}
+
+	defer closer()
+	last := p.Records
+	for {
+		rec, err := r.read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("import: %s: %s", path, err)
+		}
+
+		if rec.N <= p.Records {
+			continue
+		}
+
+		last = rec.N
+		line, fail := imp.validate(rec)
+		if fail != "" {
+			p.Rejected++
+			fmt.Fprintf(imp.out, "%s:%d: %s\n", path, rec.N, fail)
+			continue
+		}
+
+		imp.lines = append(imp.lines, line)
+		imp.records = append(imp.records, rec.N)
+		if len(imp.lines) >= imp.batchSize {
+			if err := imp.flush(path, p, last); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := imp.flush(path, p, last); err != nil {
+		return nil, err
+	}
+
+	p.Done = true
+	if err := imp.save(); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(imp.out, "%s: done, %d imported, %d rejected.\n", path, p.Imported, p.Rejected)
+	return p, nil
+}
+
+/*
+validate marks a record as imported, sets its integrations, and validates it with
+the source "rest". It returns the JSON line to send, or the reason why the record
+is rejected.
+*/
+func (imp *importer) validate(rec *record) ([]byte, string) {
+	if rec.Err != nil {
+		return nil, rec.Err.Error()
+	}
+
+	ctx, ok := rec.Message["context"].(map[string]interface{})
+	if !ok {
+		ctx = map[string]interface{}{}
+	}
+
+	ctx["library"] = library
+	rec.Message["context"] = ctx
+
+	enabled, ok := rec.Message["integrations"].(map[string]interface{})
+	if !ok {
+		enabled = map[string]interface{}{}
+	}
+
+	for name, value := range imp.integrations {
+		enabled[name] = value
+	}
+
+	rec.Message["integrations"] = enabled
+
+	b, err := json.Marshal(rec.Message)
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	if _, fail := rest.Decode(&rest.Options{}, b); fail != nil {
+		r := &response{
+			StatusCode: fail.StatusCode,
+			Message:    fail.Message,
+		}
+
+		for _, v := range fail.Validations {
+			r.Validations = append(r.Validations, validation{
+				Message: v.Message,
+				Path:    v.Path,
+			})
+		}
+
+		return nil, r.describe()
+	}
+
+	return b, ""
+}
+
+/*
+flush sends the pending lines to the gateway, and saves the progress of the file
+up to the last record read. When the gateway only handled part of the lines, the
+progress is saved up to the last line handled and an error is returned.
+*/
+func (imp *importer) flush(path string, p *progress, last int) error {
+	defer func() {
+		imp.lines = nil
+		imp.records = nil
+	}()
+
+	if len(imp.lines) > 0 && !imp.dryRun {
+		res, err := imp.upload()
+		if err != nil {
+			return fmt.Errorf("import: %s: %s", path, err)
+		}
+
+		for _, result := range res.Data.Results {
+			if result.Line < 1 || result.Line > len(imp.records) || result.Error == nil {
+				continue
+			}
+
+			fmt.Fprintf(imp.out, "%s:%d: %s\n", path, imp.records[result.Line-1], result.Error.describe())
+		}
+
+		if res.Data.Truncated {
+			fmt.Fprintf(imp.out, "%s: %d records rejected, only the first ones are listed.\n", path, res.Data.Rejected)
+		}
+
+		p.Imported += res.Data.Accepted
+		p.Rejected += res.Data.Rejected
+		if res.StatusCode >= 300 || res.Data.Lines < len(imp.lines) {
+			if res.Data.Lines > 0 {
+				p.Records = imp.records[res.Data.Lines-1]
+			}
+
+			if err := imp.save(); err != nil {
+				return err
+			}
+
+			return fmt.Errorf("import: %s: %d %s, stopped after record %d", path, res.StatusCode, res.Message, p.Records)
+		}
+	} else {
+		p.Imported += len(imp.lines)
+	}
+
+	p.Records = last
+	if err := imp.save(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(imp.out, "%s: %d records, %d imported, %d rejected.\n", path, p.Records, p.Imported, p.Rejected)
+	return nil
+}
+
+/*
+upload sends the pending lines to the NDJSON endpoint of the gateway,
+authenticated with the import key.
+*/
+func (imp *importer) upload() (*bulkResponse, error) {
+	body := bytes.Join(imp.lines, []byte("\n"))
+	req, err := http.NewRequest("POST", imp.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if imp.key != "" {
+		req.Header.Set("Authorization", "Bearer "+imp.key)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+	r := &bulkResponse{
+		StatusCode: res.StatusCode,
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(r); err != nil || r.Data == nil {
+		return nil, fmt.Errorf("%d %s", res.StatusCode, http.StatusText(res.StatusCode))
+	}
+
+	return r, nil
+}
+
+/*
+save saves the checkpoint, unless the import is a dry run.
+*/
+func (imp *importer) save() error {
+	if imp.dryRun {
+		return nil
+	}
+
+	if err := imp.checkpoint.save(); err != nil {
+		return fmt.Errorf("import: failed to save checkpoint: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImporter(t *testing.T) {
+	var received []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/v1/bulk" || req.Header.Get("Content-Type") != "application/x-ndjson" {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if req.Header.Get("Authorization") != "Bearer key" {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		lines := 0
+		scanner := bufio.NewScanner(req.Body)
+		for scanner.Scan() {
+			var message map[string]interface{}
+			json.Unmarshal(scanner.Bytes(), &message)
+			received = append(received, message)
+			lines++
+		}
+
+		fmt.Fprintf(res, `{"statusCode":200,"message":"Successful","data":{"lines":%d,"accepted":%d,"rejected":0,"results":[]}}`, lines, lines)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.ndjson")
+	lines := []string{
+		`{"type":"track","userId":"user","event":"A"}`,
+		`{"type":"track","userId":"user"}`,
+		`{"type":"track","userId":"user","event":"B"}`,
+		`{"type":"page","anonymousId":"anonymous","name":"Home","context":{"ip":"127.0.0.1"}}`,
+	}
+	os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+
+	cp, _ := loadCheckpoint(filepath.Join(dir, "import.checkpoint"))
+	cp.file(path).Records = 1
+
+	imp := &importer{
+		url:          server.URL + "/api/v1/bulk",
+		key:          "key",
+		batchSize:    1,
+		integrations: integrations{"Slack": false},
+		checkpoint:   cp,
+		out:          ioutil.Discard,
+	}
+
+	p, err := imp.file(path, "ndjson", nil)
+	if err != nil {
+		t.Fatalf("file() error = %v", err)
+	}
+
+	if p.Records != 4 || p.Imported != 2 || p.Rejected != 1 || !p.Done {
+		t.Errorf("file() progress = %+v", p)
+	}
+
+	if len(received) != 2 || received[0]["event"] != "B" {
+		t.Fatalf("file() sent = %v", received)
+	}
+
+	ctx := received[1]["context"].(map[string]interface{})
+	if ctx["ip"] != "127.0.0.1" || ctx["library"].(map[string]interface{})["name"] != "fragment-import" {
+		t.Errorf("file() context = %v", ctx)
+	}
+
+	if received[1]["integrations"].(map[string]interface{})["Slack"] != false {
+		t.Errorf("file() integrations = %v", received[1]["integrations"])
+	}
+
+	saved, _ := loadCheckpoint(cp.path)
+	if !saved.file(path).Done {
+		t.Errorf("file() did not save the checkpoint")
+	}
+
+	received = nil
+	if _, err := imp.file(path, "ndjson", nil); err != nil || len(received) != 0 {
+		t.Errorf("file() imported a file already done")
+	}
+}
+
+func TestExpand(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.gz", "a.gz", "README"} {
+		os.WriteFile(filepath.Join(dir, name), nil, 0644)
+	}
+
+	files, err := expand([]string{dir}, "")
+	if err != nil {
+		t.Fatalf("expand() error = %v", err)
+	}
+
+	if len(files) != 2 || filepath.Base(files[0].path) != "a.gz" || files[0].format != "segment" {
+		t.Errorf("expand() = %v", files)
+	}
+
+	if _, err := expand([]string{filepath.Join(dir, "README")}, ""); err == nil {
+		t.Errorf("expand() guessed the format of a file without extension")
+	}
+}
+
+func TestIntegrations_Set(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "WithDestinations",
+			value: "Webhook=true, Google Analytics 4=false",
+			want:  "Google Analytics 4=false,Slack=false,Webhook=true",
+		},
+		{
+			name:    "WithoutValue",
+			value:   "Webhook",
+			wantErr: true,
+		},
+		{
+			name:    "WithInvalidValue",
+			value:   "Webhook=yes",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := integrations{"Slack": false, "Webhook": false}
+			err := i.Set(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("integrations.Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && i.String() != tt.want {
+				t.Errorf("integrations.String() = %v, want %v", i.String(), tt.want)
+			}
+		})
+	}
+}
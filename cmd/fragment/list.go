@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+/*
+identifier is an identifier of a user uploaded as part of a list.
+*/
+type identifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+/*
+condition is the condition over a trait defining a list.
+*/
+type condition struct {
+	Name     string `json:"name"`
+	Operator string `json:"operator"`
+	Value    string `json:"value,omitempty"`
+}
+
+/*
+list creates or replaces a named list of users, either from a file of identifiers
+or from a condition over a trait. The memberships of the list are synchronized
+with the destinations once saved.
+*/
+func list(args []string) error {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	key := flags.String("key", "", "Key of the list.")
+	name := flags.String("name", "", "Human readable name of the list. Defaults to the key.")
+	file := flags.String("ids", "", "Path to a file holding one identifier per line.")
+	kind := flags.String("type", "email", "Type of the identifiers in the file, such as \"email\" or \"user_id\".")
+	trait := flags.String("trait", "", "Name of the trait defining the list.")
+	operator := flags.String("operator", "eq", "Operator used to compare the trait: exists, eq, neq, gt, gte, lt, lte, or contains.")
+	value := flags.String("value", "", "Value to compare the trait with.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *key == "" {
+		return errors.New("list: -key must be set")
+	}
+
+	if (*file == "") == (*trait == "") {
+		return errors.New("list: either -ids or -trait must be set")
+	}
+
+	payload := map[string]interface{}{
+		"key":  *key,
+		"name": *name,
+	}
+
+	if *trait != "" {
+		payload["condition"] = condition{
+			Name:     *trait,
+			Operator: *operator,
+			Value:    *value,
+		}
+	} else {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+		ids, err := readIDs(f, *kind)
+		if err != nil {
+			return err
+		}
+
+		payload["ids"] = ids
+	}
+
+	_, err := send("PUT", gateway()+"/v1/lists", payload)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("List %q saved and being synchronized.\n", *key)
+	return nil
+}
+
+/*
+readIDs reads one identifier per line. Empty lines and lines starting with "#"
+are ignored.
+*/
+func readIDs(r io.Reader, kind string) ([]identifier, error) {
+	ids := []identifier{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		ids = append(ids, identifier{
+			Type: kind,
+			ID:   line,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return nil, errors.New("list: no identifiers found")
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []identifier
+		wantErr bool
+	}{
+		{
+			name:  "WithIDs",
+			input: "john@example.com\n\n# Comment\n  jane@example.com  \n",
+			want: []identifier{
+				{Type: "email", ID: "john@example.com"},
+				{Type: "email", ID: "jane@example.com"},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "WithoutIDs",
+			input:   "\n# Comment\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readIDs(strings.NewReader(tt.input), "email")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readIDs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
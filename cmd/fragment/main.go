@@ -0,0 +1,68 @@
+/*
+Command fragment is the command-line interface of Fragment. It interacts with the
+gateway of a running Fragment application.
+
+Usage:
+
+	fragment <command> [flags]
+
+Commands:
+
+	resync  Send every memberships of an audience or a list to the destinations again.
+	list    Create or replace a named list of users.
+	import  Import historical events from CSV, NDJSON, or Segment archive files.
+
+The URL of the gateway is read from the environment variable "FRAGMENT_URL", and
+defaults to "http://localhost:9090". The secret authenticating the commands is
+read from the environment variable "FRAGMENT_API_SECRET", except for the command
+"import" which authenticates with the import key read from the environment
+variable "FRAGMENT_IMPORT_KEY".
+*/
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+/*
+commands holds the commands of the CLI, given their name.
+*/
+var commands = map[string]func(args []string) error{
+	"resync": resync,
+	"list":   list,
+	"import": importEvents,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	command, exists := commands[os.Args[1]]
+	if !exists {
+		fmt.Fprintf(os.Stderr, "fragment: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err := command(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "fragment: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+/*
+usage prints the usage of the CLI.
+*/
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage: fragment <command> [flags]
+
+Commands:
+  resync  Send every memberships of an audience or a list to the destinations again.
+  list    Create or replace a named list of users.
+  import  Import historical events from CSV, NDJSON, or Segment archive files.
+
+Run "fragment <command> -h" for the flags of a command.`)
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+/*
+record is a message read from a file to import. N is the number of the record
+within its file, starting at 1, so an import can be resumed from a checkpoint.
+Err is set when the record could not be parsed.
+*/
+type record struct {
+	N       int
+	Message map[string]interface{}
+	Err     error
+}
+
+/*
+reader reads the records of a file one at a time. It returns io.EOF once there
+is no more record to read.
+*/
+type reader interface {
+	read() (*record, error)
+}
+
+/*
+ndjsonReader reads a message per line. It is used for NDJSON files as well as
+the Segment archives, which are gzipped JSON lines. Empty lines are skipped but
+still counted.
+*/
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	n       int
+}
+
+/*
+newNDJSONReader returns a reader of NDJSON lines. Lines can be up to 1MB.
+*/
+func newNDJSONReader(r io.Reader) *ndjsonReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+
+	return &ndjsonReader{
+		scanner: scanner,
+	}
+}
+
+/*
+read returns the next non-empty line of the file.
+*/
+func (r *ndjsonReader) read() (*record, error) {
+	for r.scanner.Scan() {
+		r.n++
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		rec := &record{
+			N: r.n,
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber()
+		if err := decoder.Decode(&rec.Message); err != nil {
+			rec.Err = err
+		} else if rec.Message == nil {
+			rec.Err = errors.New("message must be a JSON object")
+		}
+
+		return rec, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+/*
+mapping describes how the columns of a CSV file are converted to messages. It is
+read from a JSON file:
+
+	{
+	  "type": "track",
+	  "columns": {
+	    "user": "userId",
+	    "name": "event",
+	    "date": "timestamp",
+	    "amount": "properties.revenue"
+	  },
+	  "kinds": {
+	    "properties.revenue": "number"
+	  }
+	}
+
+Columns maps the names of the columns to the paths of the values in the messages,
+using dots for nested keys. Columns not mapped and empty cells are ignored. Type
+is the type of every message, unless a column is mapped to "type". Kinds holds
+the kind of the values given their path, either "string", "number", or "boolean".
+Values are strings by default.
+*/
+type mapping struct {
+	Type    string            `json:"type"`
+	Columns map[string]string `json:"columns"`
+	Kinds   map[string]string `json:"kinds"`
+}
+
+/*
+readMapping reads and validates a mapping file.
+*/
+func readMapping(path string) (*mapping, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &mapping{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("import: invalid mapping: %s", err)
+	}
+
+	if len(m.Columns) == 0 {
+		return nil, errors.New("import: mapping must have columns")
+	}
+
+	typed := m.Type != ""
+	for _, path := range m.Columns {
+		if path == "type" {
+			typed = true
+		}
+	}
+
+	if !typed {
+		return nil, errors.New("import: mapping must have a type or a column mapped to \"type\"")
+	}
+
+	for path, kind := range m.Kinds {
+		switch kind {
+		case "string", "number", "boolean":
+		default:
+			return nil, fmt.Errorf("import: kind %q of %q is not supported", kind, path)
+		}
+	}
+
+	return m, nil
+}
+
+/*
+csvReader reads a message per row of a CSV file, given a mapping. The first row
+must be the header holding the names of the columns.
+*/
+type csvReader struct {
+	csv     *csv.Reader
+	mapping *mapping
+	header  []string
+	n       int
+}
+
+/*
+newCSVReader returns a reader of CSV rows, and reads the header of the file.
+*/
+func newCSVReader(r io.Reader, m *mapping) (*csvReader, error) {
+	c := csv.NewReader(r)
+	c.FieldsPerRecord = -1
+
+	header, err := c.Read()
+	if err != nil {
+		return nil, fmt.Errorf("import: failed to read CSV header: %s", err)
+	}
+
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	return &csvReader{
+		csv:     c,
+		mapping: m,
+		header:  header,
+	}, nil
+}
+
+/*
+read returns the message of the next row of the file.
+*/
+func (r *csvReader) read() (*record, error) {
+	row, err := r.csv.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+
+	r.n++
+	rec := &record{
+		N: r.n,
+	}
+
+	if err != nil {
+		if _, ok := err.(*csv.ParseError); !ok {
+			return nil, err
+		}
+
+		rec.Err = err
+		return rec, nil
+	}
+
+	rec.Message = map[string]interface{}{}
+	if r.mapping.Type != "" {
+		rec.Message["type"] = r.mapping.Type
+	}
+
+	for i, cell := range row {
+		if i >= len(r.header) || cell == "" {
+			continue
+		}
+
+		path, mapped := r.mapping.Columns[r.header[i]]
+		if !mapped {
+			continue
+		}
+
+		value, err := r.mapping.convert(path, cell)
+		if err != nil {
+			rec.Err = fmt.Errorf("column %q: %s", r.header[i], err)
+			return rec, nil
+		}
+
+		set(rec.Message, path, value)
+	}
+
+	return rec, nil
+}
+
+/*
+convert converts the value of a cell given the kind of its path.
+*/
+func (m *mapping) convert(path string, cell string) (interface{}, error) {
+	switch m.Kinds[path] {
+	case "number":
+		if _, err := strconv.ParseFloat(cell, 64); err != nil {
+			return nil, fmt.Errorf("%q is not a number", cell)
+		}
+
+		return json.Number(cell), nil
+
+	case "boolean":
+		return strconv.ParseBool(cell)
+	}
+
+	return cell, nil
+}
+
+/*
+set sets a value in a message given its path, using dots for nested keys.
+*/
+func set(message map[string]interface{}, path string, value interface{}) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		nested, ok := message[key].(map[string]interface{})
+		if !ok {
+			nested = map[string]interface{}{}
+			message[key] = nested
+		}
+
+		message = nested
+	}
+
+	message[keys[len(keys)-1]] = value
+}
+
+/*
+open opens a file to import given its format, and returns its reader alongside a
+function closing the file.
+*/
+func open(path string, format string, m *mapping) (reader, func() error, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch format {
+	case "csv":
+		r, err := newCSVReader(f, m)
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+
+		return r, f.Close, nil
+
+	case "ndjson":
+		return newNDJSONReader(f), f.Close, nil
+
+	case "segment":
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, nil, fmt.Errorf("import: %s: %s", path, err)
+		}
+
+		closer := func() error {
+			gz.Close()
+			return f.Close()
+		}
+
+		return newNDJSONReader(gz), closer, nil
+	}
+
+	f.Close()
+	return nil, nil, fmt.Errorf("import: format %q is not supported", format)
+}
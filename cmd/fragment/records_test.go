@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNDJSONReader(t *testing.T) {
+	input := `{"type":"track","event":"A"}` + "\n\n" + `not json` + "\n" + `{"type":"track","event":"B"}`
+	r := newNDJSONReader(strings.NewReader(input))
+
+	want := []struct {
+		n       int
+		wantErr bool
+	}{
+		{n: 1},
+		{n: 3, wantErr: true},
+		{n: 4},
+	}
+
+	for _, w := range want {
+		rec, err := r.read()
+		if err != nil {
+			t.Fatalf("read() error = %v", err)
+		}
+
+		if rec.N != w.n || (rec.Err != nil) != w.wantErr {
+			t.Errorf("read() = %v, %v, want %v, wantErr %v", rec.N, rec.Err, w.n, w.wantErr)
+		}
+	}
+
+	if _, err := r.read(); err != io.EOF {
+		t.Errorf("read() error = %v, want %v", err, io.EOF)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	m := &mapping{
+		Type: "track",
+		Columns: map[string]string{
+			"user":   "userId",
+			"name":   "event",
+			"amount": "properties.revenue",
+			"paid":   "properties.paid",
+		},
+		Kinds: map[string]string{
+			"properties.revenue": "number",
+			"properties.paid":    "boolean",
+		},
+	}
+
+	input := "user,name,amount,paid,ignored\nuser_1,Order Completed,12.5,true,x\nuser_2,Order Completed,abc,,x\n"
+	r, err := newCSVReader(strings.NewReader(input), m)
+	if err != nil {
+		t.Fatalf("newCSVReader() error = %v", err)
+	}
+
+	rec, err := r.read()
+	if err != nil || rec.Err != nil {
+		t.Fatalf("read() error = %v, %v", err, rec.Err)
+	}
+
+	want := map[string]interface{}{
+		"type":   "track",
+		"userId": "user_1",
+		"event":  "Order Completed",
+		"properties": map[string]interface{}{
+			"revenue": json.Number("12.5"),
+			"paid":    true,
+		},
+	}
+
+	if !reflect.DeepEqual(rec.Message, want) {
+		t.Errorf("read() = %v, want %v", rec.Message, want)
+	}
+
+	rec, err = r.read()
+	if err != nil || rec.N != 2 || rec.Err == nil {
+		t.Errorf("read() = %v, %v, want an invalid number", rec, err)
+	}
+
+	if _, err := r.read(); err != io.EOF {
+		t.Errorf("read() error = %v, want %v", err, io.EOF)
+	}
+}
+
+func TestReadMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{
+			name:    "WithType",
+			input:   `{"type":"track","columns":{"user":"userId"}}`,
+			wantErr: false,
+		},
+		{
+			name:    "WithTypeColumn",
+			input:   `{"columns":{"kind":"type","user":"userId"}}`,
+			wantErr: false,
+		},
+		{
+			name:    "WithoutType",
+			input:   `{"columns":{"user":"userId"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "WithUnknownKind",
+			input:   `{"type":"track","columns":{"user":"userId"},"kinds":{"userId":"date"}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "mapping.json")
+			os.WriteFile(path, []byte(tt.input), 0644)
+
+			_, err := readMapping(path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("readMapping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOpenSegment(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(`{"type":"track","event":"A","userId":"user"}` + "\n"))
+	gz.Close()
+
+	path := filepath.Join(t.TempDir(), "archive.gz")
+	os.WriteFile(path, buf.Bytes(), 0644)
+
+	r, closer, err := open(path, "segment", nil)
+	if err != nil {
+		t.Fatalf("open() error = %v", err)
+	}
+
+	defer closer()
+	rec, err := r.read()
+	if err != nil || rec.Err != nil || rec.Message["event"] != "A" {
+		t.Errorf("read() = %v, %v", rec, err)
+	}
+}
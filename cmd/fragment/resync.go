@@ -0,0 +1,34 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+)
+
+/*
+resync asks the gateway to send every memberships of an audience or a list to the
+destinations again. It must be run every time the definition of an audience
+changed.
+*/
+func resync(args []string) error {
+	flags := flag.NewFlagSet("resync", flag.ContinueOnError)
+	key := flags.String("key", "", "Key of the audience or list to synchronize.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *key == "" {
+		return errors.New("resync: -key must be set")
+	}
+
+	_, err := send("POST", gateway()+"/v1/audiences/resync", map[string]string{
+		"key": *key,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Audience %q is being synchronized.\n", *key)
+	return nil
+}
@@ -0,0 +1,364 @@
+/*
+Package computed offers computed traits evaluated over the events received, such
+as counters, sums of a property, first and last values, or the most frequent value
+of a property over a rolling window.
+
+Events are aggregated in daily buckets as they arrive, so computing a trait only
+requires to read the buckets of the window. Computed traits are stored on the
+profile and an Identify event is returned every time one of them changes.
+*/
+package computed
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/helper/logger"
+
+	"github.com/lib/pq"
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Store holds the computed traits.
+*/
+type Store struct {
+	env *Options
+}
+
+/*
+New returns a valid store of computed traits.
+*/
+func New(env *Options) *Store {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &Store{
+		env: env,
+	}
+}
+
+/*
+retention is the time during which the IDs of the messages aggregated are kept,
+so a message retried by a client is not aggregated twice.
+*/
+var retention = 24 * time.Hour
+
+/*
+Track aggregates a Track event into the computed traits it matches, and evaluates
+these traits for the user's profile. It returns an Identify event holding the
+traits which changed, or nil if none changed.
+
+Messages are aggregated once given their message ID. When a message is received
+again, such as when a client retries a message after an error, the buckets are
+left untouched and an Identify event holding the current values of the traits is
+returned, so the one of the failed attempt is not lost.
+*/
+func (s *Store) Track(profileID string, msg analytics.Track) (*analytics.Identify, error) {
+	at := msg.Timestamp
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+
+	traits := []*Trait{}
+	for _, trait := range s.env.Traits {
+		if trait.matches(msg.Event) {
+			traits = append(traits, trait)
+		}
+	}
+
+	if len(traits) == 0 {
+		return nil, nil
+	}
+
+	tx, err := s.env.DB.Begin()
+	if err != nil {
+		return nil, failed("Failed to aggregate event", err)
+	}
+
+	defer tx.Rollback()
+	claimed, err := claim(tx, msg.MessageId)
+	if err != nil {
+		return nil, failed("Failed to aggregate event", err)
+	}
+
+	if !claimed {
+		return s.replay(profileID, traits, msg.UserId, msg.AnonymousId)
+	}
+
+	for _, trait := range traits {
+		c, ok := trait.contribute(msg.Properties, at)
+		if !ok {
+			continue
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO fragment_computed.buckets (profile_id, trait, day, value, count, sum, first_value, first_at, last_value, last_at)
+			VALUES ($1, $2, $3::DATE, $4, 1, $5, $6, $7, $6, $7)
+			ON CONFLICT (profile_id, trait, day, value) DO UPDATE SET
+				count = buckets.count + 1,
+				sum = buckets.sum + EXCLUDED.sum,
+				first_value = CASE WHEN EXCLUDED.first_at < buckets.first_at THEN EXCLUDED.first_value ELSE buckets.first_value END,
+				first_at = LEAST(buckets.first_at, EXCLUDED.first_at),
+				last_value = CASE WHEN EXCLUDED.last_at >= buckets.last_at THEN EXCLUDED.last_value ELSE buckets.last_value END,
+				last_at = GREATEST(buckets.last_at, EXCLUDED.last_at);
+		`, profileID, trait.Name, day(at), c.value, c.sum, nullable(c.point), at)
+		if err != nil {
+			return nil, failed("Failed to aggregate event", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, failed("Failed to aggregate event", err)
+	}
+
+	return s.refresh(profileID, traits, msg.UserId, msg.AnonymousId)
+}
+
+/*
+claim records the ID of a message as aggregated. It returns false if the message
+has already been aggregated. Messages without ID are always aggregated.
+*/
+func claim(tx *sql.Tx, messageID string) (bool, error) {
+	if messageID == "" {
+		return true, nil
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO fragment_computed.messages (message_id, received_at)
+		VALUES ($1, $2)
+		ON CONFLICT (message_id) DO NOTHING;
+	`, messageID, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+/*
+Refresh evaluates the computed traits of the profiles having events leaving the
+rolling window of a trait, and removes these events from the buckets. It returns
+an Identify event for every profile for which a trait changed. It is meant to be
+called periodically so the traits stay accurate even for inactive users.
+*/
+func (s *Store) Refresh() ([]*analytics.Identify, error) {
+	now := time.Now().UTC()
+	_, err := s.env.DB.Exec(`
+		DELETE FROM fragment_computed.messages
+		WHERE received_at < $1;
+	`, now.Add(-retention))
+	if err != nil {
+		return nil, failed("Failed to remove expired messages", err)
+	}
+
+	identifies := []*analytics.Identify{}
+	for _, trait := range s.env.Traits {
+		since := trait.since(now)
+		if since == nil {
+			continue
+		}
+
+		rows, err := s.env.DB.Query(`
+			SELECT DISTINCT profile_id FROM fragment_computed.buckets
+			WHERE trait = $1 AND day < $2::DATE;
+		`, trait.Name, day(*since))
+		if err != nil {
+			return nil, failed("Failed to find expired events", err)
+		}
+
+		profileIDs := []string{}
+		for rows.Next() {
+			var profileID string
+			if err := rows.Scan(&profileID); err != nil {
+				rows.Close()
+				return nil, failed("Failed to find expired events", err)
+			}
+
+			profileIDs = append(profileIDs, profileID)
+		}
+
+		rows.Close()
+		_, err = s.env.DB.Exec(`
+			DELETE FROM fragment_computed.buckets
+			WHERE trait = $1 AND day < $2::DATE;
+		`, trait.Name, day(*since))
+		if err != nil {
+			return nil, failed("Failed to remove expired events", err)
+		}
+
+		seen := map[string]bool{}
+		for _, profileID := range profileIDs {
+			root, err := s.env.Identity.Root(profileID)
+			if err != nil {
+				return nil, err
+			} else if root == "" || seen[root] {
+				continue
+			}
+
+			seen[root] = true
+			userID, anonymousID, err := s.env.Identity.Primary(root)
+			if err != nil {
+				return nil, err
+			}
+
+			identify, err := s.refresh(root, []*Trait{trait}, userID, anonymousID)
+			if err != nil {
+				return nil, err
+			} else if identify != nil {
+				identifies = append(identifies, identify)
+			}
+		}
+	}
+
+	return identifies, nil
+}
+
+/*
+replay evaluates some computed traits for a profile, stores the ones which changed
+in case the failed attempt could not store them, and returns an Identify event
+holding their current values.
+*/
+func (s *Store) replay(profileID string, traits []*Trait, userID string, anonymousID string) (*analytics.Identify, error) {
+	values, err := s.values(profileID, traits)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if _, err := s.env.Profiles.Update(profileID, userID, anonymousID, values, now); err != nil {
+		return nil, err
+	}
+
+	return s.env.Profiles.Replay(profileID, userID, anonymousID, values, now)
+}
+
+/*
+values evaluates some computed traits for a profile.
+*/
+func (s *Store) values(profileID string, traits []*Trait) (analytics.Traits, error) {
+	now := time.Now().UTC()
+	members, err := s.env.Identity.Members(profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	values := analytics.Traits{}
+	for _, trait := range traits {
+		value, err := s.evaluate(members, trait, now)
+		if err != nil {
+			return nil, err
+		}
+
+		values[trait.Name] = value
+	}
+
+	return values, nil
+}
+
+/*
+refresh evaluates some computed traits for a profile, stores the ones which changed,
+and returns an Identify event for them.
+*/
+func (s *Store) refresh(profileID string, traits []*Trait, userID string, anonymousID string) (*analytics.Identify, error) {
+	values, err := s.values(profileID, traits)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.env.Profiles.Update(profileID, userID, anonymousID, values, time.Now().UTC())
+}
+
+/*
+evaluate computes the value of a trait for a set of profiles merged together.
+*/
+func (s *Store) evaluate(members []string, trait *Trait, now time.Time) (interface{}, error) {
+	var query string
+	switch trait.Kind {
+	case KindCount:
+		query = `SELECT COALESCE(SUM(count), 0)::TEXT::JSONB`
+	case KindSum:
+		query = `SELECT COALESCE(SUM(sum), 0)::TEXT::JSONB`
+	case KindFirst:
+		query = `SELECT first_value`
+	case KindLast:
+		query = `SELECT last_value`
+	case KindMostFrequent:
+		query = `SELECT value::JSONB`
+	}
+
+	query += `
+		FROM fragment_computed.buckets
+		WHERE profile_id = ANY($1) AND trait = $2
+		AND ($3::DATE IS NULL OR day >= $3::DATE)
+	`
+
+	switch trait.Kind {
+	case KindFirst:
+		query += `ORDER BY first_at ASC LIMIT 1;`
+	case KindLast:
+		query += `ORDER BY last_at DESC LIMIT 1;`
+	case KindMostFrequent:
+		query += `GROUP BY value ORDER BY SUM(count) DESC, MAX(last_at) DESC LIMIT 1;`
+	}
+
+	var since interface{}
+	if t := trait.since(now); t != nil {
+		since = day(*t)
+	}
+
+	var b []byte
+	err := s.env.DB.QueryRow(query, pq.Array(members), trait.Name, since).Scan(&b)
+	if err == sql.ErrNoRows || (err == nil && b == nil) {
+		return nil, nil
+	} else if err != nil {
+		return nil, failed("Failed to evaluate trait", err)
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(b, &value); err != nil {
+		return nil, failed("Failed to evaluate trait", err)
+	}
+
+	return value, nil
+}
+
+/*
+day returns the day of a timestamp in UTC, as expected by PostgreSQL for dates.
+*/
+func day(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+/*
+nullable returns nil for empty JSON values so they are stored as NULL.
+*/
+func nullable(b []byte) interface{} {
+	if len(b) == 0 {
+		return nil
+	}
+
+	return string(b)
+}
+
+/*
+failed returns a normalized error for the computed traits.
+*/
+func failed(message string, err error) error {
+	return &errors.Error{
+		Message: "computed: " + message,
+		Validations: []errors.Validation{
+			{
+				Message: err.Error(),
+			},
+		},
+	}
+}
@@ -0,0 +1,13 @@
+package computed
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDay(t *testing.T) {
+	at := time.Date(2021, 6, 15, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*60*60))
+	if got := day(at); got != "2021-06-16" {
+		t.Errorf("day() = %v, want %v", got, "2021-06-16")
+	}
+}
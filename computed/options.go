@@ -0,0 +1,100 @@
+package computed
+
+import (
+	"database/sql"
+	"strconv"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/nunchistudio/fragment/identity"
+	"github.com/nunchistudio/fragment/profiles"
+)
+
+/*
+Options is the options the computed traits can take as an input to be configured.
+*/
+type Options struct {
+
+	// DB is the PostgreSQL database connection where the events are aggregated.
+	// The tables are created by the migration "init_computed".
+	//
+	// Required.
+	DB *sql.DB
+
+	// Identity is the identity graph used to find the profile of an event.
+	//
+	// Required.
+	Identity *identity.Graph
+
+	// Profiles is the profile store where the computed traits are stored.
+	//
+	// Required.
+	Profiles *profiles.Store
+
+	// Traits is the list of computed traits to evaluate.
+	Traits []*Trait
+}
+
+/*
+validate ensures the options passed to initialize the computed traits are valid.
+*/
+func (env *Options) validate() error {
+	fail := &errors.Error{
+		Message:     "computed: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Computed"},
+		})
+
+		return fail
+	}
+
+	if env.DB == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Database connection must be set",
+			Path:    []string{"Options", "Computed", "DB"},
+		})
+	}
+
+	if env.Identity == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Identity graph must be set",
+			Path:    []string{"Options", "Computed", "Identity"},
+		})
+	}
+
+	if env.Profiles == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Profile store must be set",
+			Path:    []string{"Options", "Computed", "Profiles"},
+		})
+	}
+
+	names := map[string]bool{}
+	for i, trait := range env.Traits {
+		path := []string{"Options", "Computed", "Traits", strconv.Itoa(i)}
+		fail.Validations = append(fail.Validations, trait.validate(path)...)
+		if trait == nil {
+			continue
+		}
+
+		if names[trait.Name] {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Trait '" + trait.Name + "' is defined more than once",
+				Path:    append(path, "Name"),
+			})
+		}
+
+		names[trait.Name] = true
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
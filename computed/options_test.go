@@ -0,0 +1,56 @@
+package computed
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/nunchistudio/fragment/identity"
+	"github.com/nunchistudio/fragment/profiles"
+)
+
+func TestOptions_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  *Options
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: true,
+		},
+		{
+			name: "WithRequiredOptions",
+			fields: &Options{
+				DB:       &sql.DB{},
+				Identity: &identity.Graph{},
+				Profiles: &profiles.Store{},
+				Traits: []*Trait{
+					{Name: "orders_count", Kind: KindCount, Event: "Order Completed"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithDuplicateTraits",
+			fields: &Options{
+				DB:       &sql.DB{},
+				Identity: &identity.Graph{},
+				Profiles: &profiles.Store{},
+				Traits: []*Trait{
+					{Name: "orders_count", Kind: KindCount},
+					{Name: "orders_count", Kind: KindCount},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package computed
+
+import (
+	"strings"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+)
+
+/*
+Kind is a custom type allowing the user to only pass supported kinds of computed
+traits.
+*/
+type Kind string
+
+/*
+KindCount is used to count the events.
+*/
+var KindCount Kind = "count"
+
+/*
+KindSum is used to sum a numeric property of the events.
+*/
+var KindSum Kind = "sum"
+
+/*
+KindFirst is used to keep the value of a property of the first event. When no
+property is set, the timestamp of the first event is kept.
+*/
+var KindFirst Kind = "first"
+
+/*
+KindLast is used to keep the value of a property of the last event. When no
+property is set, the timestamp of the last event is kept.
+*/
+var KindLast Kind = "last"
+
+/*
+KindMostFrequent is used to keep the most frequent value of a property of the
+events.
+*/
+var KindMostFrequent Kind = "most_frequent"
+
+/*
+Trait is the definition of a computed trait.
+*/
+type Trait struct {
+
+	// Name is the key of the trait on the profile.
+	//
+	// Example: "orders_last_30_days"
+	//
+	// Required.
+	Name string
+
+	// Kind is the kind of computation applied to the events.
+	//
+	// Required.
+	Kind Kind
+
+	// Event is the name of the "track" events to compute. When empty, every
+	// "track" events are computed.
+	//
+	// Example: "Order Completed"
+	Event string
+
+	// Property is the property of the events to compute. Nested properties can be
+	// accessed using dots.
+	//
+	// Example: "revenue"
+	//
+	// Required for kinds KindSum and KindMostFrequent.
+	Property string
+
+	// Window is the rolling window of the events to compute. It is rounded up to
+	// days. When zero, every events since the profile creation are computed.
+	//
+	// Example: 30 * 24 * time.Hour
+	Window time.Duration
+}
+
+/*
+days returns the number of days covered by the rolling window of the trait. It
+returns 0 if the trait has no window.
+*/
+func (t *Trait) days() int {
+	if t.Window <= 0 {
+		return 0
+	}
+
+	days := int(t.Window / (24 * time.Hour))
+	if t.Window%(24*time.Hour) != 0 {
+		days++
+	}
+
+	return days
+}
+
+/*
+since returns the first day included in the rolling window of the trait at a
+given time. It returns nil if the trait has no window.
+*/
+func (t *Trait) since(now time.Time) *time.Time {
+	days := t.days()
+	if days == 0 {
+		return nil
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	since := today.AddDate(0, 0, 1-days)
+	return &since
+}
+
+/*
+matches returns if an event is computed by the trait.
+*/
+func (t *Trait) matches(event string) bool {
+	return t.Event == "" || t.Event == event
+}
+
+/*
+validate ensures the definition of the computed trait is valid.
+*/
+func (t *Trait) validate(path []string) []errors.Validation {
+	validations := []errors.Validation{}
+	if t == nil {
+		return append(validations, errors.Validation{
+			Message: "Trait must not be nil",
+			Path:    path,
+		})
+	}
+
+	if t.Name == "" {
+		validations = append(validations, errors.Validation{
+			Message: "Trait name must be set",
+			Path:    append(path, "Name"),
+		})
+	}
+
+	switch t.Kind {
+	case KindCount, KindFirst, KindLast:
+	case KindSum, KindMostFrequent:
+		if t.Property == "" {
+			validations = append(validations, errors.Validation{
+				Message: "Property must be set for kind '" + string(t.Kind) + "'",
+				Path:    append(path, "Property"),
+			})
+		}
+
+	default:
+		validations = append(validations, errors.Validation{
+			Message: "Kind must be one of 'count', 'sum', 'first', 'last', 'most_frequent'",
+			Path:    append(path, "Kind"),
+		})
+	}
+
+	if strings.HasPrefix(t.Property, ".") || strings.HasSuffix(t.Property, ".") {
+		validations = append(validations, errors.Validation{
+			Message: "Property must not start nor end with a '.'",
+			Path:    append(path, "Property"),
+		})
+	}
+
+	if t.Window < 0 {
+		validations = append(validations, errors.Validation{
+			Message: "Window must not be negative",
+			Path:    append(path, "Window"),
+		})
+	}
+
+	return validations
+}
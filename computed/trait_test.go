@@ -0,0 +1,110 @@
+package computed
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrait_since(t *testing.T) {
+	now := time.Date(2021, 6, 15, 18, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		window time.Duration
+		want   *time.Time
+	}{
+		{
+			name:   "WithoutWindow",
+			window: 0,
+			want:   nil,
+		},
+		{
+			name:   "WithOneDay",
+			window: 24 * time.Hour,
+			want:   timeAt(2021, 6, 15),
+		},
+		{
+			name:   "WithSevenDays",
+			window: 7 * 24 * time.Hour,
+			want:   timeAt(2021, 6, 9),
+		},
+		{
+			name:   "WithPartialDay",
+			window: 36 * time.Hour,
+			want:   timeAt(2021, 6, 14),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trait := &Trait{
+				Window: tt.window,
+			}
+
+			got := trait.since(now)
+			if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(*tt.want)) {
+				t.Errorf("Trait.since() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrait_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		trait   *Trait
+		wantErr bool
+	}{
+		{
+			name:    "WithNilTrait",
+			trait:   nil,
+			wantErr: true,
+		},
+		{
+			name: "WithCount",
+			trait: &Trait{
+				Name:  "orders_count",
+				Kind:  KindCount,
+				Event: "Order Completed",
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithSumWithoutProperty",
+			trait: &Trait{
+				Name: "revenue",
+				Kind: KindSum,
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithUnknownKind",
+			trait: &Trait{
+				Name: "revenue",
+				Kind: "average",
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithNegativeWindow",
+			trait: &Trait{
+				Name:   "last_seen_at",
+				Kind:   KindLast,
+				Window: -time.Hour,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validations := tt.trait.validate([]string{"Trait"})
+			if (len(validations) > 0) != tt.wantErr {
+				t.Errorf("Trait.validate() = %v, wantErr %v", validations, tt.wantErr)
+			}
+		})
+	}
+}
+
+func timeAt(year int, month time.Month, day int) *time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &t
+}
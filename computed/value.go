@@ -0,0 +1,103 @@
+package computed
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+contribution is the contribution of a single event to a computed trait. It is
+aggregated in daily buckets.
+*/
+type contribution struct {
+	value string
+	sum   float64
+	point []byte
+}
+
+/*
+lookup returns the value of a property, accessing nested properties using dots.
+*/
+func lookup(properties analytics.Properties, property string) (interface{}, bool) {
+	var current interface{} = map[string]interface{}(properties)
+	for _, key := range strings.Split(property, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = m[key]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, current != nil
+}
+
+/*
+number returns the numeric representation of a value. Numbers sent as strings are
+also supported.
+*/
+func number(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+
+	return 0, false
+}
+
+/*
+contribute returns the contribution of an event to a computed trait. It returns
+false if the event does not contribute to the trait, such as when the property
+is missing.
+*/
+func (t *Trait) contribute(properties analytics.Properties, at time.Time) (*contribution, bool) {
+	c := &contribution{}
+
+	// First and last values default to the timestamp of the event when no
+	// property is set.
+	if t.Property == "" {
+		if t.Kind == KindFirst || t.Kind == KindLast {
+			c.point, _ = json.Marshal(at.UTC().Format(time.RFC3339))
+		}
+
+		return c, true
+	}
+
+	value, exists := lookup(properties, t.Property)
+	if !exists {
+		return nil, false
+	}
+
+	point, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+
+	switch t.Kind {
+	case KindSum:
+		c.sum, exists = number(value)
+		if !exists {
+			return nil, false
+		}
+
+	case KindMostFrequent:
+		c.value = string(point)
+	}
+
+	c.point = point
+	return c, true
+}
@@ -0,0 +1,102 @@
+package computed
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+func TestTrait_contribute(t *testing.T) {
+	at := time.Date(2021, 6, 15, 18, 30, 0, 0, time.UTC)
+	properties := analytics.Properties{
+		"revenue": 42.5,
+		"total":   "10",
+		"product": map[string]interface{}{
+			"category": "shoes",
+		},
+	}
+
+	tests := []struct {
+		name   string
+		trait  *Trait
+		want   *contribution
+		wantOk bool
+	}{
+		{
+			name: "WithCount",
+			trait: &Trait{
+				Kind: KindCount,
+			},
+			want:   &contribution{},
+			wantOk: true,
+		},
+		{
+			name: "WithSum",
+			trait: &Trait{
+				Kind:     KindSum,
+				Property: "revenue",
+			},
+			want:   &contribution{sum: 42.5, point: []byte("42.5")},
+			wantOk: true,
+		},
+		{
+			name: "WithSumOfString",
+			trait: &Trait{
+				Kind:     KindSum,
+				Property: "total",
+			},
+			want:   &contribution{sum: 10, point: []byte(`"10"`)},
+			wantOk: true,
+		},
+		{
+			name: "WithSumOfNonNumeric",
+			trait: &Trait{
+				Kind:     KindSum,
+				Property: "product",
+			},
+			wantOk: false,
+		},
+		{
+			name: "WithMostFrequentNested",
+			trait: &Trait{
+				Kind:     KindMostFrequent,
+				Property: "product.category",
+			},
+			want:   &contribution{value: `"shoes"`, point: []byte(`"shoes"`)},
+			wantOk: true,
+		},
+		{
+			name: "WithLastTimestamp",
+			trait: &Trait{
+				Kind: KindLast,
+			},
+			want:   &contribution{point: []byte(`"2021-06-15T18:30:00Z"`)},
+			wantOk: true,
+		},
+		{
+			name: "WithMissingProperty",
+			trait: &Trait{
+				Kind:     KindFirst,
+				Property: "coupon",
+			},
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.trait.contribute(properties, at)
+			if ok != tt.wantOk {
+				t.Fatalf("Trait.contribute() ok = %v, want %v", ok, tt.wantOk)
+			}
+
+			if !ok {
+				return
+			}
+
+			if got.value != tt.want.value || got.sum != tt.want.sum || string(got.point) != string(tt.want.point) {
+				t.Errorf("Trait.contribute() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
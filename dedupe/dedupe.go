@@ -0,0 +1,139 @@
+/*
+Package dedupe skips the "identify" calls which would not change anything in the
+destinations. Applications often call "identify" on every page load, creating
+jobs for every destinations even though the traits of the user did not change.
+
+The last known traits of every pair of user ID and anonymous ID are stored in
+PostgreSQL. An "identify" call is only forwarded to the destinations when the pair
+has not been seen yet, when at least one of its traits is new or has a different
+value, or when the traits have not been forwarded for the duration of the refresh
+interval. Calls without traits are always forwarded.
+*/
+package dedupe
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/helper/logger"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Store holds the last known traits of the users.
+*/
+type Store struct {
+	env *Options
+}
+
+/*
+New returns a valid store for deduplicating identify calls.
+*/
+func New(env *Options) *Store {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &Store{
+		env: env,
+	}
+}
+
+/*
+key returns the key of the user of an Identify event. It is made of both the user
+ID and the anonymous ID, so an event is always forwarded for a pair not seen yet,
+such as when a user logs in or uses a new device, and destinations can link both
+identifiers.
+*/
+func key(msg analytics.Identify) string {
+	return "user_id:" + strconv.Quote(msg.UserId) + ",anonymous_id:" + strconv.Quote(msg.AnonymousId)
+}
+
+/*
+Changed records the traits of an Identify event and returns if it must be
+forwarded to the destinations. It returns true when the pair of user ID and
+anonymous ID has not been seen yet, when at least one trait is new or has changed
+since the last known traits of the pair, or when the refresh interval has elapsed
+since the traits were last forwarded. Events without traits are always forwarded.
+
+The comparison and the update are done in a single statement, so concurrent calls
+across gateway replicas forward the changes only once.
+
+Events disabling some destinations with their integrations are always forwarded
+and their traits are not recorded, since only part of the destinations would know
+them. The traits are recorded when the event is validated, not once it has been
+delivered: when the delivery to a destination fails, identical traits are not
+forwarded again until the refresh interval elapsed.
+*/
+func (s *Store) Changed(msg analytics.Identify) (bool, error) {
+	if partial(msg.Integrations) || len(msg.Traits) == 0 {
+		return true, nil
+	}
+
+	at := msg.Timestamp
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+
+	b, err := json.Marshal(msg.Traits)
+	if err != nil {
+		return false, failed("Failed to encode traits", err)
+	}
+
+	var forwarded string
+	err = s.env.DB.QueryRow(`
+		INSERT INTO fragment_dedupe.identifies (key, traits, forwarded_at)
+		VALUES ($1, $2::JSONB, $3)
+		ON CONFLICT (key) DO UPDATE SET
+			traits = identifies.traits || EXCLUDED.traits,
+			forwarded_at = GREATEST(identifies.forwarded_at, EXCLUDED.forwarded_at)
+		WHERE identifies.forwarded_at < $4
+		OR EXISTS (
+			SELECT 1 FROM jsonb_each(EXCLUDED.traits) AS t
+			WHERE identifies.traits -> t.key IS DISTINCT FROM t.value
+		)
+		RETURNING key;
+	`, key(msg), string(b), at, at.Add(-s.env.Refresh)).Scan(&forwarded)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, failed("Failed to compare traits", err)
+	}
+
+	return true, nil
+}
+
+/*
+partial returns if the integrations of an event disable at least one destination.
+*/
+func partial(integrations analytics.Integrations) bool {
+	for _, enabled := range integrations {
+		if enabled == false {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+failed returns a normalized error for the deduplication.
+*/
+func failed(message string, err error) error {
+	return &errors.Error{
+		Message: "dedupe: " + message,
+		Validations: []errors.Validation{
+			{
+				Message: err.Error(),
+			},
+		},
+	}
+}
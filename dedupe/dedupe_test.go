@@ -0,0 +1,91 @@
+package dedupe
+
+import (
+	"testing"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  analytics.Identify
+		want string
+	}{
+		{
+			name: "WithUserIDAndAnonymousID",
+			msg:  analytics.Identify{UserId: "42", AnonymousId: "a1"},
+			want: `user_id:"42",anonymous_id:"a1"`,
+		},
+		{
+			name: "WithAnonymousID",
+			msg:  analytics.Identify{AnonymousId: "a1"},
+			want: `user_id:"",anonymous_id:"a1"`,
+		},
+		{
+			name: "WithSeparatorInUserID",
+			msg:  analytics.Identify{UserId: `42",anonymous_id:"a1`},
+			want: `user_id:"42\",anonymous_id:\"a1",anonymous_id:""`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := key(tt.msg); got != tt.want {
+				t.Errorf("key() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPartial(t *testing.T) {
+	tests := []struct {
+		name         string
+		integrations analytics.Integrations
+		want         bool
+	}{
+		{
+			name: "WithoutIntegrations",
+			want: false,
+		},
+		{
+			name:         "WithEnabledIntegrations",
+			integrations: analytics.NewIntegrations().EnableAll(),
+			want:         false,
+		},
+		{
+			name:         "WithDisabledIntegration",
+			integrations: analytics.NewIntegrations().Disable("Mailchimp"),
+			want:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := partial(tt.integrations); got != tt.want {
+				t.Errorf("partial() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStore_Changed(t *testing.T) {
+	s := &Store{
+		env: &Options{},
+	}
+
+	// The database must not be reached for events not sent to every destination.
+	forward, err := s.Changed(analytics.Identify{
+		UserId:       "42",
+		Integrations: analytics.NewIntegrations().Disable("Amplitude"),
+	})
+	if err != nil || !forward {
+		t.Errorf("Store.Changed() = %v, %v, want %v", forward, err, true)
+	}
+
+	// Nor for events without traits.
+	forward, err = s.Changed(analytics.Identify{
+		UserId: "42",
+	})
+	if err != nil || !forward {
+		t.Errorf("Store.Changed() = %v, %v, want %v", forward, err, true)
+	}
+}
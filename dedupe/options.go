@@ -0,0 +1,79 @@
+package dedupe
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+)
+
+/*
+Defaults are the defaults options set for the deduplication of identify calls.
+When not set, these values will automatically be applied.
+*/
+var Defaults = &Options{
+	Refresh: 24 * time.Hour,
+}
+
+/*
+Options is the options the deduplication can take as an input to be configured.
+*/
+type Options struct {
+
+	// DB is the PostgreSQL database connection where the last known traits of
+	// every pair of user ID and anonymous ID are stored. The tables are created
+	// by the migration "init_dedupe".
+	//
+	// Required.
+	DB *sql.DB
+
+	// Refresh is the duration after which an "identify" call is sent to the
+	// destinations even if the traits of the user have not changed, so the
+	// destinations are eventually consistent with Fragment. Since the traits are
+	// recorded before being delivered, it is also the longest duration a
+	// destination can miss traits it failed to load.
+	//
+	// Defaults to 24 hours.
+	Refresh time.Duration
+}
+
+/*
+validate ensures the options passed to initialize the deduplication are valid.
+*/
+func (env *Options) validate() error {
+	fail := &errors.Error{
+		Message:     "dedupe: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Dedupe"},
+		})
+
+		return fail
+	}
+
+	if env.DB == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Database connection must be set",
+			Path:    []string{"Options", "Dedupe", "DB"},
+		})
+	}
+
+	if env.Refresh < 0 {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Refresh must not be negative",
+			Path:    []string{"Options", "Dedupe", "Refresh"},
+		})
+	} else if env.Refresh == 0 {
+		env.Refresh = Defaults.Refresh
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
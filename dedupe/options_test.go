@@ -0,0 +1,59 @@
+package dedupe
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestOptions_validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		fields      *Options
+		wantErr     bool
+		wantRefresh time.Duration
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: true,
+		},
+		{
+			name: "WithDefaultRefresh",
+			fields: &Options{
+				DB: &sql.DB{},
+			},
+			wantErr:     false,
+			wantRefresh: 24 * time.Hour,
+		},
+		{
+			name: "WithRefresh",
+			fields: &Options{
+				DB:      &sql.DB{},
+				Refresh: time.Hour,
+			},
+			wantErr:     false,
+			wantRefresh: time.Hour,
+		},
+		{
+			name: "WithNegativeRefresh",
+			fields: &Options{
+				DB:      &sql.DB{},
+				Refresh: -time.Hour,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && env.Refresh != tt.wantRefresh {
+				t.Errorf("Options.validate() refresh = %v, want %v", env.Refresh, tt.wantRefresh)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package amplitude
+
+import (
+	"github.com/nunchistudio/blacksmith/destination"
+)
+
+var _ destination.Action = Page{}
@@ -0,0 +1,60 @@
+package amplitude
+
+import (
+	"net/http"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+
+	"github.com/nunchistudio/blacksmith-modules/amplitude/amplitudedestination"
+)
+
+/*
+Track implements the Blacksmith destination.Action interface for the action
+"track". It holds the complete job's structure to load into the destination.
+*/
+type Track struct {
+	env    *Options
+	client *http.Client
+
+	Events []Event `json:"events"`
+}
+
+/*
+String returns the string representation of the action Track.
+*/
+func (a Track) String() string {
+	return "track"
+}
+
+/*
+Schedule allows the action to override the schedule options of its
+destination. Do not override.
+*/
+func (a Track) Schedule() *destination.Schedule {
+	return nil
+}
+
+/*
+Marshal is the function being run when the action receives data into
+the Track receiver. The events are marshaled by the action of the destination
+of the modules, and their session IDs are then added to the job.
+*/
+func (a Track) Marshal(tk *destination.Toolkit) (*destination.Job, error) {
+	j, err := amplitudedestination.Track{
+		Events: unwrap(a.Events),
+	}.Marshal(tk)
+	if err != nil {
+		return nil, err
+	}
+
+	return withSessions(j, a.Events)
+}
+
+/*
+Load is the function being run by the scheduler to load the data into
+the destination. It is in charge of the "L" in the ETL process.
+*/
+func (a Track) Load(tk *destination.Toolkit, queue *store.Queue, then chan<- destination.Then) {
+	load(a.env, a.client, queue, then)
+}
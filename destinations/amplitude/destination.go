@@ -0,0 +1,77 @@
+/*
+Package amplitude extends the Amplitude destination of the Blacksmith modules
+with the sessions computed by Fragment. The actions "track", "page", and "screen"
+send the ID of the session of the events as the "session_id" of the Amplitude
+HTTP API, which the destination of the modules does not support. Without it,
+Amplitude considers every event sent by a server as its own session.
+
+The other actions are the ones of the destination of the modules.
+*/
+package amplitude
+
+import (
+	"net/http"
+
+	"github.com/nunchistudio/blacksmith/destination"
+
+	"github.com/nunchistudio/blacksmith-modules/amplitude/amplitudedestination"
+)
+
+/*
+Options is the options the destination can take as an input to be configured.
+They are the options of the destination of the modules.
+*/
+type Options = amplitudedestination.Options
+
+/*
+Amplitude implements the Blacksmith destination.Destination interface for the
+destination "amplitude".
+*/
+type Amplitude struct {
+	destination.Destination
+
+	env    *Options
+	client *http.Client
+}
+
+/*
+New returns a valid Blacksmith destination.Destination for Amplitude. The
+options are validated by the destination of the modules.
+*/
+func New(env *Options) destination.Destination {
+	d := amplitudedestination.New(env)
+	if d == nil {
+		return nil
+	}
+
+	return &Amplitude{
+		Destination: d,
+		env:         env,
+		client:      http.DefaultClient,
+	}
+}
+
+/*
+Actions return a list of actions the destination Amplitude is able to handle.
+The actions "track", "page", and "screen" replace the ones of the destination of
+the modules.
+*/
+func (d *Amplitude) Actions() map[string]destination.Action {
+	actions := d.Destination.Actions()
+	actions["track"] = Track{
+		env:    d.env,
+		client: d.client,
+	}
+
+	actions["page"] = Page{
+		env:    d.env,
+		client: d.client,
+	}
+
+	actions["screen"] = Screen{
+		env:    d.env,
+		client: d.client,
+	}
+
+	return actions
+}
@@ -0,0 +1,38 @@
+package amplitude
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/destination"
+)
+
+var _ destination.Destination = &Amplitude{}
+
+func TestAmplitude_Actions(t *testing.T) {
+	d := New(&Options{
+		APIKey: "key",
+	})
+
+	if d.String() != "amplitude" {
+		t.Errorf("Amplitude.String() = %v, want %v", d.String(), "amplitude")
+	}
+
+	actions := d.Actions()
+	for _, name := range []string{"identify", "group", "alias"} {
+		if _, exists := actions[name]; !exists {
+			t.Errorf("Amplitude.Actions() is missing action %v", name)
+		}
+	}
+
+	if _, ok := actions["track"].(Track); !ok {
+		t.Errorf("Amplitude.Actions() track = %T, want %T", actions["track"], Track{})
+	}
+
+	if _, ok := actions["page"].(Page); !ok {
+		t.Errorf("Amplitude.Actions() page = %T, want %T", actions["page"], Page{})
+	}
+
+	if _, ok := actions["screen"].(Screen); !ok {
+		t.Errorf("Amplitude.Actions() screen = %T, want %T", actions["screen"], Screen{})
+	}
+}
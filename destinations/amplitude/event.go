@@ -0,0 +1,167 @@
+package amplitude
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/nunchistudio/blacksmith-modules/amplitude/amplitudedestination"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+endpoint is the endpoint of the Amplitude HTTP API.
+*/
+var endpoint = "https://api2.amplitude.com/2/httpapi"
+
+/*
+statusForceDiscards holds informations if a job must be discarded based on
+the status code returned by the Amplitude API, as for the destination of the
+modules.
+
+Reference: https://developers.amplitude.com/docs/http-api-v2#response-format
+*/
+var statusForceDiscards = map[int]bool{
+	400: true,
+	413: true,
+	422: true,
+	429: false,
+	500: false,
+	502: false,
+	503: false,
+	504: false,
+}
+
+/*
+Event is an Amplitude event alongside the session it is part of. The session ID
+is the time at which the session started, in milliseconds since the Unix epoch.
+*/
+type Event struct {
+	amplitudedestination.Event
+
+	SessionID int64 `json:"session_id,omitempty"`
+}
+
+/*
+payload is the data of the jobs of the actions, and the body of the requests
+made against the Amplitude HTTP API.
+*/
+type payload struct {
+	APIKey string  `json:"api_key,omitempty"`
+	Events []Event `json:"events"`
+}
+
+/*
+unwrap returns the events of the destination of the modules, so their actions can
+marshal them.
+*/
+func unwrap(events []Event) []amplitudedestination.Event {
+	unwrapped := make([]amplitudedestination.Event, len(events))
+	for i := range events {
+		unwrapped[i] = events[i].Event
+	}
+
+	return unwrapped
+}
+
+/*
+withSessions adds the session IDs of the events to the data of a job marshaled by
+an action of the destination of the modules.
+*/
+func withSessions(j *destination.Job, events []Event) (*destination.Job, error) {
+	var d payload
+	if err := json.Unmarshal(j.Data, &d); err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+		}
+	}
+
+	for i := range d.Events {
+		if i < len(events) {
+			d.Events[i].SessionID = events[i].SessionID
+		}
+	}
+
+	data, err := json.Marshal(&d)
+	if err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+		}
+	}
+
+	j.Data = data
+	return j, nil
+}
+
+/*
+load loads the jobs of a queue into Amplitude. It is shared by the actions since
+their jobs have the same structure.
+*/
+func load(env *Options, client *http.Client, queue *store.Queue, then chan<- destination.Then) {
+	for _, event := range queue.Events {
+		for _, job := range event.Jobs {
+
+			// Unmarshal the context and the data of the job, and apply the context
+			// as the "Event Properties".
+			var c analytics.Context
+			json.Unmarshal(job.Context, &c)
+
+			var d payload
+			json.Unmarshal(job.Data, &d)
+			for i := range d.Events {
+				d.Events[i].Context = &c
+			}
+
+			// Add the Amplitude API key just before loading the data so it is not
+			// saved in the store adapter.
+			d.APIKey = env.APIKey
+			b, _ := json.Marshal(&d)
+
+			req, _ := http.NewRequest("POST", endpoint, bytes.NewReader(b))
+			req.Header.Set("Accept", "*/*")
+			req.Header.Set("Content-Type", "application/json")
+			res, err := client.Do(req)
+			if err != nil {
+				then <- destination.Then{
+					Jobs:         []string{job.ID},
+					ForceDiscard: true,
+					Error: &errors.Error{
+						StatusCode: 500,
+						Message:    err.Error(),
+					},
+				}
+
+				continue
+			}
+
+			if res.StatusCode >= 300 {
+				buf := new(bytes.Buffer)
+				buf.ReadFrom(res.Body)
+				res.Body.Close()
+				then <- destination.Then{
+					Jobs:         []string{job.ID},
+					ForceDiscard: statusForceDiscards[res.StatusCode],
+					Error: &errors.Error{
+						StatusCode: res.StatusCode,
+						Message:    buf.String(),
+					},
+				}
+
+				continue
+			}
+
+			res.Body.Close()
+			then <- destination.Then{
+				Jobs:  []string{job.ID},
+				Error: nil,
+			}
+		}
+	}
+}
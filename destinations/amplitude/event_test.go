@@ -0,0 +1,123 @@
+package amplitude
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+
+	"github.com/nunchistudio/blacksmith-modules/amplitude/amplitudedestination"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		wantErr     bool
+		wantDiscard bool
+	}{
+		{
+			name:   "WithSuccess",
+			status: 200,
+		},
+		{
+			name:        "WithServerError",
+			status:      503,
+			wantErr:     true,
+			wantDiscard: false,
+		},
+		{
+			name:        "WithClientError",
+			status:      400,
+			wantErr:     true,
+			wantDiscard: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				requests++
+				body, _ := ioutil.ReadAll(req.Body)
+				var p payload
+				if err := json.Unmarshal(body, &p); err != nil {
+					t.Errorf("request body is not a valid payload: %s", body)
+				}
+
+				if p.APIKey != "key" || len(p.Events) != 1 {
+					t.Fatalf("request body = %s", body)
+				}
+
+				if p.Events[0].SessionID != 1600000000000 || p.Events[0].Event.Event != "Viewed page 'Home'" || p.Events[0].InsertId == "" {
+					t.Errorf("request body = %s", body)
+				}
+
+				if !bytes.Contains(body, []byte(`"session_id":1600000000000`)) {
+					t.Errorf("request body = %s, want session_id", body)
+				}
+
+				res.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			defer func(url string) {
+				endpoint = url
+			}(endpoint)
+			endpoint = server.URL
+
+			action := Page{
+				env: &Options{
+					APIKey: "key",
+				},
+				client: server.Client(),
+				Events: []Event{
+					{
+						Event: amplitudedestination.Event{
+							UserId: "user",
+							Event:  "Home",
+						},
+						SessionID: 1600000000000,
+					},
+				},
+			}
+
+			job, err := action.Marshal(nil)
+			if err != nil {
+				t.Fatalf("Page.Marshal() error = %v", err)
+			}
+
+			then := make(chan destination.Then, 1)
+			action.Load(nil, &store.Queue{
+				Events: []*store.Event{
+					{
+						Jobs: []*store.Job{
+							{
+								ID:   "job",
+								Data: job.Data,
+							},
+						},
+					},
+				},
+			}, then)
+			close(then)
+
+			result := <-then
+			if (result.Error != nil) != tt.wantErr {
+				t.Errorf("Page.Load() error = %v, wantErr %v", result.Error, tt.wantErr)
+			}
+
+			if result.ForceDiscard != tt.wantDiscard {
+				t.Errorf("Page.Load() discard = %v, want %v", result.ForceDiscard, tt.wantDiscard)
+			}
+
+			if requests != 1 {
+				t.Errorf("Page.Load() requests = %v, want %v", requests, 1)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package customerio
+
+import (
+	"net/http"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Alias implements the Blacksmith destination.Action interface for the action
+"alias". It holds the complete job's structure to send to Customer.io.
+*/
+type Alias struct {
+	env    *Options
+	client *http.Client
+
+	analytics.Alias
+}
+
+/*
+String returns the string representation of the action Alias.
+*/
+func (a Alias) String() string {
+	return "alias"
+}
+
+/*
+Schedule allows the action to override the schedule options of its
+destination. Do not override.
+*/
+func (a Alias) Schedule() *destination.Schedule {
+	return nil
+}
+
+/*
+Marshal is the function being run when the action receives data into
+the Alias receiver. It allows to transform and enrich the data before
+saving it in the store adapter.
+*/
+func (a Alias) Marshal(tk *destination.Toolkit) (*destination.Job, error) {
+	// The previous profile of the user is merged into the one of the new
+	// user ID, which is kept.
+	return newJob(&a.Timestamp, &request{
+		Method: "POST",
+		Path:   "/api/v1/merge_customers",
+		Body: map[string]interface{}{
+			"primary": map[string]string{
+				"id": a.UserId,
+			},
+			"secondary": map[string]string{
+				"id": a.PreviousId,
+			},
+		},
+		Skip: a.UserId == "" || a.PreviousId == "" || a.UserId == a.PreviousId,
+	})
+}
+
+/*
+Load is the function being run by the scheduler to load the data into
+the destination. It is in charge of the "L" in the ETL process.
+*/
+func (a Alias) Load(tk *destination.Toolkit, queue *store.Queue, then chan<- destination.Then) {
+	load(a.env, a.client, queue, then)
+}
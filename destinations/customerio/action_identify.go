@@ -0,0 +1,65 @@
+package customerio
+
+import (
+	"net/http"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Identify implements the Blacksmith destination.Action interface for the action
+"identify". It holds the complete job's structure to send to Customer.io.
+*/
+type Identify struct {
+	env    *Options
+	client *http.Client
+
+	analytics.Identify
+}
+
+/*
+String returns the string representation of the action Identify.
+*/
+func (a Identify) String() string {
+	return "identify"
+}
+
+/*
+Schedule allows the action to override the schedule options of its
+destination. Do not override.
+*/
+func (a Identify) Schedule() *destination.Schedule {
+	return nil
+}
+
+/*
+Marshal is the function being run when the action receives data into
+the Identify receiver. It allows to transform and enrich the data before
+saving it in the store adapter.
+*/
+func (a Identify) Marshal(tk *destination.Toolkit) (*destination.Job, error) {
+	// Customer.io can not create a customer without an identifier. Traits of
+	// anonymous users reach the customer once the user is identified.
+	if a.UserId == "" {
+		return newJob(&a.Timestamp, &request{
+			Skip: true,
+		})
+	}
+
+	return newJob(&a.Timestamp, &request{
+		Method: "PUT",
+		Path:   customerPath(a.UserId),
+		Body:   attributes(a.Traits, a.AnonymousId),
+	})
+}
+
+/*
+Load is the function being run by the scheduler to load the data into
+the destination. It is in charge of the "L" in the ETL process.
+*/
+func (a Identify) Load(tk *destination.Toolkit, queue *store.Queue, then chan<- destination.Then) {
+	load(a.env, a.client, queue, then)
+}
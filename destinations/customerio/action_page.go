@@ -0,0 +1,53 @@
+package customerio
+
+import (
+	"net/http"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Page implements the Blacksmith destination.Action interface for the action
+"page". It holds the complete job's structure to send to Customer.io.
+*/
+type Page struct {
+	env    *Options
+	client *http.Client
+
+	analytics.Page
+}
+
+/*
+String returns the string representation of the action Page.
+*/
+func (a Page) String() string {
+	return "page"
+}
+
+/*
+Schedule allows the action to override the schedule options of its
+destination. Do not override.
+*/
+func (a Page) Schedule() *destination.Schedule {
+	return nil
+}
+
+/*
+Marshal is the function being run when the action receives data into
+the Page receiver. It allows to transform and enrich the data before
+saving it in the store adapter.
+*/
+func (a Page) Marshal(tk *destination.Toolkit) (*destination.Job, error) {
+	return newJob(&a.Timestamp, eventRequest("page", pageName(a.Page), a.UserId, a.AnonymousId, a.Timestamp, pageData(a.Page)))
+}
+
+/*
+Load is the function being run by the scheduler to load the data into
+the destination. It is in charge of the "L" in the ETL process.
+*/
+func (a Page) Load(tk *destination.Toolkit, queue *store.Queue, then chan<- destination.Then) {
+	load(a.env, a.client, queue, then)
+}
@@ -0,0 +1,7 @@
+package customerio
+
+import (
+	"github.com/nunchistudio/blacksmith/destination"
+)
+
+var _ destination.Action = Page{}
@@ -0,0 +1,58 @@
+package customerio
+
+import (
+	"net/http"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Track implements the Blacksmith destination.Action interface for the action
+"track". It holds the complete job's structure to send to Customer.io.
+*/
+type Track struct {
+	env    *Options
+	client *http.Client
+
+	analytics.Track
+}
+
+/*
+String returns the string representation of the action Track.
+*/
+func (a Track) String() string {
+	return "track"
+}
+
+/*
+Schedule allows the action to override the schedule options of its
+destination. Do not override.
+*/
+func (a Track) Schedule() *destination.Schedule {
+	return nil
+}
+
+/*
+Marshal is the function being run when the action receives data into
+the Track receiver. It allows to transform and enrich the data before
+saving it in the store adapter.
+*/
+func (a Track) Marshal(tk *destination.Toolkit) (*destination.Job, error) {
+	data := map[string]interface{}{}
+	for key, value := range a.Properties {
+		data[key] = value
+	}
+
+	return newJob(&a.Timestamp, eventRequest("", a.Event, a.UserId, a.AnonymousId, a.Timestamp, data))
+}
+
+/*
+Load is the function being run by the scheduler to load the data into
+the destination. It is in charge of the "L" in the ETL process.
+*/
+func (a Track) Load(tk *destination.Toolkit, queue *store.Queue, then chan<- destination.Then) {
+	load(a.env, a.client, queue, then)
+}
@@ -0,0 +1,95 @@
+/*
+Package customerio implements a Blacksmith destination sending the events to
+Customer.io for lifecycle messaging. Identify events create or update customers,
+Track and Page events are sent as events and page views, and Alias events merge
+customers.
+*/
+package customerio
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/logger"
+)
+
+/*
+CustomerIO implements the Blacksmith destination.Destination interface for the
+destination "customerio".
+*/
+type CustomerIO struct {
+	options *destination.Options
+	env     *Options
+	client  *http.Client
+}
+
+/*
+New returns a valid Blacksmith destination.Destination for Customer.io.
+*/
+func New(env *Options) destination.Destination {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &CustomerIO{
+		options: &destination.Options{
+			DefaultSchedule: &destination.Schedule{
+				Realtime:   env.Realtime,
+				Interval:   env.Interval,
+				MaxRetries: env.MaxRetries,
+			},
+			DefaultVersion: "v1.0",
+			Versions: map[string]time.Time{
+				"v1.0": time.Time{},
+			},
+		},
+		env: env,
+		client: &http.Client{
+			Timeout: env.Timeout,
+		},
+	}
+}
+
+/*
+String returns the string representation of the destination CustomerIO.
+*/
+func (d *CustomerIO) String() string {
+	return "customerio"
+}
+
+/*
+Options returns common destination options for Customer.io. They will be shared
+across every actions of this destination, except when overridden.
+*/
+func (d *CustomerIO) Options() *destination.Options {
+	return d.options
+}
+
+/*
+Actions return a list of actions the destination CustomerIO is able to handle.
+*/
+func (d *CustomerIO) Actions() map[string]destination.Action {
+	return map[string]destination.Action{
+		"identify": Identify{
+			env:    d.env,
+			client: d.client,
+		},
+		"track": Track{
+			env:    d.env,
+			client: d.client,
+		},
+		"alias": Alias{
+			env:    d.env,
+			client: d.client,
+		},
+		"page": Page{
+			env:    d.env,
+			client: d.client,
+		},
+	}
+}
@@ -0,0 +1,27 @@
+package customerio
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/destination"
+)
+
+var _ destination.Destination = &CustomerIO{}
+
+func TestCustomerIO_Actions(t *testing.T) {
+	d := New(&Options{
+		SiteID: "site",
+		APIKey: "key",
+	})
+
+	if d.String() != "customerio" {
+		t.Errorf("CustomerIO.String() = %v, want %v", d.String(), "customerio")
+	}
+
+	actions := d.Actions()
+	for _, name := range []string{"identify", "track", "alias", "page"} {
+		if _, exists := actions[name]; !exists {
+			t.Errorf("CustomerIO.Actions() is missing action %v", name)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package customerio
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/nunchistudio/fragment/destinations/retry"
+)
+
+/*
+received is a request as saved in the data of a job.
+*/
+type received struct {
+	Method    string          `json:"method"`
+	Path      string          `json:"path"`
+	Body      json.RawMessage `json:"body"`
+	Anonymous bool            `json:"anonymous"`
+	Skip      bool            `json:"skip"`
+}
+
+/*
+load goes through every events received from the queue and their related jobs,
+and sends their request to Customer.io. Jobs with nothing to send, and the ones of
+anonymous users when they must be dropped, are marked as succeeded without being
+sent.
+*/
+func load(env *Options, client *http.Client, queue *store.Queue, then chan<- destination.Then) {
+	for _, event := range queue.Events {
+		for _, job := range event.Jobs {
+			var r received
+			if err := json.Unmarshal(job.Data, &r); err != nil {
+				then <- destination.Then{
+					Jobs:         []string{job.ID},
+					ForceDiscard: true,
+					Error: &errors.Error{
+						StatusCode: 400,
+						Message:    err.Error(),
+					},
+				}
+
+				continue
+			}
+
+			if r.Skip || (r.Anonymous && env.Anonymous == AnonymousDrop) {
+				then <- destination.Then{
+					Jobs:  []string{job.ID},
+					Error: nil,
+				}
+
+				continue
+			}
+
+			then <- send(env, client, job.ID, &r)
+		}
+	}
+}
+
+/*
+send sends the request of a job to Customer.io, and returns the result of the job
+to send to the scheduler. A failure is retried by the scheduler, unless the
+request is invalid.
+*/
+func send(env *Options, client *http.Client, job string, r *received) destination.Then {
+	req, _ := http.NewRequest(r.Method, env.Endpoint+r.Path, bytes.NewReader(r.Body))
+	req.SetBasicAuth(env.SiteID, env.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return destination.Then{
+			Jobs: []string{job},
+			Error: &errors.Error{
+				StatusCode: 500,
+				Message:    err.Error(),
+			},
+		}
+	}
+
+	// Since a non-2xx status code doesn't cause an error, catch HTTP status
+	// code to ensure nothing bad happened.
+	buf := new(bytes.Buffer)
+	buf.ReadFrom(res.Body)
+	res.Body.Close()
+	if res.StatusCode >= 300 {
+		return destination.Then{
+			Jobs:         []string{job},
+			ForceDiscard: discard(res.StatusCode),
+			Error: &errors.Error{
+				StatusCode: res.StatusCode,
+				Message:    buf.String(),
+			},
+		}
+	}
+
+	// Finally, inform the scheduler about the success.
+	return destination.Then{
+		Jobs:  []string{job},
+		Error: nil,
+	}
+}
+
+/*
+discard returns if a job must be discarded given the status code returned by
+Customer.io. It overrides retry.Discard to retry authentication errors as well,
+since they are fixed by updating the credentials rather than the job.
+*/
+func discard(status int) bool {
+	if status == 401 {
+		return false
+	}
+
+	return retry.Discard(status)
+}
@@ -0,0 +1,144 @@
+package customerio
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name         string
+		anonymous    Anonymous
+		userID       string
+		status       int
+		wantPath     string
+		wantRequests int
+		wantErr      bool
+		wantDiscard  bool
+	}{
+		{
+			name:         "WithCustomer",
+			userID:       "user",
+			status:       200,
+			wantPath:     "/api/v1/customers/user/events",
+			wantRequests: 1,
+		},
+		{
+			name:         "WithAnonymousSent",
+			anonymous:    AnonymousSend,
+			status:       200,
+			wantPath:     "/api/v1/events",
+			wantRequests: 1,
+		},
+		{
+			name:         "WithAnonymousDropped",
+			anonymous:    AnonymousDrop,
+			status:       200,
+			wantRequests: 0,
+		},
+		{
+			name:         "WithRateLimit",
+			userID:       "user",
+			status:       429,
+			wantPath:     "/api/v1/customers/user/events",
+			wantRequests: 1,
+			wantErr:      true,
+			wantDiscard:  false,
+		},
+		{
+			name:         "WithInvalidCredentials",
+			userID:       "user",
+			status:       401,
+			wantPath:     "/api/v1/customers/user/events",
+			wantRequests: 1,
+			wantErr:      true,
+			wantDiscard:  false,
+		},
+		{
+			name:         "WithInvalidRequest",
+			userID:       "user",
+			status:       400,
+			wantPath:     "/api/v1/customers/user/events",
+			wantRequests: 1,
+			wantErr:      true,
+			wantDiscard:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				requests++
+				if site, key, ok := req.BasicAuth(); !ok || site != "site" || key != "key" {
+					t.Errorf("request is missing credentials")
+				}
+
+				if req.URL.Path != tt.wantPath {
+					t.Errorf("request path = %v, want %v", req.URL.Path, tt.wantPath)
+				}
+
+				res.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			env := &Options{
+				SiteID:    "site",
+				APIKey:    "key",
+				Endpoint:  server.URL,
+				Anonymous: tt.anonymous,
+			}
+			env.validate()
+
+			action := Track{
+				env:    env,
+				client: server.Client(),
+				Track: analytics.Track{
+					UserId:      tt.userID,
+					AnonymousId: "anon",
+					Event:       "Order Completed",
+					Timestamp:   time.Now(),
+				},
+			}
+
+			job, err := action.Marshal(nil)
+			if err != nil {
+				t.Fatalf("Track.Marshal() error = %v", err)
+			}
+
+			then := make(chan destination.Then, 1)
+			action.Load(nil, &store.Queue{
+				Events: []*store.Event{
+					{
+						Jobs: []*store.Job{
+							{
+								ID:   "job",
+								Data: job.Data,
+							},
+						},
+					},
+				},
+			}, then)
+			close(then)
+
+			result := <-then
+			if (result.Error != nil) != tt.wantErr {
+				t.Errorf("Track.Load() error = %v, wantErr %v", result.Error, tt.wantErr)
+			}
+
+			if result.ForceDiscard != tt.wantDiscard {
+				t.Errorf("Track.Load() discard = %v, want %v", result.ForceDiscard, tt.wantDiscard)
+			}
+
+			if requests != tt.wantRequests {
+				t.Errorf("Track.Load() requests = %v, want %v", requests, tt.wantRequests)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package customerio
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+)
+
+/*
+Anonymous is a custom type allowing the user to only pass supported behaviors for
+the events of users not identified yet.
+*/
+type Anonymous string
+
+/*
+AnonymousSend is used to send the events of anonymous users as anonymous events.
+Customer.io attaches them to the customer once it is identified with the same
+anonymous ID, so they can trigger campaigns retroactively.
+*/
+var AnonymousSend Anonymous = "send"
+
+/*
+AnonymousDrop is used to ignore the events of anonymous users.
+*/
+var AnonymousDrop Anonymous = "drop"
+
+/*
+Defaults are the defaults options set for the destination. When not set, these
+values will automatically be applied.
+*/
+var Defaults = &Options{
+	Endpoint:  "https://track.customer.io",
+	Anonymous: AnonymousSend,
+	Timeout:   10 * time.Second,
+}
+
+/*
+Options is the options the destination can take as an input to be configured.
+*/
+type Options struct {
+
+	// Realtime indicates if the pubsub adapter of the Blacksmith application shall
+	// be used to load events to the destination in realtime or not. When false, the
+	// Interval will be used.
+	Realtime bool
+
+	// Interval represents an interval or a CRON string at which a job shall be
+	// loaded to the destination. It is used as the time-lapse between retries in
+	// case of a job failure.
+	//
+	// Defaults to "@every 1h".
+	Interval string
+
+	// MaxRetries indicates the maximum number of retries per job the scheduler will
+	// attempt to execute before it succeed. When the limit is reached, the job is
+	// marked as "discarded".
+	//
+	// Defaults to 72.
+	MaxRetries uint16
+
+	// SiteID is the site ID of the Customer.io workspace.
+	//
+	// Required.
+	SiteID string
+
+	// APIKey is the Track API key of the Customer.io workspace.
+	//
+	// Required.
+	APIKey string
+
+	// Endpoint is the base URL of the Customer.io Track API. It can be set to
+	// "https://track-eu.customer.io" for workspaces hosted in the EU.
+	//
+	// Defaults to "https://track.customer.io".
+	Endpoint string
+
+	// Anonymous is the behavior to apply to the Track and Page events of users not
+	// identified yet.
+	//
+	// Defaults to AnonymousSend.
+	Anonymous Anonymous
+
+	// Timeout is the time limit for a request made to Customer.io.
+	//
+	// Defaults to 10 seconds.
+	Timeout time.Duration
+}
+
+/*
+validate ensures the options passed to initialize the destination are valid.
+*/
+func (env *Options) validate() error {
+	var interval string = destination.Defaults.DefaultSchedule.Interval
+	var maxRetries uint16 = destination.Defaults.DefaultSchedule.MaxRetries
+
+	fail := &errors.Error{
+		Message:     "destination/customerio: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Destinations", "customerio"},
+		})
+
+		return fail
+	}
+
+	if env.Interval == "" {
+		env.Interval = interval
+	}
+
+	if env.MaxRetries == 0 {
+		env.MaxRetries = maxRetries
+	}
+
+	if env.SiteID == "" {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Customer.io site ID must be set",
+			Path:    []string{"Options", "Destinations", "customerio", "SiteID"},
+		})
+	}
+
+	if env.APIKey == "" {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Customer.io API key must be set",
+			Path:    []string{"Options", "Destinations", "customerio", "APIKey"},
+		})
+	}
+
+	if env.Endpoint == "" {
+		env.Endpoint = Defaults.Endpoint
+	}
+
+	parsed, err := url.Parse(env.Endpoint)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Endpoint must be a valid HTTP or HTTPS URL",
+			Path:    []string{"Options", "Destinations", "customerio", "Endpoint"},
+		})
+	}
+
+	if env.Anonymous == "" {
+		env.Anonymous = Defaults.Anonymous
+	}
+
+	if env.Anonymous != AnonymousSend && env.Anonymous != AnonymousDrop {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Anonymous behavior '" + string(env.Anonymous) + "' is not supported",
+			Path:    []string{"Options", "Destinations", "customerio", "Anonymous"},
+		})
+	}
+
+	if env.Timeout == 0 {
+		env.Timeout = Defaults.Timeout
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package customerio
+
+import (
+	"testing"
+)
+
+func TestOptions_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  *Options
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: true,
+		},
+		{
+			name: "WithCredentials",
+			fields: &Options{
+				SiteID: "site",
+				APIKey: "key",
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithAnonymousDrop",
+			fields: &Options{
+				SiteID:    "site",
+				APIKey:    "key",
+				Anonymous: AnonymousDrop,
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithUnsupportedAnonymous",
+			fields: &Options{
+				SiteID:    "site",
+				APIKey:    "key",
+				Anonymous: "identify",
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithInvalidEndpoint",
+			fields: &Options{
+				SiteID:   "site",
+				APIKey:   "key",
+				Endpoint: "track.customer.io",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
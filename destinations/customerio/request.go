@@ -0,0 +1,177 @@
+package customerio
+
+import (
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/nunchistudio/fragment/normalize"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+request is a request to send to the Customer.io Track API. It is the data of the
+jobs created by the actions.
+*/
+type request struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Body   interface{} `json:"body"`
+
+	// Anonymous indicates the request is related to a user not identified yet,
+	// so the behavior set in the options applies.
+	Anonymous bool `json:"anonymous,omitempty"`
+
+	// Skip indicates there is nothing to send, such as for an Identify event
+	// without user ID since Customer.io can not create a customer without it.
+	Skip bool `json:"skip,omitempty"`
+}
+
+/*
+newJob returns a job holding the request to send to Customer.io.
+*/
+func newJob(timestamp *time.Time, r *request) (*destination.Job, error) {
+
+	// Try to marshal the request created by the action.
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+		}
+	}
+
+	// Create a job with the data. Since the 'Context' key is not
+	// set, the one from the event will automatically be applied.
+	j := &destination.Job{
+		Version: "v1.0",
+		Data:    data,
+		SentAt:  timestamp,
+	}
+
+	// Return the job including the marshaled data.
+	return j, nil
+}
+
+/*
+customerPath returns the path of a customer in the Track API.
+*/
+func customerPath(userID string) string {
+	return "/api/v1/customers/" + url.PathEscape(userID)
+}
+
+/*
+eventRequest returns the request sending an event of a user. Events of identified
+users are attached to the customer, and events of anonymous users are sent as
+anonymous events.
+*/
+func eventRequest(typ string, name string, userID string, anonymousID string, timestamp time.Time, data map[string]interface{}) *request {
+	body := map[string]interface{}{
+		"name": name,
+		"data": data,
+	}
+
+	if typ != "" {
+		body["type"] = typ
+	}
+
+	if !timestamp.IsZero() {
+		body["timestamp"] = timestamp.Unix()
+	}
+
+	if userID != "" {
+		return &request{
+			Method: "POST",
+			Path:   customerPath(userID) + "/events",
+			Body:   body,
+		}
+	}
+
+	body["anonymous_id"] = anonymousID
+	return &request{
+		Method:    "POST",
+		Path:      "/api/v1/events",
+		Body:      body,
+		Anonymous: true,
+		Skip:      anonymousID == "",
+	}
+}
+
+/*
+attributes returns the attributes of a customer from the traits of a user. The
+creation date is converted to a Unix timestamp as expected by Customer.io, and the
+anonymous ID is added so Customer.io merges the anonymous events of the user into
+the customer.
+*/
+func attributes(traits map[string]interface{}, anonymousID string) map[string]interface{} {
+	attrs := make(map[string]interface{}, len(traits)+1)
+	for key, value := range traits {
+		if normalize.Snake(key) == "created_at" {
+			if s, ok := value.(string); ok {
+				if t, err := time.Parse(time.RFC3339, s); err == nil {
+					attrs["created_at"] = t.Unix()
+					continue
+				}
+			}
+		}
+
+		attrs[key] = value
+	}
+
+	if anonymousID != "" {
+		attrs["anonymous_id"] = anonymousID
+	}
+
+	return attrs
+}
+
+/*
+pageName returns the name of a page view, which is the URL of the page as expected
+by Customer.io. It falls back to the name of the page when the URL is unknown.
+*/
+func pageName(page analytics.Page) string {
+	if u, ok := page.Properties["url"].(string); ok && u != "" {
+		return u
+	}
+
+	if page.Context != nil && page.Context.Page.URL != "" {
+		return page.Context.Page.URL
+	}
+
+	return page.Name
+}
+
+/*
+pageData returns the data of a page view, including the name and the page
+information of the context.
+*/
+func pageData(page analytics.Page) map[string]interface{} {
+	data := map[string]interface{}{}
+	if page.Context != nil {
+		info := page.Context.Page
+		for key, value := range map[string]string{
+			"path":     info.Path,
+			"title":    info.Title,
+			"referrer": info.Referrer,
+			"search":   info.Search,
+		} {
+			if value != "" {
+				data[key] = value
+			}
+		}
+	}
+
+	if page.Name != "" {
+		data["name"] = page.Name
+	}
+
+	for key, value := range page.Properties {
+		data[key] = value
+	}
+
+	return data
+}
@@ -0,0 +1,109 @@
+package customerio
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+func TestEventRequest(t *testing.T) {
+	timestamp := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		userID        string
+		anonymousID   string
+		wantPath      string
+		wantAnonymous bool
+		wantSkip      bool
+	}{
+		{
+			name:        "WithUserID",
+			userID:      "user/1",
+			anonymousID: "anon",
+			wantPath:    "/api/v1/customers/user%2F1/events",
+		},
+		{
+			name:          "WithAnonymousID",
+			anonymousID:   "anon",
+			wantPath:      "/api/v1/events",
+			wantAnonymous: true,
+		},
+		{
+			name:          "WithoutIDs",
+			wantPath:      "/api/v1/events",
+			wantAnonymous: true,
+			wantSkip:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := eventRequest("", "Order Completed", tt.userID, tt.anonymousID, timestamp, nil)
+			if r.Path != tt.wantPath || r.Anonymous != tt.wantAnonymous || r.Skip != tt.wantSkip {
+				t.Errorf("eventRequest() = %v %v %v, want %v %v %v", r.Path, r.Anonymous, r.Skip, tt.wantPath, tt.wantAnonymous, tt.wantSkip)
+			}
+
+			body := r.Body.(map[string]interface{})
+			if body["timestamp"] != timestamp.Unix() {
+				t.Errorf("eventRequest() timestamp = %v, want %v", body["timestamp"], timestamp.Unix())
+			}
+		})
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	got := attributes(map[string]interface{}{
+		"email":     "jane@example.com",
+		"createdAt": "2026-10-18T12:00:00Z",
+	}, "anon")
+
+	want := map[string]interface{}{
+		"email":        "jane@example.com",
+		"created_at":   time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC).Unix(),
+		"anonymous_id": "anon",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("attributes() = %v, want %v", got, want)
+	}
+}
+
+func TestPageName(t *testing.T) {
+	tests := []struct {
+		name string
+		page analytics.Page
+		want string
+	}{
+		{
+			name: "WithURLProperty",
+			page: analytics.Page{
+				Name:       "Pricing",
+				Properties: analytics.Properties{"url": "https://example.com/pricing"},
+			},
+			want: "https://example.com/pricing",
+		},
+		{
+			name: "WithContextURL",
+			page: analytics.Page{
+				Name: "Pricing",
+				Context: &analytics.Context{
+					Page: analytics.PageInfo{URL: "https://example.com/"},
+				},
+			},
+			want: "https://example.com/",
+		},
+		{
+			name: "WithNameOnly",
+			page: analytics.Page{Name: "Pricing"},
+			want: "Pricing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pageName(tt.page); got != tt.want {
+				t.Errorf("pageName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
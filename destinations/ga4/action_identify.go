@@ -0,0 +1,83 @@
+package ga4
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Identify implements the Blacksmith destination.Action interface for the action
+"identify". It holds the complete job's structure to send to Google Analytics 4.
+*/
+type Identify struct {
+	env    *Options
+	client *http.Client
+
+	analytics.Identify
+}
+
+/*
+String returns the string representation of the action Identify.
+*/
+func (a Identify) String() string {
+	return "identify"
+}
+
+/*
+Schedule allows the action to override the schedule options of its
+destination. Do not override.
+*/
+func (a Identify) Schedule() *destination.Schedule {
+	return nil
+}
+
+/*
+Marshal is the function being run when the action receives data into
+the Identify receiver. It allows to transform and enrich the data before
+saving it in the store adapter.
+*/
+func (a Identify) Marshal(tk *destination.Toolkit) (*destination.Job, error) {
+	p, err := newPayload(a.AnonymousId, a.UserId, a.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	// The Measurement Protocol requires at least one event per request, so the
+	// user properties are sent along a custom "identify" event.
+	p.setUserProperties(a.Traits)
+	p.Events = append(p.Events, newEvent("identify", a.Context))
+
+	// Try to marshal the payload sent to the Measurement Protocol.
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+		}
+	}
+
+	// Create a job with the data. Since the 'Context' key is not
+	// set, the one from the event will automatically be applied.
+	j := &destination.Job{
+		Version: "v1.0",
+		Data:    data,
+		SentAt:  &a.Timestamp,
+	}
+
+	// Return the job including the marshaled data.
+	return j, nil
+}
+
+/*
+Load is the function being run by the scheduler to load the data into
+the destination. It is in charge of the "L" in the ETL process.
+*/
+func (a Identify) Load(tk *destination.Toolkit, queue *store.Queue, then chan<- destination.Then) {
+	load(a.env, a.client, queue, then)
+}
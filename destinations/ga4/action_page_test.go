@@ -0,0 +1,7 @@
+package ga4
+
+import (
+	"github.com/nunchistudio/blacksmith/destination"
+)
+
+var _ destination.Action = Page{}
@@ -0,0 +1,82 @@
+package ga4
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Track implements the Blacksmith destination.Action interface for the action
+"track". It holds the complete job's structure to send to Google Analytics 4.
+*/
+type Track struct {
+	env    *Options
+	client *http.Client
+
+	analytics.Track
+}
+
+/*
+String returns the string representation of the action Track.
+*/
+func (a Track) String() string {
+	return "track"
+}
+
+/*
+Schedule allows the action to override the schedule options of its
+destination. Do not override.
+*/
+func (a Track) Schedule() *destination.Schedule {
+	return nil
+}
+
+/*
+Marshal is the function being run when the action receives data into
+the Track receiver. It allows to transform and enrich the data before
+saving it in the store adapter.
+*/
+func (a Track) Marshal(tk *destination.Toolkit) (*destination.Job, error) {
+	p, err := newPayload(a.AnonymousId, a.UserId, a.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map the event to its GA4 recommended event when it is part of the
+	// Segment spec, or send it as a custom event.
+	p.Events = append(p.Events, trackEvent(a.Track))
+
+	// Try to marshal the payload sent to the Measurement Protocol.
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+		}
+	}
+
+	// Create a job with the data. Since the 'Context' key is not
+	// set, the one from the event will automatically be applied.
+	j := &destination.Job{
+		Version: "v1.0",
+		Data:    data,
+		SentAt:  &a.Timestamp,
+	}
+
+	// Return the job including the marshaled data.
+	return j, nil
+}
+
+/*
+Load is the function being run by the scheduler to load the data into
+the destination. It is in charge of the "L" in the ETL process.
+*/
+func (a Track) Load(tk *destination.Toolkit, queue *store.Queue, then chan<- destination.Then) {
+	load(a.env, a.client, queue, then)
+}
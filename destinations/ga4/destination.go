@@ -0,0 +1,91 @@
+/*
+Package ga4 implements a Blacksmith destination sending the events to Google
+Analytics 4 using the Measurement Protocol. Track events of the Segment ecommerce
+spec are mapped to the GA4 recommended events, pages are sent as "page_view"
+events, and the traits of identified users as user properties.
+*/
+package ga4
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/logger"
+)
+
+/*
+GA4 implements the Blacksmith destination.Destination interface for the
+destination "ga4".
+*/
+type GA4 struct {
+	options *destination.Options
+	env     *Options
+	client  *http.Client
+}
+
+/*
+New returns a valid Blacksmith destination.Destination for Google Analytics 4.
+*/
+func New(env *Options) destination.Destination {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &GA4{
+		options: &destination.Options{
+			DefaultSchedule: &destination.Schedule{
+				Realtime:   env.Realtime,
+				Interval:   env.Interval,
+				MaxRetries: env.MaxRetries,
+			},
+			DefaultVersion: "v1.0",
+			Versions: map[string]time.Time{
+				"v1.0": time.Time{},
+			},
+		},
+		env: env,
+		client: &http.Client{
+			Timeout: env.Timeout,
+		},
+	}
+}
+
+/*
+String returns the string representation of the destination GA4.
+*/
+func (d *GA4) String() string {
+	return "ga4"
+}
+
+/*
+Options returns common destination options for Google Analytics 4. They will be
+shared across every actions of this destination, except when overridden.
+*/
+func (d *GA4) Options() *destination.Options {
+	return d.options
+}
+
+/*
+Actions return a list of actions the destination GA4 is able to handle.
+*/
+func (d *GA4) Actions() map[string]destination.Action {
+	return map[string]destination.Action{
+		"identify": Identify{
+			env:    d.env,
+			client: d.client,
+		},
+		"track": Track{
+			env:    d.env,
+			client: d.client,
+		},
+		"page": Page{
+			env:    d.env,
+			client: d.client,
+		},
+	}
+}
@@ -0,0 +1,27 @@
+package ga4
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/destination"
+)
+
+var _ destination.Destination = &GA4{}
+
+func TestGA4_Actions(t *testing.T) {
+	d := New(&Options{
+		MeasurementID: "G-XXXXXXXXXX",
+		APISecret:     "secret",
+	})
+
+	if d.String() != "ga4" {
+		t.Errorf("GA4.String() = %v, want %v", d.String(), "ga4")
+	}
+
+	actions := d.Actions()
+	for _, name := range []string{"identify", "track", "page"} {
+		if _, exists := actions[name]; !exists {
+			t.Errorf("GA4.Actions() is missing action %v", name)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package ga4
+
+import (
+	"gopkg.in/segmentio/analytics-go.v3"
+
+	"github.com/nunchistudio/fragment/normalize"
+)
+
+/*
+recommended is a GA4 recommended event a Segment spec event is mapped to.
+*/
+type recommended struct {
+
+	// Name is the name of the GA4 event.
+	Name string
+
+	// Product indicates the properties of the Segment event describe a single
+	// product, which is sent as the only item of the GA4 event. Otherwise, the
+	// items are read from the "products" property.
+	Product bool
+
+	// Params are the properties renamed to GA4 parameters for this event, in
+	// addition to the ones shared by every ecommerce events.
+	Params map[string]string
+}
+
+/*
+recommendedEvents maps the events of the Segment ecommerce and B2B SaaS specs to
+the GA4 recommended events. Other events are sent as custom events.
+*/
+var recommendedEvents = map[string]*recommended{
+	"Products Searched": {
+		Name: "search",
+		Params: map[string]string{
+			"query": "search_term",
+		},
+	},
+	"Product List Viewed": {
+		Name: "view_item_list",
+		Params: map[string]string{
+			"list_id":  "item_list_id",
+			"category": "item_list_name",
+		},
+	},
+	"Product Clicked": {
+		Name:    "select_item",
+		Product: true,
+	},
+	"Product Viewed": {
+		Name:    "view_item",
+		Product: true,
+	},
+	"Product Added": {
+		Name:    "add_to_cart",
+		Product: true,
+	},
+	"Product Removed": {
+		Name:    "remove_from_cart",
+		Product: true,
+	},
+	"Product Added to Wishlist": {
+		Name:    "add_to_wishlist",
+		Product: true,
+	},
+	"Product Shared": {
+		Name:    "share",
+		Product: true,
+		Params: map[string]string{
+			"share_via": "method",
+		},
+	},
+	"Cart Viewed": {
+		Name: "view_cart",
+	},
+	"Checkout Started": {
+		Name: "begin_checkout",
+	},
+	"Payment Info Entered": {
+		Name: "add_payment_info",
+	},
+	"Order Completed": {
+		Name: "purchase",
+	},
+	"Order Refunded": {
+		Name: "refund",
+	},
+	"Promotion Viewed": {
+		Name: "view_promotion",
+		Params: map[string]string{
+			"name":     "promotion_name",
+			"creative": "creative_name",
+			"position": "creative_slot",
+		},
+	},
+	"Promotion Clicked": {
+		Name: "select_promotion",
+		Params: map[string]string{
+			"name":     "promotion_name",
+			"creative": "creative_name",
+			"position": "creative_slot",
+		},
+	},
+	"Signed Up": {
+		Name: "sign_up",
+	},
+	"Signed In": {
+		Name: "login",
+	},
+}
+
+/*
+ecommerceParams are the properties renamed to GA4 parameters for every recommended
+events.
+*/
+var ecommerceParams = map[string]string{
+	"order_id":        "transaction_id",
+	"payment_method":  "payment_type",
+	"shipping_method": "shipping_tier",
+}
+
+/*
+itemParams maps the properties of a product in the Segment ecommerce spec to the
+parameters of a GA4 item.
+*/
+var itemParams = map[string]string{
+	"product_id": "item_id",
+	"sku":        "item_id",
+	"name":       "item_name",
+	"category":   "item_category",
+	"brand":      "item_brand",
+	"variant":    "item_variant",
+	"price":      "price",
+	"quantity":   "quantity",
+	"coupon":     "coupon",
+	"position":   "index",
+}
+
+/*
+trackEvent returns the GA4 event of a Track message. Segment spec events are mapped
+to their recommended event, and others are sent as custom events with their name
+converted to snake case.
+*/
+func trackEvent(track analytics.Track) *event {
+	spec, exists := recommendedEvents[track.Event]
+	if !exists {
+		n := name(track.Event, maxNameLength)
+		if n == "" || reservedEvents[n] {
+			n = name("event_"+n, maxNameLength)
+		}
+
+		e := newEvent(n, track.Context)
+		e.add(track.Properties, nil, nil)
+		return e
+	}
+
+	e := newEvent(spec.Name, track.Context)
+	if v, exists := value(track.Properties, spec.Product); exists {
+		e.set("value", v)
+	}
+
+	skip := map[string]bool{
+		"products": true,
+		"value":    true,
+	}
+
+	items := []map[string]interface{}{}
+	if spec.Product {
+		if i := item(track.Properties); i != nil {
+			items = append(items, i)
+		}
+
+		for key := range itemParams {
+			skip[key] = true
+		}
+	} else if products, ok := track.Properties["products"].([]interface{}); ok {
+		for _, product := range products {
+			if len(items) >= maxItems {
+				break
+			}
+
+			if p, ok := product.(map[string]interface{}); ok {
+				if i := item(p); i != nil {
+					items = append(items, i)
+				}
+			}
+		}
+	}
+
+	renames := make(map[string]string, len(ecommerceParams)+len(spec.Params))
+	for key, param := range ecommerceParams {
+		renames[key] = param
+	}
+
+	for key, param := range spec.Params {
+		renames[key] = param
+		delete(skip, key)
+	}
+
+	e.add(track.Properties, renames, skip)
+	if len(items) > 0 {
+		e.Params["items"] = items
+	}
+
+	return e
+}
+
+/*
+value returns the monetary value of an ecommerce event. It is the "value" property
+when set, then the total or the revenue of an order. For single product events it
+is the price of the product multiplied by its quantity.
+*/
+func value(properties map[string]interface{}, product bool) (float64, bool) {
+	for _, key := range []string{"value", "total", "revenue"} {
+		if v, ok := properties[key].(float64); ok {
+			return v, true
+		}
+	}
+
+	if product {
+		price, ok := properties["price"].(float64)
+		if !ok {
+			return 0, false
+		}
+
+		quantity, ok := properties["quantity"].(float64)
+		if !ok {
+			quantity = 1
+		}
+
+		return price * quantity, true
+	}
+
+	return 0, false
+}
+
+/*
+item returns the GA4 item of a product. It returns nil if the product has neither
+an ID nor a name, since GA4 requires one of them.
+*/
+func item(product map[string]interface{}) map[string]interface{} {
+	i := map[string]interface{}{}
+	for key, v := range product {
+		param, exists := itemParams[normalize.Snake(key)]
+		if !exists {
+			continue
+		}
+
+		// The product ID takes precedence over the SKU.
+		if _, set := i[param]; set && normalize.Snake(key) == "sku" {
+			continue
+		}
+
+		if v, ok := scalar(v, maxParamValueLength); ok {
+			i[param] = v
+		}
+	}
+
+	if i["item_id"] == nil && i["item_name"] == nil {
+		return nil
+	}
+
+	return i
+}
@@ -0,0 +1,141 @@
+package ga4
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+func TestTrackEvent(t *testing.T) {
+	tests := []struct {
+		name  string
+		track analytics.Track
+		want  *event
+	}{
+		{
+			name: "WithOrderCompleted",
+			track: analytics.Track{
+				Event: "Order Completed",
+				Properties: analytics.Properties{
+					"order_id": "50314b8e",
+					"revenue":  25.0,
+					"currency": "USD",
+					"products": []interface{}{
+						map[string]interface{}{
+							"product_id": "507f1f77",
+							"sku":        "45790-32",
+							"name":       "Monopoly",
+							"price":      19.0,
+							"quantity":   1.0,
+						},
+						map[string]interface{}{
+							"category": "Games",
+						},
+					},
+				},
+			},
+			want: &event{
+				Name: "purchase",
+				Params: map[string]interface{}{
+					"engagement_time_msec": 1,
+					"transaction_id":       "50314b8e",
+					"value":                25.0,
+					"revenue":              25.0,
+					"currency":             "USD",
+					"items": []map[string]interface{}{
+						{
+							"item_id":   "507f1f77",
+							"item_name": "Monopoly",
+							"price":     19.0,
+							"quantity":  1.0,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "WithProductAdded",
+			track: analytics.Track{
+				Event: "Product Added",
+				Properties: analytics.Properties{
+					"cart_id":    "skdjsidjsdkdj29j",
+					"product_id": "507f1f77",
+					"name":       "Monopoly",
+					"price":      18.99,
+					"quantity":   2.0,
+					"currency":   "USD",
+				},
+			},
+			want: &event{
+				Name: "add_to_cart",
+				Params: map[string]interface{}{
+					"engagement_time_msec": 1,
+					"value":                37.98,
+					"cart_id":              "skdjsidjsdkdj29j",
+					"currency":             "USD",
+					"items": []map[string]interface{}{
+						{
+							"item_id":   "507f1f77",
+							"item_name": "Monopoly",
+							"price":     18.99,
+							"quantity":  2.0,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "WithProductsSearched",
+			track: analytics.Track{
+				Event: "Products Searched",
+				Properties: analytics.Properties{
+					"query": "blue roses",
+				},
+			},
+			want: &event{
+				Name: "search",
+				Params: map[string]interface{}{
+					"engagement_time_msec": 1,
+					"search_term":          "blue roses",
+				},
+			},
+		},
+		{
+			name: "WithCustomEvent",
+			track: analytics.Track{
+				Event: "Video Played",
+				Properties: analytics.Properties{
+					"videoId": "v1",
+					"tags":    []interface{}{"a", "b"},
+				},
+			},
+			want: &event{
+				Name: "video_played",
+				Params: map[string]interface{}{
+					"engagement_time_msec": 1,
+					"video_id":             "v1",
+				},
+			},
+		},
+		{
+			name: "WithReservedEvent",
+			track: analytics.Track{
+				Event: "Error",
+			},
+			want: &event{
+				Name: "event_error",
+				Params: map[string]interface{}{
+					"engagement_time_msec": 1,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trackEvent(tt.track); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("trackEvent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package ga4
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+)
+
+/*
+Defaults are the defaults options set for the destination. When not set, these
+values will automatically be applied.
+*/
+var Defaults = &Options{
+	Endpoint: "https://www.google-analytics.com/mp/collect",
+	Timeout:  10 * time.Second,
+}
+
+/*
+Options is the options the destination can take as an input to be configured.
+*/
+type Options struct {
+
+	// Realtime indicates if the pubsub adapter of the Blacksmith application shall
+	// be used to load events to the destination in realtime or not. When false, the
+	// Interval will be used.
+	Realtime bool
+
+	// Interval represents an interval or a CRON string at which a job shall be
+	// loaded to the destination. It is used as the time-lapse between retries in
+	// case of a job failure.
+	//
+	// Defaults to "@every 1h".
+	Interval string
+
+	// MaxRetries indicates the maximum number of retries per job the scheduler will
+	// attempt to execute before it succeed. When the limit is reached, the job is
+	// marked as "discarded".
+	//
+	// Defaults to 72.
+	MaxRetries uint16
+
+	// MeasurementID is the ID of the GA4 data stream, such as "G-XXXXXXXXXX".
+	//
+	// Required.
+	MeasurementID string
+
+	// APISecret is the Measurement Protocol API secret created in the settings of
+	// the data stream.
+	//
+	// Required.
+	APISecret string
+
+	// Endpoint is the URL of the Measurement Protocol. It can be set to the debug
+	// endpoint "https://www.google-analytics.com/debug/mp/collect" to validate the
+	// events without collecting them.
+	//
+	// Defaults to "https://www.google-analytics.com/mp/collect".
+	Endpoint string
+
+	// Timeout is the time limit for a request made to the Measurement Protocol.
+	//
+	// Defaults to 10 seconds.
+	Timeout time.Duration
+}
+
+/*
+validate ensures the options passed to initialize the destination are valid.
+*/
+func (env *Options) validate() error {
+	var interval string = destination.Defaults.DefaultSchedule.Interval
+	var maxRetries uint16 = destination.Defaults.DefaultSchedule.MaxRetries
+
+	fail := &errors.Error{
+		Message:     "destination/ga4: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Destinations", "ga4"},
+		})
+
+		return fail
+	}
+
+	if env.Interval == "" {
+		env.Interval = interval
+	}
+
+	if env.MaxRetries == 0 {
+		env.MaxRetries = maxRetries
+	}
+
+	if env.MeasurementID == "" {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "GA4 measurement ID must be set",
+			Path:    []string{"Options", "Destinations", "ga4", "MeasurementID"},
+		})
+	}
+
+	if env.APISecret == "" {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "GA4 API secret must be set",
+			Path:    []string{"Options", "Destinations", "ga4", "APISecret"},
+		})
+	}
+
+	if env.Endpoint == "" {
+		env.Endpoint = Defaults.Endpoint
+	}
+
+	parsed, err := url.Parse(env.Endpoint)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Endpoint must be a valid HTTP or HTTPS URL",
+			Path:    []string{"Options", "Destinations", "ga4", "Endpoint"},
+		})
+	}
+
+	if env.Timeout == 0 {
+		env.Timeout = Defaults.Timeout
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package ga4
+
+import (
+	"testing"
+)
+
+func TestOptions_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  *Options
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: true,
+		},
+		{
+			name: "WithCredentials",
+			fields: &Options{
+				MeasurementID: "G-XXXXXXXXXX",
+				APISecret:     "secret",
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithoutAPISecret",
+			fields: &Options{
+				MeasurementID: "G-XXXXXXXXXX",
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithInvalidEndpoint",
+			fields: &Options{
+				MeasurementID: "G-XXXXXXXXXX",
+				APISecret:     "secret",
+				Endpoint:      "www.google-analytics.com/mp/collect",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
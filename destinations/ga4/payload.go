@@ -0,0 +1,317 @@
+package ga4
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/nunchistudio/fragment/normalize"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Limits of the Measurement Protocol. Events, parameters and user properties not
+respecting them are silently dropped by Google Analytics, so they are enforced
+before sending.
+*/
+var (
+	maxNameLength          = 40
+	maxParams              = 25
+	maxParamValueLength    = 100
+	maxPropertyNameLength  = 24
+	maxPropertyValueLength = 36
+	maxProperties          = 25
+	maxItems               = 200
+)
+
+/*
+reservedPrefixes are the prefixes event names, parameters and user properties
+must not start with.
+*/
+var reservedPrefixes = []string{"google_", "ga_", "firebase_"}
+
+/*
+reservedEvents are the event names reserved by Google Analytics.
+*/
+var reservedEvents = map[string]bool{
+	"ad_activeview": true, "ad_click": true, "ad_exposure": true, "ad_query": true,
+	"ad_reward": true, "adunit_exposure": true, "app_clear_data": true,
+	"app_exception": true, "app_install": true, "app_remove": true,
+	"app_store_refund": true, "app_update": true, "app_upgrade": true,
+	"dynamic_link_app_open": true, "dynamic_link_app_update": true,
+	"dynamic_link_first_open": true, "error": true, "first_open": true,
+	"first_visit": true, "in_app_purchase": true, "notification_dismiss": true,
+	"notification_foreground": true, "notification_open": true,
+	"notification_receive": true, "os_update": true, "screen_view": true,
+	"session_start": true, "user_engagement": true,
+}
+
+/*
+payload is the body of a request sent to the Measurement Protocol. It is the data
+of the jobs created by the actions.
+*/
+type payload struct {
+	ClientID        string               `json:"client_id"`
+	UserID          string               `json:"user_id,omitempty"`
+	TimestampMicros int64                `json:"timestamp_micros,omitempty"`
+	UserProperties  map[string]*property `json:"user_properties,omitempty"`
+	Events          []*event             `json:"events"`
+}
+
+/*
+property is the value of a user property.
+*/
+type property struct {
+	Value interface{} `json:"value"`
+}
+
+/*
+event is an event sent to the Measurement Protocol.
+*/
+type event struct {
+	Name   string                 `json:"name"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+/*
+newPayload returns a payload for a message. The client ID is the anonymous ID of
+the message, and falls back to the user ID for server-side events which have no
+anonymous ID.
+*/
+func newPayload(anonymousID string, userID string, timestamp time.Time) (*payload, error) {
+	clientID := anonymousID
+	if clientID == "" {
+		clientID = userID
+	}
+
+	if clientID == "" {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+			Validations: []errors.Validation{
+				{
+					Message: "anonymousId or userId must be set",
+					Path:    []string{"anonymousId"},
+				},
+			},
+		}
+	}
+
+	p := &payload{
+		ClientID: clientID,
+		UserID:   userID,
+		Events:   []*event{},
+	}
+
+	if !timestamp.IsZero() {
+		p.TimestampMicros = timestamp.UnixNano() / int64(time.Microsecond)
+	}
+
+	return p, nil
+}
+
+/*
+newEvent returns an event with the parameters Google Analytics needs to attach it
+to a session and display it in the realtime reports. The session ID is the one set
+by the sessionization of Fragment, if any.
+*/
+func newEvent(name string, ctx *analytics.Context) *event {
+	e := &event{
+		Name: name,
+		Params: map[string]interface{}{
+			"engagement_time_msec": 1,
+		},
+	}
+
+	if ctx != nil {
+		if session, exists := ctx.Extra["session_id"]; exists {
+			e.set("session_id", session)
+		}
+	}
+
+	return e
+}
+
+/*
+set sets a parameter of the event if it is supported by the Measurement Protocol
+and if the limit of parameters is not reached yet. Strings are truncated.
+*/
+func (e *event) set(key string, value interface{}) {
+	if _, exists := e.Params[key]; !exists && len(e.Params) >= maxParams {
+		return
+	}
+
+	if v, ok := scalar(value, maxParamValueLength); ok {
+		e.Params[key] = v
+	}
+}
+
+/*
+add sets the properties of a message as parameters of the event. Keys are renamed
+with renames when present, and keys in skip are ignored. Keys are sorted so the
+same parameters are kept when the limit is reached.
+*/
+func (e *event) add(properties map[string]interface{}, renames map[string]string, skip map[string]bool) {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		snake := normalize.Snake(key)
+		if skip[snake] {
+			continue
+		}
+
+		if _, exists := e.Params[snake]; exists {
+			continue
+		}
+
+		if renamed, exists := renames[snake]; exists {
+			e.set(renamed, properties[key])
+			continue
+		}
+
+		if n := name(key, maxNameLength); n != "" {
+			e.set(n, properties[key])
+		}
+	}
+}
+
+/*
+setUserProperties sets the traits of a user as user properties of the payload.
+*/
+func (p *payload) setUserProperties(traits map[string]interface{}) {
+	keys := make([]string, 0, len(traits))
+	for key := range traits {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		if len(p.UserProperties) >= maxProperties {
+			return
+		}
+
+		n := name(key, maxPropertyNameLength)
+		v, ok := scalar(traits[key], maxPropertyValueLength)
+		if n == "" || !ok {
+			continue
+		}
+
+		if p.UserProperties == nil {
+			p.UserProperties = map[string]*property{}
+		}
+
+		p.UserProperties[n] = &property{
+			Value: v,
+		}
+	}
+}
+
+/*
+name converts a key to a valid name for an event, a parameter or a user property.
+Names are in snake case, only contain ASCII letters, digits and underscores, start
+with a letter, and are truncated to max. It returns an empty string if the key can
+not be converted, such as when it starts with a reserved prefix.
+*/
+func name(key string, max int) string {
+	var b strings.Builder
+	for _, r := range normalize.Snake(key) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		}
+	}
+
+	n := strings.Trim(b.String(), "_")
+	if n == "" || n[0] < 'a' || n[0] > 'z' {
+		return ""
+	}
+
+	for _, prefix := range reservedPrefixes {
+		if strings.HasPrefix(n, prefix) {
+			return ""
+		}
+	}
+
+	if len(n) > max {
+		n = strings.TrimRight(n[:max], "_")
+	}
+
+	return n
+}
+
+/*
+scalar returns a value supported by the Measurement Protocol. Strings are truncated
+to max, booleans are converted to strings, and other types such as objects and
+arrays are not supported.
+*/
+func scalar(value interface{}, max int) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		if len(v) > max {
+			runes := []rune(v)
+			for len(string(runes)) > max {
+				runes = runes[:len(runes)-1]
+			}
+
+			v = string(runes)
+		}
+
+		return v, true
+
+	case bool:
+		return strconv.FormatBool(v), true
+
+	case float64, float32, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return v, true
+	}
+
+	return nil, false
+}
+
+/*
+pageEvent returns the GA4 "page_view" event of a Page message. The location, title
+and referrer of the page are read from the properties, and fall back to the page
+information of the context.
+*/
+func pageEvent(page analytics.Page) *event {
+	e := newEvent("page_view", page.Context)
+
+	var info analytics.PageInfo
+	if page.Context != nil {
+		info = page.Context.Page
+	}
+
+	params := []struct {
+		param    string
+		property string
+		fallback string
+	}{
+		{param: "page_location", property: "url", fallback: info.URL},
+		{param: "page_title", property: "title", fallback: info.Title},
+		{param: "page_referrer", property: "referrer", fallback: info.Referrer},
+	}
+
+	skip := map[string]bool{}
+	for _, p := range params {
+		skip[p.property] = true
+		if v, ok := page.Properties[p.property].(string); ok && v != "" {
+			e.set(p.param, v)
+		} else if p.fallback != "" {
+			e.set(p.param, p.fallback)
+		}
+	}
+
+	if _, exists := e.Params["page_title"]; !exists && page.Name != "" {
+		e.set("page_title", page.Name)
+	}
+
+	e.add(page.Properties, nil, skip)
+	return e
+}
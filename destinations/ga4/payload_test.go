@@ -0,0 +1,158 @@
+package ga4
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+func TestNewPayload(t *testing.T) {
+	timestamp := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		anonymousID  string
+		userID       string
+		wantClientID string
+		wantErr      bool
+	}{
+		{
+			name:         "WithAnonymousID",
+			anonymousID:  "anon",
+			userID:       "user",
+			wantClientID: "anon",
+		},
+		{
+			name:         "WithUserIDOnly",
+			userID:       "user",
+			wantClientID: "user",
+		},
+		{
+			name:    "WithoutIDs",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := newPayload(tt.anonymousID, tt.userID, timestamp)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newPayload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if p.ClientID != tt.wantClientID {
+				t.Errorf("newPayload() client_id = %v, want %v", p.ClientID, tt.wantClientID)
+			}
+
+			if p.TimestampMicros != timestamp.UnixNano()/1000 {
+				t.Errorf("newPayload() timestamp_micros = %v, want %v", p.TimestampMicros, timestamp.UnixNano()/1000)
+			}
+		})
+	}
+}
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		key  string
+		max  int
+		want string
+	}{
+		{key: "Video Played", max: 40, want: "video_played"},
+		{key: "planName", max: 40, want: "plan_name"},
+		{key: "2fa enabled", max: 40, want: ""},
+		{key: "google_campaign", max: 40, want: ""},
+		{key: "Résumé Uploaded", max: 40, want: "rsum_uploaded"},
+		{key: "subscription_plan_name", max: 12, want: "subscription"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := name(tt.key, tt.max); got != tt.want {
+				t.Errorf("name() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScalar(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		want   interface{}
+		wantOk bool
+	}{
+		{name: "WithString", value: "pro", want: "pro", wantOk: true},
+		{name: "WithLongString", value: strings.Repeat("a", 120), want: strings.Repeat("a", 100), wantOk: true},
+		{name: "WithNumber", value: 42.5, want: 42.5, wantOk: true},
+		{name: "WithBoolean", value: true, want: "true", wantOk: true},
+		{name: "WithObject", value: map[string]interface{}{"a": 1}, want: nil, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := scalar(tt.value, maxParamValueLength)
+			if ok != tt.wantOk || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scalar() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestPageEvent(t *testing.T) {
+	page := analytics.Page{
+		Name: "Pricing",
+		Properties: analytics.Properties{
+			"url":  "https://example.com/pricing",
+			"plan": "pro",
+		},
+		Context: &analytics.Context{
+			Page: analytics.PageInfo{
+				URL:      "https://example.com/ignored",
+				Referrer: "https://google.com",
+			},
+			Extra: map[string]interface{}{
+				"session_id": float64(1760788800000),
+			},
+		},
+	}
+
+	e := pageEvent(page)
+	want := map[string]interface{}{
+		"engagement_time_msec": 1,
+		"session_id":           float64(1760788800000),
+		"page_location":        "https://example.com/pricing",
+		"page_title":           "Pricing",
+		"page_referrer":        "https://google.com",
+		"plan":                 "pro",
+	}
+
+	if e.Name != "page_view" {
+		t.Errorf("pageEvent() name = %v, want %v", e.Name, "page_view")
+	}
+
+	if !reflect.DeepEqual(e.Params, want) {
+		t.Errorf("pageEvent() params = %v, want %v", e.Params, want)
+	}
+}
+
+func TestPayload_setUserProperties(t *testing.T) {
+	p := &payload{}
+	p.setUserProperties(map[string]interface{}{
+		"plan":      "pro",
+		"createdAt": "2026-10-18T12:00:00Z",
+		"address":   map[string]interface{}{"city": "Paris"},
+		"ga_client": "ignored",
+	})
+
+	want := map[string]*property{
+		"plan":       {Value: "pro"},
+		"created_at": {Value: "2026-10-18T12:00:00Z"},
+	}
+
+	if !reflect.DeepEqual(p.UserProperties, want) {
+		t.Errorf("payload.setUserProperties() = %v, want %v", p.UserProperties, want)
+	}
+}
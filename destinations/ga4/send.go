@@ -0,0 +1,111 @@
+package ga4
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/nunchistudio/fragment/destinations/retry"
+)
+
+/*
+validation is the response returned by the validation server of the Measurement
+Protocol, when the endpoint is the debug one. The production endpoint always
+returns an empty body.
+*/
+type validation struct {
+	ValidationMessages []struct {
+		FieldPath      string `json:"fieldPath"`
+		Description    string `json:"description"`
+		ValidationCode string `json:"validationCode"`
+	} `json:"validationMessages"`
+}
+
+/*
+load goes through every events received from the queue and their related jobs,
+and sends them to the Measurement Protocol. Each job holds a complete payload, so
+it is sent as is.
+*/
+func load(env *Options, client *http.Client, queue *store.Queue, then chan<- destination.Then) {
+	for _, event := range queue.Events {
+		for _, job := range event.Jobs {
+			then <- send(env, client, job)
+		}
+	}
+}
+
+/*
+send sends the payload of a job to the Measurement Protocol, and returns the result
+of the job to send to the scheduler. A failure is retried by the scheduler, unless
+the request is invalid.
+*/
+func send(env *Options, client *http.Client, job *store.Job) destination.Then {
+	query := url.Values{}
+	query.Set("measurement_id", env.MeasurementID)
+	query.Set("api_secret", env.APISecret)
+
+	req, _ := http.NewRequest("POST", env.Endpoint+"?"+query.Encode(), bytes.NewReader(job.Data))
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return destination.Then{
+			Jobs: []string{job.ID},
+			Error: &errors.Error{
+				StatusCode: 500,
+				Message:    err.Error(),
+			},
+		}
+	}
+
+	// Since a non-2xx status code doesn't cause an error, catch HTTP status
+	// code to ensure nothing bad happened.
+	buf := new(bytes.Buffer)
+	buf.ReadFrom(res.Body)
+	res.Body.Close()
+	if res.StatusCode >= 300 {
+		return destination.Then{
+			Jobs:         []string{job.ID},
+			ForceDiscard: retry.Discard(res.StatusCode),
+			Error: &errors.Error{
+				StatusCode: res.StatusCode,
+				Message:    buf.String(),
+			},
+		}
+	}
+
+	// The validation server returns the reasons why the payload is invalid. It
+	// would never be accepted so there is no need to retry.
+	var v validation
+	if json.Unmarshal(buf.Bytes(), &v) == nil && len(v.ValidationMessages) > 0 {
+		fail := &errors.Error{
+			StatusCode:  400,
+			Message:     "Bad Request",
+			Validations: []errors.Validation{},
+		}
+
+		for _, message := range v.ValidationMessages {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: message.Description,
+				Path:    []string{message.FieldPath},
+			})
+		}
+
+		return destination.Then{
+			Jobs:         []string{job.ID},
+			ForceDiscard: true,
+			Error:        fail,
+		}
+	}
+
+	// Finally, inform the scheduler about the success.
+	return destination.Then{
+		Jobs:  []string{job.ID},
+		Error: nil,
+	}
+}
@@ -0,0 +1,129 @@
+package ga4
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		response    string
+		wantErr     bool
+		wantDiscard bool
+	}{
+		{
+			name:   "WithSuccess",
+			status: 204,
+		},
+		{
+			name:        "WithValidationMessages",
+			status:      200,
+			response:    `{"validationMessages":[{"fieldPath":"events","description":"Event name is reserved","validationCode":"NAME_RESERVED"}]}`,
+			wantErr:     true,
+			wantDiscard: true,
+		},
+		{
+			name:        "WithServerError",
+			status:      503,
+			wantErr:     true,
+			wantDiscard: false,
+		},
+		{
+			name:        "WithClientError",
+			status:      400,
+			wantErr:     true,
+			wantDiscard: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				requests++
+				if req.URL.Query().Get("measurement_id") != "G-XXXXXXXXXX" || req.URL.Query().Get("api_secret") != "secret" {
+					t.Errorf("request is missing credentials: %v", req.URL.RawQuery)
+				}
+
+				body, _ := ioutil.ReadAll(req.Body)
+				var p payload
+				if err := json.Unmarshal(body, &p); err != nil {
+					t.Errorf("request body is not a valid payload: %s", body)
+				}
+
+				if p.ClientID != "anon" || p.UserID != "user" || len(p.Events) != 1 || p.Events[0].Name != "purchase" {
+					t.Errorf("request body = %s", body)
+				}
+
+				res.WriteHeader(tt.status)
+				res.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			env := &Options{
+				MeasurementID: "G-XXXXXXXXXX",
+				APISecret:     "secret",
+				Endpoint:      server.URL,
+			}
+			env.validate()
+
+			action := Track{
+				env:    env,
+				client: server.Client(),
+				Track: analytics.Track{
+					AnonymousId: "anon",
+					UserId:      "user",
+					Event:       "Order Completed",
+					Timestamp:   time.Now(),
+					Properties: analytics.Properties{
+						"order_id": "50314b8e",
+						"total":    27.5,
+					},
+				},
+			}
+
+			job, err := action.Marshal(nil)
+			if err != nil {
+				t.Fatalf("Track.Marshal() error = %v", err)
+			}
+
+			then := make(chan destination.Then, 1)
+			action.Load(nil, &store.Queue{
+				Events: []*store.Event{
+					{
+						Jobs: []*store.Job{
+							{
+								ID:   "job",
+								Data: job.Data,
+							},
+						},
+					},
+				},
+			}, then)
+			close(then)
+
+			result := <-then
+			if (result.Error != nil) != tt.wantErr {
+				t.Errorf("Track.Load() error = %v, wantErr %v", result.Error, tt.wantErr)
+			}
+
+			if result.ForceDiscard != tt.wantDiscard {
+				t.Errorf("Track.Load() discard = %v, want %v", result.ForceDiscard, tt.wantDiscard)
+			}
+
+			if requests != 1 {
+				t.Errorf("Track.Load() requests = %v, want %v", requests, 1)
+			}
+		})
+	}
+}
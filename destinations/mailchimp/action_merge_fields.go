@@ -0,0 +1,130 @@
+package mailchimp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+MergeFields implements the Blacksmith destination.Action interface for the action
+"merge_fields". It updates the merge fields of a member of the audience set in the
+options, given the traits of the user and the merge tags set in the options.
+*/
+type MergeFields struct {
+	env    *Options
+	client *http.Client
+
+	Email  string           `json:"email_address"`
+	Traits analytics.Traits `json:"traits"`
+}
+
+/*
+String returns the string representation of the action MergeFields.
+*/
+func (a MergeFields) String() string {
+	return "merge_fields"
+}
+
+/*
+Schedule allows the action to override the schedule options of its
+destination. Do not override.
+*/
+func (a MergeFields) Schedule() *destination.Schedule {
+	return nil
+}
+
+/*
+Marshal is the function being run when the action receives data into
+the MergeFields receiver. It allows to transform and enrich the data before
+saving it in the store adapter.
+*/
+func (a MergeFields) Marshal(tk *destination.Toolkit) (*destination.Job, error) {
+
+	// Ensure the email address is lowercase. As described in the Mailchimp
+	// documentation, the subscriber hash is "the lowercase version of the list
+	// member's email address".
+	a.Email = strings.ToLower(a.Email)
+
+	// Try to marshal the data passed directly to the receiver.
+	data, err := json.Marshal(&a)
+	if err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+		}
+	}
+
+	// Create a job with the data. Since the 'Context' key is not
+	// set, the one from the event will automatically be applied.
+	j := &destination.Job{
+		Version: "v3.0",
+		Data:    data,
+	}
+
+	// Return the job including the marshaled data.
+	return j, nil
+}
+
+/*
+Load is the function being run by the scheduler to load the data into
+the destination. It is in charge of the "L" in the ETL process.
+*/
+func (a MergeFields) Load(tk *destination.Toolkit, queue *store.Queue, then chan<- destination.Then) {
+	for _, event := range queue.Events {
+		for _, job := range event.Jobs {
+
+			// Unmarshal the `data` key of the job.
+			var d MergeFields
+			json.Unmarshal(job.Data, &d)
+
+			// Nothing needs to be sent if none of the traits is mapped to a merge tag.
+			fields := mergeFields(d.Traits, a.env.MergeFields)
+			if len(fields) == 0 {
+				then <- destination.Then{
+					Jobs:  []string{job.ID},
+					Error: nil,
+				}
+
+				continue
+			}
+
+			then <- member(a.env, a.client, job.ID, "PATCH", d.Email, "", map[string]interface{}{
+				"merge_fields": fields,
+			})
+		}
+	}
+}
+
+/*
+mergeFields returns the merge fields to send to Mailchimp given the traits of a
+user and the merge tags of the traits. Values are sent as strings, and traits set
+to nil clear the merge field.
+*/
+func mergeFields(traits analytics.Traits, tags map[string]string) map[string]string {
+	fields := map[string]string{}
+	for trait, tag := range tags {
+		value, exists := traits[trait]
+		if !exists {
+			continue
+		}
+
+		switch v := value.(type) {
+		case nil:
+			fields[tag] = ""
+		case string:
+			fields[tag] = v
+		default:
+			fields[tag] = fmt.Sprint(v)
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,55 @@
+package mailchimp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/destination"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+var _ destination.Action = MergeFields{}
+
+func TestMergeFields(t *testing.T) {
+	tags := map[string]string{
+		"initial_utm_source": "IUTMSOURCE",
+		"latest_utm_source":  "LUTMSOURCE",
+		"lifetime_revenue":   "REVENUE",
+	}
+
+	tests := []struct {
+		name   string
+		traits analytics.Traits
+		want   map[string]string
+	}{
+		{
+			name: "WithMappedTraits",
+			traits: analytics.Traits{
+				"initial_utm_source": "newsletter",
+				"latest_utm_source":  nil,
+				"lifetime_revenue":   float64(42.5),
+				"plan":               "enterprise",
+			},
+			want: map[string]string{
+				"IUTMSOURCE": "newsletter",
+				"LUTMSOURCE": "",
+				"REVENUE":    "42.5",
+			},
+		},
+		{
+			name: "WithoutMappedTraits",
+			traits: analytics.Traits{
+				"plan": "enterprise",
+			},
+			want: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeFields(tt.traits, tags); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package mailchimp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+)
+
+/*
+TagActive is the status of a tag added to a member.
+*/
+var TagActive = "active"
+
+/*
+TagInactive is the status of a tag removed from a member.
+*/
+var TagInactive = "inactive"
+
+/*
+Tag is a tag of a member of the Mailchimp audience.
+*/
+type Tag struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+/*
+Tags implements the Blacksmith destination.Action interface for the action
+"tags". It adds or removes tags of a member of the audience set in the options.
+*/
+type Tags struct {
+	env    *Options
+	client *http.Client
+
+	Email string `json:"email_address"`
+	Tags  []Tag  `json:"tags"`
+}
+
+/*
+String returns the string representation of the action Tags.
+*/
+func (a Tags) String() string {
+	return "tags"
+}
+
+/*
+Schedule allows the action to override the schedule options of its
+destination. Do not override.
+*/
+func (a Tags) Schedule() *destination.Schedule {
+	return nil
+}
+
+/*
+Marshal is the function being run when the action receives data into
+the Tags receiver. It allows to transform and enrich the data before
+saving it in the store adapter.
+*/
+func (a Tags) Marshal(tk *destination.Toolkit) (*destination.Job, error) {
+
+	// Ensure the email address is lowercase. As described in the Mailchimp
+	// documentation, the subscriber hash is "the lowercase version of the list
+	// member's email address".
+	a.Email = strings.ToLower(a.Email)
+
+	// Try to marshal the data passed directly to the receiver.
+	data, err := json.Marshal(&a)
+	if err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+		}
+	}
+
+	// Create a job with the data. Since the 'Context' key is not
+	// set, the one from the event will automatically be applied.
+	j := &destination.Job{
+		Version: "v3.0",
+		Data:    data,
+	}
+
+	// Return the job including the marshaled data.
+	return j, nil
+}
+
+/*
+Load is the function being run by the scheduler to load the data into
+the destination. It is in charge of the "L" in the ETL process.
+*/
+func (a Tags) Load(tk *destination.Toolkit, queue *store.Queue, then chan<- destination.Then) {
+	for _, event := range queue.Events {
+		for _, job := range event.Jobs {
+
+			// Unmarshal the `data` key of the job.
+			var d Tags
+			json.Unmarshal(job.Data, &d)
+
+			// Only the tags are expected in the body.
+			then <- member(a.env, a.client, job.ID, "POST", d.Email, "/tags", map[string]interface{}{
+				"tags": d.Tags,
+			})
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package mailchimp
+
+import (
+	"github.com/nunchistudio/blacksmith/destination"
+)
+
+var _ destination.Action = Tags{}
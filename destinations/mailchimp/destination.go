@@ -0,0 +1,88 @@
+/*
+Package mailchimp extends the Mailchimp destination of the Blacksmith modules with
+the actions needed by Fragment, such as synchronizing audiences as tags.
+*/
+package mailchimp
+
+import (
+	"net/http"
+
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/logger"
+
+	"github.com/nunchistudio/blacksmith-modules/mailchimp/mailchimpdestination"
+)
+
+/*
+statusForceDiscards holds informations if a job must be discarded based on
+the status code returned by the Mailchimp API.
+
+Reference: https://mailchimp.com/developer/marketing/docs/errors/
+*/
+var statusForceDiscards = map[int]bool{
+	400: true,
+	401: false,
+	403: false,
+	404: false,
+	405: true,
+	414: true,
+	422: true,
+	429: false,
+	500: false,
+	503: false,
+}
+
+/*
+Mailchimp implements the Blacksmith destination.Destination interface for the
+destination "mailchimp". It embeds the destination of the Mailchimp module so
+its actions are still available.
+*/
+type Mailchimp struct {
+	destination.Destination
+
+	env    *Options
+	client *http.Client
+}
+
+/*
+New returns a valid Blacksmith destination.Destination for Mailchimp.
+*/
+func New(env *Options) destination.Destination {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	d := mailchimpdestination.New(&env.Options)
+	if d == nil {
+		return nil
+	}
+
+	return &Mailchimp{
+		Destination: d,
+		env:         env,
+		client:      http.DefaultClient,
+	}
+}
+
+/*
+Actions return a list of actions the destination Mailchimp is able to handle. It
+adds the actions "tags" and "merge_fields" to the ones of the Mailchimp module.
+*/
+func (d *Mailchimp) Actions() map[string]destination.Action {
+	actions := d.Destination.Actions()
+	actions["tags"] = Tags{
+		env:    d.env,
+		client: d.client,
+	}
+
+	actions["merge_fields"] = MergeFields{
+		env:    d.env,
+		client: d.client,
+	}
+
+	return actions
+}
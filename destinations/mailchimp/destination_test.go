@@ -0,0 +1,32 @@
+package mailchimp
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/destination"
+
+	"github.com/nunchistudio/blacksmith-modules/mailchimp/mailchimpdestination"
+)
+
+var _ destination.Destination = &Mailchimp{}
+
+func TestMailchimp_Actions(t *testing.T) {
+	d := New(&Options{
+		Options: mailchimpdestination.Options{
+			APIKey:       "key",
+			DatacenterID: "us1",
+			AudienceID:   "audience",
+		},
+	})
+
+	if d.String() != "mailchimp" {
+		t.Errorf("Mailchimp.String() = %v, want %v", d.String(), "mailchimp")
+	}
+
+	actions := d.Actions()
+	for _, name := range []string{"identify", "tags", "merge_fields"} {
+		if _, exists := actions[name]; !exists {
+			t.Errorf("Mailchimp.Actions() is missing action %v", name)
+		}
+	}
+}
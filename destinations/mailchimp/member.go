@@ -0,0 +1,68 @@
+package mailchimp
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+)
+
+/*
+member runs a request against an endpoint of a member of the audience set in the
+options, and returns the result of the job to send to the scheduler.
+*/
+func member(env *Options, client *http.Client, jobID string, method string, email string, endpoint string, body interface{}) destination.Then {
+
+	// Marshal and create a reader for making the HTTP request.
+	b, _ := json.Marshal(body)
+	r := bytes.NewReader(b)
+
+	// Create the subscriber hash based on the email address.
+	h := md5.Sum([]byte(email))
+	s := hex.EncodeToString(h[:])
+
+	// Run the HTTP request against the Mailchimp endpoint using the API Key.
+	// Inform the scheduler if any error happened.
+	req, _ := http.NewRequest(method, "https://"+env.DatacenterID+".api.mailchimp.com/3.0/lists/"+env.AudienceID+"/members/"+s+endpoint, r)
+	req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("anystring:"+env.APIKey)))
+	req.Header.Set("Content-Type", "application/json")
+	res, err := client.Do(req)
+	if err != nil {
+		return destination.Then{
+			Jobs:         []string{jobID},
+			ForceDiscard: true,
+			Error: &errors.Error{
+				StatusCode: 500,
+				Message:    err.Error(),
+			},
+		}
+	}
+
+	defer res.Body.Close()
+
+	// Since a non-2xx status code doesn't cause an error, catch HTTP status
+	// code to ensure nothing bad happened.
+	if res.StatusCode >= 300 {
+		buf := new(bytes.Buffer)
+		buf.ReadFrom(res.Body)
+		return destination.Then{
+			Jobs:         []string{jobID},
+			ForceDiscard: statusForceDiscards[res.StatusCode],
+			Error: &errors.Error{
+				StatusCode: res.StatusCode,
+				Message:    buf.String(),
+			},
+		}
+	}
+
+	// Finally, inform the scheduler about the success.
+	return destination.Then{
+		Jobs:  []string{jobID},
+		Error: nil,
+	}
+}
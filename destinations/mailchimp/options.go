@@ -0,0 +1,65 @@
+package mailchimp
+
+import (
+	"regexp"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/nunchistudio/blacksmith-modules/mailchimp/mailchimpdestination"
+)
+
+/*
+mergeTag is the format of a merge tag accepted by Mailchimp.
+*/
+var mergeTag = regexp.MustCompile(`^[A-Z0-9_]{1,10}$`)
+
+/*
+Options is the options the destination can take as an input to be configured.
+*/
+type Options struct {
+
+	// Options is the options of the Mailchimp module.
+	mailchimpdestination.Options
+
+	// MergeFields maps the traits of the users to the merge tags of the Mailchimp
+	// audience. The merge fields must exist in the audience. Traits not present in
+	// the map are not sent to Mailchimp.
+	//
+	// Example: map[string]string{"initial_utm_source": "IUTMSOURCE"}
+	MergeFields map[string]string
+}
+
+/*
+validate ensures the options passed to initialize the destination are valid. The
+options of the Mailchimp module are validated by the module itself.
+*/
+func (env *Options) validate() error {
+	fail := &errors.Error{
+		Message:     "destination/mailchimp: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Destinations", "mailchimp"},
+		})
+
+		return fail
+	}
+
+	for trait, tag := range env.MergeFields {
+		if !mergeTag.MatchString(tag) {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Merge tag of trait '" + trait + "' must be up to 10 uppercase letters, digits, or underscores",
+				Path:    []string{"Options", "Destinations", "mailchimp", "MergeFields", trait},
+			})
+		}
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
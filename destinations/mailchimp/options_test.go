@@ -0,0 +1,41 @@
+package mailchimp
+
+import (
+	"testing"
+)
+
+func TestOptions_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  *Options
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: false,
+		},
+		{
+			name: "WithMergeFields",
+			fields: &Options{
+				MergeFields: map[string]string{"initial_utm_source": "IUTMSOURCE"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithInvalidMergeTag",
+			fields: &Options{
+				MergeFields: map[string]string{"initial_utm_source": "INITIAL_UTM_SOURCE"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package mixpanel
+
+import (
+	"net/http"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Alias implements the Blacksmith destination.Action interface for the action
+"alias". It holds the complete job's structure to send to Mixpanel.
+*/
+type Alias struct {
+	env    *Options
+	client *http.Client
+
+	analytics.Alias
+}
+
+/*
+String returns the string representation of the action Alias.
+*/
+func (a Alias) String() string {
+	return "alias"
+}
+
+/*
+Schedule allows the action to override the schedule options of its
+destination. Do not override.
+*/
+func (a Alias) Schedule() *destination.Schedule {
+	return nil
+}
+
+/*
+Marshal is the function being run when the action receives data into
+the Alias receiver. It allows to transform and enrich the data before
+saving it in the store adapter.
+*/
+func (a Alias) Marshal(tk *destination.Toolkit) (*destination.Job, error) {
+	// Mixpanel merges the previous ID of the user into the new one when
+	// receiving the special event "$create_alias".
+	return newJob(&a.Timestamp, &message{
+		Endpoint: endpointTrack,
+		Data: &event{
+			Event: "$create_alias",
+			Properties: map[string]interface{}{
+				"distinct_id": a.PreviousId,
+				"alias":       a.UserId,
+			},
+		},
+	})
+}
+
+/*
+Load is the function being run by the scheduler to load the data into
+the destination. It is in charge of the "L" in the ETL process.
+*/
+func (a Alias) Load(tk *destination.Toolkit, queue *store.Queue, then chan<- destination.Then) {
+	load(a.env, a.client, queue, then)
+}
@@ -0,0 +1,84 @@
+package mixpanel
+
+import (
+	"net/http"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Group implements the Blacksmith destination.Action interface for the action
+"group". It holds the complete job's structure to send to Mixpanel.
+*/
+type Group struct {
+	env    *Options
+	client *http.Client
+
+	analytics.Group
+}
+
+/*
+String returns the string representation of the action Group.
+*/
+func (a Group) String() string {
+	return "group"
+}
+
+/*
+Schedule allows the action to override the schedule options of its
+destination. Do not override.
+*/
+func (a Group) Schedule() *destination.Schedule {
+	return nil
+}
+
+/*
+Marshal is the function being run when the action receives data into
+the Group receiver. It allows to transform and enrich the data before
+saving it in the store adapter.
+*/
+func (a Group) Marshal(tk *destination.Toolkit) (*destination.Job, error) {
+	traits := map[string]interface{}{}
+	for key, value := range a.Traits {
+		traits[key] = value
+	}
+
+	messages := []*message{
+		{
+			Endpoint: endpointGroups,
+			Data: map[string]interface{}{
+				"$group_key": a.env.GroupKey,
+				"$group_id":  a.GroupId,
+				"$set":       traits,
+			},
+		},
+	}
+
+	// Add the group to the people profile of the user so the user is listed
+	// as a member of the group.
+	if id := distinctID(a.UserId, a.AnonymousId); id != "" {
+		messages = append(messages, &message{
+			Endpoint: endpointEngage,
+			Data: map[string]interface{}{
+				"$distinct_id": id,
+				"$ip":          ip(a.Context),
+				"$union": map[string]interface{}{
+					a.env.GroupKey: []string{a.GroupId},
+				},
+			},
+		})
+	}
+
+	return newJob(&a.Timestamp, messages...)
+}
+
+/*
+Load is the function being run by the scheduler to load the data into
+the destination. It is in charge of the "L" in the ETL process.
+*/
+func (a Group) Load(tk *destination.Toolkit, queue *store.Queue, then chan<- destination.Then) {
+	load(a.env, a.client, queue, then)
+}
@@ -0,0 +1,7 @@
+package mixpanel
+
+import (
+	"github.com/nunchistudio/blacksmith/destination"
+)
+
+var _ destination.Action = Identify{}
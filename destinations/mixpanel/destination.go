@@ -0,0 +1,99 @@
+/*
+Package mixpanel implements a Blacksmith destination sending the events to
+Mixpanel. Track and Page events are sent as events, Identify events update the
+people profiles, Group events update the group profiles, and Alias events merge
+the identities of users. Requests are batched within the limits of Mixpanel.
+*/
+package mixpanel
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/logger"
+)
+
+/*
+Mixpanel implements the Blacksmith destination.Destination interface for the
+destination "mixpanel".
+*/
+type Mixpanel struct {
+	options *destination.Options
+	env     *Options
+	client  *http.Client
+}
+
+/*
+New returns a valid Blacksmith destination.Destination for Mixpanel.
+*/
+func New(env *Options) destination.Destination {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &Mixpanel{
+		options: &destination.Options{
+			DefaultSchedule: &destination.Schedule{
+				Realtime:   env.Realtime,
+				Interval:   env.Interval,
+				MaxRetries: env.MaxRetries,
+			},
+			DefaultVersion: "v1.0",
+			Versions: map[string]time.Time{
+				"v1.0": time.Time{},
+			},
+		},
+		env: env,
+		client: &http.Client{
+			Timeout: env.Timeout,
+		},
+	}
+}
+
+/*
+String returns the string representation of the destination Mixpanel.
+*/
+func (d *Mixpanel) String() string {
+	return "mixpanel"
+}
+
+/*
+Options returns common destination options for Mixpanel. They will be shared
+across every actions of this destination, except when overridden.
+*/
+func (d *Mixpanel) Options() *destination.Options {
+	return d.options
+}
+
+/*
+Actions return a list of actions the destination Mixpanel is able to handle.
+*/
+func (d *Mixpanel) Actions() map[string]destination.Action {
+	return map[string]destination.Action{
+		"identify": Identify{
+			env:    d.env,
+			client: d.client,
+		},
+		"track": Track{
+			env:    d.env,
+			client: d.client,
+		},
+		"group": Group{
+			env:    d.env,
+			client: d.client,
+		},
+		"alias": Alias{
+			env:    d.env,
+			client: d.client,
+		},
+		"page": Page{
+			env:    d.env,
+			client: d.client,
+		},
+	}
+}
@@ -0,0 +1,26 @@
+package mixpanel
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/destination"
+)
+
+var _ destination.Destination = &Mixpanel{}
+
+func TestMixpanel_Actions(t *testing.T) {
+	d := New(&Options{
+		Token: "token",
+	})
+
+	if d.String() != "mixpanel" {
+		t.Errorf("Mixpanel.String() = %v, want %v", d.String(), "mixpanel")
+	}
+
+	actions := d.Actions()
+	for _, name := range []string{"identify", "track", "group", "alias", "page"} {
+		if _, exists := actions[name]; !exists {
+			t.Errorf("Mixpanel.Actions() is missing action %v", name)
+		}
+	}
+}
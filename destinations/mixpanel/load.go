@@ -0,0 +1,217 @@
+package mixpanel
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/nunchistudio/fragment/destinations/retry"
+)
+
+/*
+response is the response returned by Mixpanel when requests are sent in verbose
+mode.
+*/
+type response struct {
+	Status int    `json:"status"`
+	Error  string `json:"error"`
+}
+
+/*
+received is a message as saved in the data of a job.
+*/
+type received struct {
+	Endpoint string          `json:"endpoint"`
+	Data     json.RawMessage `json:"data"`
+}
+
+/*
+batch is a list of messages waiting to be sent to the same endpoint, with the
+jobs they belong to.
+*/
+type batch struct {
+	jobs []string
+	data []json.RawMessage
+}
+
+/*
+load goes through every events received from the queue and their related jobs,
+and sends their messages to Mixpanel in batches, one per endpoint. A job succeeds
+only if all of its messages have been accepted. Messages of failed jobs already
+accepted are sent again on retry, which is safe since events are deduplicated by
+their insert ID and profile updates are idempotent.
+*/
+func load(env *Options, client *http.Client, queue *store.Queue, then chan<- destination.Then) {
+	jobs := []string{}
+	failures := map[string]*destination.Then{}
+	batches := map[string]*batch{}
+	flush := func(endpoint string) {
+		b := batches[endpoint]
+		if b == nil || len(b.data) == 0 {
+			return
+		}
+
+		delete(batches, endpoint)
+		result := send(env, client, endpoint, b.data)
+		if result == nil {
+			return
+		}
+
+		for _, job := range b.jobs {
+			if _, failed := failures[job]; !failed {
+				failures[job] = result
+			}
+		}
+	}
+
+	for _, event := range queue.Events {
+		for _, job := range event.Jobs {
+			jobs = append(jobs, job.ID)
+
+			var messages []*received
+			if err := json.Unmarshal(job.Data, &messages); err != nil {
+				failures[job.ID] = &destination.Then{
+					ForceDiscard: true,
+					Error: &errors.Error{
+						StatusCode: 400,
+						Message:    err.Error(),
+					},
+				}
+
+				continue
+			}
+
+			for _, m := range messages {
+				if batches[m.Endpoint] == nil {
+					batches[m.Endpoint] = &batch{}
+				}
+
+				b := batches[m.Endpoint]
+				b.jobs = append(b.jobs, job.ID)
+				b.data = append(b.data, withToken(env, m))
+				if len(b.data) >= env.BatchSize {
+					flush(m.Endpoint)
+				}
+			}
+		}
+	}
+
+	for endpoint := range batches {
+		flush(endpoint)
+	}
+
+	// Inform the scheduler about the failed jobs one by one, and about the
+	// succeeded ones at once.
+	succeeded := []string{}
+	for _, job := range jobs {
+		failure, failed := failures[job]
+		if !failed {
+			succeeded = append(succeeded, job)
+			continue
+		}
+
+		then <- destination.Then{
+			Jobs:         []string{job},
+			ForceDiscard: failure.ForceDiscard,
+			Error:        failure.Error,
+		}
+	}
+
+	if len(succeeded) > 0 {
+		then <- destination.Then{
+			Jobs:  succeeded,
+			Error: nil,
+		}
+	}
+}
+
+/*
+withToken returns the data of a message with the token of the Mixpanel project.
+The token is added just before loading the data so it is not saved in the store
+adapter. Events hold it in their properties, whereas profile updates hold it at
+the root of their data.
+*/
+func withToken(env *Options, m *received) json.RawMessage {
+	var data map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(m.Data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil || data == nil {
+		return m.Data
+	}
+
+	if m.Endpoint == endpointTrack {
+		properties, _ := data["properties"].(map[string]interface{})
+		if properties == nil {
+			properties = map[string]interface{}{}
+		}
+
+		properties["token"] = env.Token
+		data["properties"] = properties
+	} else {
+		data["$token"] = env.Token
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return m.Data
+	}
+
+	return b
+}
+
+/*
+send sends a batch of messages to an endpoint of Mixpanel. It returns nil on
+success, or the failure to apply to the jobs of the batch. A failure is retried by
+the scheduler, unless Mixpanel rejected the request as invalid.
+*/
+func send(env *Options, client *http.Client, endpoint string, data []json.RawMessage) *destination.Then {
+	body, _ := json.Marshal(data)
+	req, _ := http.NewRequest("POST", env.Endpoint+endpoint+"?verbose=1", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return &destination.Then{
+			Error: &errors.Error{
+				StatusCode: 500,
+				Message:    err.Error(),
+			},
+		}
+	}
+
+	// Since a non-2xx status code doesn't cause an error, catch HTTP status
+	// code to ensure nothing bad happened.
+	buf := new(bytes.Buffer)
+	buf.ReadFrom(res.Body)
+	res.Body.Close()
+	if res.StatusCode >= 300 {
+		return &destination.Then{
+			ForceDiscard: retry.Discard(res.StatusCode),
+			Error: &errors.Error{
+				StatusCode: res.StatusCode,
+				Message:    buf.String(),
+			},
+		}
+	}
+
+	// In verbose mode, Mixpanel returns a status of 0 along an error message
+	// when the payload is invalid. It would never be accepted so there is no
+	// need to retry.
+	var r response
+	if err := json.Unmarshal(buf.Bytes(), &r); err == nil && r.Status != 1 {
+		return &destination.Then{
+			ForceDiscard: true,
+			Error: &errors.Error{
+				StatusCode: 400,
+				Message:    r.Error,
+			},
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package mixpanel
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name         string
+		groups       int
+		status       map[string]int
+		response     map[string]string
+		wantRequests map[string]int
+		wantFailed   int
+		wantDiscard  bool
+	}{
+		{
+			name:         "WithSuccess",
+			groups:       3,
+			wantRequests: map[string]int{"/groups": 2, "/engage": 2},
+			wantFailed:   0,
+		},
+		{
+			name:         "WithServerError",
+			groups:       1,
+			status:       map[string]int{"/engage": 503},
+			wantRequests: map[string]int{"/groups": 1, "/engage": 1},
+			wantFailed:   1,
+			wantDiscard:  false,
+		},
+		{
+			name:         "WithInvalidPayload",
+			groups:       1,
+			response:     map[string]string{"/groups": `{"status":0,"error":"$group_key is required"}`},
+			wantRequests: map[string]int{"/groups": 1, "/engage": 1},
+			wantFailed:   1,
+			wantDiscard:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mutex sync.Mutex
+			requests := map[string]int{}
+			server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				body, _ := ioutil.ReadAll(req.Body)
+
+				mutex.Lock()
+				requests[req.URL.Path]++
+				mutex.Unlock()
+
+				var batch []map[string]interface{}
+				if err := json.Unmarshal(body, &batch); err != nil || len(batch) == 0 || len(batch) > 2 {
+					t.Errorf("request body is not a valid batch: %s", body)
+				}
+
+				for _, update := range batch {
+					if update["$token"] != "token" {
+						t.Errorf("update is missing the project token: %v", update)
+					}
+				}
+
+				if status, exists := tt.status[req.URL.Path]; exists {
+					res.WriteHeader(status)
+					return
+				}
+
+				if response, exists := tt.response[req.URL.Path]; exists {
+					res.Write([]byte(response))
+					return
+				}
+
+				res.Write([]byte(`{"status":1,"error":null}`))
+			}))
+			defer server.Close()
+
+			env := &Options{
+				Token:     "token",
+				Endpoint:  server.URL,
+				BatchSize: 2,
+			}
+			env.validate()
+
+			action := Group{env: env, client: server.Client()}
+			event := &store.Event{}
+			for i := 0; i < tt.groups; i++ {
+				action.Group = analytics.Group{
+					UserId:  "user",
+					GroupId: string(rune('a' + i)),
+					Traits:  analytics.Traits{"plan": "pro"},
+				}
+
+				job, err := action.Marshal(nil)
+				if err != nil {
+					t.Fatalf("Group.Marshal() error = %v", err)
+				}
+
+				event.Jobs = append(event.Jobs, &store.Job{
+					ID:   string(rune('a' + i)),
+					Data: job.Data,
+				})
+			}
+
+			then := make(chan destination.Then, tt.groups+1)
+			action.Load(nil, &store.Queue{Events: []*store.Event{event}}, then)
+			close(then)
+
+			failed, succeeded := 0, 0
+			for result := range then {
+				if result.Error == nil {
+					succeeded += len(result.Jobs)
+					continue
+				}
+
+				failed += len(result.Jobs)
+				if result.ForceDiscard != tt.wantDiscard {
+					t.Errorf("Group.Load() discard = %v, want %v", result.ForceDiscard, tt.wantDiscard)
+				}
+			}
+
+			for endpoint, want := range tt.wantRequests {
+				if requests[endpoint] != want {
+					t.Errorf("Group.Load() requests to %v = %v, want %v", endpoint, requests[endpoint], want)
+				}
+			}
+
+			if failed != tt.wantFailed || failed+succeeded != tt.groups {
+				t.Errorf("Group.Load() failed jobs = %v, succeeded = %v, want failed %v", failed, succeeded, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestWithToken(t *testing.T) {
+	env := &Options{
+		Token: "token",
+	}
+
+	tests := []struct {
+		name    string
+		message *received
+		want    string
+	}{
+		{
+			name: "WithEvent",
+			message: &received{
+				Endpoint: endpointTrack,
+				Data:     json.RawMessage(`{"event":"Order Completed","properties":{"distinct_id":"user","time":1600000000123}}`),
+			},
+			want: `{"event":"Order Completed","properties":{"distinct_id":"user","time":1600000000123,"token":"token"}}`,
+		},
+		{
+			name: "WithProfileUpdate",
+			message: &received{
+				Endpoint: endpointEngage,
+				Data:     json.RawMessage(`{"$distinct_id":"user"}`),
+			},
+			want: `{"$distinct_id":"user","$token":"token"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(withToken(env, tt.message)); got != tt.want {
+				t.Errorf("withToken() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package mixpanel
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/nunchistudio/fragment/normalize"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Endpoints of the Mixpanel ingestion API messages are sent to.
+*/
+var (
+	endpointTrack  = "/track"
+	endpointEngage = "/engage"
+	endpointGroups = "/groups"
+)
+
+/*
+message is a single event or profile update sent to an endpoint of Mixpanel. The
+data of a job is a list of messages, since some Segment events require updates on
+several endpoints.
+*/
+type message struct {
+	Endpoint string      `json:"endpoint"`
+	Data     interface{} `json:"data"`
+}
+
+/*
+event is an event sent to the "/track" endpoint.
+*/
+type event struct {
+	Event      string                 `json:"event"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+/*
+reservedTraits maps the traits of the Segment spec to the reserved properties of
+Mixpanel people profiles.
+*/
+var reservedTraits = map[string]string{
+	"avatar":     "$avatar",
+	"created_at": "$created",
+	"email":      "$email",
+	"first_name": "$first_name",
+	"last_name":  "$last_name",
+	"name":       "$name",
+	"phone":      "$phone",
+}
+
+/*
+distinctID returns the Mixpanel distinct ID of a user. It is the user ID, or the
+anonymous ID for users not identified yet.
+*/
+func distinctID(userID string, anonymousID string) string {
+	if userID != "" {
+		return userID
+	}
+
+	return anonymousID
+}
+
+/*
+newJob returns a job holding the messages to send to Mixpanel.
+*/
+func newJob(timestamp *time.Time, messages ...*message) (*destination.Job, error) {
+
+	// Try to marshal the messages created by the action.
+	data, err := json.Marshal(messages)
+	if err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+		}
+	}
+
+	// Create a job with the data. Since the 'Context' key is not
+	// set, the one from the event will automatically be applied.
+	j := &destination.Job{
+		Version: "v1.0",
+		Data:    data,
+		SentAt:  timestamp,
+	}
+
+	// Return the job including the marshaled data.
+	return j, nil
+}
+
+/*
+ip returns the IP address of the context, used by Mixpanel to geolocate users. It
+returns "0" when unknown so Mixpanel does not geolocate users with the address of
+Fragment.
+*/
+func ip(ctx *analytics.Context) string {
+	if ctx == nil || ctx.IP == nil {
+		return "0"
+	}
+
+	return ctx.IP.String()
+}
+
+/*
+newEvent returns an event sent to the "/track" endpoint. Known keys of the context
+are mapped to the default properties of Mixpanel, and the properties of the event
+take precedence over them. The message ID is used as insert ID so Mixpanel can
+deduplicate events sent more than once when a job is retried.
+*/
+func newEvent(name string, messageID string, userID string, anonymousID string, timestamp time.Time, ctx *analytics.Context, properties map[string]interface{}) *event {
+	e := &event{
+		Event:      name,
+		Properties: contextProperties(ctx),
+	}
+
+	for key, value := range properties {
+		e.Properties[key] = value
+	}
+
+	e.Properties["distinct_id"] = distinctID(userID, anonymousID)
+	e.Properties["mp_lib"] = "fragment"
+	if messageID != "" {
+		e.Properties["$insert_id"] = messageID
+	}
+
+	if userID != "" {
+		e.Properties["$user_id"] = userID
+	}
+
+	if anonymousID != "" {
+		e.Properties["$device_id"] = anonymousID
+	}
+
+	if !timestamp.IsZero() {
+		e.Properties["time"] = timestamp.UnixNano() / int64(time.Millisecond)
+	}
+
+	return e
+}
+
+/*
+contextProperties returns the default properties of Mixpanel found in the context
+of an event.
+*/
+func contextProperties(ctx *analytics.Context) map[string]interface{} {
+	properties := map[string]interface{}{}
+	if ctx == nil {
+		return properties
+	}
+
+	set := func(key string, value string) {
+		if value != "" {
+			properties[key] = value
+		}
+	}
+
+	if ctx.IP != nil {
+		set("ip", ctx.IP.String())
+	}
+
+	set("$current_url", ctx.Page.URL)
+	set("$referrer", ctx.Page.Referrer)
+	set("$os", ctx.OS.Name)
+	set("$os_version", ctx.OS.Version)
+	set("$manufacturer", ctx.Device.Manufacturer)
+	set("$model", ctx.Device.Model)
+	set("$app_version_string", ctx.App.Version)
+	set("$app_build_number", ctx.App.Build)
+	set("utm_source", ctx.Campaign.Source)
+	set("utm_medium", ctx.Campaign.Medium)
+	set("utm_campaign", ctx.Campaign.Name)
+	set("utm_term", ctx.Campaign.Term)
+	set("utm_content", ctx.Campaign.Content)
+	if ctx.Screen.Width > 0 && ctx.Screen.Height > 0 {
+		properties["$screen_width"] = ctx.Screen.Width
+		properties["$screen_height"] = ctx.Screen.Height
+	}
+
+	return properties
+}
+
+/*
+pageName returns the name of the event sent for a Page message, following the
+naming of the Segment integration so reports built on it keep working.
+*/
+func pageName(page analytics.Page) string {
+	if page.Name == "" {
+		return "Loaded a Page"
+	}
+
+	return "Viewed " + page.Name + " Page"
+}
+
+/*
+pageProperties returns the properties of a Page message, including the name of
+the page and the page information of the context.
+*/
+func pageProperties(page analytics.Page) map[string]interface{} {
+	properties := map[string]interface{}{}
+	if page.Context != nil {
+		info := page.Context.Page
+		for key, value := range map[string]string{
+			"url":      info.URL,
+			"path":     info.Path,
+			"title":    info.Title,
+			"referrer": info.Referrer,
+			"search":   info.Search,
+		} {
+			if value != "" {
+				properties[key] = value
+			}
+		}
+	}
+
+	if page.Name != "" {
+		properties["name"] = page.Name
+	}
+
+	for key, value := range page.Properties {
+		properties[key] = value
+	}
+
+	return properties
+}
+
+/*
+peopleProperties returns the traits of a user with the traits of the Segment spec
+renamed to the reserved properties of Mixpanel.
+*/
+func peopleProperties(traits map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{}, len(traits))
+	for key, value := range traits {
+		if reserved, exists := reservedTraits[normalize.Snake(key)]; exists {
+			properties[reserved] = value
+			continue
+		}
+
+		properties[key] = value
+	}
+
+	return properties
+}
@@ -0,0 +1,94 @@
+package mixpanel
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+func TestNewEvent(t *testing.T) {
+	timestamp := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	ctx := &analytics.Context{
+		IP: net.ParseIP("10.0.0.1"),
+		Page: analytics.PageInfo{
+			URL: "https://example.com/pricing",
+		},
+		Campaign: analytics.CampaignInfo{
+			Source: "newsletter",
+		},
+	}
+
+	e := newEvent("Order Completed", "msg", "user", "anon", timestamp, ctx, map[string]interface{}{
+		"revenue":    25.0,
+		"utm_source": "override",
+	})
+
+	want := &event{
+		Event: "Order Completed",
+		Properties: map[string]interface{}{
+			"ip":           "10.0.0.1",
+			"$current_url": "https://example.com/pricing",
+			"utm_source":   "override",
+			"revenue":      25.0,
+			"distinct_id":  "user",
+			"mp_lib":       "fragment",
+			"$insert_id":   "msg",
+			"$user_id":     "user",
+			"$device_id":   "anon",
+			"time":         timestamp.UnixNano() / int64(time.Millisecond),
+		},
+	}
+
+	if !reflect.DeepEqual(e, want) {
+		t.Errorf("newEvent() = %v, want %v", e, want)
+	}
+}
+
+func TestPageName(t *testing.T) {
+	tests := []struct {
+		name string
+		page analytics.Page
+		want string
+	}{
+		{
+			name: "WithName",
+			page: analytics.Page{Name: "Pricing"},
+			want: "Viewed Pricing Page",
+		},
+		{
+			name: "WithoutName",
+			page: analytics.Page{},
+			want: "Loaded a Page",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pageName(tt.page); got != tt.want {
+				t.Errorf("pageName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeopleProperties(t *testing.T) {
+	got := peopleProperties(map[string]interface{}{
+		"email":     "jane@example.com",
+		"firstName": "Jane",
+		"createdAt": "2026-10-18T12:00:00Z",
+		"plan":      "pro",
+	})
+
+	want := map[string]interface{}{
+		"$email":      "jane@example.com",
+		"$first_name": "Jane",
+		"$created":    "2026-10-18T12:00:00Z",
+		"plan":        "pro",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("peopleProperties() = %v, want %v", got, want)
+	}
+}
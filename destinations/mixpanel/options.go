@@ -0,0 +1,155 @@
+package mixpanel
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+)
+
+/*
+maxBatchSize is the maximum number of events or profile updates Mixpanel accepts
+in a single request.
+*/
+var maxBatchSize = 50
+
+/*
+Defaults are the defaults options set for the destination. When not set, these
+values will automatically be applied.
+*/
+var Defaults = &Options{
+	Endpoint:  "https://api.mixpanel.com",
+	GroupKey:  "company_id",
+	BatchSize: maxBatchSize,
+	Timeout:   10 * time.Second,
+}
+
+/*
+Options is the options the destination can take as an input to be configured.
+*/
+type Options struct {
+
+	// Realtime indicates if the pubsub adapter of the Blacksmith application shall
+	// be used to load events to the destination in realtime or not. When false, the
+	// Interval will be used.
+	Realtime bool
+
+	// Interval represents an interval or a CRON string at which a job shall be
+	// loaded to the destination. It is used as the time-lapse between retries in
+	// case of a job failure.
+	//
+	// Defaults to "@every 1h".
+	Interval string
+
+	// MaxRetries indicates the maximum number of retries per job the scheduler will
+	// attempt to execute before it succeed. When the limit is reached, the job is
+	// marked as "discarded".
+	//
+	// Defaults to 72.
+	MaxRetries uint16
+
+	// Token is the token of the Mixpanel project. It is added to the messages when
+	// loading them, so it is not saved in the store adapter.
+	//
+	// Required.
+	Token string
+
+	// Endpoint is the base URL of the Mixpanel ingestion API. It can be set to
+	// "https://api-eu.mixpanel.com" for projects hosted in the EU.
+	//
+	// Defaults to "https://api.mixpanel.com".
+	Endpoint string
+
+	// GroupKey is the group key of the Mixpanel project Group events are sent to,
+	// as configured in the settings of the project.
+	//
+	// Defaults to "company_id".
+	GroupKey string
+
+	// BatchSize is the maximum number of events or profile updates sent within a
+	// single request. It can not exceed 50, which is the limit of Mixpanel.
+	//
+	// Defaults to 50.
+	BatchSize int
+
+	// Timeout is the time limit for a request made to Mixpanel.
+	//
+	// Defaults to 10 seconds.
+	Timeout time.Duration
+}
+
+/*
+validate ensures the options passed to initialize the destination are valid.
+*/
+func (env *Options) validate() error {
+	var interval string = destination.Defaults.DefaultSchedule.Interval
+	var maxRetries uint16 = destination.Defaults.DefaultSchedule.MaxRetries
+
+	fail := &errors.Error{
+		Message:     "destination/mixpanel: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Destinations", "mixpanel"},
+		})
+
+		return fail
+	}
+
+	if env.Interval == "" {
+		env.Interval = interval
+	}
+
+	if env.MaxRetries == 0 {
+		env.MaxRetries = maxRetries
+	}
+
+	if env.Token == "" {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Mixpanel project token must be set",
+			Path:    []string{"Options", "Destinations", "mixpanel", "Token"},
+		})
+	}
+
+	if env.Endpoint == "" {
+		env.Endpoint = Defaults.Endpoint
+	}
+
+	parsed, err := url.Parse(env.Endpoint)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Endpoint must be a valid HTTP or HTTPS URL",
+			Path:    []string{"Options", "Destinations", "mixpanel", "Endpoint"},
+		})
+	}
+
+	if env.GroupKey == "" {
+		env.GroupKey = Defaults.GroupKey
+	}
+
+	if env.BatchSize == 0 {
+		env.BatchSize = Defaults.BatchSize
+	}
+
+	if env.BatchSize < 0 || env.BatchSize > maxBatchSize {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Batch size must be between 1 and " + strconv.Itoa(maxBatchSize),
+			Path:    []string{"Options", "Destinations", "mixpanel", "BatchSize"},
+		})
+	}
+
+	if env.Timeout == 0 {
+		env.Timeout = Defaults.Timeout
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package mixpanel
+
+import (
+	"testing"
+)
+
+func TestOptions_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  *Options
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: true,
+		},
+		{
+			name: "WithToken",
+			fields: &Options{
+				Token: "token",
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithEUEndpoint",
+			fields: &Options{
+				Token:    "token",
+				Endpoint: "https://api-eu.mixpanel.com",
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithInvalidEndpoint",
+			fields: &Options{
+				Token:    "token",
+				Endpoint: "api.mixpanel.com",
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithBatchSizeAboveLimit",
+			fields: &Options{
+				Token:     "token",
+				BatchSize: 100,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package nats
+
+import (
+	"github.com/nunchistudio/blacksmith/destination"
+)
+
+var _ destination.Action = Group{}
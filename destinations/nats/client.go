@@ -0,0 +1,140 @@
+package nats
+
+import (
+	"sync"
+
+	natsgo "github.com/nats-io/nats.go"
+)
+
+/*
+publisher publishes messages to NATS.
+*/
+type publisher interface {
+
+	// publish publishes a message with its ID. When it returns no error, the
+	// message may still be in flight until the next flush.
+	publish(subject string, id string, data []byte) error
+
+	// flush waits for NATS to acknowledge every message published.
+	flush() error
+}
+
+/*
+client holds the connection to NATS shared across the actions. The connection is
+established on first use, so the application can start while NATS is unavailable
+and the jobs are retried once it is back.
+*/
+type client struct {
+	mutex     sync.Mutex
+	env       *Options
+	publisher publisher
+}
+
+/*
+get returns the publisher of the client, connecting to NATS if necessary.
+*/
+func (c *client) get() (publisher, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.publisher != nil {
+		return c.publisher, nil
+	}
+
+	conn, err := natsgo.Connect(c.env.URL, natsgo.Name(c.env.Name), natsgo.Timeout(c.env.Timeout))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &connection{
+		env:  c.env,
+		conn: conn,
+	}
+
+	if c.env.JetStream {
+		p.js, err = conn.JetStream(natsgo.MaxWait(c.env.Timeout))
+		if err == nil {
+			err = stream(p.js, c.env)
+		}
+
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	c.publisher = p
+	return c.publisher, nil
+}
+
+/*
+connection is the publisher using a NATS connection, and JetStream when enabled.
+*/
+type connection struct {
+	env  *Options
+	conn *natsgo.Conn
+	js   natsgo.JetStreamContext
+}
+
+/*
+publish publishes a message. With JetStream, it only returns once the message has
+been persisted by the stream.
+*/
+func (c *connection) publish(subject string, id string, data []byte) error {
+	msg := natsgo.NewMsg(subject)
+	msg.Data = data
+	if c.js != nil {
+		_, err := c.js.PublishMsg(msg, natsgo.MsgId(id))
+		return err
+	}
+
+	return c.conn.PublishMsg(msg)
+}
+
+/*
+flush waits for the NATS server to process every message published.
+*/
+func (c *connection) flush() error {
+	if c.js != nil {
+		return nil
+	}
+
+	return c.conn.FlushTimeout(c.env.Timeout)
+}
+
+/*
+stream creates the JetStream stream capturing the subjects of the prefix if it
+does not exist yet. The retention of an existing stream is updated with the one
+of the options.
+*/
+func stream(js natsgo.JetStreamContext, env *Options) error {
+	info, err := js.StreamInfo(env.Stream)
+	if err != nil {
+		_, err = js.AddStream(streamConfig(env, nil))
+		return err
+	}
+
+	_, err = js.UpdateStream(streamConfig(env, &info.Config))
+	return err
+}
+
+/*
+streamConfig returns the configuration of the stream given the options. When the
+stream already exists, only its retention is changed.
+*/
+func streamConfig(env *Options, existing *natsgo.StreamConfig) *natsgo.StreamConfig {
+	config := &natsgo.StreamConfig{
+		Name:     env.Stream,
+		Subjects: []string{env.Prefix + ".>"},
+	}
+
+	if existing != nil {
+		copied := *existing
+		config = &copied
+	}
+
+	config.MaxAge = env.MaxAge
+	config.MaxBytes = env.MaxBytes
+	config.Duplicates = env.Duplicates
+	return config
+}
@@ -0,0 +1,60 @@
+package nats
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+)
+
+func TestStreamConfig(t *testing.T) {
+	env := &Options{
+		Prefix:     "fragment.events",
+		Stream:     "FRAGMENT_EVENTS",
+		MaxAge:     48 * time.Hour,
+		MaxBytes:   1 << 20,
+		Duplicates: 2 * time.Hour,
+	}
+
+	tests := []struct {
+		name     string
+		existing *natsgo.StreamConfig
+		want     *natsgo.StreamConfig
+	}{
+		{
+			name: "WithNewStream",
+			want: &natsgo.StreamConfig{
+				Name:       "FRAGMENT_EVENTS",
+				Subjects:   []string{"fragment.events.>"},
+				MaxAge:     48 * time.Hour,
+				MaxBytes:   1 << 20,
+				Duplicates: 2 * time.Hour,
+			},
+		},
+		{
+			name: "WithExistingStream",
+			existing: &natsgo.StreamConfig{
+				Name:       "FRAGMENT_EVENTS",
+				Subjects:   []string{"fragment.events.>", "other.>"},
+				Replicas:   3,
+				Duplicates: 2 * time.Minute,
+			},
+			want: &natsgo.StreamConfig{
+				Name:       "FRAGMENT_EVENTS",
+				Subjects:   []string{"fragment.events.>", "other.>"},
+				Replicas:   3,
+				MaxAge:     48 * time.Hour,
+				MaxBytes:   1 << 20,
+				Duplicates: 2 * time.Hour,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := streamConfig(env, tt.existing); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("streamConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Package nats implements a Blacksmith destination re-publishing the events to NATS
+subjects, so internal services can subscribe to the validated and enriched events
+without polling the store or the warehouse.
+
+Every event is published as a JSON Envelope to the subject "<prefix>.<type>", or
+"<prefix>.track.<event>" for track events where the name of the event is in snake
+case. With the default prefix, an "Order Completed" event is published to the
+subject "fragment.events.track.order_completed" and an identify event to the
+subject "fragment.events.identify". Services can subscribe to every events with
+the wildcard subject "fragment.events.>".
+
+Events are published with at-least-once semantics: a job succeeds only once NATS
+acknowledged the message, and is retried by the scheduler otherwise. Consumers can
+therefore receive the same event more than once, and should deduplicate them using
+the ID of the envelope. Since core NATS does not persist messages, JetStream should
+be enabled so events are not lost when no consumer is connected. The stream is
+created on first use if it does not exist.
+*/
+package nats
+
+import (
+	"time"
+
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/logger"
+)
+
+/*
+NATS implements the Blacksmith destination.Destination interface for the
+destination "nats".
+*/
+type NATS struct {
+	options *destination.Options
+	env     *Options
+	client  *client
+}
+
+/*
+New returns a valid Blacksmith destination.Destination for NATS.
+*/
+func New(env *Options) destination.Destination {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &NATS{
+		options: &destination.Options{
+			DefaultSchedule: &destination.Schedule{
+				Realtime:   env.Realtime,
+				Interval:   env.Interval,
+				MaxRetries: env.MaxRetries,
+			},
+			DefaultVersion: "v1.0",
+			Versions: map[string]time.Time{
+				"v1.0": time.Time{},
+			},
+		},
+		env: env,
+		client: &client{
+			env: env,
+		},
+	}
+}
+
+/*
+String returns the string representation of the destination NATS.
+*/
+func (d *NATS) String() string {
+	return "nats"
+}
+
+/*
+Options returns common destination options for NATS. They will be shared across
+every actions of this destination, except when overridden.
+*/
+func (d *NATS) Options() *destination.Options {
+	return d.options
+}
+
+/*
+Actions return a list of actions the destination NATS is able to handle.
+*/
+func (d *NATS) Actions() map[string]destination.Action {
+	return map[string]destination.Action{
+		"identify": Identify{
+			env:    d.env,
+			client: d.client,
+		},
+		"track": Track{
+			env:    d.env,
+			client: d.client,
+		},
+		"group": Group{
+			env:    d.env,
+			client: d.client,
+		},
+		"alias": Alias{
+			env:    d.env,
+			client: d.client,
+		},
+		"page": Page{
+			env:    d.env,
+			client: d.client,
+		},
+		"screen": Screen{
+			env:    d.env,
+			client: d.client,
+		},
+	}
+}
@@ -0,0 +1,26 @@
+package nats
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/destination"
+)
+
+var _ destination.Destination = &NATS{}
+
+func TestNATS_Actions(t *testing.T) {
+	d := New(&Options{
+		URL: "nats://localhost:4222",
+	})
+
+	if d.String() != "nats" {
+		t.Errorf("NATS.String() = %v, want %v", d.String(), "nats")
+	}
+
+	actions := d.Actions()
+	for _, name := range []string{"identify", "track", "group", "alias", "page", "screen"} {
+		if _, exists := actions[name]; !exists {
+			t.Errorf("NATS.Actions() is missing action %v", name)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package nats
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/nunchistudio/fragment/normalize"
+)
+
+/*
+EnvelopeVersion is the version of the schema of the Envelope. It only changes
+when a breaking change is made, so consumers can safely ignore unknown keys.
+*/
+var EnvelopeVersion = "1"
+
+/*
+Envelope is the JSON document published to NATS for every event. Consumers written
+in Go can decode messages directly into it.
+
+Example for a track event:
+
+	{
+	  "version": "1",
+	  "id": "ajs-f8ca1e4de5024d9430b3928bd8ac6b96",
+	  "type": "track",
+	  "event": "Order Completed",
+	  "subject": "fragment.events.track.order_completed",
+	  "eventId": "1UYc8EebLqCAFMOSkbYZdJwNLAJ",
+	  "receivedAt": "2021-06-01T10:00:00Z",
+	  "publishedAt": "2021-06-01T10:00:01Z",
+	  "data": {
+	    "type": "track",
+	    "event": "Order Completed",
+	    "userId": "019mr8mf4r",
+	    "properties": { "revenue": 1250 },
+	    "context": { "ip": "8.8.8.8" }
+	  }
+	}
+*/
+type Envelope struct {
+
+	// Version is the version of the schema of the envelope.
+	Version string `json:"version"`
+
+	// ID is the unique identifier of the message, which is the message ID of the
+	// event as sent by the client. Consumers should rely on it to deduplicate
+	// events.
+	ID string `json:"id"`
+
+	// Type is the type of the event, such as "track" or "identify".
+	Type string `json:"type"`
+
+	// Event is the name of the event. It is only set for track events.
+	Event string `json:"event,omitempty"`
+
+	// Subject is the NATS subject the envelope is published to.
+	Subject string `json:"subject"`
+
+	// EventID is the ID of the event in the Blacksmith store.
+	EventID string `json:"eventId"`
+
+	// ReceivedAt is the time the event was received by Fragment.
+	ReceivedAt time.Time `json:"receivedAt"`
+
+	// PublishedAt is the time the envelope was published. It changes on every
+	// attempt.
+	PublishedAt time.Time `json:"publishedAt"`
+
+	// Data is the event following the Segment spec, once validated and enriched by
+	// Fragment.
+	Data json.RawMessage `json:"data"`
+}
+
+/*
+message holds the fields of a job needed to build its envelope.
+*/
+type message struct {
+	MessageID string `json:"messageId"`
+	Event     string `json:"event"`
+}
+
+/*
+subject returns the subject an event of the given type and name is published to.
+Track events are published to a subject per event, named after the event in snake
+case.
+*/
+func subject(prefix string, typ string, event string) string {
+	if typ != "track" {
+		return prefix + "." + typ
+	}
+
+	token := normalize.Snake(event)
+	if !validSubject(token) {
+		token = "unknown"
+	}
+
+	return prefix + ".track." + token
+}
@@ -0,0 +1,46 @@
+package nats
+
+import (
+	"testing"
+)
+
+func TestSubject(t *testing.T) {
+	tests := []struct {
+		name  string
+		typ   string
+		event string
+		want  string
+	}{
+		{
+			name:  "WithTrack",
+			typ:   "track",
+			event: "Order Completed",
+			want:  "fragment.events.track.order_completed",
+		},
+		{
+			name:  "WithTrackWildcards",
+			typ:   "track",
+			event: "Signed Up > Step *2",
+			want:  "fragment.events.track.signed_up_step_2",
+		},
+		{
+			name:  "WithTrackWithoutName",
+			typ:   "track",
+			event: "",
+			want:  "fragment.events.track.unknown",
+		},
+		{
+			name:  "WithIdentify",
+			typ:   "identify",
+			event: "",
+			want:  "fragment.events.identify",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := subject("fragment.events", tt.typ, tt.event); got != tt.want {
+				t.Errorf("subject() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
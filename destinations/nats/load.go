@@ -0,0 +1,114 @@
+package nats
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+)
+
+/*
+load goes through every events received from the queue and their related jobs,
+and publishes their envelope to NATS. Jobs published are only marked as succeeded
+once NATS acknowledged them.
+*/
+func load(env *Options, c *client, typ string, queue *store.Queue, then chan<- destination.Then) {
+	p, err := c.get()
+	if err != nil {
+		jobs := []string{}
+		for _, event := range queue.Events {
+			for _, job := range event.Jobs {
+				jobs = append(jobs, job.ID)
+			}
+		}
+
+		then <- destination.Then{
+			Jobs: jobs,
+			Error: &errors.Error{
+				StatusCode: 500,
+				Message:    err.Error(),
+			},
+		}
+
+		return
+	}
+
+	published := []string{}
+	for _, event := range queue.Events {
+		for _, job := range event.Jobs {
+			var m message
+			if err := json.Unmarshal(job.Data, &m); err != nil {
+				then <- destination.Then{
+					Jobs:         []string{job.ID},
+					ForceDiscard: true,
+					Error: &errors.Error{
+						StatusCode: 400,
+						Message:    err.Error(),
+					},
+				}
+
+				continue
+			}
+
+			e := &Envelope{
+				Version:     EnvelopeVersion,
+				ID:          m.MessageID,
+				Type:        typ,
+				Subject:     subject(env.Prefix, typ, m.Event),
+				EventID:     event.ID,
+				ReceivedAt:  event.ReceivedAt,
+				PublishedAt: time.Now().UTC(),
+				Data:        job.Data,
+			}
+
+			if typ == "track" {
+				e.Event = m.Event
+			}
+
+			// Fallback to the job's ID so JetStream can still deduplicate retries of
+			// events without message ID.
+			if e.ID == "" {
+				e.ID = job.ID
+			}
+
+			body, _ := json.Marshal(e)
+			if err := p.publish(e.Subject, e.ID, body); err != nil {
+				then <- destination.Then{
+					Jobs: []string{job.ID},
+					Error: &errors.Error{
+						StatusCode: 500,
+						Message:    err.Error(),
+					},
+				}
+
+				continue
+			}
+
+			published = append(published, job.ID)
+		}
+	}
+
+	if len(published) == 0 {
+		return
+	}
+
+	if err := p.flush(); err != nil {
+		then <- destination.Then{
+			Jobs: published,
+			Error: &errors.Error{
+				StatusCode: 500,
+				Message:    err.Error(),
+			},
+		}
+
+		return
+	}
+
+	// Finally, inform the scheduler about the success.
+	then <- destination.Then{
+		Jobs:  published,
+		Error: nil,
+	}
+}
@@ -0,0 +1,138 @@
+package nats
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+fake is a publisher keeping the messages published in memory.
+*/
+type fake struct {
+	published  map[string][]byte
+	publishErr error
+	flushErr   error
+}
+
+func (f *fake) publish(subject string, id string, data []byte) error {
+	if f.publishErr != nil {
+		return f.publishErr
+	}
+
+	f.published[subject] = data
+	return nil
+}
+
+func (f *fake) flush() error {
+	return f.flushErr
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name          string
+		publishErr    error
+		flushErr      error
+		wantPublished int
+		wantErr       bool
+	}{
+		{
+			name:          "WithSuccess",
+			wantPublished: 1,
+		},
+		{
+			name:          "WithPublishFailure",
+			publishErr:    fmt.Errorf("nats: connection closed"),
+			wantPublished: 0,
+			wantErr:       true,
+		},
+		{
+			name:          "WithFlushFailure",
+			flushErr:      fmt.Errorf("nats: timeout"),
+			wantPublished: 1,
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fake{
+				published:  map[string][]byte{},
+				publishErr: tt.publishErr,
+				flushErr:   tt.flushErr,
+			}
+
+			env := &Options{
+				URL: "nats://localhost:4222",
+			}
+			env.validate()
+
+			action := Track{
+				env: env,
+				client: &client{
+					env:       env,
+					publisher: f,
+				},
+				Track: analytics.Track{
+					MessageId: "message",
+					UserId:    "user",
+					Event:     "Order Completed",
+					Timestamp: time.Now(),
+				},
+			}
+
+			job, err := action.Marshal(nil)
+			if err != nil {
+				t.Fatalf("Track.Marshal() error = %v", err)
+			}
+
+			then := make(chan destination.Then, 1)
+			action.Load(nil, &store.Queue{
+				Events: []*store.Event{
+					{
+						ID:         "event",
+						ReceivedAt: time.Now(),
+						Jobs: []*store.Job{
+							{
+								ID:   "job",
+								Data: job.Data,
+							},
+						},
+					},
+				},
+			}, then)
+			close(then)
+
+			result := <-then
+			if (result.Error != nil) != tt.wantErr {
+				t.Errorf("Track.Load() error = %v, wantErr %v", result.Error, tt.wantErr)
+			}
+
+			if result.ForceDiscard {
+				t.Errorf("Track.Load() discard = %v, want %v", result.ForceDiscard, false)
+			}
+
+			if len(f.published) != tt.wantPublished {
+				t.Fatalf("Track.Load() published = %v, want %v", len(f.published), tt.wantPublished)
+			}
+
+			if tt.wantPublished == 0 {
+				return
+			}
+
+			var e Envelope
+			if err := json.Unmarshal(f.published["fragment.events.track.order_completed"], &e); err != nil {
+				t.Fatalf("Track.Load() envelope error = %v", err)
+			}
+
+			if e.ID != "message" || e.Type != "track" || e.Event != "Order Completed" || e.EventID != "event" {
+				t.Errorf("Track.Load() envelope = %+v", e)
+			}
+		})
+	}
+}
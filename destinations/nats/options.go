@@ -0,0 +1,247 @@
+package nats
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+)
+
+/*
+Defaults are the defaults options set for the destination. When not set, these
+values will automatically be applied.
+*/
+var Defaults = &Options{
+	Prefix:   "fragment.events",
+	Stream:   "FRAGMENT_EVENTS",
+	Name:     "fragment",
+	Timeout:  10 * time.Second,
+	MaxAge:   7 * 24 * time.Hour,
+	MaxBytes: 1 << 30,
+}
+
+/*
+Options is the options the destination can take as an input to be configured.
+*/
+type Options struct {
+
+	// Realtime indicates if the pubsub adapter of the Blacksmith application shall
+	// be used to load events to the destination in realtime or not. When false, the
+	// Interval will be used.
+	Realtime bool
+
+	// Interval represents an interval or a CRON string at which a job shall be
+	// loaded to the destination. It is used as the time-lapse between retries in
+	// case of a job failure.
+	//
+	// Defaults to "@every 1h".
+	Interval string
+
+	// MaxRetries indicates the maximum number of retries per job the scheduler will
+	// attempt to execute before it succeed. When the limit is reached, the job is
+	// marked as "discarded".
+	//
+	// Defaults to 72.
+	MaxRetries uint16
+
+	// URL is the URL of the NATS server, such as "nats://localhost:4222".
+	//
+	// Required.
+	URL string
+
+	// Prefix is the prefix of the subjects events are published to. It must not
+	// contain wildcards.
+	//
+	// Defaults to "fragment.events".
+	Prefix string
+
+	// JetStream indicates if the events shall be published to JetStream. When true,
+	// the ID of the envelope is used as the message ID so JetStream can deduplicate
+	// retries.
+	JetStream bool
+
+	// Stream is the name of the JetStream stream capturing the subjects of the
+	// prefix. It is created if it does not exist. It is not used when JetStream
+	// is disabled.
+	//
+	// Defaults to "FRAGMENT_EVENTS".
+	Stream string
+
+	// Name is the name of the connection, as shown in the monitoring of the NATS
+	// server.
+	//
+	// Defaults to "fragment".
+	Name string
+
+	// Timeout is the time limit for connecting to NATS and for NATS to acknowledge
+	// the messages published.
+	//
+	// Defaults to 10 seconds.
+	Timeout time.Duration
+
+	// MaxAge is the maximum age of the messages kept by the JetStream stream. Older
+	// messages are removed. It is not used when JetStream is disabled.
+	//
+	// Defaults to 7 days.
+	MaxAge time.Duration
+
+	// MaxBytes is the maximum size in bytes of the JetStream stream. The oldest
+	// messages are removed once it is reached. Set to -1 for no limit. It is not
+	// used when JetStream is disabled.
+	//
+	// Defaults to 1GB.
+	MaxBytes int64
+
+	// Duplicates is the window during which JetStream deduplicates the messages
+	// given their ID. It must cover the time-lapse between retries, otherwise a job
+	// retried after a partial failure publishes its messages twice. It must not be
+	// greater than MaxAge. It is not used when JetStream is disabled.
+	//
+	// Defaults to twice the Interval when it is set as "@every <duration>", and to
+	// 24 hours otherwise.
+	Duplicates time.Duration
+}
+
+/*
+validate ensures the options passed to initialize the destination are valid.
+*/
+func (env *Options) validate() error {
+	var interval string = destination.Defaults.DefaultSchedule.Interval
+	var maxRetries uint16 = destination.Defaults.DefaultSchedule.MaxRetries
+
+	fail := &errors.Error{
+		Message:     "destination/nats: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Destinations", "nats"},
+		})
+
+		return fail
+	}
+
+	if env.Interval == "" {
+		env.Interval = interval
+	}
+
+	if env.MaxRetries == 0 {
+		env.MaxRetries = maxRetries
+	}
+
+	parsed, err := url.Parse(env.URL)
+	if env.URL == "" || err != nil || parsed.Host == "" {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "URL must be a valid NATS URL",
+			Path:    []string{"Options", "Destinations", "nats", "URL"},
+		})
+	}
+
+	if env.Prefix == "" {
+		env.Prefix = Defaults.Prefix
+	}
+
+	if !validSubject(env.Prefix) {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Prefix must be a valid subject without wildcards",
+			Path:    []string{"Options", "Destinations", "nats", "Prefix"},
+		})
+	}
+
+	if env.Stream == "" {
+		env.Stream = Defaults.Stream
+	}
+
+	if strings.ContainsAny(env.Stream, ".*> \t\r\n") {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Stream must not contain dots, wildcards or whitespaces",
+			Path:    []string{"Options", "Destinations", "nats", "Stream"},
+		})
+	}
+
+	if env.Name == "" {
+		env.Name = Defaults.Name
+	}
+
+	if env.Timeout < 0 {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Timeout must not be negative",
+			Path:    []string{"Options", "Destinations", "nats", "Timeout"},
+		})
+	} else if env.Timeout == 0 {
+		env.Timeout = Defaults.Timeout
+	}
+
+	if env.MaxAge < 0 {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "MaxAge must not be negative",
+			Path:    []string{"Options", "Destinations", "nats", "MaxAge"},
+		})
+	} else if env.MaxAge == 0 {
+		env.MaxAge = Defaults.MaxAge
+	}
+
+	if env.MaxBytes == 0 {
+		env.MaxBytes = Defaults.MaxBytes
+	} else if env.MaxBytes < -1 {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "MaxBytes must be positive, or -1 for no limit",
+			Path:    []string{"Options", "Destinations", "nats", "MaxBytes"},
+		})
+	}
+
+	if env.Duplicates < 0 {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Duplicates must not be negative",
+			Path:    []string{"Options", "Destinations", "nats", "Duplicates"},
+		})
+	} else if env.Duplicates == 0 {
+		env.Duplicates = retryWindow(env.Interval)
+	}
+
+	if env.Duplicates > env.MaxAge && env.MaxAge > 0 {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Duplicates must not be greater than MaxAge",
+			Path:    []string{"Options", "Destinations", "nats", "Duplicates"},
+		})
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
+
+/*
+validSubject returns if a subject is made of non-empty tokens separated by dots,
+with neither wildcards nor whitespaces.
+*/
+func validSubject(subject string) bool {
+	for _, token := range strings.Split(subject, ".") {
+		if token == "" || strings.ContainsAny(token, "*> \t\r\n") {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+retryWindow returns the default deduplication window given the interval between
+retries. It is twice the interval so a retry delayed by the scheduler is still
+deduplicated. Intervals set as CRON expressions can not be converted, in which
+case a day is assumed.
+*/
+func retryWindow(interval string) time.Duration {
+	every, err := time.ParseDuration(strings.TrimPrefix(interval, "@every "))
+	if !strings.HasPrefix(interval, "@every ") || err != nil || every <= 0 {
+		return 24 * time.Hour
+	}
+
+	return 2 * every
+}
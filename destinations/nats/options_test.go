@@ -0,0 +1,120 @@
+package nats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOptions_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  *Options
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: true,
+		},
+		{
+			name: "WithURL",
+			fields: &Options{
+				URL: "nats://localhost:4222",
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithInvalidURL",
+			fields: &Options{
+				URL: "localhost",
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithPrefix",
+			fields: &Options{
+				URL:    "nats://localhost:4222",
+				Prefix: "internal.analytics",
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithWildcardPrefix",
+			fields: &Options{
+				URL:    "nats://localhost:4222",
+				Prefix: "fragment.>",
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithEmptyToken",
+			fields: &Options{
+				URL:    "nats://localhost:4222",
+				Prefix: "fragment..events",
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithInvalidStream",
+			fields: &Options{
+				URL:       "nats://localhost:4222",
+				JetStream: true,
+				Stream:    "fragment.events",
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithRetention",
+			fields: &Options{
+				URL:        "nats://localhost:4222",
+				JetStream:  true,
+				MaxAge:     48 * time.Hour,
+				MaxBytes:   -1,
+				Duplicates: 2 * time.Hour,
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithInvalidMaxBytes",
+			fields: &Options{
+				URL:      "nats://localhost:4222",
+				MaxBytes: -2,
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithDuplicatesGreaterThanMaxAge",
+			fields: &Options{
+				URL:        "nats://localhost:4222",
+				MaxAge:     time.Hour,
+				Duplicates: 2 * time.Hour,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryWindow(t *testing.T) {
+	tests := []struct {
+		interval string
+		want     time.Duration
+	}{
+		{interval: "@every 1h", want: 2 * time.Hour},
+		{interval: "@every 30s", want: time.Minute},
+		{interval: "0 * * * *", want: 24 * time.Hour},
+		{interval: "@every forever", want: 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := retryWindow(tt.interval); got != tt.want {
+			t.Errorf("retryWindow(%q) = %v, want %v", tt.interval, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+/*
+Package retry holds the helpers shared by the destinations of Fragment to decide
+if a job must be retried given the response of a third-party API.
+*/
+package retry
+
+/*
+Discard returns if a job must be discarded given the HTTP status code returned
+by a third-party API. Client errors are not retried, except timeouts and rate
+limits. Server errors are always retried.
+*/
+func Discard(status int) bool {
+	return status >= 400 && status < 500 && status != 408 && status != 429
+}
@@ -0,0 +1,26 @@
+package retry
+
+import (
+	"testing"
+)
+
+func TestDiscard(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{status: 200, want: false},
+		{status: 400, want: true},
+		{status: 401, want: true},
+		{status: 404, want: true},
+		{status: 408, want: false},
+		{status: 429, want: false},
+		{status: 500, want: false},
+		{status: 503, want: false},
+	}
+	for _, tt := range tests {
+		if got := Discard(tt.status); got != tt.want {
+			t.Errorf("Discard(%d) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/nunchistudio/fragment/destinations/retry"
+)
+
+/*
+message is the body of a message posted to a Slack incoming webhook.
+*/
+type message struct {
+	Text string `json:"text"`
+}
+
+/*
+load goes through every events received from the queue and their related jobs,
+and posts a message for every rule satisfied by the event. Jobs not satisfying
+any rule, or only rate limited ones, are marked as succeeded without posting
+anything.
+
+When a job fails after posting the message of a rule, messages are posted again
+on retry for the other rules satisfied.
+*/
+func load(env *Options, client *http.Client, queue *store.Queue, then chan<- destination.Then) {
+	for _, event := range queue.Events {
+		for _, job := range event.Jobs {
+			data, err := decode(job.Data)
+			if err != nil {
+				then <- destination.Then{
+					Jobs:         []string{job.ID},
+					ForceDiscard: true,
+					Error: &errors.Error{
+						StatusCode: 400,
+						Message:    err.Error(),
+					},
+				}
+
+				continue
+			}
+
+			then <- alert(env, client, job.ID, data)
+		}
+	}
+}
+
+/*
+alert posts the messages of the rules satisfied by the event of a job, and returns
+the result of the job to send to the scheduler.
+*/
+func alert(env *Options, client *http.Client, job string, data map[string]interface{}) destination.Then {
+	for _, rule := range env.Rules {
+		if !rule.match(data) {
+			continue
+		}
+
+		text, err := rule.render(data)
+		if err != nil {
+			return destination.Then{
+				Jobs:         []string{job},
+				ForceDiscard: true,
+				Error: &errors.Error{
+					StatusCode: 400,
+					Message:    err.Error(),
+				},
+			}
+		}
+
+		allowed, suppressed := rule.limiter.allow(time.Now())
+		if !allowed {
+			continue
+		}
+
+		if suppressed > 0 {
+			text += "\n_" + strconv.Itoa(suppressed) + " similar alert(s) were not posted because of the rate limit._"
+		}
+
+		if fail := post(client, rule.WebhookURL, text); fail != nil {
+			return destination.Then{
+				Jobs:         []string{job},
+				ForceDiscard: retry.Discard(fail.StatusCode),
+				Error:        fail,
+			}
+		}
+	}
+
+	// Finally, inform the scheduler about the success.
+	return destination.Then{
+		Jobs:  []string{job},
+		Error: nil,
+	}
+}
+
+/*
+match returns if an event satisfies the rule.
+*/
+func (r *Rule) match(data map[string]interface{}) bool {
+	if data["event"] != r.Event {
+		return false
+	}
+
+	for _, filter := range r.Filters {
+		if !filter.match(data) {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+render returns the text of the message of the rule for an event.
+*/
+func (r *Rule) render(data map[string]interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := r.template.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+/*
+post posts a message to a Slack incoming webhook. A failure is retried by the
+scheduler, unless Slack rejected the request as invalid.
+*/
+func post(client *http.Client, webhookURL string, text string) *errors.Error {
+	body, _ := json.Marshal(&message{
+		Text: text,
+	})
+
+	res, err := client.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return &errors.Error{
+			StatusCode: 500,
+			Message:    err.Error(),
+		}
+	}
+
+	// Since a non-2xx status code doesn't cause an error, catch HTTP status
+	// code to ensure nothing bad happened.
+	buf := new(bytes.Buffer)
+	buf.ReadFrom(res.Body)
+	res.Body.Close()
+	if res.StatusCode >= 300 {
+		return &errors.Error{
+			StatusCode: res.StatusCode,
+			Message:    buf.String(),
+		}
+	}
+
+	return nil
+}
+
+/*
+decode decodes the data of a job. Numbers are kept as is so they are rendered in
+messages the way they have been received.
+*/
+func decode(b []byte) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,191 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name         string
+		event        string
+		revenue      float64
+		status       int
+		wantText     string
+		wantRequests int
+		wantErr      bool
+		wantDiscard  bool
+	}{
+		{
+			name:         "WithLargeOrder",
+			event:        "Order Completed",
+			revenue:      1250,
+			status:       200,
+			wantText:     "New order of $1250 by user",
+			wantRequests: 1,
+		},
+		{
+			name:         "WithSmallOrder",
+			event:        "Order Completed",
+			revenue:      20,
+			status:       200,
+			wantRequests: 0,
+		},
+		{
+			name:         "WithOtherEvent",
+			event:        "Product Viewed",
+			status:       200,
+			wantRequests: 0,
+		},
+		{
+			name:         "WithRateLimit",
+			event:        "Order Completed",
+			revenue:      1250,
+			status:       429,
+			wantText:     "New order of $1250 by user",
+			wantRequests: 1,
+			wantErr:      true,
+			wantDiscard:  false,
+		},
+		{
+			name:         "WithInvalidWebhook",
+			event:        "Order Completed",
+			revenue:      1250,
+			status:       404,
+			wantText:     "New order of $1250 by user",
+			wantRequests: 1,
+			wantErr:      true,
+			wantDiscard:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				requests++
+				var msg message
+				json.NewDecoder(req.Body).Decode(&msg)
+				if msg.Text != tt.wantText {
+					t.Errorf("message text = %v, want %v", msg.Text, tt.wantText)
+				}
+
+				res.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			env := &Options{
+				WebhookURL: server.URL,
+				Rules: []*Rule{
+					{
+						Name:  "large_orders",
+						Event: "Order Completed",
+						Filters: []*Filter{
+							{Path: "properties.revenue", Operator: OperatorGreater, Value: 1000},
+						},
+						Template: "New order of ${{ .properties.revenue }} by {{ .userId }}",
+					},
+				},
+			}
+			if err := env.validate(); err != nil {
+				t.Fatalf("Options.validate() error = %v", err)
+			}
+
+			action := Track{
+				env:    env,
+				client: server.Client(),
+				Track: analytics.Track{
+					UserId: "user",
+					Event:  tt.event,
+					Properties: analytics.Properties{
+						"revenue": tt.revenue,
+					},
+					Timestamp: time.Now(),
+				},
+			}
+
+			job, err := action.Marshal(nil)
+			if err != nil {
+				t.Fatalf("Track.Marshal() error = %v", err)
+			}
+
+			then := make(chan destination.Then, 1)
+			action.Load(nil, &store.Queue{
+				Events: []*store.Event{
+					{
+						Jobs: []*store.Job{
+							{
+								ID:   "job",
+								Data: job.Data,
+							},
+						},
+					},
+				},
+			}, then)
+			close(then)
+
+			result := <-then
+			if (result.Error != nil) != tt.wantErr {
+				t.Errorf("Track.Load() error = %v, wantErr %v", result.Error, tt.wantErr)
+			}
+
+			if result.ForceDiscard != tt.wantDiscard {
+				t.Errorf("Track.Load() discard = %v, want %v", result.ForceDiscard, tt.wantDiscard)
+			}
+
+			if requests != tt.wantRequests {
+				t.Errorf("Track.Load() requests = %v, want %v", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestAlert_RateLimit(t *testing.T) {
+	var texts []string
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		var msg message
+		json.NewDecoder(req.Body).Decode(&msg)
+		texts = append(texts, msg.Text)
+	}))
+	defer server.Close()
+
+	env := &Options{
+		WebhookURL: server.URL,
+		Rules: []*Rule{
+			{
+				Name:         "deleted_accounts",
+				Event:        "Account Deleted",
+				Template:     "Account {{ .properties.account_id }} has been deleted",
+				RateLimit:    1,
+				RateInterval: time.Hour,
+			},
+		},
+	}
+	if err := env.validate(); err != nil {
+		t.Fatalf("Options.validate() error = %v", err)
+	}
+
+	data := map[string]interface{}{
+		"event": "Account Deleted",
+		"properties": map[string]interface{}{
+			"account_id": "acme",
+		},
+	}
+
+	for i := 0; i < 3; i++ {
+		if then := alert(env, server.Client(), "job", data); then.Error != nil {
+			t.Fatalf("alert() error = %v", then.Error)
+		}
+	}
+
+	if len(texts) != 1 {
+		t.Errorf("alert() messages = %v, want %v", len(texts), 1)
+	}
+}
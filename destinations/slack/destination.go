@@ -0,0 +1,83 @@
+/*
+Package slack implements a Blacksmith destination posting alerts to Slack incoming
+webhooks when business events occur, such as large orders or deleted accounts.
+Rules define the events to alert on, filters over their properties and context,
+the message template, and a rate limit to avoid flooding a channel.
+*/
+package slack
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/logger"
+)
+
+/*
+Slack implements the Blacksmith destination.Destination interface for the
+destination "slack".
+*/
+type Slack struct {
+	options *destination.Options
+	env     *Options
+	client  *http.Client
+}
+
+/*
+New returns a valid Blacksmith destination.Destination for Slack.
+*/
+func New(env *Options) destination.Destination {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &Slack{
+		options: &destination.Options{
+			DefaultSchedule: &destination.Schedule{
+				Realtime:   env.Realtime,
+				Interval:   env.Interval,
+				MaxRetries: env.MaxRetries,
+			},
+			DefaultVersion: "v1.0",
+			Versions: map[string]time.Time{
+				"v1.0": time.Time{},
+			},
+		},
+		env: env,
+		client: &http.Client{
+			Timeout: env.Timeout,
+		},
+	}
+}
+
+/*
+String returns the string representation of the destination Slack.
+*/
+func (d *Slack) String() string {
+	return "slack"
+}
+
+/*
+Options returns common destination options for Slack. They will be shared across
+every actions of this destination, except when overridden.
+*/
+func (d *Slack) Options() *destination.Options {
+	return d.options
+}
+
+/*
+Actions return a list of actions the destination Slack is able to handle.
+*/
+func (d *Slack) Actions() map[string]destination.Action {
+	return map[string]destination.Action{
+		"track": Track{
+			env:    d.env,
+			client: d.client,
+		},
+	}
+}
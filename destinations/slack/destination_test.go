@@ -0,0 +1,30 @@
+package slack
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/destination"
+)
+
+var _ destination.Destination = &Slack{}
+
+func TestSlack_Actions(t *testing.T) {
+	d := New(&Options{
+		WebhookURL: "https://hooks.slack.com/services/T000/B000/XXXX",
+		Rules: []*Rule{
+			{
+				Name:     "deleted_accounts",
+				Event:    "Account Deleted",
+				Template: "Account {{ .properties.account_id }} has been deleted",
+			},
+		},
+	})
+
+	if d.String() != "slack" {
+		t.Errorf("Slack.String() = %v, want %v", d.String(), "slack")
+	}
+
+	if _, exists := d.Actions()["track"]; !exists {
+		t.Errorf("Slack.Actions() is missing action %v", "track")
+	}
+}
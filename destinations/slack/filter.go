@@ -0,0 +1,167 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+Operator is a custom type allowing the user to only pass supported operators
+when filtering events.
+*/
+type Operator string
+
+/*
+OperatorExists is used to check if a value is set.
+*/
+var OperatorExists Operator = "exists"
+
+/*
+OperatorEqual is used to check if a value is equal to another.
+*/
+var OperatorEqual Operator = "eq"
+
+/*
+OperatorNotEqual is used to check if a value is not equal to another.
+*/
+var OperatorNotEqual Operator = "neq"
+
+/*
+OperatorGreater is used to check if a numeric value is greater than another.
+*/
+var OperatorGreater Operator = "gt"
+
+/*
+OperatorGreaterOrEqual is used to check if a numeric value is greater than or
+equal to another.
+*/
+var OperatorGreaterOrEqual Operator = "gte"
+
+/*
+OperatorLess is used to check if a numeric value is less than another.
+*/
+var OperatorLess Operator = "lt"
+
+/*
+OperatorLessOrEqual is used to check if a numeric value is less than or equal to
+another.
+*/
+var OperatorLessOrEqual Operator = "lte"
+
+/*
+OperatorContains is used to check if a string value contains another.
+*/
+var OperatorContains Operator = "contains"
+
+/*
+Filter is a condition over a value of an event a rule applies to.
+*/
+type Filter struct {
+
+	// Path is the path of the value in the event, with keys separated by dots
+	// such as "properties.revenue" or "context.campaign.source".
+	//
+	// Required.
+	Path string
+
+	// Operator is the operator used to compare the value of the event with the
+	// value of the filter.
+	//
+	// Required.
+	Operator Operator
+
+	// Value is the value to compare the value of the event with. It is not used by
+	// the operator OperatorExists.
+	Value interface{}
+}
+
+/*
+match returns if an event satisfies the filter.
+*/
+func (f *Filter) match(event map[string]interface{}) bool {
+	value, exists := lookup(event, f.Path)
+	exists = exists && value != nil
+	switch f.Operator {
+	case OperatorExists:
+		return exists
+
+	case OperatorEqual:
+		return exists && fmt.Sprint(value) == fmt.Sprint(f.Value)
+
+	case OperatorNotEqual:
+		return !exists || fmt.Sprint(value) != fmt.Sprint(f.Value)
+
+	case OperatorContains:
+		s, ok := value.(string)
+		return exists && ok && strings.Contains(s, fmt.Sprint(f.Value))
+
+	case OperatorGreater, OperatorGreaterOrEqual, OperatorLess, OperatorLessOrEqual:
+		a, ok := number(value)
+		if !exists || !ok {
+			return false
+		}
+
+		b, ok := number(f.Value)
+		if !ok {
+			return false
+		}
+
+		switch f.Operator {
+		case OperatorGreater:
+			return a > b
+		case OperatorGreaterOrEqual:
+			return a >= b
+		case OperatorLess:
+			return a < b
+		case OperatorLessOrEqual:
+			return a <= b
+		}
+	}
+
+	return false
+}
+
+/*
+lookup returns the value at a path of an event, and if it exists.
+*/
+func lookup(event map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = event
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = object[key]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+/*
+number returns the numeric representation of a value. Numbers sent as strings are
+also supported.
+*/
+func number(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+
+	return 0, false
+}
@@ -0,0 +1,81 @@
+package slack
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFilter_match(t *testing.T) {
+	event := map[string]interface{}{
+		"event": "Order Completed",
+		"properties": map[string]interface{}{
+			"revenue":  json.Number("1250.50"),
+			"currency": "USD",
+			"coupon":   nil,
+		},
+		"context": map[string]interface{}{
+			"campaign": map[string]interface{}{
+				"source": "newsletter-weekly",
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   bool
+	}{
+		{
+			name:   "WithExistingValue",
+			filter: &Filter{Path: "properties.currency", Operator: OperatorExists},
+			want:   true,
+		},
+		{
+			name:   "WithNullValue",
+			filter: &Filter{Path: "properties.coupon", Operator: OperatorExists},
+			want:   false,
+		},
+		{
+			name:   "WithEqualValue",
+			filter: &Filter{Path: "properties.currency", Operator: OperatorEqual, Value: "USD"},
+			want:   true,
+		},
+		{
+			name:   "WithNotEqualMissingValue",
+			filter: &Filter{Path: "properties.tax", Operator: OperatorNotEqual, Value: 0},
+			want:   true,
+		},
+		{
+			name:   "WithGreaterThreshold",
+			filter: &Filter{Path: "properties.revenue", Operator: OperatorGreater, Value: 1000},
+			want:   true,
+		},
+		{
+			name:   "WithLowerThreshold",
+			filter: &Filter{Path: "properties.revenue", Operator: OperatorLessOrEqual, Value: "1000"},
+			want:   false,
+		},
+		{
+			name:   "WithNonNumericValue",
+			filter: &Filter{Path: "properties.currency", Operator: OperatorGreater, Value: 0},
+			want:   false,
+		},
+		{
+			name:   "WithNestedContext",
+			filter: &Filter{Path: "context.campaign.source", Operator: OperatorContains, Value: "newsletter"},
+			want:   true,
+		},
+		{
+			name:   "WithPathThroughString",
+			filter: &Filter{Path: "properties.currency.code", Operator: OperatorExists},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.match(event); got != tt.want {
+				t.Errorf("Filter.match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package slack
+
+import (
+	"sync"
+	"time"
+)
+
+/*
+limiter limits the number of messages a rule posts within a fixed window of time.
+Messages above the limit are suppressed and counted, so the next message posted
+can mention them.
+*/
+type limiter struct {
+	mutex      sync.Mutex
+	limit      int
+	interval   time.Duration
+	start      time.Time
+	count      int
+	suppressed int
+}
+
+/*
+allow returns if a message can be posted at a given time, and the number of
+messages suppressed since the last one posted. A limit of zero disables the rate
+limiting.
+*/
+func (l *limiter) allow(now time.Time) (bool, int) {
+	if l.limit <= 0 {
+		return true, 0
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if now.Sub(l.start) >= l.interval {
+		l.start = now
+		l.count = 0
+	}
+
+	if l.count >= l.limit {
+		l.suppressed++
+		return false, 0
+	}
+
+	l.count++
+	suppressed := l.suppressed
+	l.suppressed = 0
+	return true, suppressed
+}
@@ -0,0 +1,35 @@
+package slack
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter_allow(t *testing.T) {
+	now := time.Now()
+	l := &limiter{
+		limit:    2,
+		interval: time.Minute,
+	}
+
+	for i := 0; i < 2; i++ {
+		if allowed, _ := l.allow(now); !allowed {
+			t.Fatalf("limiter.allow() = %v, want %v", allowed, true)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		if allowed, _ := l.allow(now.Add(time.Second)); allowed {
+			t.Fatalf("limiter.allow() = %v, want %v", allowed, false)
+		}
+	}
+
+	allowed, suppressed := l.allow(now.Add(time.Minute))
+	if !allowed {
+		t.Errorf("limiter.allow() = %v, want %v", allowed, true)
+	}
+
+	if suppressed != 3 {
+		t.Errorf("limiter.allow() suppressed = %v, want %v", suppressed, 3)
+	}
+}
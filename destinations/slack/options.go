@@ -0,0 +1,307 @@
+package slack
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+)
+
+/*
+ruleName is the regular expression a rule name must match.
+*/
+var ruleName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+/*
+Defaults are the defaults options set for the destination. When not set, these
+values will automatically be applied.
+*/
+var Defaults = &Options{
+	Timeout: 10 * time.Second,
+}
+
+/*
+DefaultRateInterval is the window of time applied to the rate limit of the rules
+when not set.
+*/
+var DefaultRateInterval = time.Minute
+
+/*
+Rule is a rule posting a message to Slack when a "track" event satisfies its
+filters.
+
+Example for orders above $1,000:
+
+	&slack.Rule{
+	  Name:  "large_orders",
+	  Event: "Order Completed",
+	  Filters: []*slack.Filter{
+	    {Path: "properties.revenue", Operator: slack.OperatorGreater, Value: 1000},
+	  },
+	  Template:  "New order of ${{ .properties.revenue }} by {{ .userId }}",
+	  RateLimit: 10,
+	}
+*/
+type Rule struct {
+
+	// Name is the unique name of the rule, in snake case.
+	//
+	// Required.
+	Name string
+
+	// Event is the name of the "track" event the rule applies to.
+	//
+	// Required.
+	Event string
+
+	// Filters are the conditions the event must satisfy for the message to be
+	// posted. All of them must be satisfied.
+	Filters []*Filter
+
+	// WebhookURL is the URL of the Slack incoming webhook the message is posted
+	// to.
+	//
+	// Defaults to the WebhookURL of the options.
+	WebhookURL string
+
+	// Template is the text of the message, as a Go template rendered with the
+	// event following the Segment spec, such as "{{ .properties.revenue }}" or
+	// "{{ .context.page.url }}". Slack formatting is supported.
+	//
+	// Required.
+	Template string
+
+	// RateLimit is the maximum number of messages posted by the rule within the
+	// RateInterval. Messages above the limit are not posted, and are mentioned in
+	// the next message posted. When zero, messages are not rate limited.
+	RateLimit int
+
+	// RateInterval is the window of time of the RateLimit.
+	//
+	// Defaults to 1 minute.
+	RateInterval time.Duration
+
+	template *template.Template
+	limiter  *limiter
+}
+
+/*
+Options is the options the destination can take as an input to be configured.
+*/
+type Options struct {
+
+	// Realtime indicates if the pubsub adapter of the Blacksmith application shall
+	// be used to load events to the destination in realtime or not. When false, the
+	// Interval will be used.
+	Realtime bool
+
+	// Interval represents an interval or a CRON string at which a job shall be
+	// loaded to the destination. It is used as the time-lapse between retries in
+	// case of a job failure.
+	//
+	// Defaults to "@every 1h".
+	Interval string
+
+	// MaxRetries indicates the maximum number of retries per job the scheduler will
+	// attempt to execute before it succeed. When the limit is reached, the job is
+	// marked as "discarded".
+	//
+	// Defaults to 72.
+	MaxRetries uint16
+
+	// WebhookURL is the URL of the Slack incoming webhook messages are posted to,
+	// for the rules not having their own.
+	WebhookURL string
+
+	// Rules are the rules posting messages to Slack. Events not satisfying any
+	// rule are ignored.
+	//
+	// Required.
+	Rules []*Rule
+
+	// Timeout is the time limit for a request made to Slack.
+	//
+	// Defaults to 10 seconds.
+	Timeout time.Duration
+}
+
+/*
+validate ensures the options passed to initialize the destination are valid. It
+also compiles the templates and creates the rate limiters of the rules.
+*/
+func (env *Options) validate() error {
+	var interval string = destination.Defaults.DefaultSchedule.Interval
+	var maxRetries uint16 = destination.Defaults.DefaultSchedule.MaxRetries
+
+	fail := &errors.Error{
+		Message:     "destination/slack: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Destinations", "slack"},
+		})
+
+		return fail
+	}
+
+	if env.Interval == "" {
+		env.Interval = interval
+	}
+
+	if env.MaxRetries == 0 {
+		env.MaxRetries = maxRetries
+	}
+
+	if env.Timeout == 0 {
+		env.Timeout = Defaults.Timeout
+	}
+
+	if len(env.Rules) == 0 {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "At least one rule must be set",
+			Path:    []string{"Options", "Destinations", "slack", "Rules"},
+		})
+	}
+
+	names := map[string]bool{}
+	for i, rule := range env.Rules {
+		path := []string{"Options", "Destinations", "slack", "Rules", strconv.Itoa(i)}
+		if rule == nil {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Rule must not be nil",
+				Path:    path,
+			})
+
+			continue
+		}
+
+		if !ruleName.MatchString(rule.Name) {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Rule name must be in snake case",
+				Path:    at(path, "Name"),
+			})
+		}
+
+		if names[rule.Name] {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Rule name '" + rule.Name + "' must be unique",
+				Path:    at(path, "Name"),
+			})
+		}
+
+		names[rule.Name] = true
+		if rule.Event == "" {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Event must be set",
+				Path:    at(path, "Event"),
+			})
+		}
+
+		for j, filter := range rule.Filters {
+			fail.Validations = append(fail.Validations, filter.validate(at(path, "Filters", strconv.Itoa(j)))...)
+		}
+
+		if rule.WebhookURL == "" {
+			rule.WebhookURL = env.WebhookURL
+		}
+
+		parsed, err := url.Parse(rule.WebhookURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Webhook URL must be a valid HTTP or HTTPS URL",
+				Path:    at(path, "WebhookURL"),
+			})
+		}
+
+		rule.template, err = template.New(rule.Name).Parse(rule.Template)
+		if err != nil || rule.Template == "" {
+			message := "Template must be set"
+			if err != nil {
+				message = err.Error()
+			}
+
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: message,
+				Path:    at(path, "Template"),
+			})
+		}
+
+		if rule.RateLimit < 0 || rule.RateInterval < 0 {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Rate limit must not be negative",
+				Path:    at(path, "RateLimit"),
+			})
+		}
+
+		if rule.RateInterval == 0 {
+			rule.RateInterval = DefaultRateInterval
+		}
+
+		rule.limiter = &limiter{
+			limit:    rule.RateLimit,
+			interval: rule.RateInterval,
+		}
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
+
+/*
+validate ensures the filter is valid.
+*/
+func (f *Filter) validate(path []string) []errors.Validation {
+	validations := []errors.Validation{}
+	if f == nil {
+		return append(validations, errors.Validation{
+			Message: "Filter must not be nil",
+			Path:    path,
+		})
+	}
+
+	if f.Path == "" {
+		validations = append(validations, errors.Validation{
+			Message: "Path must be set",
+			Path:    at(path, "Path"),
+		})
+	}
+
+	switch f.Operator {
+	case OperatorExists, OperatorEqual, OperatorNotEqual, OperatorContains:
+	case OperatorGreater, OperatorGreaterOrEqual, OperatorLess, OperatorLessOrEqual:
+		if _, ok := number(f.Value); !ok {
+			validations = append(validations, errors.Validation{
+				Message: "Value must be a number for operator '" + string(f.Operator) + "'",
+				Path:    at(path, "Value"),
+			})
+		}
+
+	default:
+		validations = append(validations, errors.Validation{
+			Message: "Operator must be one of 'exists', 'eq', 'neq', 'gt', 'gte', 'lt', 'lte', 'contains'",
+			Path:    at(path, "Operator"),
+		})
+	}
+
+	return validations
+}
+
+/*
+at returns a copy of a path with the keys appended, so paths of sibling values
+never share the same underlying array.
+*/
+func at(path []string, keys ...string) []string {
+	p := make([]string, 0, len(path)+len(keys))
+	p = append(p, path...)
+	return append(p, keys...)
+}
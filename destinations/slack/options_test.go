@@ -0,0 +1,127 @@
+package slack
+
+import (
+	"testing"
+)
+
+func TestOptions_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  *Options
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: true,
+		},
+		{
+			name: "WithRule",
+			fields: &Options{
+				WebhookURL: "https://hooks.slack.com/services/T000/B000/XXXX",
+				Rules: []*Rule{
+					{
+						Name:  "large_orders",
+						Event: "Order Completed",
+						Filters: []*Filter{
+							{Path: "properties.revenue", Operator: OperatorGreater, Value: 1000},
+						},
+						Template:  "New order of ${{ .properties.revenue }}",
+						RateLimit: 10,
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithRuleWebhookURL",
+			fields: &Options{
+				Rules: []*Rule{
+					{
+						Name:       "deleted_accounts",
+						Event:      "Account Deleted",
+						WebhookURL: "https://hooks.slack.com/services/T000/B000/XXXX",
+						Template:   "Account deleted",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithoutWebhookURL",
+			fields: &Options{
+				Rules: []*Rule{
+					{
+						Name:     "deleted_accounts",
+						Event:    "Account Deleted",
+						Template: "Account deleted",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithDuplicateRuleNames",
+			fields: &Options{
+				WebhookURL: "https://hooks.slack.com/services/T000/B000/XXXX",
+				Rules: []*Rule{
+					{Name: "alerts", Event: "Account Deleted", Template: "Account deleted"},
+					{Name: "alerts", Event: "Order Completed", Template: "Order completed"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithInvalidTemplate",
+			fields: &Options{
+				WebhookURL: "https://hooks.slack.com/services/T000/B000/XXXX",
+				Rules: []*Rule{
+					{Name: "deleted_accounts", Event: "Account Deleted", Template: "{{ .properties"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithNonNumericThreshold",
+			fields: &Options{
+				WebhookURL: "https://hooks.slack.com/services/T000/B000/XXXX",
+				Rules: []*Rule{
+					{
+						Name:  "large_orders",
+						Event: "Order Completed",
+						Filters: []*Filter{
+							{Path: "properties.revenue", Operator: OperatorGreater, Value: "a lot"},
+						},
+						Template: "New order",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithUnsupportedOperator",
+			fields: &Options{
+				WebhookURL: "https://hooks.slack.com/services/T000/B000/XXXX",
+				Rules: []*Rule{
+					{
+						Name:  "large_orders",
+						Event: "Order Completed",
+						Filters: []*Filter{
+							{Path: "properties.revenue", Operator: "between", Value: 1000},
+						},
+						Template: "New order",
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package warehouse
+
+import (
+	"encoding/json"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Page implements the Blacksmith destination.Action interface for the action
+"page". It writes the event into the table "pages". The standard properties of
+the page are written into their own columns.
+*/
+type Page struct {
+	loader *loader
+
+	analytics.Page
+}
+
+/*
+String returns the string representation of the action Page.
+*/
+func (a Page) String() string {
+	return "page"
+}
+
+/*
+Schedule allows the action to override the schedule options of its
+destination. Do not override.
+*/
+func (a Page) Schedule() *destination.Schedule {
+	return nil
+}
+
+/*
+Marshal is the function being run when the action receives data into
+the Page receiver. It allows to transform and enrich the data before
+saving it in the store adapter.
+*/
+func (a Page) Marshal(tk *destination.Toolkit) (*destination.Job, error) {
+
+	// Try to marshal the data passed directly to the receiver.
+	data, err := json.Marshal(&a)
+	if err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+		}
+	}
+
+	// Create a job with the data. Since the 'Context' key is not
+	// set, the one from the event will automatically be applied.
+	j := &destination.Job{
+		Version: "v1.0",
+		Data:    data,
+		SentAt:  &a.Timestamp,
+	}
+
+	// Return the job including the marshaled data.
+	return j, nil
+}
+
+/*
+Load is the function being run by the scheduler to load the data into
+the destination. It is in charge of the "L" in the ETL process.
+*/
+func (a Page) Load(tk *destination.Toolkit, queue *store.Queue, then chan<- destination.Then) {
+	load(a.loader, queue, then, func(event *store.Event, job *store.Job) ([]*row, error) {
+		var d analytics.Page
+		if err := json.Unmarshal(job.Data, &d); err != nil {
+			return nil, err
+		}
+
+		values := message{
+			ID:          d.MessageId,
+			UserID:      d.UserId,
+			AnonymousID: d.AnonymousId,
+			Timestamp:   d.Timestamp,
+			Context:     d.Context,
+		}.values(job.ID, event.ReceivedAt, event.SentAt)
+
+		// The name is part of the message, while the other columns of the page are
+		// standard properties.
+		values["name"] = nullable(d.Name)
+		for _, c := range pageColumns {
+			if value, ok := d.Properties[c.Name].(string); ok && c.Name != "name" {
+				values[c.Name] = value
+			}
+		}
+
+		return []*row{
+			{table: tablePages, values: values},
+		}, nil
+	})
+}
@@ -0,0 +1,7 @@
+package warehouse
+
+import (
+	"github.com/nunchistudio/blacksmith/destination"
+)
+
+var _ destination.Action = Page{}
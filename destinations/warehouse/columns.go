@@ -0,0 +1,153 @@
+package warehouse
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/nunchistudio/fragment/normalize"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+row is a row to write into a table of the warehouse. Values of the standard
+columns are indexed by column names. Properties are the flattened properties or
+traits of the event, for which columns are added or widened when needed.
+*/
+type row struct {
+	table      *table
+	values     map[string]interface{}
+	properties map[string]interface{}
+	upsert     bool
+}
+
+/*
+flatten flattens nested objects into a single level map, as done by Segment when
+writing properties into columns: the key "page" holding the object {"url": "..."}
+becomes "page_url" when prefixed by the parent's key. Keys are converted to snake
+case. Arrays are kept as JSON, and numbers as json.Number so they are not altered
+by a conversion to float64.
+
+Keys are flattened in lexical order, and the first value flattened into a name is
+kept. This way, when several keys have the same name once converted to snake case,
+such as "userName" and "user_name", the same value is always written.
+*/
+func flatten(prefix string, value interface{}, into map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+		for _, key := range keys {
+			name := normalize.Snake(key)
+			if prefix != "" {
+				name = prefix + "_" + name
+			}
+
+			flatten(name, v[key], into)
+		}
+
+	case []interface{}:
+		if _, exists := into[prefix]; !exists {
+			b, _ := json.Marshal(v)
+			into[prefix] = string(b)
+		}
+
+	default:
+		if _, exists := into[prefix]; !exists {
+			into[prefix] = v
+		}
+	}
+}
+
+/*
+decode decodes a JSON object using json.Number for numbers.
+*/
+func decode(b []byte) map[string]interface{} {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	var m map[string]interface{}
+	decoder.Decode(&m)
+	return m
+}
+
+/*
+properties returns the flattened object at the given key of a JSON object, such
+as the properties of a track or the traits of an identify.
+*/
+func properties(data []byte, key string) map[string]interface{} {
+	flattened := map[string]interface{}{}
+	if object, ok := decode(data)[key].(map[string]interface{}); ok {
+		flatten("", object, flattened)
+	}
+
+	return flattened
+}
+
+/*
+message holds the fields common to every messages written to the warehouse.
+*/
+type message struct {
+	ID          string
+	UserID      string
+	AnonymousID string
+	Timestamp   time.Time
+	Context     *analytics.Context
+}
+
+/*
+values returns the common and context values of a message. The ID of the job is
+used as ID of the row when the message has none, so retries do not write the same
+message twice. Context values are converted to the type of their column.
+*/
+func (m message) values(jobID string, receivedAt time.Time, sentAt *time.Time) map[string]interface{} {
+	values := map[string]interface{}{}
+	if m.Context != nil {
+		b, _ := json.Marshal(m.Context)
+
+		flattened := map[string]interface{}{}
+		flatten("context", decode(b), flattened)
+		for _, c := range contextColumns {
+			if value, exists := flattened[c.Name]; exists {
+				values[c.Name] = convert(value, c.Type)
+			}
+		}
+	}
+
+	values["id"] = m.ID
+	if m.ID == "" {
+		values["id"] = jobID
+	}
+
+	values["received_at"] = receivedAt
+	if sentAt != nil && !sentAt.IsZero() {
+		values["sent_at"] = *sentAt
+	}
+
+	if !m.Timestamp.IsZero() {
+		values["timestamp"] = m.Timestamp
+		values["original_timestamp"] = m.Timestamp
+	}
+
+	values["uuid_ts"] = time.Now().UTC()
+	values["user_id"] = nullable(m.UserID)
+	values["anonymous_id"] = nullable(m.AnonymousID)
+	return values
+}
+
+/*
+nullable returns nil for an empty string so it is written as NULL.
+*/
+func nullable(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+
+	return value
+}
@@ -0,0 +1,114 @@
+package warehouse
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+func TestFlatten(t *testing.T) {
+	tests := []struct {
+		name  string
+		value map[string]interface{}
+		want  map[string]interface{}
+	}{
+		{
+			name: "WithNestedObject",
+			value: map[string]interface{}{
+				"page": map[string]interface{}{
+					"url": "https://example.com",
+				},
+			},
+			want: map[string]interface{}{
+				"context_page_url": "https://example.com",
+			},
+		},
+		{
+			name: "WithCamelCaseKeys",
+			value: map[string]interface{}{
+				"userAgent": "Mozilla/5.0",
+			},
+			want: map[string]interface{}{
+				"context_user_agent": "Mozilla/5.0",
+			},
+		},
+		{
+			name: "WithSameSnakeCaseName",
+			value: map[string]interface{}{
+				"userAgent":  "Mozilla/5.0",
+				"user_agent": "curl/8.0",
+				"page_url":   "https://example.org",
+				"page": map[string]interface{}{
+					"url": "https://example.com",
+				},
+			},
+			want: map[string]interface{}{
+				"context_user_agent": "Mozilla/5.0",
+				"context_page_url":   "https://example.com",
+			},
+		},
+		{
+			name: "WithArrayAndNumber",
+			value: map[string]interface{}{
+				"ids":        []interface{}{"a", "b"},
+				"session_id": json.Number("1697616000000"),
+			},
+			want: map[string]interface{}{
+				"context_ids":        `["a","b"]`,
+				"context_session_id": json.Number("1697616000000"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[string]interface{}{}
+			flatten("context", tt.value, got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("flatten() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessage_values(t *testing.T) {
+	receivedAt := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	m := message{
+		UserID: "u1",
+		Context: &analytics.Context{
+			IP: []byte{127, 0, 0, 1},
+			Page: analytics.PageInfo{
+				URL: "https://example.com",
+			},
+			Traits: analytics.Traits{
+				"email": "john@example.com",
+			},
+			Extra: map[string]interface{}{
+				"session_id": 1697616000000,
+			},
+		},
+	}
+
+	values := m.values("job", receivedAt, nil)
+	want := map[string]interface{}{
+		"id":                 "job",
+		"user_id":            "u1",
+		"anonymous_id":       nil,
+		"received_at":        receivedAt,
+		"context_ip":         "127.0.0.1",
+		"context_page_url":   "https://example.com",
+		"context_session_id": int64(1697616000000),
+	}
+
+	for key, value := range want {
+		if !reflect.DeepEqual(values[key], value) {
+			t.Errorf("message.values()[%v] = %v, want %v", key, values[key], value)
+		}
+	}
+
+	if _, exists := values["context_traits_email"]; exists {
+		t.Errorf("message.values() must not contain unknown context columns")
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Package warehouse implements a Blacksmith destination writing every events into a
+PostgreSQL schema following the layout of the Segment warehouses, so the queries
+written against Segment keep working against Fragment.
+
+Properties of track events and traits of identify events are written as columns,
+which are added and widened automatically as new properties and types are seen.
+
+Reference: https://segment.com/docs/connections/storage/warehouses/schema/
+*/
+package warehouse
+
+import (
+	"time"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/helper/logger"
+	"github.com/nunchistudio/blacksmith/warehouse"
+)
+
+/*
+Warehouse implements the Blacksmith destination.Destination interface for the
+destination "warehouse".
+*/
+type Warehouse struct {
+	options *destination.Options
+	env     *Options
+	loader  *loader
+}
+
+/*
+New returns a valid Blacksmith destination.Destination for the warehouse.
+*/
+func New(env *Options) destination.Destination {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &Warehouse{
+		options: &destination.Options{
+			DefaultSchedule: &destination.Schedule{
+				Realtime:   env.Realtime,
+				Interval:   env.Interval,
+				MaxRetries: env.MaxRetries,
+			},
+			DefaultVersion: "v1.0",
+			Versions: map[string]time.Time{
+				"v1.0": time.Time{},
+			},
+		},
+		env: env,
+		loader: &loader{
+			env:    env,
+			tables: map[string]map[string]string{},
+		},
+	}
+}
+
+/*
+String returns the string representation of the destination Warehouse.
+*/
+func (d *Warehouse) String() string {
+	return "warehouse"
+}
+
+/*
+Options returns common destination options for the warehouse. They will be
+shared across every actions of this destination, except when overridden.
+*/
+func (d *Warehouse) Options() *destination.Options {
+	return d.options
+}
+
+/*
+Actions return a list of actions the destination Warehouse is able to handle.
+*/
+func (d *Warehouse) Actions() map[string]destination.Action {
+	return map[string]destination.Action{
+		"identify": Identify{
+			loader: d.loader,
+		},
+		"track": Track{
+			loader: d.loader,
+		},
+		"group": Group{
+			loader: d.loader,
+		},
+		"alias": Alias{
+			loader: d.loader,
+		},
+		"page": Page{
+			loader: d.loader,
+		},
+		"screen": Screen{
+			loader: d.loader,
+		},
+	}
+}
+
+/*
+AsWarehouse returns the warehouse so SQL operations and queries can run on top of
+it, such as the modeled tables built from the raw events. The schema and the
+tables shared by every events are created if needed, so operations can rely on
+them even before the first events are loaded.
+*/
+func (d *Warehouse) AsWarehouse() (*warehouse.Warehouse, error) {
+	if err := d.loader.prepare(); err != nil {
+		return nil, &errors.Error{
+			Message: "destination/warehouse: Failed to prepare tables",
+			Validations: []errors.Validation{
+				{
+					Message: err.Error(),
+				},
+			},
+		}
+	}
+
+	return warehouse.New(&warehouse.Options{
+		Name: "warehouse",
+		DB:   d.env.DB,
+	})
+}
+
+/*
+load goes through every events received from the queue and their related jobs,
+and writes the rows returned for each job into the warehouse. The result of every
+job is sent to the scheduler.
+*/
+func load(l *loader, queue *store.Queue, then chan<- destination.Then, rows func(event *store.Event, job *store.Job) ([]*row, error)) {
+	for _, event := range queue.Events {
+		for _, job := range event.Jobs {
+
+			// A job which can not be decoded will never succeed, so there is no
+			// need to retry it.
+			r, err := rows(event, job)
+			if err != nil {
+				then <- destination.Then{
+					Jobs:         []string{job.ID},
+					ForceDiscard: true,
+					Error: &errors.Error{
+						StatusCode: 400,
+						Message:    err.Error(),
+					},
+				}
+
+				continue
+			}
+
+			// Write the rows and inform the scheduler if any error happened.
+			if err := l.write(r...); err != nil {
+				then <- destination.Then{
+					Jobs: []string{job.ID},
+					Error: &errors.Error{
+						StatusCode: 500,
+						Message:    err.Error(),
+					},
+				}
+
+				continue
+			}
+
+			// Finally, inform the scheduler about the success.
+			then <- destination.Then{
+				Jobs:  []string{job.ID},
+				Error: nil,
+			}
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package warehouse
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/warehouse"
+)
+
+var _ destination.Destination = &Warehouse{}
+var _ warehouse.AsWarehouse = &Warehouse{}
+
+func TestWarehouse_Actions(t *testing.T) {
+	d := New(&Options{
+		DB: &sql.DB{},
+	})
+
+	if d.String() != "warehouse" {
+		t.Errorf("Warehouse.String() = %v, want %v", d.String(), "warehouse")
+	}
+
+	actions := d.Actions()
+	for _, name := range []string{"identify", "track", "group", "alias", "page", "screen"} {
+		if _, exists := actions[name]; !exists {
+			t.Errorf("Warehouse.Actions() is missing action %v", name)
+		}
+	}
+}
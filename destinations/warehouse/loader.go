@@ -0,0 +1,319 @@
+package warehouse
+
+import (
+	"database/sql"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nunchistudio/blacksmith/adapter/wanderer"
+
+	"github.com/lib/pq"
+	"github.com/segmentio/ksuid"
+)
+
+/*
+loader writes rows into the tables of the warehouse. It keeps track of the columns
+of the tables already ensured so the information schema is only queried when a
+table evolves.
+*/
+type loader struct {
+	env *Options
+
+	mutex  sync.Mutex
+	tables map[string]map[string]string
+}
+
+/*
+identifier returns the quoted identifier of a table in the schema of the warehouse.
+*/
+func (l *loader) identifier(t *table) string {
+	return pq.QuoteIdentifier(l.env.Schema) + "." + pq.QuoteIdentifier(t.Name)
+}
+
+/*
+columns returns a copy of the columns of a table known by the loader, or nil if
+the table has not been ensured yet.
+*/
+func (l *loader) columns(t *table) map[string]string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	known, exists := l.tables[t.Name]
+	if !exists {
+		return nil
+	}
+
+	columns := make(map[string]string, len(known))
+	for name, typ := range known {
+		columns[name] = typ
+	}
+
+	return columns
+}
+
+/*
+write writes rows into the warehouse within a single transaction, creating the
+schema and the tables, and adding or widening the columns if needed. The columns
+known by the loader are only updated once the transaction is committed.
+*/
+func (l *loader) write(rows ...*row) error {
+	tx, err := l.env.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	ensured := map[string]map[string]string{}
+	for _, r := range rows {
+		columns, ok := ensured[r.table.Name]
+		if !ok {
+			columns = l.columns(r.table)
+		}
+
+		columns, values, err := l.ensure(tx, r, columns)
+		if err != nil {
+			return err
+		}
+
+		ensured[r.table.Name] = columns
+		if err := l.insert(tx, r, values); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	l.mutex.Lock()
+	for name, columns := range ensured {
+		l.tables[name] = columns
+	}
+
+	l.mutex.Unlock()
+	return nil
+}
+
+/*
+prepare creates the schema and the shared tables if they do not exist. The columns
+of the tables are still described on first write.
+*/
+func (l *loader) prepare() error {
+	tx, err := l.env.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+	for _, t := range shared {
+		_, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1));`, l.env.Schema+"."+t.Name)
+		if err != nil {
+			return err
+		}
+
+		if err := l.create(tx, t); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+/*
+ensure ensures the table of a row exists and has the columns needed to write its
+properties, and returns the columns of the table alongside the values to write.
+
+The table is locked using a transaction-level advisory lock when it is created or
+evolves, and its columns are loaded again from the information schema so changes
+made by other loaders are taken into account.
+*/
+func (l *loader) ensure(tx *sql.Tx, r *row, columns map[string]string) (map[string]string, map[string]interface{}, error) {
+	var properties map[string]interface{}
+	var changes []change
+	if columns != nil {
+		properties, changes = plan(r.table, columns, r.properties, int(l.env.MaxColumns))
+		if len(changes) == 0 {
+			return columns, merge(r.values, properties), nil
+		}
+	}
+
+	_, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1));`, l.env.Schema+"."+r.table.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if columns == nil {
+		if err := l.create(tx, r.table); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	columns, err = l.describe(tx, r.table)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	properties, changes = plan(r.table, columns, r.properties, int(l.env.MaxColumns))
+	for _, c := range changes {
+		if err := l.alter(tx, r.table, c); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return columns, merge(r.values, properties), nil
+}
+
+/*
+create creates the schema and a table with its standard columns if they do not
+exist.
+*/
+func (l *loader) create(tx *sql.Tx, t *table) error {
+	_, err := tx.Exec(`CREATE SCHEMA IF NOT EXISTS ` + pq.QuoteIdentifier(l.env.Schema) + `;`)
+	if err != nil {
+		return err
+	}
+
+	definitions := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		definitions[i] = pq.QuoteIdentifier(c.Name) + " " + c.Type
+		if c.Name == "id" {
+			definitions[i] += " PRIMARY KEY"
+		}
+	}
+
+	_, err = tx.Exec(`CREATE TABLE IF NOT EXISTS ` + l.identifier(t) + ` (` + strings.Join(definitions, ", ") + `);`)
+	return err
+}
+
+/*
+describe returns the columns of a table and their types from the information
+schema. Columns of a type not handled by the warehouse are considered as text.
+*/
+func (l *loader) describe(tx *sql.Tx, t *table) (map[string]string, error) {
+	rows, err := tx.Query(`
+		SELECT column_name, data_type FROM information_schema.columns
+		WHERE table_schema = $1 AND table_name = $2;
+	`, l.env.Schema, t.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	columns := map[string]string{}
+	for rows.Next() {
+		var name, typ string
+		if err := rows.Scan(&name, &typ); err != nil {
+			return nil, err
+		}
+
+		columns[name] = TypeText
+		if known, exists := types[typ]; exists {
+			columns[name] = known
+		}
+	}
+
+	return columns, rows.Err()
+}
+
+/*
+alter applies a change on a table, and records it as a migration in the wanderer
+if enabled.
+*/
+func (l *loader) alter(tx *sql.Tx, t *table, c change) error {
+	column := pq.QuoteIdentifier(c.Column)
+	query := `ALTER TABLE ` + l.identifier(t) + ` ADD COLUMN IF NOT EXISTS ` + column + ` ` + c.To + `;`
+	if c.From != "" {
+		query = `ALTER TABLE ` + l.identifier(t) + ` ALTER COLUMN ` + column + ` TYPE ` + c.To + ` USING ` + column + `::` + c.To + `;`
+	}
+
+	if _, err := tx.Exec(query); err != nil {
+		return err
+	}
+
+	if !l.env.Migrations {
+		return nil
+	}
+
+	// The change has already been applied, so the migration is acknowledged and
+	// marked as succeeded right away. The version relies on the clock time so
+	// changes applied within the same transaction have distinct versions.
+	migration := ksuid.New().String()
+	_, err := tx.Exec(`
+		INSERT INTO blacksmith_wanderer.migrations (id, version, scope, name, created_at)
+		VALUES ($1, CLOCK_TIMESTAMP() AT TIME ZONE 'UTC', $2, $3, NOW());
+	`, migration, "destination:warehouse", c.name())
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO blacksmith_wanderer.transitions (id, state_before, state_after, error, migration_id, created_at)
+		VALUES ($1, NULL, $2, NULL, $5, CLOCK_TIMESTAMP()),
+			($3, $2, $4, NULL, $5, CLOCK_TIMESTAMP());
+	`, ksuid.New().String(), wanderer.StatusAcknowledged, ksuid.New().String(), wanderer.StatusSucceededUp, migration)
+	return err
+}
+
+/*
+insert inserts the values of a row into its table. Rows already written are
+ignored, unless the row is an upsert in which case the existing row is updated.
+*/
+func (l *loader) insert(tx *sql.Tx, r *row, values map[string]interface{}) error {
+	query, args := statement(l.identifier(r.table), values, r.upsert)
+	_, err := tx.Exec(query, args...)
+	return err
+}
+
+/*
+merge returns the standard values of a row merged with the values of its
+properties.
+*/
+func merge(values map[string]interface{}, properties map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(values)+len(properties))
+	for name, value := range properties {
+		merged[name] = value
+	}
+
+	for name, value := range values {
+		merged[name] = value
+	}
+
+	return merged
+}
+
+/*
+statement returns the SQL statement and its arguments to insert values into a
+table. Columns are sorted so the statement is the same across rows.
+*/
+func statement(identifier string, values map[string]interface{}, upsert bool) (string, []interface{}) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	columns := make([]string, len(names))
+	placeholders := make([]string, len(names))
+	updates := []string{}
+	args := make([]interface{}, len(names))
+	for i, name := range names {
+		columns[i] = pq.QuoteIdentifier(name)
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+		args[i] = values[name]
+		if name != "id" {
+			updates = append(updates, columns[i]+" = EXCLUDED."+columns[i])
+		}
+	}
+
+	query := `INSERT INTO ` + identifier + ` (` + strings.Join(columns, ", ") + `) VALUES (` + strings.Join(placeholders, ", ") + `) ON CONFLICT ("id") DO NOTHING;`
+	if upsert && len(updates) > 0 {
+		query = `INSERT INTO ` + identifier + ` (` + strings.Join(columns, ", ") + `) VALUES (` + strings.Join(placeholders, ", ") + `) ON CONFLICT ("id") DO UPDATE SET ` + strings.Join(updates, ", ") + `;`
+	}
+
+	return query, args
+}
@@ -0,0 +1,48 @@
+package warehouse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStatement(t *testing.T) {
+	tests := []struct {
+		name      string
+		row       *row
+		wantQuery string
+		wantArgs  []interface{}
+	}{
+		{
+			name: "WithInsert",
+			row: &row{
+				table:  tableAliases,
+				values: map[string]interface{}{"previous_id": "a1", "id": "m1"},
+			},
+			wantQuery: `INSERT INTO "warehouse"."aliases" ("id", "previous_id") VALUES ($1, $2) ON CONFLICT ("id") DO NOTHING;`,
+			wantArgs:  []interface{}{"m1", "a1"},
+		},
+		{
+			name: "WithUpsert",
+			row: &row{
+				table:  tableUsers,
+				values: map[string]interface{}{"id": "u1", "received_at": "now"},
+				upsert: true,
+			},
+			wantQuery: `INSERT INTO "warehouse"."users" ("id", "received_at") VALUES ($1, $2) ON CONFLICT ("id") DO UPDATE SET "received_at" = EXCLUDED."received_at";`,
+			wantArgs:  []interface{}{"u1", "now"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &loader{env: &Options{Schema: "warehouse"}}
+			query, args := statement(l.identifier(tt.row.table), tt.row.values, tt.row.upsert)
+			if query != tt.wantQuery {
+				t.Errorf("statement() query = %v, want %v", query, tt.wantQuery)
+			}
+
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("statement() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
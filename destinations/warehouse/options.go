@@ -0,0 +1,129 @@
+package warehouse
+
+import (
+	"database/sql"
+	"regexp"
+
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+)
+
+/*
+schemaName is the format of a schema name accepted by the destination. It avoids
+the need of quoting the schema in the SQL queries written by analysts.
+*/
+var schemaName = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+/*
+Options is the options the destination can take as an input to be configured.
+*/
+type Options struct {
+
+	// Realtime indicates if the pubsub adapter of the Blacksmith application shall
+	// be used to load events to the destination in realtime or not. When false, the
+	// Interval will be used.
+	Realtime bool
+
+	// Interval represents an interval or a CRON string at which a job shall be
+	// loaded to the destination. It is used as the time-lapse between retries in
+	// case of a job failure.
+	//
+	// Defaults to "@every 1h".
+	Interval string
+
+	// MaxRetries indicates the maximum number of retries per job the scheduler will
+	// attempt to execute before it succeed. When the limit is reached, the job is
+	// marked as "discarded".
+	//
+	// Defaults to 72.
+	MaxRetries uint16
+
+	// DB is the connection to the PostgreSQL database the events are written to.
+	// It is usually the same as the one used by the store.
+	//
+	// Required.
+	DB *sql.DB
+
+	// Schema is the PostgreSQL schema holding the tables of the warehouse. It is
+	// created if it does not exist.
+	//
+	// Defaults to "warehouse".
+	Schema string
+
+	// MaxColumns is the maximum number of columns a table can have, including the
+	// standard columns. Properties and traits which would create a column beyond
+	// this limit are not written to the warehouse.
+	//
+	// Defaults to 500. Maximum is 1600, the limit of PostgreSQL.
+	MaxColumns uint16
+
+	// Migrations indicates if the columns added or widened automatically shall be
+	// recorded as migrations in the wanderer, so every changes of the schema can be
+	// audited. The wanderer must use the PostgreSQL driver on the same database.
+	Migrations bool
+}
+
+/*
+validate ensures the options passed to initialize the destination are valid.
+*/
+func (env *Options) validate() error {
+	var interval string = destination.Defaults.DefaultSchedule.Interval
+	var maxRetries uint16 = destination.Defaults.DefaultSchedule.MaxRetries
+
+	fail := &errors.Error{
+		Message:     "destination/warehouse: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Destinations", "warehouse"},
+		})
+
+		return fail
+	}
+
+	if env.Interval == "" {
+		env.Interval = interval
+	}
+
+	if env.MaxRetries == 0 {
+		env.MaxRetries = maxRetries
+	}
+
+	if env.DB == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Database connection must be set",
+			Path:    []string{"Options", "Destinations", "warehouse", "DB"},
+		})
+	}
+
+	if env.Schema == "" {
+		env.Schema = "warehouse"
+	}
+
+	if !schemaName.MatchString(env.Schema) {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Schema must only contain lowercase letters, digits, and underscores",
+			Path:    []string{"Options", "Destinations", "warehouse", "Schema"},
+		})
+	}
+
+	if env.MaxColumns == 0 {
+		env.MaxColumns = 500
+	}
+
+	if env.MaxColumns > 1600 {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Maximum number of columns must not exceed 1600",
+			Path:    []string{"Options", "Destinations", "warehouse", "MaxColumns"},
+		})
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
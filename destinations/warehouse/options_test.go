@@ -0,0 +1,59 @@
+package warehouse
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestOptions_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  *Options
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: true,
+		},
+		{
+			name: "WithDB",
+			fields: &Options{
+				DB: &sql.DB{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithSchema",
+			fields: &Options{
+				DB:     &sql.DB{},
+				Schema: "segment_rest",
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithInvalidSchema",
+			fields: &Options{
+				DB:     &sql.DB{},
+				Schema: "Segment-Rest",
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithTooManyColumns",
+			fields: &Options{
+				DB:         &sql.DB{},
+				MaxColumns: 2000,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package warehouse
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+/*
+types maps the data types returned by the PostgreSQL information schema to the
+column types used by the warehouse.
+*/
+var types = map[string]string{
+	"text":                     TypeText,
+	"timestamp with time zone": TypeTimestamp,
+	"boolean":                  TypeBoolean,
+	"double precision":         TypeNumber,
+	"bigint":                   TypeInteger,
+}
+
+/*
+change is a change of the schema of a table, needed to write the properties or
+traits of an event. From is empty when the column is added.
+*/
+type change struct {
+	Table  string
+	Column string
+	From   string
+	To     string
+}
+
+/*
+name returns the name of the migration recording the change.
+*/
+func (c change) name() string {
+	if c.From == "" {
+		return "add_column_" + c.Table + "_" + c.Column
+	}
+
+	return "widen_column_" + c.Table + "_" + c.Column
+}
+
+/*
+infer returns the column type of a value. Numbers without decimals are integers,
+and strings formatted as RFC 3339 are timestamps. It returns an empty string for
+a null value, since its type can not be inferred.
+*/
+func infer(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+
+	case bool:
+		return TypeBoolean
+
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return TypeInteger
+		}
+
+		return TypeNumber
+
+	case float64:
+		return TypeNumber
+
+	case string:
+		if _, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return TypeTimestamp
+		}
+	}
+
+	return TypeText
+}
+
+/*
+convert returns a value converted to the type of the column it is written into.
+It returns nil when the value can not be converted, so it is written as NULL
+instead of failing the insert of the whole row.
+*/
+func convert(value interface{}, typ string) interface{} {
+	switch typ {
+	case TypeText:
+		switch v := value.(type) {
+		case nil, string:
+			return v
+		case json.Number:
+			return v.String()
+		case bool:
+			return strconv.FormatBool(v)
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil
+		}
+
+		return string(b)
+
+	case TypeInteger:
+		switch v := value.(type) {
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				return n
+			}
+
+		case float64:
+			if v == float64(int64(v)) {
+				return int64(v)
+			}
+
+		case string:
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return n
+			}
+		}
+
+	case TypeNumber:
+		switch v := value.(type) {
+		case json.Number:
+			if n, err := v.Float64(); err == nil {
+				return n
+			}
+
+		case float64:
+			return v
+
+		case string:
+			if n, err := strconv.ParseFloat(v, 64); err == nil {
+				return n
+			}
+		}
+
+	case TypeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v
+
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b
+			}
+		}
+
+	case TypeTimestamp:
+		switch v := value.(type) {
+		case time.Time:
+			return v
+
+		case string:
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return t
+			}
+		}
+	}
+
+	return nil
+}
+
+/*
+widen returns the type of a column able to hold both values of the current type
+and values of the incoming type. Integers are widened to numbers, and any other
+conflict, such as a string received for a number, is resolved as text.
+*/
+func widen(current string, incoming string) string {
+	switch {
+	case current == incoming:
+		return current
+
+	case (current == TypeInteger || current == TypeNumber) && (incoming == TypeInteger || incoming == TypeNumber):
+		return TypeNumber
+	}
+
+	return TypeText
+}
+
+/*
+columnName returns the name of a column, truncated to the maximum length of an
+identifier in PostgreSQL.
+*/
+func columnName(name string) string {
+	for len(name) > 63 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+
+	return name
+}
+
+/*
+plan returns the values of the properties to write into a table given its current
+columns, and the changes needed on the table to write them. Columns are added or
+widened in the map of columns passed. Properties conflicting with the standard
+columns of the table are ignored, as well as properties which would exceed the
+maximum number of columns. Values are converted to the type of their column.
+
+Properties are sorted by name, so when several properties have the same column
+name once truncated, the first one in lexical order is written.
+*/
+func plan(t *table, columns map[string]string, properties map[string]interface{}, max int) (map[string]interface{}, []change) {
+	standard := map[string]bool{}
+	for _, c := range t.Columns {
+		standard[c.Name] = true
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	values := map[string]interface{}{}
+	changes := []change{}
+	for _, property := range names {
+		name := columnName(property)
+		incoming := infer(properties[property])
+		if _, planned := values[name]; planned || standard[name] || incoming == "" {
+			continue
+		}
+
+		current, exists := columns[name]
+		switch {
+		case !exists && len(columns) >= max:
+			continue
+
+		case !exists:
+			changes = append(changes, change{Table: t.Name, Column: name, To: incoming})
+			columns[name] = incoming
+
+		case widen(current, incoming) != current:
+			changes = append(changes, change{Table: t.Name, Column: name, From: current, To: widen(current, incoming)})
+			columns[name] = widen(current, incoming)
+		}
+
+		values[name] = convert(properties[property], columns[name])
+	}
+
+	return values, changes
+}
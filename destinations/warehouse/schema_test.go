@@ -0,0 +1,287 @@
+package warehouse
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInfer(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{
+			name:  "WithNull",
+			value: nil,
+			want:  "",
+		},
+		{
+			name:  "WithBoolean",
+			value: true,
+			want:  TypeBoolean,
+		},
+		{
+			name:  "WithInteger",
+			value: json.Number("42"),
+			want:  TypeInteger,
+		},
+		{
+			name:  "WithNumber",
+			value: json.Number("42.5"),
+			want:  TypeNumber,
+		},
+		{
+			name:  "WithTimestamp",
+			value: "2026-10-18T09:00:00Z",
+			want:  TypeTimestamp,
+		},
+		{
+			name:  "WithString",
+			value: "enterprise",
+			want:  TypeText,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := infer(tt.value); got != tt.want {
+				t.Errorf("infer() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWiden(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  string
+		incoming string
+		want     string
+	}{
+		{
+			name:     "WithSameType",
+			current:  TypeBoolean,
+			incoming: TypeBoolean,
+			want:     TypeBoolean,
+		},
+		{
+			name:     "WithIntegerToNumber",
+			current:  TypeInteger,
+			incoming: TypeNumber,
+			want:     TypeNumber,
+		},
+		{
+			name:     "WithNumberAndInteger",
+			current:  TypeNumber,
+			incoming: TypeInteger,
+			want:     TypeNumber,
+		},
+		{
+			name:     "WithNumberToString",
+			current:  TypeNumber,
+			incoming: TypeText,
+			want:     TypeText,
+		},
+		{
+			name:     "WithStringAndNumber",
+			current:  TypeText,
+			incoming: TypeInteger,
+			want:     TypeText,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := widen(tt.current, tt.incoming); got != tt.want {
+				t.Errorf("widen() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name        string
+		columns     map[string]string
+		properties  map[string]interface{}
+		max         int
+		wantValues  map[string]interface{}
+		wantChanges []change
+	}{
+		{
+			name:    "WithNewColumn",
+			columns: map[string]string{"id": TypeText},
+			properties: map[string]interface{}{
+				"revenue": json.Number("42"),
+			},
+			max:        10,
+			wantValues: map[string]interface{}{"revenue": int64(42)},
+			wantChanges: []change{
+				{Table: "order_completed", Column: "revenue", To: TypeInteger},
+			},
+		},
+		{
+			name:    "WithWidenedColumn",
+			columns: map[string]string{"id": TypeText, "revenue": TypeInteger},
+			properties: map[string]interface{}{
+				"revenue": "42 EUR",
+			},
+			max:        10,
+			wantValues: map[string]interface{}{"revenue": "42 EUR"},
+			wantChanges: []change{
+				{Table: "order_completed", Column: "revenue", From: TypeInteger, To: TypeText},
+			},
+		},
+		{
+			name:    "WithExistingColumn",
+			columns: map[string]string{"id": TypeText, "revenue": TypeNumber},
+			properties: map[string]interface{}{
+				"revenue": json.Number("42"),
+			},
+			max:         10,
+			wantValues:  map[string]interface{}{"revenue": float64(42)},
+			wantChanges: []change{},
+		},
+		{
+			name:    "WithStandardColumnAndNull",
+			columns: map[string]string{"id": TypeText},
+			properties: map[string]interface{}{
+				"id":     "p1",
+				"coupon": nil,
+			},
+			max:         10,
+			wantValues:  map[string]interface{}{},
+			wantChanges: []change{},
+		},
+		{
+			name:    "WithSameColumnName",
+			columns: map[string]string{"id": TypeText},
+			properties: map[string]interface{}{
+				"plan_" + strings.Repeat("a", 60) + "_monthly": "pro",
+				"plan_" + strings.Repeat("a", 60) + "_annual":  json.Number("42"),
+			},
+			max: 10,
+			wantValues: map[string]interface{}{
+				"plan_" + strings.Repeat("a", 58): int64(42),
+			},
+			wantChanges: []change{
+				{Table: "order_completed", Column: "plan_" + strings.Repeat("a", 58), To: TypeInteger},
+			},
+		},
+		{
+			name:    "WithMaxColumns",
+			columns: map[string]string{"id": TypeText, "revenue": TypeNumber},
+			properties: map[string]interface{}{
+				"coupon":  "WELCOME",
+				"revenue": json.Number("42"),
+			},
+			max:         2,
+			wantValues:  map[string]interface{}{"revenue": float64(42)},
+			wantChanges: []change{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, changes := plan(eventTable("Order Completed"), tt.columns, tt.properties, tt.max)
+			if !reflect.DeepEqual(values, tt.wantValues) {
+				t.Errorf("plan() values = %v, want %v", values, tt.wantValues)
+			}
+
+			if !reflect.DeepEqual(changes, tt.wantChanges) {
+				t.Errorf("plan() changes = %v, want %v", changes, tt.wantChanges)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	at := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value interface{}
+		typ   string
+		want  interface{}
+	}{
+		{
+			name:  "WithNumberToText",
+			value: json.Number("42.5"),
+			typ:   TypeText,
+			want:  "42.5",
+		},
+		{
+			name:  "WithBooleanToText",
+			value: true,
+			typ:   TypeText,
+			want:  "true",
+		},
+		{
+			name:  "WithObjectToText",
+			value: map[string]interface{}{"plan": "pro"},
+			typ:   TypeText,
+			want:  `{"plan":"pro"}`,
+		},
+		{
+			name:  "WithInteger",
+			value: json.Number("42"),
+			typ:   TypeInteger,
+			want:  int64(42),
+		},
+		{
+			name:  "WithStringToInteger",
+			value: "1697616000000",
+			typ:   TypeInteger,
+			want:  int64(1697616000000),
+		},
+		{
+			name:  "WithInvalidInteger",
+			value: "abc",
+			typ:   TypeInteger,
+			want:  nil,
+		},
+		{
+			name:  "WithDecimalToInteger",
+			value: json.Number("42.5"),
+			typ:   TypeInteger,
+			want:  nil,
+		},
+		{
+			name:  "WithIntegerToNumber",
+			value: json.Number("42"),
+			typ:   TypeNumber,
+			want:  float64(42),
+		},
+		{
+			name:  "WithStringToBoolean",
+			value: "true",
+			typ:   TypeBoolean,
+			want:  true,
+		},
+		{
+			name:  "WithInvalidBoolean",
+			value: json.Number("1"),
+			typ:   TypeBoolean,
+			want:  nil,
+		},
+		{
+			name:  "WithTimestamp",
+			value: "2026-10-18T09:00:00Z",
+			typ:   TypeTimestamp,
+			want:  at,
+		},
+		{
+			name:  "WithInvalidTimestamp",
+			value: "yesterday",
+			typ:   TypeTimestamp,
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convert(tt.value, tt.typ); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("convert() = %v (%T), want %v (%T)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
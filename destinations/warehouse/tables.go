@@ -0,0 +1,201 @@
+package warehouse
+
+import (
+	"unicode/utf8"
+
+	"github.com/nunchistudio/fragment/normalize"
+)
+
+/*
+Column types used by the tables of the warehouse.
+*/
+var (
+	TypeText      = "TEXT"
+	TypeTimestamp = "TIMESTAMPTZ"
+	TypeBoolean   = "BOOLEAN"
+	TypeNumber    = "DOUBLE PRECISION"
+	TypeInteger   = "BIGINT"
+)
+
+/*
+column is a column of a table of the warehouse.
+*/
+type column struct {
+	Name string
+	Type string
+}
+
+/*
+table is a table of the warehouse with the columns it is created with.
+*/
+type table struct {
+	Name    string
+	Columns []column
+}
+
+/*
+reserved holds the names of the tables shared by every events. A track event can
+not have its own table named after one of them.
+*/
+var reserved = map[string]bool{
+	"tracks":     true,
+	"identifies": true,
+	"pages":      true,
+	"screens":    true,
+	"groups":     true,
+	"aliases":    true,
+	"users":      true,
+}
+
+/*
+messageColumns are the columns common to every tables of events, as described by
+the Segment warehouse schema.
+
+Reference: https://segment.com/docs/connections/storage/warehouses/schema/
+*/
+var messageColumns = []column{
+	{Name: "id", Type: TypeText},
+	{Name: "received_at", Type: TypeTimestamp},
+	{Name: "sent_at", Type: TypeTimestamp},
+	{Name: "timestamp", Type: TypeTimestamp},
+	{Name: "original_timestamp", Type: TypeTimestamp},
+	{Name: "uuid_ts", Type: TypeTimestamp},
+	{Name: "user_id", Type: TypeText},
+	{Name: "anonymous_id", Type: TypeText},
+}
+
+/*
+contextColumns are the columns holding the context of the events. Keys of the
+context not listed here are not written to the warehouse.
+*/
+var contextColumns = []column{
+	{Name: "context_ip", Type: TypeText},
+	{Name: "context_locale", Type: TypeText},
+	{Name: "context_timezone", Type: TypeText},
+	{Name: "context_user_agent", Type: TypeText},
+	{Name: "context_session_id", Type: TypeInteger},
+	{Name: "context_library_name", Type: TypeText},
+	{Name: "context_library_version", Type: TypeText},
+	{Name: "context_page_path", Type: TypeText},
+	{Name: "context_page_referrer", Type: TypeText},
+	{Name: "context_page_search", Type: TypeText},
+	{Name: "context_page_title", Type: TypeText},
+	{Name: "context_page_url", Type: TypeText},
+	{Name: "context_referrer_type", Type: TypeText},
+	{Name: "context_referrer_name", Type: TypeText},
+	{Name: "context_referrer_url", Type: TypeText},
+	{Name: "context_referrer_link", Type: TypeText},
+	{Name: "context_campaign_name", Type: TypeText},
+	{Name: "context_campaign_source", Type: TypeText},
+	{Name: "context_campaign_medium", Type: TypeText},
+	{Name: "context_campaign_term", Type: TypeText},
+	{Name: "context_campaign_content", Type: TypeText},
+	{Name: "context_app_name", Type: TypeText},
+	{Name: "context_app_version", Type: TypeText},
+	{Name: "context_app_build", Type: TypeText},
+	{Name: "context_app_namespace", Type: TypeText},
+	{Name: "context_device_id", Type: TypeText},
+	{Name: "context_device_manufacturer", Type: TypeText},
+	{Name: "context_device_model", Type: TypeText},
+	{Name: "context_device_name", Type: TypeText},
+	{Name: "context_device_type", Type: TypeText},
+	{Name: "context_device_version", Type: TypeText},
+	{Name: "context_os_name", Type: TypeText},
+	{Name: "context_os_version", Type: TypeText},
+	{Name: "context_network_carrier", Type: TypeText},
+	{Name: "context_network_bluetooth", Type: TypeBoolean},
+	{Name: "context_network_cellular", Type: TypeBoolean},
+	{Name: "context_network_wifi", Type: TypeBoolean},
+	{Name: "context_location_city", Type: TypeText},
+	{Name: "context_location_country", Type: TypeText},
+	{Name: "context_location_region", Type: TypeText},
+	{Name: "context_location_latitude", Type: TypeNumber},
+	{Name: "context_location_longitude", Type: TypeNumber},
+	{Name: "context_location_speed", Type: TypeNumber},
+	{Name: "context_screen_density", Type: TypeNumber},
+	{Name: "context_screen_height", Type: TypeInteger},
+	{Name: "context_screen_width", Type: TypeInteger},
+}
+
+/*
+with returns a table of events with the common columns, the context columns, and
+the columns given.
+*/
+func with(name string, columns ...column) *table {
+	t := &table{
+		Name: name,
+	}
+
+	t.Columns = append(t.Columns, messageColumns...)
+	t.Columns = append(t.Columns, columns...)
+	t.Columns = append(t.Columns, contextColumns...)
+	return t
+}
+
+/*
+Tables of the warehouse shared by every events of the same type.
+*/
+var (
+	tableTracks     = with("tracks", column{Name: "event", Type: TypeText}, column{Name: "event_text", Type: TypeText})
+	tableIdentifies = with("identifies")
+	tablePages      = with("pages", pageColumns...)
+	tableScreens    = with("screens", column{Name: "name", Type: TypeText})
+	tableGroups     = with("groups", column{Name: "group_id", Type: TypeText})
+	tableAliases    = with("aliases", column{Name: "previous_id", Type: TypeText})
+	tableUsers      = &table{
+		Name: "users",
+		Columns: []column{
+			{Name: "id", Type: TypeText},
+			{Name: "received_at", Type: TypeTimestamp},
+			{Name: "uuid_ts", Type: TypeTimestamp},
+		},
+	}
+)
+
+/*
+shared holds the tables shared by every events of the same type.
+*/
+var shared = []*table{
+	tableTracks,
+	tableIdentifies,
+	tablePages,
+	tableScreens,
+	tableGroups,
+	tableAliases,
+	tableUsers,
+}
+
+/*
+pageColumns are the columns of the table "pages" extracted from the properties
+of the page.
+*/
+var pageColumns = []column{
+	{Name: "name", Type: TypeText},
+	{Name: "category", Type: TypeText},
+	{Name: "path", Type: TypeText},
+	{Name: "referrer", Type: TypeText},
+	{Name: "search", Type: TypeText},
+	{Name: "title", Type: TypeText},
+	{Name: "url", Type: TypeText},
+}
+
+/*
+eventTable returns the table of a track event. As done by Segment, the name of
+the table is the event name in snake case, such as "order_completed" for the event
+"Order Completed". The name is prefixed with an underscore when it would conflict
+with a shared table or start with a digit, and truncated to the maximum length of
+an identifier in PostgreSQL.
+*/
+func eventTable(event string) *table {
+	name := normalize.Snake(event)
+	if name == "" || reserved[name] || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+
+	for len(name) > 63 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+
+	return with(name, column{Name: "event", Type: TypeText}, column{Name: "event_text", Type: TypeText})
+}
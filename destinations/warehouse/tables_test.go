@@ -0,0 +1,47 @@
+package warehouse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEventTable(t *testing.T) {
+	tests := []struct {
+		name  string
+		event string
+		want  string
+	}{
+		{
+			name:  "WithSpaces",
+			event: "Order Completed",
+			want:  "order_completed",
+		},
+		{
+			name:  "WithCamelCase",
+			event: "cartViewed",
+			want:  "cart_viewed",
+		},
+		{
+			name:  "WithReservedName",
+			event: "Users",
+			want:  "_users",
+		},
+		{
+			name:  "WithLeadingDigit",
+			event: "2fa enabled",
+			want:  "_2fa_enabled",
+		},
+		{
+			name:  "WithLongName",
+			event: strings.Repeat("a", 70),
+			want:  strings.Repeat("a", 63),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := eventTable(tt.event).Name; got != tt.want {
+				t.Errorf("eventTable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
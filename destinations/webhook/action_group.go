@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Group implements the Blacksmith destination.Action interface for the action
+"group". It holds the complete job's structure to send to the endpoints.
+*/
+type Group struct {
+	env    *Options
+	client *http.Client
+
+	analytics.Group
+}
+
+/*
+String returns the string representation of the action Group.
+*/
+func (a Group) String() string {
+	return "group"
+}
+
+/*
+Schedule allows the action to override the schedule options of its
+destination. Do not override.
+*/
+func (a Group) Schedule() *destination.Schedule {
+	return nil
+}
+
+/*
+Marshal is the function being run when the action receives data into
+the Group receiver. It allows to transform and enrich the data before
+saving it in the store adapter.
+*/
+func (a Group) Marshal(tk *destination.Toolkit) (*destination.Job, error) {
+
+	// Set the type of the message so endpoints can handle every types of events
+	// within a batch.
+	a.Type = "group"
+
+	// Try to marshal the data passed directly to the receiver.
+	data, err := json.Marshal(&a)
+	if err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+		}
+	}
+
+	// Create a job with the data. Since the 'Context' key is not
+	// set, the one from the event will automatically be applied.
+	j := &destination.Job{
+		Version: "v1.0",
+		Data:    data,
+		SentAt:  &a.Timestamp,
+	}
+
+	// Return the job including the marshaled data.
+	return j, nil
+}
+
+/*
+Load is the function being run by the scheduler to load the data into
+the destination. It is in charge of the "L" in the ETL process.
+*/
+func (a Group) Load(tk *destination.Toolkit, queue *store.Queue, then chan<- destination.Then) {
+	deliver(a.env, a.client, "group", queue, then)
+}
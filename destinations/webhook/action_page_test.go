@@ -0,0 +1,7 @@
+package webhook
+
+import (
+	"github.com/nunchistudio/blacksmith/destination"
+)
+
+var _ destination.Action = Page{}
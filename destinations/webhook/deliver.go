@@ -0,0 +1,304 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/nunchistudio/fragment/destinations/retry"
+
+	"github.com/lib/pq"
+)
+
+/*
+HeaderSignature is the header holding the HMAC-SHA256 signature of a request, in
+the form "sha256=<hex>". The signature is computed over the timestamp of the
+request, a dot, and the body, so a request can not be replayed with another
+timestamp.
+*/
+var HeaderSignature = "X-Fragment-Signature"
+
+/*
+HeaderTimestamp is the header holding the Unix timestamp of a request, in seconds.
+*/
+var HeaderTimestamp = "X-Fragment-Timestamp"
+
+/*
+payload is the body sent to the endpoints, following the format of the Segment
+batch API.
+*/
+type payload struct {
+	Batch  []json.RawMessage `json:"batch"`
+	SentAt time.Time         `json:"sentAt"`
+}
+
+/*
+message holds the fields of a job needed to filter it.
+*/
+type message struct {
+	Event string `json:"event"`
+}
+
+/*
+deliver goes through every events received from the queue and their related jobs,
+and sends them to the endpoints in batches. Jobs ignored by the filters of the
+options are marked as succeeded without being sent.
+*/
+func deliver(env *Options, client *http.Client, typ string, queue *store.Queue, then chan<- destination.Then) {
+	ids := []string{}
+	batch := []json.RawMessage{}
+	flush := func() {
+		if len(ids) > 0 {
+			then <- send(env, client, ids, batch)
+		}
+
+		ids = []string{}
+		batch = []json.RawMessage{}
+	}
+
+	for _, event := range queue.Events {
+		for _, job := range event.Jobs {
+			var m message
+			if err := json.Unmarshal(job.Data, &m); err != nil {
+				then <- destination.Then{
+					Jobs:         []string{job.ID},
+					ForceDiscard: true,
+					Error: &errors.Error{
+						StatusCode: 400,
+						Message:    err.Error(),
+					},
+				}
+
+				continue
+			}
+
+			if !env.accepts(typ, m.Event) {
+				then <- destination.Then{
+					Jobs:  []string{job.ID},
+					Error: nil,
+				}
+
+				continue
+			}
+
+			ids = append(ids, job.ID)
+			batch = append(batch, job.Data)
+			if len(ids) >= env.BatchSize {
+				flush()
+			}
+		}
+	}
+
+	flush()
+}
+
+/*
+send sends a batch of messages to every endpoints, and returns the result of the
+jobs to send to the scheduler. A failure is retried by the scheduler, unless every
+endpoint which failed rejected the request as invalid.
+
+The endpoints which accepted the messages of a job are recorded, so only the ones
+which failed receive them again when the job is retried.
+*/
+func send(env *Options, client *http.Client, jobs []string, batch []json.RawMessage) destination.Then {
+	done, err := delivered(env, jobs)
+	if err != nil {
+		return destination.Then{
+			Jobs: jobs,
+			Error: &errors.Error{
+				StatusCode: 500,
+				Message:    err.Error(),
+			},
+		}
+	}
+
+	var fail *errors.Error
+	discard := true
+	for _, u := range env.URLs {
+		ids, messages := pending(jobs, batch, done[u])
+		if len(ids) == 0 {
+			continue
+		}
+
+		rejected, err := post(env, client, u, messages)
+		if err != nil {
+			fail = err
+			discard = discard && rejected
+			continue
+		}
+
+		if err := record(env, u, ids); err != nil {
+			fail = &errors.Error{
+				StatusCode: 500,
+				Message:    err.Error(),
+			}
+
+			discard = false
+		}
+	}
+
+	if fail != nil {
+		return destination.Then{
+			Jobs:         jobs,
+			ForceDiscard: discard,
+			Error:        fail,
+		}
+	}
+
+	// The deliveries are not needed anymore once every endpoint accepted the jobs.
+	// The jobs succeeded even if they could not be removed.
+	forget(env, jobs)
+
+	// Finally, inform the scheduler about the success.
+	return destination.Then{
+		Jobs:  jobs,
+		Error: nil,
+	}
+}
+
+/*
+pending returns the jobs of a batch, and their messages, not yet delivered to an
+endpoint.
+*/
+func pending(jobs []string, batch []json.RawMessage, done map[string]bool) ([]string, []json.RawMessage) {
+	ids := []string{}
+	messages := []json.RawMessage{}
+	for i, id := range jobs {
+		if !done[id] {
+			ids = append(ids, id)
+			messages = append(messages, batch[i])
+		}
+	}
+
+	return ids, messages
+}
+
+/*
+post sends messages to an endpoint. It returns if the endpoint rejected the
+request as invalid, alongside the error if any.
+*/
+func post(env *Options, client *http.Client, u string, messages []json.RawMessage) (bool, *errors.Error) {
+	now := time.Now().UTC()
+	body, _ := json.Marshal(&payload{
+		Batch:  messages,
+		SentAt: now,
+	})
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req, _ := http.NewRequest("POST", u, bytes.NewReader(body))
+	for key, value := range env.Headers {
+		req.Header.Set(key, value)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if env.Secret != "" {
+		req.Header.Set(HeaderSignature, "sha256="+sign(env.Secret, timestamp, body))
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return false, &errors.Error{
+			StatusCode: 500,
+			Message:    err.Error(),
+		}
+	}
+
+	// Since a non-2xx status code doesn't cause an error, catch HTTP status
+	// code to ensure nothing bad happened.
+	buf := new(bytes.Buffer)
+	buf.ReadFrom(res.Body)
+	res.Body.Close()
+	if res.StatusCode >= 300 {
+		return retry.Discard(res.StatusCode), &errors.Error{
+			StatusCode: res.StatusCode,
+			Message:    buf.String(),
+		}
+	}
+
+	return false, nil
+}
+
+/*
+delivered returns the jobs already delivered to each endpoint, indexed by URL.
+Nothing is returned when no database is set.
+*/
+func delivered(env *Options, jobs []string) (map[string]map[string]bool, error) {
+	done := map[string]map[string]bool{}
+	if env.DB == nil {
+		return done, nil
+	}
+
+	rows, err := env.DB.Query(`
+		SELECT job_id, url FROM fragment_webhook.deliveries
+		WHERE job_id = ANY($1);
+	`, pq.Array(jobs))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var id, u string
+		if err := rows.Scan(&id, &u); err != nil {
+			return nil, err
+		}
+
+		if done[u] == nil {
+			done[u] = map[string]bool{}
+		}
+
+		done[u][id] = true
+	}
+
+	return done, rows.Err()
+}
+
+/*
+record records the jobs delivered to an endpoint.
+*/
+func record(env *Options, u string, jobs []string) error {
+	if env.DB == nil {
+		return nil
+	}
+
+	_, err := env.DB.Exec(`
+		INSERT INTO fragment_webhook.deliveries (job_id, url, delivered_at)
+		SELECT UNNEST($1::TEXT[]), $2, $3
+		ON CONFLICT (job_id, url) DO NOTHING;
+	`, pq.Array(jobs), u, time.Now().UTC())
+	return err
+}
+
+/*
+forget removes the deliveries of jobs delivered to every endpoints.
+*/
+func forget(env *Options, jobs []string) {
+	if env.DB == nil {
+		return
+	}
+
+	env.DB.Exec(`
+		DELETE FROM fragment_webhook.deliveries
+		WHERE job_id = ANY($1);
+	`, pq.Array(jobs))
+}
+
+/*
+sign returns the hex encoded HMAC-SHA256 signature of a request.
+*/
+func sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
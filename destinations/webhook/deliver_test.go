@@ -0,0 +1,211 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/adapter/store"
+	"github.com/nunchistudio/blacksmith/destination"
+)
+
+func TestDeliver(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		events       []string
+		wantRequests int
+		wantFailed   int
+		wantDiscard  bool
+	}{
+		{
+			name:         "WithSuccess",
+			status:       200,
+			events:       []string{"Order Completed", "Order Completed", "Order Completed"},
+			wantRequests: 2,
+			wantFailed:   0,
+		},
+		{
+			name:         "WithIgnoredEvents",
+			status:       200,
+			events:       []string{"Cart Viewed", "Order Completed"},
+			wantRequests: 1,
+			wantFailed:   0,
+		},
+		{
+			name:         "WithServerError",
+			status:       503,
+			events:       []string{"Order Completed"},
+			wantRequests: 1,
+			wantFailed:   1,
+			wantDiscard:  false,
+		},
+		{
+			name:         "WithClientError",
+			status:       400,
+			events:       []string{"Order Completed"},
+			wantRequests: 1,
+			wantFailed:   1,
+			wantDiscard:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mutex sync.Mutex
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				body, _ := ioutil.ReadAll(req.Body)
+
+				mutex.Lock()
+				requests++
+				mutex.Unlock()
+
+				if req.Header.Get("Authorization") != "Bearer token" {
+					t.Errorf("request is missing custom header")
+				}
+
+				want := "sha256=" + sign("secret", req.Header.Get(HeaderTimestamp), body)
+				if req.Header.Get(HeaderSignature) != want {
+					t.Errorf("request signature = %v, want %v", req.Header.Get(HeaderSignature), want)
+				}
+
+				var p payload
+				if err := json.Unmarshal(body, &p); err != nil || len(p.Batch) == 0 {
+					t.Errorf("request body is not a valid batch: %s", body)
+				}
+
+				res.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			env := &Options{
+				URLs:      []string{server.URL},
+				Events:    []string{"Order Completed"},
+				Headers:   map[string]string{"Authorization": "Bearer token"},
+				Secret:    "secret",
+				BatchSize: 2,
+			}
+			env.validate()
+
+			event := &store.Event{}
+			for i, name := range tt.events {
+				data, _ := json.Marshal(map[string]string{"type": "track", "event": name})
+				event.Jobs = append(event.Jobs, &store.Job{
+					ID:   string(rune('a' + i)),
+					Data: data,
+				})
+			}
+
+			then := make(chan destination.Then, len(tt.events))
+			action := Track{env: env, client: server.Client()}
+			action.Load(nil, &store.Queue{Events: []*store.Event{event}}, then)
+			close(then)
+
+			failed := 0
+			for result := range then {
+				if result.Error != nil {
+					failed += len(result.Jobs)
+					if result.ForceDiscard != tt.wantDiscard {
+						t.Errorf("Track.Load() discard = %v, want %v", result.ForceDiscard, tt.wantDiscard)
+					}
+				}
+			}
+
+			if requests != tt.wantRequests {
+				t.Errorf("Track.Load() requests = %v, want %v", requests, tt.wantRequests)
+			}
+
+			if failed != tt.wantFailed {
+				t.Errorf("Track.Load() failed jobs = %v, want %v", failed, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestDeliver_URLs(t *testing.T) {
+	requests := map[string]int{}
+	var mutex sync.Mutex
+	handler := func(status int) http.HandlerFunc {
+		return func(res http.ResponseWriter, req *http.Request) {
+			mutex.Lock()
+			requests[req.Host]++
+			mutex.Unlock()
+
+			res.WriteHeader(status)
+		}
+	}
+
+	ok := httptest.NewServer(handler(200))
+	defer ok.Close()
+	unavailable := httptest.NewServer(handler(503))
+	defer unavailable.Close()
+	invalid := httptest.NewServer(handler(400))
+	defer invalid.Close()
+
+	tests := []struct {
+		name        string
+		urls        []string
+		wantDiscard bool
+	}{
+		{
+			name:        "WithUnavailableURL",
+			urls:        []string{unavailable.URL, ok.URL, invalid.URL},
+			wantDiscard: false,
+		},
+		{
+			name:        "WithInvalidURL",
+			urls:        []string{invalid.URL, ok.URL},
+			wantDiscard: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = map[string]int{}
+			env := &Options{
+				URLs: tt.urls,
+			}
+
+			data, _ := json.Marshal(map[string]string{"type": "track", "event": "Order Completed"})
+			result := send(env, ok.Client(), []string{"a"}, []json.RawMessage{data})
+			if result.Error == nil || result.ForceDiscard != tt.wantDiscard {
+				t.Errorf("send() = %v, %v, want discard %v", result.Error, result.ForceDiscard, tt.wantDiscard)
+			}
+
+			// Every endpoint is requested even after a failure, so the ones accepting
+			// the jobs do not depend on the others.
+			for _, u := range tt.urls {
+				host := strings.TrimPrefix(u, "http://")
+				if requests[host] != 1 {
+					t.Errorf("send() requests to %v = %v, want %v", u, requests[host], 1)
+				}
+			}
+		})
+	}
+}
+
+func TestPending(t *testing.T) {
+	batch := []json.RawMessage{
+		json.RawMessage(`{"event":"a"}`),
+		json.RawMessage(`{"event":"b"}`),
+		json.RawMessage(`{"event":"c"}`),
+	}
+
+	ids, messages := pending([]string{"a", "b", "c"}, batch, map[string]bool{"b": true})
+	if !reflect.DeepEqual(ids, []string{"a", "c"}) {
+		t.Errorf("pending() ids = %v, want %v", ids, []string{"a", "c"})
+	}
+
+	if !reflect.DeepEqual(messages, []json.RawMessage{batch[0], batch[2]}) {
+		t.Errorf("pending() messages = %s", messages)
+	}
+
+	ids, _ = pending([]string{"a"}, batch[:1], map[string]bool{"a": true})
+	if len(ids) != 0 {
+		t.Errorf("pending() ids = %v, want none", ids)
+	}
+}
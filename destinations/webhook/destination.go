@@ -0,0 +1,103 @@
+/*
+Package webhook implements a Blacksmith destination sending the events to HTTP
+endpoints, such as internal services which have no Blacksmith module. Events are
+sent in batches following the format of the Segment batch API, and requests can
+be signed so endpoints can verify they come from Fragment.
+*/
+package webhook
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/logger"
+)
+
+/*
+Webhook implements the Blacksmith destination.Destination interface for the
+destination "webhook".
+*/
+type Webhook struct {
+	options *destination.Options
+	env     *Options
+	client  *http.Client
+}
+
+/*
+New returns a valid Blacksmith destination.Destination for the webhook.
+*/
+func New(env *Options) destination.Destination {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &Webhook{
+		options: &destination.Options{
+			DefaultSchedule: &destination.Schedule{
+				Realtime:   env.Realtime,
+				Interval:   env.Interval,
+				MaxRetries: env.MaxRetries,
+			},
+			DefaultVersion: "v1.0",
+			Versions: map[string]time.Time{
+				"v1.0": time.Time{},
+			},
+		},
+		env: env,
+		client: &http.Client{
+			Timeout: env.Timeout,
+		},
+	}
+}
+
+/*
+String returns the string representation of the destination Webhook.
+*/
+func (d *Webhook) String() string {
+	return "webhook"
+}
+
+/*
+Options returns common destination options for the webhook. They will be
+shared across every actions of this destination, except when overridden.
+*/
+func (d *Webhook) Options() *destination.Options {
+	return d.options
+}
+
+/*
+Actions return a list of actions the destination Webhook is able to handle.
+*/
+func (d *Webhook) Actions() map[string]destination.Action {
+	return map[string]destination.Action{
+		"identify": Identify{
+			env:    d.env,
+			client: d.client,
+		},
+		"track": Track{
+			env:    d.env,
+			client: d.client,
+		},
+		"group": Group{
+			env:    d.env,
+			client: d.client,
+		},
+		"alias": Alias{
+			env:    d.env,
+			client: d.client,
+		},
+		"page": Page{
+			env:    d.env,
+			client: d.client,
+		},
+		"screen": Screen{
+			env:    d.env,
+			client: d.client,
+		},
+	}
+}
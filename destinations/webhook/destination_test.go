@@ -0,0 +1,26 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/destination"
+)
+
+var _ destination.Destination = &Webhook{}
+
+func TestWebhook_Actions(t *testing.T) {
+	d := New(&Options{
+		URLs: []string{"https://example.com/events"},
+	})
+
+	if d.String() != "webhook" {
+		t.Errorf("Webhook.String() = %v, want %v", d.String(), "webhook")
+	}
+
+	actions := d.Actions()
+	for _, name := range []string{"identify", "track", "group", "alias", "page", "screen"} {
+		if _, exists := actions[name]; !exists {
+			t.Errorf("Webhook.Actions() is missing action %v", name)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package webhook
+
+import (
+	"database/sql"
+	"net/url"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+)
+
+/*
+Defaults are the defaults options set for the destination. When not set, these
+values will automatically be applied.
+*/
+var Defaults = &Options{
+	BatchSize: 100,
+	Timeout:   10 * time.Second,
+}
+
+/*
+types holds the types of events the destination is able to send.
+*/
+var types = map[string]bool{
+	"identify": true,
+	"track":    true,
+	"group":    true,
+	"alias":    true,
+	"page":     true,
+	"screen":   true,
+}
+
+/*
+Options is the options the destination can take as an input to be configured.
+*/
+type Options struct {
+
+	// Realtime indicates if the pubsub adapter of the Blacksmith application shall
+	// be used to load events to the destination in realtime or not. When false, the
+	// Interval will be used.
+	Realtime bool
+
+	// Interval represents an interval or a CRON string at which a job shall be
+	// loaded to the destination. It is used as the time-lapse between retries in
+	// case of a job failure.
+	//
+	// Defaults to "@every 1h".
+	Interval string
+
+	// MaxRetries indicates the maximum number of retries per job the scheduler will
+	// attempt to execute before it succeed. When the limit is reached, the job is
+	// marked as "discarded".
+	//
+	// Defaults to 72.
+	MaxRetries uint16
+
+	// URLs are the endpoints the events are sent to. A batch is successful only if
+	// every endpoint accepted it. When a job is retried, it is only sent again to
+	// the endpoints which failed. Endpoints should still rely on the message ID to
+	// deduplicate events, since a request can succeed after timing out.
+	//
+	// Required.
+	URLs []string
+
+	// DB is the PostgreSQL database connection where the endpoints which accepted
+	// the jobs are recorded. The tables are created by the migration
+	// "init_webhook".
+	//
+	// Required when several URLs are set.
+	DB *sql.DB
+
+	// Types are the types of events to send, such as "track" or "identify". Events
+	// of other types are ignored.
+	//
+	// Defaults to every types.
+	Types []string
+
+	// Events are the names of the track events to send. Other track events are
+	// ignored. It does not apply to the events of other types.
+	//
+	// Defaults to every track events.
+	Events []string
+
+	// Headers are custom headers added to every requests, such as an API key
+	// expected by the endpoints.
+	Headers map[string]string
+
+	// Secret is the key used to sign the body of the requests with HMAC-SHA256. The
+	// signature is sent in the header "X-Fragment-Signature". When empty, requests
+	// are not signed.
+	Secret string
+
+	// BatchSize is the maximum number of events sent within a single request.
+	//
+	// Defaults to 100.
+	BatchSize int
+
+	// Timeout is the maximum duration of a request.
+	//
+	// Defaults to 10 seconds.
+	Timeout time.Duration
+}
+
+/*
+validate ensures the options passed to initialize the destination are valid.
+*/
+func (env *Options) validate() error {
+	var interval string = destination.Defaults.DefaultSchedule.Interval
+	var maxRetries uint16 = destination.Defaults.DefaultSchedule.MaxRetries
+
+	fail := &errors.Error{
+		Message:     "destination/webhook: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Destinations", "webhook"},
+		})
+
+		return fail
+	}
+
+	if env.Interval == "" {
+		env.Interval = interval
+	}
+
+	if env.MaxRetries == 0 {
+		env.MaxRetries = maxRetries
+	}
+
+	if len(env.URLs) == 0 {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "At least one URL must be set",
+			Path:    []string{"Options", "Destinations", "webhook", "URLs"},
+		})
+	}
+
+	if len(env.URLs) > 1 && env.DB == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Database connection must be set when several URLs are set",
+			Path:    []string{"Options", "Destinations", "webhook", "DB"},
+		})
+	}
+
+	for _, u := range env.URLs {
+		parsed, err := url.Parse(u)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "URL '" + u + "' must be a valid HTTP or HTTPS URL",
+				Path:    []string{"Options", "Destinations", "webhook", "URLs"},
+			})
+		}
+	}
+
+	for _, typ := range env.Types {
+		if !types[typ] {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Type '" + typ + "' is not supported",
+				Path:    []string{"Options", "Destinations", "webhook", "Types"},
+			})
+		}
+	}
+
+	if env.BatchSize < 0 {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Batch size must not be negative",
+			Path:    []string{"Options", "Destinations", "webhook", "BatchSize"},
+		})
+	} else if env.BatchSize == 0 {
+		env.BatchSize = Defaults.BatchSize
+	}
+
+	if env.Timeout < 0 {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Timeout must not be negative",
+			Path:    []string{"Options", "Destinations", "webhook", "Timeout"},
+		})
+	} else if env.Timeout == 0 {
+		env.Timeout = Defaults.Timeout
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
+
+/*
+accepts returns if an event of the given type and name shall be sent.
+*/
+func (env *Options) accepts(typ string, event string) bool {
+	if len(env.Types) > 0 && !contains(env.Types, typ) {
+		return false
+	}
+
+	if typ == "track" && len(env.Events) > 0 && !contains(env.Events, event) {
+		return false
+	}
+
+	return true
+}
+
+/*
+contains returns if a slice of strings contains a value.
+*/
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
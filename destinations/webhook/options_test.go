@@ -0,0 +1,117 @@
+package webhook
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestOptions_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  *Options
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: true,
+		},
+		{
+			name: "WithURL",
+			fields: &Options{
+				URLs: []string{"https://example.com/events"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithInvalidURL",
+			fields: &Options{
+				URLs: []string{"example.com/events"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithURLsWithoutDB",
+			fields: &Options{
+				URLs: []string{"https://example.com/events", "https://example.org/events"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithURLsAndDB",
+			fields: &Options{
+				URLs: []string{"https://example.com/events", "https://example.org/events"},
+				DB:   &sql.DB{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithInvalidType",
+			fields: &Options{
+				URLs:  []string{"https://example.com/events"},
+				Types: []string{"track", "event"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithNegativeBatchSize",
+			fields: &Options{
+				URLs:      []string{"https://example.com/events"},
+				BatchSize: -1,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOptions_accepts(t *testing.T) {
+	env := &Options{
+		Types:  []string{"track", "identify"},
+		Events: []string{"Order Completed"},
+	}
+
+	tests := []struct {
+		name  string
+		typ   string
+		event string
+		want  bool
+	}{
+		{
+			name:  "WithAcceptedEvent",
+			typ:   "track",
+			event: "Order Completed",
+			want:  true,
+		},
+		{
+			name:  "WithIgnoredEvent",
+			typ:   "track",
+			event: "Cart Viewed",
+			want:  false,
+		},
+		{
+			name: "WithAcceptedType",
+			typ:  "identify",
+			want: true,
+		},
+		{
+			name: "WithIgnoredType",
+			typ:  "page",
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := env.accepts(tt.typ, tt.event); got != tt.want {
+				t.Errorf("Options.accepts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package fragmentflow
+
+import (
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/flow"
+
+	"github.com/nunchistudio/blacksmith-modules/segment/segmentflow"
+
+	"github.com/nunchistudio/fragment/destinations/customerio"
+	"github.com/nunchistudio/fragment/destinations/mixpanel"
+	natsdestination "github.com/nunchistudio/fragment/destinations/nats"
+	"github.com/nunchistudio/fragment/destinations/warehouse"
+	"github.com/nunchistudio/fragment/destinations/webhook"
+)
+
+/*
+Alias implements the Blacksmith flow.Flow interface for the flow
+"alias". It extends the Alias flow of the Segment module.
+*/
+type Alias struct {
+	segmentflow.Alias
+}
+
+/*
+Transform is the function being run by when executing the flow from
+triggers. It is up to the flow to transform the data from sources'
+triggers to destinations' actions.
+*/
+func (f *Alias) Transform(tk *flow.Toolkit) destination.Actions {
+	integrations := f.Alias.Transform(tk)
+
+	if enabled(f.Alias.Alias.Integrations, "Customer.io") {
+		integrations["customerio"] = append(integrations["customerio"], customerio.Alias{
+			Alias: f.Alias.Alias,
+		})
+	}
+
+	if enabled(f.Alias.Alias.Integrations, "Mixpanel") {
+		integrations["mixpanel"] = append(integrations["mixpanel"], mixpanel.Alias{
+			Alias: f.Alias.Alias,
+		})
+	}
+
+	if enabled(f.Alias.Alias.Integrations, "NATS") {
+		integrations["nats"] = append(integrations["nats"], natsdestination.Alias{
+			Alias: f.Alias.Alias,
+		})
+	}
+
+	if enabled(f.Alias.Alias.Integrations, "Warehouse") {
+		integrations["warehouse"] = append(integrations["warehouse"], warehouse.Alias{
+			Alias: f.Alias.Alias,
+		})
+	}
+
+	if enabled(f.Alias.Alias.Integrations, "Webhook") {
+		integrations["webhook"] = append(integrations["webhook"], webhook.Alias{
+			Alias: f.Alias.Alias,
+		})
+	}
+
+	return integrations
+}
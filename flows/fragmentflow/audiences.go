@@ -0,0 +1,84 @@
+package fragmentflow
+
+import (
+	"github.com/nunchistudio/blacksmith/destination"
+
+	"github.com/nunchistudio/blacksmith-modules/amplitude/amplitudedestination"
+
+	"github.com/nunchistudio/fragment/audiences"
+	"github.com/nunchistudio/fragment/destinations/mailchimp"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+enabled returns if an integration is enabled for an event. Integrations are
+enabled unless explicitly disabled, as done by the flows of the Segment module.
+*/
+func enabled(integrations analytics.Integrations, name string) bool {
+	value, exists := integrations[name]
+	return !exists || value == true
+}
+
+/*
+audienceSync adds the actions synchronizing the membership of a user in an
+audience or a list when the event is "Audience Entered" or "Audience Exited":
+
+  - the audience's name is added or removed as a tag of the member in Mailchimp,
+    if the email of the user is known;
+  - the audience's key is set as a boolean user property in Amplitude, if the user
+    ID is known.
+*/
+func audienceSync(integrations destination.Actions, track analytics.Track) {
+	if track.Event != audiences.EventEntered && track.Event != audiences.EventExited {
+		return
+	}
+
+	key, _ := track.Properties["audience_key"].(string)
+	name, _ := track.Properties["audience_name"].(string)
+	if key == "" {
+		return
+	}
+
+	if name == "" {
+		name = key
+	}
+
+	member := track.Event == audiences.EventEntered
+
+	var email string
+	if track.Context != nil {
+		email, _ = track.Context.Traits["email"].(string)
+	}
+
+	if email != "" && enabled(track.Integrations, "Mailchimp") {
+		status := mailchimp.TagInactive
+		if member {
+			status = mailchimp.TagActive
+		}
+
+		integrations["mailchimp"] = append(integrations["mailchimp"], mailchimp.Tags{
+			Email: email,
+			Tags: []mailchimp.Tag{
+				{
+					Name:   name,
+					Status: status,
+				},
+			},
+		})
+	}
+
+	if track.UserId != "" && enabled(track.Integrations, "Amplitude") {
+		integrations["amplitude"] = append(integrations["amplitude"], amplitudedestination.Identify{
+			Events: []amplitudedestination.Event{
+				{
+					UserId: track.UserId,
+					Time:   track.Timestamp.Unix(),
+					Traits: analytics.Traits{
+						key: member,
+					},
+				},
+			},
+		})
+	}
+}
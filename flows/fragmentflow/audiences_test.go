@@ -0,0 +1,99 @@
+package fragmentflow
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/destination"
+
+	"github.com/nunchistudio/blacksmith-modules/amplitude/amplitudedestination"
+
+	"github.com/nunchistudio/fragment/destinations/mailchimp"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+func TestAudienceSync(t *testing.T) {
+	tests := []struct {
+		name      string
+		track     analytics.Track
+		mailchimp string
+		amplitude interface{}
+	}{
+		{
+			name: "WithAudienceEntered",
+			track: analytics.Track{
+				Event:  "Audience Entered",
+				UserId: "u1",
+				Properties: analytics.Properties{
+					"audience_key":  "cart_abandoners",
+					"audience_name": "Cart Abandoners",
+				},
+				Context: &analytics.Context{
+					Traits: analytics.Traits{"email": "john@example.com"},
+				},
+			},
+			mailchimp: "active",
+			amplitude: true,
+		},
+		{
+			name: "WithAudienceExited",
+			track: analytics.Track{
+				Event:  "Audience Exited",
+				UserId: "u1",
+				Properties: analytics.Properties{
+					"audience_key":  "cart_abandoners",
+					"audience_name": "Cart Abandoners",
+				},
+				Context: &analytics.Context{
+					Traits: analytics.Traits{"email": "john@example.com"},
+				},
+			},
+			mailchimp: "inactive",
+			amplitude: false,
+		},
+		{
+			name: "WithoutEmailAndUserID",
+			track: analytics.Track{
+				Event:       "Audience Entered",
+				AnonymousId: "a1",
+				Properties: analytics.Properties{
+					"audience_key": "cart_abandoners",
+				},
+			},
+		},
+		{
+			name: "WithOtherEvent",
+			track: analytics.Track{
+				Event:  "Order Completed",
+				UserId: "u1",
+				Properties: analytics.Properties{
+					"audience_key": "cart_abandoners",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			integrations := destination.Actions{}
+			audienceSync(integrations, tt.track)
+
+			if tt.mailchimp == "" && len(integrations["mailchimp"]) > 0 {
+				t.Errorf("audienceSync() mailchimp actions = %v, want none", integrations["mailchimp"])
+			} else if tt.mailchimp != "" {
+				tags := integrations["mailchimp"][0].(mailchimp.Tags)
+				if tags.Tags[0].Name != "Cart Abandoners" || tags.Tags[0].Status != tt.mailchimp {
+					t.Errorf("audienceSync() mailchimp tags = %v, want status %v", tags.Tags, tt.mailchimp)
+				}
+			}
+
+			if tt.amplitude == nil && len(integrations["amplitude"]) > 0 {
+				t.Errorf("audienceSync() amplitude actions = %v, want none", integrations["amplitude"])
+			} else if tt.amplitude != nil {
+				identify := integrations["amplitude"][0].(amplitudedestination.Identify)
+				if identify.Events[0].Traits["cart_abandoners"] != tt.amplitude {
+					t.Errorf("audienceSync() amplitude user properties = %v, want %v", identify.Events[0].Traits, tt.amplitude)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Package fragmentflow extends the flows of the Segment module with the features of
+Fragment which can not be handled by the destinations' actions as is.
+*/
+package fragmentflow
+
+import (
+	"encoding/json"
+
+	"github.com/nunchistudio/blacksmith/destination"
+
+	"github.com/nunchistudio/blacksmith-modules/amplitude/amplitudedestination"
+
+	"github.com/nunchistudio/fragment/destinations/amplitude"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+groupTraits returns the group traits attached to the context of an event, if any.
+*/
+func groupTraits(ctx *analytics.Context) (interface{}, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	traits, exists := ctx.Extra["groupTraits"]
+	return traits, exists
+}
+
+/*
+withAmplitudeGroupTraits adds the group traits to the user properties of the
+events sent to Amplitude. The Amplitude actions only keep the known keys of the
+context, so the group traits would be lost otherwise.
+*/
+func withAmplitudeGroupTraits(events []amplitudedestination.Event, traits interface{}) []amplitudedestination.Event {
+	for i := range events {
+		properties := analytics.Traits{}
+		for key, value := range events[i].Traits {
+			properties[key] = value
+		}
+
+		properties["groupTraits"] = traits
+		events[i].Traits = properties
+	}
+
+	return events
+}
+
+/*
+amplitudeGroupTraits applies withAmplitudeGroupTraits to every Amplitude actions
+supporting it.
+*/
+func amplitudeGroupTraits(integrations destination.Actions, traits interface{}) {
+	for i, action := range integrations["amplitude"] {
+		switch a := action.(type) {
+		case amplitudedestination.Track:
+			a.Events = withAmplitudeGroupTraits(a.Events, traits)
+			integrations["amplitude"][i] = a
+
+		case amplitudedestination.Page:
+			a.Events = withAmplitudeGroupTraits(a.Events, traits)
+			integrations["amplitude"][i] = a
+		}
+	}
+}
+
+/*
+sessionID returns the ID of the session attached to the context of an event by
+the sessions package, if any. The context may have been decoded from JSON, so the
+ID can be a number of any kind.
+*/
+func sessionID(ctx *analytics.Context) (int64, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+
+	switch id := ctx.Extra["session_id"].(type) {
+	case int64:
+		return id, true
+	case int:
+		return int64(id), true
+	case float64:
+		return int64(id), true
+	case json.Number:
+		n, err := id.Int64()
+		return n, err == nil
+	}
+
+	return 0, false
+}
+
+/*
+withAmplitudeSession returns the events sent to Amplitude alongside the ID of
+their session.
+*/
+func withAmplitudeSession(events []amplitudedestination.Event, id int64) []amplitude.Event {
+	sessions := make([]amplitude.Event, len(events))
+	for i := range events {
+		sessions[i] = amplitude.Event{
+			Event:     events[i],
+			SessionID: id,
+		}
+	}
+
+	return sessions
+}
+
+/*
+amplitudeSession replaces the Amplitude actions of the Segment module by the ones
+of Fragment, so the ID of the session is sent as the "session_id" of the events.
+The Amplitude actions of the Segment module do not support sessions, and every
+event would be considered as its own session otherwise.
+*/
+func amplitudeSession(integrations destination.Actions, id int64) {
+	for i, action := range integrations["amplitude"] {
+		switch a := action.(type) {
+		case amplitudedestination.Track:
+			integrations["amplitude"][i] = amplitude.Track{
+				Events: withAmplitudeSession(a.Events, id),
+			}
+
+		case amplitudedestination.Page:
+			integrations["amplitude"][i] = amplitude.Page{
+				Events: withAmplitudeSession(a.Events, id),
+			}
+
+		case amplitudedestination.Screen:
+			integrations["amplitude"][i] = amplitude.Screen{
+				Events: withAmplitudeSession(a.Events, id),
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package fragmentflow
+
+import (
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/flow"
+
+	"github.com/nunchistudio/blacksmith-modules/segment/segmentflow"
+
+	"github.com/nunchistudio/fragment/destinations/mixpanel"
+	natsdestination "github.com/nunchistudio/fragment/destinations/nats"
+	"github.com/nunchistudio/fragment/destinations/warehouse"
+	"github.com/nunchistudio/fragment/destinations/webhook"
+)
+
+/*
+Group implements the Blacksmith flow.Flow interface for the flow
+"group". It extends the Group flow of the Segment module.
+*/
+type Group struct {
+	segmentflow.Group
+}
+
+/*
+Transform is the function being run by when executing the flow from
+triggers. It is up to the flow to transform the data from sources'
+triggers to destinations' actions.
+*/
+func (f *Group) Transform(tk *flow.Toolkit) destination.Actions {
+	integrations := f.Group.Transform(tk)
+
+	if enabled(f.Group.Group.Integrations, "Mixpanel") {
+		integrations["mixpanel"] = append(integrations["mixpanel"], mixpanel.Group{
+			Group: f.Group.Group,
+		})
+	}
+
+	if enabled(f.Group.Group.Integrations, "NATS") {
+		integrations["nats"] = append(integrations["nats"], natsdestination.Group{
+			Group: f.Group.Group,
+		})
+	}
+
+	if enabled(f.Group.Group.Integrations, "Warehouse") {
+		integrations["warehouse"] = append(integrations["warehouse"], warehouse.Group{
+			Group: f.Group.Group,
+		})
+	}
+
+	if enabled(f.Group.Group.Integrations, "Webhook") {
+		integrations["webhook"] = append(integrations["webhook"], webhook.Group{
+			Group: f.Group.Group,
+		})
+	}
+
+	return integrations
+}
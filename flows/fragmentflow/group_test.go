@@ -0,0 +1,7 @@
+package fragmentflow
+
+import (
+	"github.com/nunchistudio/blacksmith/flow"
+)
+
+var _ flow.Flow = &Group{}
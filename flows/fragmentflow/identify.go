@@ -0,0 +1,83 @@
+package fragmentflow
+
+import (
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/flow"
+
+	"github.com/nunchistudio/blacksmith-modules/segment/segmentflow"
+
+	"github.com/nunchistudio/fragment/destinations/customerio"
+	"github.com/nunchistudio/fragment/destinations/ga4"
+	"github.com/nunchistudio/fragment/destinations/mailchimp"
+	"github.com/nunchistudio/fragment/destinations/mixpanel"
+	natsdestination "github.com/nunchistudio/fragment/destinations/nats"
+	"github.com/nunchistudio/fragment/destinations/warehouse"
+	"github.com/nunchistudio/fragment/destinations/webhook"
+)
+
+/*
+Identify implements the Blacksmith flow.Flow interface for the flow
+"identify". It extends the Identify flow of the Segment module.
+*/
+type Identify struct {
+	segmentflow.Identify
+}
+
+/*
+Transform is the function being run by when executing the flow from
+triggers. It is up to the flow to transform the data from sources'
+triggers to destinations' actions.
+
+The traits of the user are also sent to Mailchimp as merge fields, since the
+Identify action of the Mailchimp module only handles the email and the name of
+the user. Every identify is also written to the warehouse.
+*/
+func (f *Identify) Transform(tk *flow.Toolkit) destination.Actions {
+	integrations := f.Identify.Transform(tk)
+
+	email, _ := f.Identify.Identify.Traits["email"].(string)
+	if email != "" && enabled(f.Identify.Identify.Integrations, "Mailchimp") {
+		integrations["mailchimp"] = append(integrations["mailchimp"], mailchimp.MergeFields{
+			Email:  email,
+			Traits: f.Identify.Identify.Traits,
+		})
+	}
+
+	if enabled(f.Identify.Identify.Integrations, "Google Analytics 4") {
+		integrations["ga4"] = append(integrations["ga4"], ga4.Identify{
+			Identify: f.Identify.Identify,
+		})
+	}
+
+	if enabled(f.Identify.Identify.Integrations, "Customer.io") {
+		integrations["customerio"] = append(integrations["customerio"], customerio.Identify{
+			Identify: f.Identify.Identify,
+		})
+	}
+
+	if enabled(f.Identify.Identify.Integrations, "Mixpanel") {
+		integrations["mixpanel"] = append(integrations["mixpanel"], mixpanel.Identify{
+			Identify: f.Identify.Identify,
+		})
+	}
+
+	if enabled(f.Identify.Identify.Integrations, "NATS") {
+		integrations["nats"] = append(integrations["nats"], natsdestination.Identify{
+			Identify: f.Identify.Identify,
+		})
+	}
+
+	if enabled(f.Identify.Identify.Integrations, "Warehouse") {
+		integrations["warehouse"] = append(integrations["warehouse"], warehouse.Identify{
+			Identify: f.Identify.Identify,
+		})
+	}
+
+	if enabled(f.Identify.Identify.Integrations, "Webhook") {
+		integrations["webhook"] = append(integrations["webhook"], webhook.Identify{
+			Identify: f.Identify.Identify,
+		})
+	}
+
+	return integrations
+}
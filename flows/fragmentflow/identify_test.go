@@ -0,0 +1,66 @@
+package fragmentflow
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/flow"
+
+	"github.com/nunchistudio/blacksmith-modules/segment/segmentflow"
+
+	"github.com/nunchistudio/fragment/destinations/mailchimp"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+var _ flow.Flow = &Identify{}
+
+func TestIdentify_Transform(t *testing.T) {
+	tests := []struct {
+		name      string
+		identify  analytics.Identify
+		wantMerge bool
+	}{
+		{
+			name: "WithEmail",
+			identify: analytics.Identify{
+				UserId: "u1",
+				Traits: analytics.Traits{
+					"email":              "john@example.com",
+					"initial_utm_source": "newsletter",
+				},
+			},
+			wantMerge: true,
+		},
+		{
+			name: "WithoutEmail",
+			identify: analytics.Identify{
+				UserId: "u1",
+				Traits: analytics.Traits{
+					"initial_utm_source": "newsletter",
+				},
+				Integrations: analytics.Integrations{"Mailchimp": false},
+			},
+			wantMerge: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Identify{
+				Identify: segmentflow.Identify{
+					Identify: tt.identify,
+				},
+			}
+
+			found := false
+			for _, action := range f.Transform(&flow.Toolkit{})["mailchimp"] {
+				if _, ok := action.(mailchimp.MergeFields); ok {
+					found = true
+				}
+			}
+
+			if found != tt.wantMerge {
+				t.Errorf("Identify.Transform() merge fields = %v, want %v", found, tt.wantMerge)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package fragmentflow
+
+import (
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/flow"
+
+	"github.com/nunchistudio/blacksmith-modules/segment/segmentflow"
+
+	"github.com/nunchistudio/fragment/destinations/customerio"
+	"github.com/nunchistudio/fragment/destinations/ga4"
+	"github.com/nunchistudio/fragment/destinations/mixpanel"
+	natsdestination "github.com/nunchistudio/fragment/destinations/nats"
+	"github.com/nunchistudio/fragment/destinations/warehouse"
+	"github.com/nunchistudio/fragment/destinations/webhook"
+)
+
+/*
+Page implements the Blacksmith flow.Flow interface for the flow
+"page". It extends the Page flow of the Segment module.
+*/
+type Page struct {
+	segmentflow.Page
+}
+
+/*
+Transform is the function being run by when executing the flow from
+triggers. It is up to the flow to transform the data from sources'
+triggers to destinations' actions.
+*/
+func (f *Page) Transform(tk *flow.Toolkit) destination.Actions {
+	integrations := f.Page.Transform(tk)
+
+	if traits, exists := groupTraits(f.Page.Page.Context); exists {
+		amplitudeGroupTraits(integrations, traits)
+	}
+
+	if id, exists := sessionID(f.Page.Page.Context); exists {
+		amplitudeSession(integrations, id)
+	}
+
+	if enabled(f.Page.Page.Integrations, "Google Analytics 4") {
+		integrations["ga4"] = append(integrations["ga4"], ga4.Page{
+			Page: f.Page.Page,
+		})
+	}
+
+	if enabled(f.Page.Page.Integrations, "Customer.io") {
+		integrations["customerio"] = append(integrations["customerio"], customerio.Page{
+			Page: f.Page.Page,
+		})
+	}
+
+	if enabled(f.Page.Page.Integrations, "Mixpanel") {
+		integrations["mixpanel"] = append(integrations["mixpanel"], mixpanel.Page{
+			Page: f.Page.Page,
+		})
+	}
+
+	if enabled(f.Page.Page.Integrations, "NATS") {
+		integrations["nats"] = append(integrations["nats"], natsdestination.Page{
+			Page: f.Page.Page,
+		})
+	}
+
+	if enabled(f.Page.Page.Integrations, "Warehouse") {
+		integrations["warehouse"] = append(integrations["warehouse"], warehouse.Page{
+			Page: f.Page.Page,
+		})
+	}
+
+	if enabled(f.Page.Page.Integrations, "Webhook") {
+		integrations["webhook"] = append(integrations["webhook"], webhook.Page{
+			Page: f.Page.Page,
+		})
+	}
+
+	return integrations
+}
@@ -0,0 +1,53 @@
+package fragmentflow
+
+import (
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/flow"
+
+	"github.com/nunchistudio/blacksmith-modules/segment/segmentflow"
+
+	natsdestination "github.com/nunchistudio/fragment/destinations/nats"
+	"github.com/nunchistudio/fragment/destinations/warehouse"
+	"github.com/nunchistudio/fragment/destinations/webhook"
+)
+
+/*
+Screen implements the Blacksmith flow.Flow interface for the flow
+"screen". It extends the Screen flow of the Segment module.
+*/
+type Screen struct {
+	segmentflow.Screen
+}
+
+/*
+Transform is the function being run by when executing the flow from
+triggers. It is up to the flow to transform the data from sources'
+triggers to destinations' actions.
+*/
+func (f *Screen) Transform(tk *flow.Toolkit) destination.Actions {
+	integrations := f.Screen.Transform(tk)
+
+	if id, exists := sessionID(f.Screen.Screen.Context); exists {
+		amplitudeSession(integrations, id)
+	}
+
+	if enabled(f.Screen.Screen.Integrations, "NATS") {
+		integrations["nats"] = append(integrations["nats"], natsdestination.Screen{
+			Screen: f.Screen.Screen,
+		})
+	}
+
+	if enabled(f.Screen.Screen.Integrations, "Warehouse") {
+		integrations["warehouse"] = append(integrations["warehouse"], warehouse.Screen{
+			Screen: f.Screen.Screen,
+		})
+	}
+
+	if enabled(f.Screen.Screen.Integrations, "Webhook") {
+		integrations["webhook"] = append(integrations["webhook"], webhook.Screen{
+			Screen: f.Screen.Screen,
+		})
+	}
+
+	return integrations
+}
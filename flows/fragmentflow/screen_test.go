@@ -0,0 +1,61 @@
+package fragmentflow
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/flow"
+
+	"github.com/nunchistudio/blacksmith-modules/segment/segmentflow"
+
+	"github.com/nunchistudio/fragment/destinations/warehouse"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+var _ flow.Flow = &Screen{}
+
+func TestScreen_Transform(t *testing.T) {
+	tests := []struct {
+		name          string
+		screen        analytics.Screen
+		wantWarehouse bool
+	}{
+		{
+			name: "WithWarehouseEnabled",
+			screen: analytics.Screen{
+				UserId: "u1",
+				Name:   "Home",
+			},
+			wantWarehouse: true,
+		},
+		{
+			name: "WithWarehouseDisabled",
+			screen: analytics.Screen{
+				UserId:       "u1",
+				Name:         "Home",
+				Integrations: analytics.Integrations{"Warehouse": false},
+			},
+			wantWarehouse: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Screen{
+				Screen: segmentflow.Screen{
+					Screen: tt.screen,
+				},
+			}
+
+			found := false
+			for _, action := range f.Transform(&flow.Toolkit{})["warehouse"] {
+				if _, ok := action.(warehouse.Screen); ok {
+					found = true
+				}
+			}
+
+			if found != tt.wantWarehouse {
+				t.Errorf("Screen.Transform() warehouse = %v, want %v", found, tt.wantWarehouse)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package fragmentflow
+
+import (
+	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/flow"
+
+	"github.com/nunchistudio/blacksmith-modules/segment/segmentflow"
+
+	"github.com/nunchistudio/fragment/destinations/customerio"
+	"github.com/nunchistudio/fragment/destinations/ga4"
+	"github.com/nunchistudio/fragment/destinations/mixpanel"
+	natsdestination "github.com/nunchistudio/fragment/destinations/nats"
+	"github.com/nunchistudio/fragment/destinations/slack"
+	"github.com/nunchistudio/fragment/destinations/warehouse"
+	"github.com/nunchistudio/fragment/destinations/webhook"
+)
+
+/*
+Track implements the Blacksmith flow.Flow interface for the flow
+"track". It extends the Track flow of the Segment module.
+*/
+type Track struct {
+	segmentflow.Track
+}
+
+/*
+Transform is the function being run by when executing the flow from
+triggers. It is up to the flow to transform the data from sources'
+triggers to destinations' actions.
+*/
+func (f *Track) Transform(tk *flow.Toolkit) destination.Actions {
+	integrations := f.Track.Transform(tk)
+
+	if traits, exists := groupTraits(f.Track.Track.Context); exists {
+		amplitudeGroupTraits(integrations, traits)
+	}
+
+	if id, exists := sessionID(f.Track.Track.Context); exists {
+		amplitudeSession(integrations, id)
+	}
+
+	audienceSync(integrations, f.Track.Track)
+
+	if enabled(f.Track.Track.Integrations, "Google Analytics 4") {
+		integrations["ga4"] = append(integrations["ga4"], ga4.Track{
+			Track: f.Track.Track,
+		})
+	}
+
+	if enabled(f.Track.Track.Integrations, "Customer.io") {
+		integrations["customerio"] = append(integrations["customerio"], customerio.Track{
+			Track: f.Track.Track,
+		})
+	}
+
+	if enabled(f.Track.Track.Integrations, "Mixpanel") {
+		integrations["mixpanel"] = append(integrations["mixpanel"], mixpanel.Track{
+			Track: f.Track.Track,
+		})
+	}
+
+	if enabled(f.Track.Track.Integrations, "NATS") {
+		integrations["nats"] = append(integrations["nats"], natsdestination.Track{
+			Track: f.Track.Track,
+		})
+	}
+
+	if enabled(f.Track.Track.Integrations, "Slack") {
+		integrations["slack"] = append(integrations["slack"], slack.Track{
+			Track: f.Track.Track,
+		})
+	}
+
+	if enabled(f.Track.Track.Integrations, "Warehouse") {
+		integrations["warehouse"] = append(integrations["warehouse"], warehouse.Track{
+			Track: f.Track.Track,
+		})
+	}
+
+	if enabled(f.Track.Track.Integrations, "Webhook") {
+		integrations["webhook"] = append(integrations["webhook"], webhook.Track{
+			Track: f.Track.Track,
+		})
+	}
+
+	return integrations
+}
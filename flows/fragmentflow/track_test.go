@@ -0,0 +1,145 @@
+package fragmentflow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/flow"
+
+	"github.com/nunchistudio/blacksmith-modules/amplitude/amplitudedestination"
+	"github.com/nunchistudio/blacksmith-modules/segment/segmentflow"
+
+	"github.com/nunchistudio/fragment/destinations/amplitude"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+var _ flow.Flow = &Track{}
+
+func TestTrack_Transform(t *testing.T) {
+	f := &Track{
+		Track: segmentflow.Track{
+			Track: analytics.Track{
+				Event:  "Order Completed",
+				UserId: "u1",
+				Context: &analytics.Context{
+					Extra: map[string]interface{}{
+						"groupTraits": map[string]interface{}{
+							"plan": "enterprise",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	integrations := f.Transform(&flow.Toolkit{})
+	if len(integrations["amplitude"]) != 1 {
+		t.Fatalf("Track.Transform() amplitude actions = %d, want 1", len(integrations["amplitude"]))
+	}
+
+	a := integrations["amplitude"][0].(amplitudedestination.Track)
+	if _, exists := a.Events[0].Traits["groupTraits"]; !exists {
+		t.Errorf("Track.Transform() user properties = %v, want groupTraits", a.Events[0].Traits)
+	}
+}
+
+func TestTrack_TransformSession(t *testing.T) {
+	tests := []struct {
+		name      string
+		extra     map[string]interface{}
+		want      int64
+		wantTyped bool
+	}{
+		{
+			name: "WithSession",
+			extra: map[string]interface{}{
+				"session_id": int64(1600000000000),
+			},
+			want:      1600000000000,
+			wantTyped: true,
+		},
+		{
+			name: "WithDecodedSession",
+			extra: map[string]interface{}{
+				"session_id": float64(1600000000000),
+			},
+			want:      1600000000000,
+			wantTyped: true,
+		},
+		{
+			name:      "WithoutSession",
+			extra:     map[string]interface{}{},
+			wantTyped: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Track{
+				Track: segmentflow.Track{
+					Track: analytics.Track{
+						Event:  "Order Completed",
+						UserId: "u1",
+						Context: &analytics.Context{
+							Extra: tt.extra,
+						},
+					},
+				},
+			}
+
+			integrations := f.Transform(&flow.Toolkit{})
+			if len(integrations["amplitude"]) != 1 {
+				t.Fatalf("Track.Transform() amplitude actions = %d, want 1", len(integrations["amplitude"]))
+			}
+
+			a, typed := integrations["amplitude"][0].(amplitude.Track)
+			if typed != tt.wantTyped {
+				t.Fatalf("Track.Transform() amplitude action = %T", integrations["amplitude"][0])
+			}
+
+			if typed && a.Events[0].SessionID != tt.want {
+				t.Errorf("Track.Transform() session_id = %v, want %v", a.Events[0].SessionID, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrack_TransformSessionPayload(t *testing.T) {
+	f := &Track{
+		Track: segmentflow.Track{
+			Track: analytics.Track{
+				Event:  "Order Completed",
+				UserId: "u1",
+				Context: &analytics.Context{
+					Extra: map[string]interface{}{
+						"session_id": float64(1600000000000),
+					},
+				},
+			},
+		},
+	}
+
+	integrations := f.Transform(&flow.Toolkit{})
+	a, typed := integrations["amplitude"][0].(amplitude.Track)
+	if !typed {
+		t.Fatalf("Track.Transform() amplitude action = %T", integrations["amplitude"][0])
+	}
+
+	// The job is the body sent to Amplitude, so the session ID must be part of
+	// its raw JSON as expected by the Amplitude HTTP API.
+	job, err := a.Marshal(nil)
+	if err != nil {
+		t.Fatalf("Track.Marshal() error = %v", err)
+	}
+
+	var data struct {
+		Events []map[string]interface{} `json:"events"`
+	}
+	if err := json.Unmarshal(job.Data, &data); err != nil || len(data.Events) != 1 {
+		t.Fatalf("Track.Marshal() data = %s", job.Data)
+	}
+
+	if data.Events[0]["session_id"] != float64(1600000000000) || data.Events[0]["event_type"] != "Order Completed" {
+		t.Errorf("Track.Marshal() data = %s", job.Data)
+	}
+}
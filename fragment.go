@@ -1,8 +1,10 @@
 package main
 
 import (
+	"database/sql"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/nunchistudio/blacksmith"
 	"github.com/nunchistudio/blacksmith/adapter/pubsub"
@@ -10,15 +12,41 @@ import (
 	"github.com/nunchistudio/blacksmith/adapter/supervisor"
 	"github.com/nunchistudio/blacksmith/adapter/wanderer"
 	"github.com/nunchistudio/blacksmith/destination"
+	"github.com/nunchistudio/blacksmith/helper/logger"
 	"github.com/nunchistudio/blacksmith/service"
 	"github.com/nunchistudio/blacksmith/source"
+	"github.com/nunchistudio/blacksmith/warehouse"
 
-	"github.com/nunchistudio/blacksmith-modules/amplitude/amplitudedestination"
 	"github.com/nunchistudio/blacksmith-modules/mailchimp/mailchimpdestination"
 	"github.com/nunchistudio/blacksmith-modules/segment/segmentdestination"
 
+	"github.com/nunchistudio/fragment/accounts"
+	"github.com/nunchistudio/fragment/attribution"
+	"github.com/nunchistudio/fragment/audiences"
+	"github.com/nunchistudio/fragment/computed"
+	"github.com/nunchistudio/fragment/dedupe"
+	"github.com/nunchistudio/fragment/destinations/amplitude"
+	"github.com/nunchistudio/fragment/destinations/customerio"
+	"github.com/nunchistudio/fragment/destinations/ga4"
+	"github.com/nunchistudio/fragment/destinations/mailchimp"
+	"github.com/nunchistudio/fragment/destinations/mixpanel"
+	natsdestination "github.com/nunchistudio/fragment/destinations/nats"
+	"github.com/nunchistudio/fragment/destinations/slack"
+	warehousedestination "github.com/nunchistudio/fragment/destinations/warehouse"
+	"github.com/nunchistudio/fragment/destinations/webhook"
+	"github.com/nunchistudio/fragment/identity"
+	"github.com/nunchistudio/fragment/models"
+	"github.com/nunchistudio/fragment/normalize"
+	"github.com/nunchistudio/fragment/profiles"
+	"github.com/nunchistudio/fragment/sessions"
+	grpcsource "github.com/nunchistudio/fragment/sources/grpc"
+	"github.com/nunchistudio/fragment/sources/lists"
+	natssource "github.com/nunchistudio/fragment/sources/nats"
 	"github.com/nunchistudio/fragment/sources/rest"
+	"github.com/nunchistudio/fragment/sources/reverse"
+	"github.com/nunchistudio/fragment/sources/scheduled"
 
+	_ "github.com/lib/pq"
 	"github.com/rs/cors"
 )
 
@@ -33,6 +61,147 @@ func Init() *blacksmith.Options {
 		AllowCredentials: true,
 	})
 
+	// Open a connection to the PostgreSQL database used by the store. It is shared
+	// by the packages of Fragment writing their own tables.
+	db, err := sql.Open("postgres", os.Getenv("POSTGRES_STORE_URL"))
+	if err != nil {
+		logger.Default.Fatal(err)
+	}
+
+	graph := identity.New(&identity.Options{
+		DB:          db,
+		ExternalIDs: []string{"email"},
+	})
+
+	profileStore := profiles.New(&profiles.Options{
+		DB:       db,
+		Identity: graph,
+		Secrets:  []string{os.Getenv("FRAGMENT_API_SECRET")},
+	})
+
+	accountStore := accounts.New(&accounts.Options{
+		DB:          db,
+		GroupTraits: true,
+	})
+
+	computedStore := computed.New(&computed.Options{
+		DB:       db,
+		Identity: graph,
+		Profiles: profileStore,
+		Traits: []*computed.Trait{
+			{
+				Name:   "orders_last_30_days",
+				Kind:   computed.KindCount,
+				Event:  "Order Completed",
+				Window: 30 * 24 * time.Hour,
+			},
+			{
+				Name:     "lifetime_revenue",
+				Kind:     computed.KindSum,
+				Event:    "Order Completed",
+				Property: "revenue",
+			},
+			{
+				Name:  "first_order_at",
+				Kind:  computed.KindFirst,
+				Event: "Order Completed",
+			},
+		},
+	})
+
+	audienceStore := audiences.New(&audiences.Options{
+		DB:       db,
+		Identity: graph,
+		Profiles: profileStore,
+		Audiences: []*audiences.Audience{
+			{
+				Key:  "cart_abandoners",
+				Name: "Cart Abandoners",
+				Condition: &audiences.Condition{
+					And: []*audiences.Condition{
+						{
+							Event: &audiences.EventCondition{
+								Name:   "Cart Viewed",
+								Within: 7 * 24 * time.Hour,
+							},
+						},
+						{
+							Not: &audiences.Condition{
+								Event: &audiences.EventCondition{
+									Name:   "Order Completed",
+									Within: 7 * 24 * time.Hour,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	attributionStore := attribution.New(&attribution.Options{
+		DB:       db,
+		Identity: graph,
+		Profiles: profileStore,
+	})
+
+	sessionStore := sessions.New(&sessions.Options{
+		DB:      db,
+		Timeout: 30 * time.Minute,
+		Events:  true,
+	})
+
+	// The warehouse destination is also used as a data warehouse by the models,
+	// built on top of the raw events.
+	warehouseDestination := warehousedestination.New(&warehousedestination.Options{
+		Realtime:   true,
+		DB:         db,
+		Schema:     "warehouse",
+		MaxColumns: 500,
+		Migrations: true,
+	})
+
+	modelRunner := models.New(&models.Options{
+		Warehouse: warehouseDestination.(warehouse.AsWarehouse),
+		Schema:    "warehouse",
+		Models:    "warehouse_models",
+		Lookback:  time.Hour,
+	})
+
+	// The options of the source "rest" are shared with the sources "grpc" and "nats",
+	// so events received from every sources are enriched the same way.
+	restOptions := &rest.Options{
+		ShowMeta: true,
+		ShowData: true,
+		Prefix:   "",
+		Normalize: &normalize.Options{
+			Emails:        true,
+			Phones:        true,
+			DefaultRegion: "US",
+			Names:         true,
+			PropertyCase:  normalize.CaseNone,
+		},
+		Identity:    graph,
+		Profiles:    profileStore,
+		Accounts:    accountStore,
+		Computed:    computedStore,
+		Audiences:   audienceStore,
+		Attribution: attributionStore,
+		Sessions:    sessionStore,
+		Dedupe: dedupe.New(&dedupe.Options{
+			DB:      db,
+			Refresh: 24 * time.Hour,
+		}),
+		WebSocket: &rest.WebSocketOptions{
+			WriteKeys: []string{os.Getenv("FRAGMENT_WRITE_KEY")},
+		},
+		Bulk: &rest.BulkOptions{
+			WriteKeys:  []string{os.Getenv("FRAGMENT_WRITE_KEY")},
+			ImportKeys: []string{os.Getenv("FRAGMENT_IMPORT_KEY")},
+			ChunkSize:  500,
+		},
+	}
+
 	var options = &blacksmith.Options{
 		Gateway: &service.Options{
 			Admin: &service.Admin{
@@ -47,6 +216,7 @@ func Init() *blacksmith.Options {
 					c.ServeHTTP(res, req, next.ServeHTTP)
 				})
 			},
+			Attach: rest.BulkHandler(restOptions, rest.WebSocketHandler(restOptions, profileStore.Handler())),
 		},
 		Scheduler: &service.Options{
 			Admin: &service.Admin{
@@ -70,29 +240,133 @@ func Init() *blacksmith.Options {
 		},
 
 		Sources: []source.Source{
-			rest.New(&rest.Options{
-				ShowMeta: true,
-				ShowData: true,
-				Prefix:   "",
+			rest.New(restOptions),
+			grpcsource.New(&grpcsource.Options{
+				Address:   ":9092",
+				WriteKeys: restOptions.WebSocket.WriteKeys,
+				REST:      restOptions,
+			}),
+			natssource.New(&natssource.Options{
+				REST: restOptions,
+				Subscriptions: []*natssource.Subscription{
+					{
+						Name:    "segment",
+						Subject: "segment.>",
+					},
+				},
+			}),
+			lists.New(&lists.Options{
+				ShowMeta:  true,
+				ShowData:  true,
+				Prefix:    "",
+				Audiences: audienceStore,
+				Secrets:   []string{os.Getenv("FRAGMENT_API_SECRET")},
+			}),
+			scheduled.New(&scheduled.Options{
+				Interval:  "@every 1h",
+				Computed:  computedStore,
+				Audiences: audienceStore,
+				Sessions:  sessionStore,
+				Models:    modelRunner,
+			}),
+			reverse.New(&reverse.Options{
+				Interval: "@every 1h",
+				DB:       db,
+				Queries: []*reverse.Query{
+					{
+						Name: "lifetime_value",
+						Type: reverse.TypeIdentify,
+						SQL: `
+							SELECT user_id, SUM(revenue) AS lifetime_value, COUNT(*) AS orders_count
+							FROM warehouse.order_completed
+							WHERE user_id IS NOT NULL
+							GROUP BY user_id;
+						`,
+					},
+				},
 			}),
 		},
 
 		Destinations: []destination.Destination{
-			amplitudedestination.New(&amplitudedestination.Options{
+			amplitude.New(&amplitude.Options{
 				Realtime: true,
 				APIKey:   os.Getenv("AMPLITUDE_API_KEY"),
 			}),
-			mailchimpdestination.New(&mailchimpdestination.Options{
-				Realtime:          true,
-				APIKey:            os.Getenv("MAILCHIMP_API_KEY"),
-				DatacenterID:      os.Getenv("MAILCHIMP_DATACENTER"),
-				AudienceID:        os.Getenv("MAILCHIMP_AUDIENCE"),
-				EnableDoubleOptIn: false,
+			customerio.New(&customerio.Options{
+				Realtime:  true,
+				SiteID:    os.Getenv("CUSTOMERIO_SITE_ID"),
+				APIKey:    os.Getenv("CUSTOMERIO_API_KEY"),
+				Anonymous: customerio.AnonymousSend,
+			}),
+			ga4.New(&ga4.Options{
+				Realtime:      true,
+				MeasurementID: os.Getenv("GA4_MEASUREMENT_ID"),
+				APISecret:     os.Getenv("GA4_API_SECRET"),
+			}),
+			mailchimp.New(&mailchimp.Options{
+				Options: mailchimpdestination.Options{
+					Realtime:          true,
+					APIKey:            os.Getenv("MAILCHIMP_API_KEY"),
+					DatacenterID:      os.Getenv("MAILCHIMP_DATACENTER"),
+					AudienceID:        os.Getenv("MAILCHIMP_AUDIENCE"),
+					EnableDoubleOptIn: false,
+				},
+				MergeFields: map[string]string{
+					"initial_utm_source":   "IUTMSOURCE",
+					"initial_utm_medium":   "IUTMMEDIUM",
+					"initial_utm_campaign": "IUTMCAMP",
+					"latest_utm_source":    "LUTMSOURCE",
+					"latest_utm_medium":    "LUTMMEDIUM",
+					"latest_utm_campaign":  "LUTMCAMP",
+				},
+			}),
+			mixpanel.New(&mixpanel.Options{
+				Realtime: true,
+				Token:    os.Getenv("MIXPANEL_TOKEN"),
+			}),
+			natsdestination.New(&natsdestination.Options{
+				Realtime:  true,
+				URL:       os.Getenv("NATS_SERVER_URL"),
+				Prefix:    "fragment.events",
+				JetStream: true,
 			}),
 			segmentdestination.New(&segmentdestination.Options{
 				Realtime: true,
 				WriteKey: os.Getenv("SEGMENT_WRITE_KEY"),
 			}),
+			slack.New(&slack.Options{
+				Realtime:   true,
+				WebhookURL: os.Getenv("SLACK_WEBHOOK_URL"),
+				Rules: []*slack.Rule{
+					{
+						Name:  "large_orders",
+						Event: "Order Completed",
+						Filters: []*slack.Filter{
+							{Path: "properties.revenue", Operator: slack.OperatorGreater, Value: 1000},
+						},
+						Template:  "New order of ${{ .properties.revenue }} by {{ .userId }}",
+						RateLimit: 10,
+					},
+					{
+						Name:      "deleted_accounts",
+						Event:     "Account Deleted",
+						Template:  "Account {{ .properties.account_id }} has been deleted by {{ .userId }}",
+						RateLimit: 10,
+					},
+				},
+			}),
+			warehouseDestination,
+			webhook.New(&webhook.Options{
+				Realtime: true,
+				URLs:     []string{os.Getenv("WEBHOOK_URL")},
+				DB:       db,
+				Types:    []string{"track", "identify", "group"},
+				Headers: map[string]string{
+					"Authorization": "Bearer " + os.Getenv("WEBHOOK_TOKEN"),
+				},
+				Secret:    os.Getenv("WEBHOOK_SECRET"),
+				BatchSize: 100,
+			}),
 		},
 	}
 
@@ -3,11 +3,18 @@ module github.com/nunchistudio/fragment
 go 1.16
 
 require (
+	github.com/gorilla/websocket v1.4.2
+	github.com/lib/pq v1.10.2
+	github.com/nats-io/nats.go v1.11.0
 	github.com/nunchistudio/blacksmith v0.18.0
 	github.com/nunchistudio/blacksmith-modules/amplitude v0.18.0
 	github.com/nunchistudio/blacksmith-modules/mailchimp v0.18.0
 	github.com/nunchistudio/blacksmith-modules/segment v0.18.0
 	github.com/rs/cors v1.7.0
+	github.com/segmentio/ksuid v1.0.3
+	github.com/sirupsen/logrus v1.8.1
+	google.golang.org/grpc v1.38.0
+	google.golang.org/protobuf v1.26.0
 	gopkg.in/segmentio/analytics-go.v3 v3.1.0
 )
 
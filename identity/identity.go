@@ -0,0 +1,540 @@
+/*
+Package identity offers an identity graph stored in PostgreSQL. It links the
+anonymous IDs, user IDs, and external IDs (such as emails) of the events received
+to profiles, and merges profiles when the events reveal they are the same person.
+*/
+package identity
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/helper/logger"
+
+	"github.com/lib/pq"
+	"github.com/segmentio/ksuid"
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+TypeUserID is the identifier type of user IDs.
+*/
+var TypeUserID = "user_id"
+
+/*
+TypeAnonymousID is the identifier type of anonymous IDs.
+*/
+var TypeAnonymousID = "anonymous_id"
+
+/*
+Identifier is an identifier linked to a profile, such as a user ID or an email.
+*/
+type Identifier struct {
+	Type        string     `json:"type"`
+	Value       string     `json:"id"`
+	FirstSeenAt *time.Time `json:"first_seen_at,omitempty"`
+	LastSeenAt  *time.Time `json:"last_seen_at,omitempty"`
+}
+
+/*
+key returns the string representation of the identifier, such as "user_id:42".
+*/
+func (id Identifier) key() string {
+	return id.Type + ":" + id.Value
+}
+
+/*
+Graph is the identity graph linking identifiers to profiles.
+*/
+type Graph struct {
+	env *Options
+}
+
+/*
+New returns a valid identity graph.
+*/
+func New(env *Options) *Graph {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &Graph{
+		env: env,
+	}
+}
+
+/*
+FromIdentify returns the identifiers of an Identify event, ordered by priority.
+*/
+func (g *Graph) FromIdentify(msg analytics.Identify) []Identifier {
+	ids := []Identifier{}
+	if msg.UserId != "" {
+		ids = append(ids, Identifier{Type: TypeUserID, Value: msg.UserId})
+	}
+
+	for _, kind := range g.env.ExternalIDs {
+		if value, ok := msg.Traits[kind].(string); ok && value != "" {
+			ids = append(ids, Identifier{Type: kind, Value: value})
+		}
+	}
+
+	if msg.AnonymousId != "" {
+		ids = append(ids, Identifier{Type: TypeAnonymousID, Value: msg.AnonymousId})
+	}
+
+	return ids
+}
+
+/*
+Identify updates the identity graph given an Identify event. It returns the ID of
+the user's profile.
+*/
+func (g *Graph) Identify(msg analytics.Identify) (string, error) {
+	return g.Resolve(msg.Timestamp, g.FromIdentify(msg)...)
+}
+
+/*
+Alias updates the identity graph given an Alias event. The previous ID is either
+a known user ID or considered as an anonymous ID. It returns the ID of the user's
+profile.
+*/
+func (g *Graph) Alias(msg analytics.Alias) (string, error) {
+	previous := Identifier{Type: TypeAnonymousID, Value: msg.PreviousId}
+	profileID, err := g.Lookup(Identifier{Type: TypeUserID, Value: msg.PreviousId})
+	if err != nil {
+		return "", err
+	}
+
+	if profileID != "" {
+		previous.Type = TypeUserID
+	}
+
+	return g.Resolve(msg.Timestamp, Identifier{Type: TypeUserID, Value: msg.UserId}, previous)
+}
+
+/*
+User updates the identity graph given the user ID and anonymous ID of any event,
+such as a Track event. It returns the ID of the user's profile.
+*/
+func (g *Graph) User(at time.Time, userID string, anonymousID string) (string, error) {
+	return g.Resolve(at, Identifier{
+		Type:  TypeUserID,
+		Value: userID,
+	}, Identifier{
+		Type:  TypeAnonymousID,
+		Value: anonymousID,
+	})
+}
+
+/*
+Lookup returns the ID of the profile linked to an identifier. It returns an empty
+string if the identifier is not known.
+*/
+func (g *Graph) Lookup(id Identifier) (string, error) {
+	var profileID string
+	err := g.env.DB.QueryRow(`
+		SELECT profile_id FROM fragment_identity.identifiers
+		WHERE type = $1 AND value = $2;
+	`, id.Type, id.Value).Scan(&profileID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	} else if err != nil {
+		return "", failed("Failed to lookup identifier", err)
+	}
+
+	return profileID, nil
+}
+
+/*
+Root returns the ID of the profile a profile has been merged into, or the profile
+ID itself if it has not been merged. It returns an empty string if the profile
+does not exist.
+*/
+func (g *Graph) Root(profileID string) (string, error) {
+	var root string
+	err := g.env.DB.QueryRow(`
+		WITH RECURSIVE chain (id, merged_into) AS (
+			SELECT id, merged_into FROM fragment_identity.profiles WHERE id = $1
+			UNION
+			SELECT p.id, p.merged_into FROM fragment_identity.profiles AS p
+			INNER JOIN chain AS c ON p.id = c.merged_into
+		)
+		SELECT id FROM chain WHERE merged_into IS NULL;
+	`, profileID).Scan(&root)
+	if err == sql.ErrNoRows {
+		return "", nil
+	} else if err != nil {
+		return "", failed("Failed to find profile", err)
+	}
+
+	return root, nil
+}
+
+/*
+Members returns the ID of a profile alongside the IDs of every profiles merged
+into it, directly or not.
+*/
+func (g *Graph) Members(profileID string) ([]string, error) {
+	rows, err := g.env.DB.Query(`
+		WITH RECURSIVE tree (id) AS (
+			SELECT id FROM fragment_identity.profiles WHERE id = $1
+			UNION
+			SELECT p.id FROM fragment_identity.profiles AS p
+			INNER JOIN tree AS t ON p.merged_into = t.id
+		)
+		SELECT id FROM tree;
+	`, profileID)
+	if err != nil {
+		return nil, failed("Failed to find profiles", err)
+	}
+
+	defer rows.Close()
+	members := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, failed("Failed to find profiles", err)
+		}
+
+		members = append(members, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, failed("Failed to find profiles", err)
+	}
+
+	return members, nil
+}
+
+/*
+Profiles returns the IDs of the profiles which have not been merged into another
+one, ordered by ID. It returns at most limit profiles with an ID greater than
+after, so every profiles can be walked through page by page.
+*/
+func (g *Graph) Profiles(after string, limit int) ([]string, error) {
+	rows, err := g.env.DB.Query(`
+		SELECT id FROM fragment_identity.profiles
+		WHERE merged_into IS NULL AND id > $1
+		ORDER BY id ASC
+		LIMIT $2;
+	`, after, limit)
+	if err != nil {
+		return nil, failed("Failed to find profiles", err)
+	}
+
+	defer rows.Close()
+	profileIDs := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, failed("Failed to find profiles", err)
+		}
+
+		profileIDs = append(profileIDs, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, failed("Failed to find profiles", err)
+	}
+
+	return profileIDs, nil
+}
+
+/*
+Identifiers returns the identifiers linked to a profile.
+*/
+func (g *Graph) Identifiers(profileID string) ([]*Identifier, error) {
+	rows, err := g.env.DB.Query(`
+		SELECT type, value, first_seen_at, last_seen_at
+		FROM fragment_identity.identifiers
+		WHERE profile_id = $1
+		ORDER BY first_seen_at ASC;
+	`, profileID)
+	if err != nil {
+		return nil, failed("Failed to find identifiers", err)
+	}
+
+	defer rows.Close()
+	ids := []*Identifier{}
+	for rows.Next() {
+		id := &Identifier{}
+		if err := rows.Scan(&id.Type, &id.Value, &id.FirstSeenAt, &id.LastSeenAt); err != nil {
+			return nil, failed("Failed to find identifiers", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, failed("Failed to find identifiers", err)
+	}
+
+	return ids, nil
+}
+
+/*
+Primary returns the user ID and the most recently seen anonymous ID of a profile,
+so events can be created on behalf of the user.
+*/
+func (g *Graph) Primary(profileID string) (string, string, error) {
+	ids, err := g.Identifiers(profileID)
+	if err != nil {
+		return "", "", err
+	}
+
+	var userID, anonymousID string
+	var lastSeenAt time.Time
+	for _, id := range ids {
+		switch id.Type {
+		case TypeUserID:
+			if userID == "" {
+				userID = id.Value
+			}
+
+		case TypeAnonymousID:
+			if id.LastSeenAt != nil && !id.LastSeenAt.Before(lastSeenAt) {
+				anonymousID = id.Value
+				lastSeenAt = *id.LastSeenAt
+			}
+		}
+	}
+
+	return userID, anonymousID, nil
+}
+
+/*
+Resolve links the identifiers to a profile, merging the profiles found for these
+identifiers when the limits allow it. Identifiers must be ordered by priority.
+It returns the ID of the profile linked to the identifier of highest priority.
+
+Everything is done within a single transaction. Identifiers and profiles are
+locked so concurrent events sharing identifiers are applied one after the other.
+*/
+func (g *Graph) Resolve(at time.Time, ids ...Identifier) (string, error) {
+	ids = dedupe(ids)
+	if len(ids) == 0 {
+		return "", nil
+	}
+
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+
+	// A profile found can be merged by a concurrent transaction before being
+	// locked. In this case the transaction is rolled back and tried again.
+	var err error
+	for attempt := 0; attempt < 3; attempt++ {
+		var profileID string
+		profileID, err = g.resolve(at, ids)
+		if err != errConflict {
+			return profileID, err
+		}
+	}
+
+	return "", failed("Failed to resolve profile", err)
+}
+
+/*
+errConflict is returned when a profile has been merged by a concurrent transaction.
+*/
+var errConflict = fmt.Errorf("Profile merged by a concurrent transaction")
+
+/*
+resolve applies the logic of Resolve within a transaction.
+*/
+func (g *Graph) resolve(at time.Time, ids []Identifier) (string, error) {
+	tx, err := g.env.DB.Begin()
+	if err != nil {
+		return "", failed("Failed to resolve profile", err)
+	}
+
+	defer tx.Rollback()
+
+	// Lock the identifiers in a consistent order to avoid deadlocks between
+	// transactions sharing some of them.
+	types, values := columns(sorted(ids))
+	_, err = tx.Exec(`
+		SELECT pg_advisory_xact_lock(hashtext(t || ':' || v))
+		FROM unnest($1::TEXT[], $2::TEXT[]) AS ids (t, v);
+	`, pq.Array(types), pq.Array(values))
+	if err != nil {
+		return "", failed("Failed to lock identifiers", err)
+	}
+
+	// Find the profiles already linked to the identifiers.
+	known := map[string]string{}
+	rows, err := tx.Query(`
+		SELECT i.type, i.value, i.profile_id
+		FROM fragment_identity.identifiers AS i
+		INNER JOIN unnest($1::TEXT[], $2::TEXT[]) AS ids (t, v)
+			ON i.type = ids.t AND i.value = ids.v;
+	`, pq.Array(types), pq.Array(values))
+	if err != nil {
+		return "", failed("Failed to find identifiers", err)
+	}
+
+	profileIDs := []string{}
+	for rows.Next() {
+		var id Identifier
+		var profileID string
+		if err := rows.Scan(&id.Type, &id.Value, &profileID); err != nil {
+			rows.Close()
+			return "", failed("Failed to find identifiers", err)
+		}
+
+		known[id.key()] = profileID
+		profileIDs = append(profileIDs, profileID)
+	}
+
+	rows.Close()
+
+	// Lock the profiles found and count their identifiers by type, so we know if
+	// identifiers can be linked and if profiles can be merged.
+	counts := map[string]map[string]uint16{}
+	if len(profileIDs) > 0 {
+		var merged int
+		err = tx.QueryRow(`
+			WITH locked AS (
+				SELECT id, merged_into FROM fragment_identity.profiles
+				WHERE id = ANY($1)
+				ORDER BY id
+				FOR UPDATE
+			)
+			SELECT COUNT(*) FROM locked WHERE merged_into IS NOT NULL;
+		`, pq.Array(profileIDs)).Scan(&merged)
+		if err != nil {
+			return "", failed("Failed to lock profiles", err)
+		} else if merged > 0 {
+			return "", errConflict
+		}
+
+		rows, err = tx.Query(`
+			SELECT profile_id, type, COUNT(*)
+			FROM fragment_identity.identifiers
+			WHERE profile_id = ANY($1)
+			GROUP BY profile_id, type;
+		`, pq.Array(profileIDs))
+		if err != nil {
+			return "", failed("Failed to count identifiers", err)
+		}
+
+		for rows.Next() {
+			var profileID, kind string
+			var count uint16
+			if err := rows.Scan(&profileID, &kind, &count); err != nil {
+				rows.Close()
+				return "", failed("Failed to count identifiers", err)
+			}
+
+			if counts[profileID] == nil {
+				counts[profileID] = map[string]uint16{}
+			}
+
+			counts[profileID][kind] = count
+		}
+
+		rows.Close()
+	}
+
+	// Plan what needs to be done, and apply it.
+	p := newPlan(ids, known, counts, g.env.Limits)
+	if len(p.create) > 0 {
+		p.created = ksuid.New().String()
+		_, err = tx.Exec(`
+			INSERT INTO fragment_identity.profiles (id, created_at, updated_at)
+			VALUES ($1, $2, $2);
+		`, p.created, at)
+		if err != nil {
+			return "", failed("Failed to create profile", err)
+		}
+
+		p.link(p.created, p.create...)
+	}
+
+	// Keep track of every merges, including the ones rejected. A rejected merge
+	// is only logged once for a pair of profiles.
+	for _, m := range p.merges {
+		if m.accepted {
+			_, err = tx.Exec(`
+				UPDATE fragment_identity.identifiers SET profile_id = $2
+				WHERE profile_id = $1;
+			`, m.from, m.into)
+			if err != nil {
+				return "", failed("Failed to merge profiles", err)
+			}
+
+			_, err = tx.Exec(`
+				UPDATE fragment_identity.profiles SET merged_into = $2, updated_at = $3
+				WHERE id = $1;
+			`, m.from, m.into, at)
+			if err != nil {
+				return "", failed("Failed to merge profiles", err)
+			}
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO fragment_identity.merges (id, from_profile_id, into_profile_id, is_accepted, reason, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (from_profile_id, into_profile_id) DO UPDATE
+			SET is_accepted = EXCLUDED.is_accepted, reason = EXCLUDED.reason, created_at = EXCLUDED.created_at
+			WHERE merges.is_accepted = FALSE AND EXCLUDED.is_accepted = TRUE;
+		`, ksuid.New().String(), m.from, m.into, m.accepted, m.reason, at)
+		if err != nil {
+			return "", failed("Failed to log merge", err)
+		}
+	}
+
+	for profileID, linked := range p.links {
+		types, values := columns(linked)
+		_, err = tx.Exec(`
+			INSERT INTO fragment_identity.identifiers (type, value, profile_id, first_seen_at, last_seen_at)
+			SELECT t, v, $3, $4, $4 FROM unnest($1::TEXT[], $2::TEXT[]) AS ids (t, v);
+		`, pq.Array(types), pq.Array(values), profileID, at)
+		if err != nil {
+			return "", failed("Failed to link identifiers", err)
+		}
+
+		_, err = tx.Exec(`
+			UPDATE fragment_identity.profiles SET updated_at = $2
+			WHERE id = $1 AND updated_at < $2;
+		`, profileID, at)
+		if err != nil {
+			return "", failed("Failed to link identifiers", err)
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE fragment_identity.identifiers AS i SET last_seen_at = $3
+		FROM unnest($1::TEXT[], $2::TEXT[]) AS ids (t, v)
+		WHERE i.type = ids.t AND i.value = ids.v AND i.last_seen_at < $3;
+	`, pq.Array(types), pq.Array(values), at)
+	if err != nil {
+		return "", failed("Failed to update identifiers", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", failed("Failed to resolve profile", err)
+	}
+
+	return p.profile(), nil
+}
+
+/*
+failed returns a normalized error for the identity graph.
+*/
+func failed(message string, err error) error {
+	return &errors.Error{
+		Message: "identity: " + message,
+		Validations: []errors.Validation{
+			{
+				Message: err.Error(),
+			},
+		},
+	}
+}
@@ -0,0 +1,35 @@
+package identity
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+func TestGraph_FromIdentify(t *testing.T) {
+	g := &Graph{
+		env: &Options{
+			ExternalIDs: []string{"email", "phone"},
+		},
+	}
+
+	msg := analytics.Identify{
+		UserId:      "u1",
+		AnonymousId: "a1",
+		Traits: analytics.Traits{
+			"email": "johndoe@example.com",
+			"phone": 42,
+		},
+	}
+
+	want := []Identifier{
+		{Type: TypeUserID, Value: "u1"},
+		{Type: "email", Value: "johndoe@example.com"},
+		{Type: TypeAnonymousID, Value: "a1"},
+	}
+
+	if got := g.FromIdentify(msg); !reflect.DeepEqual(got, want) {
+		t.Errorf("Graph.FromIdentify() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,126 @@
+package identity
+
+import (
+	"database/sql"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+)
+
+/*
+Defaults are the defaults options set for the identity graph. When not set, these
+values will automatically be applied.
+
+A profile can only hold a single user ID so two known users are never merged. The
+limit of anonymous IDs prevents a shared device from collapsing the profiles of
+every users using it.
+*/
+var Defaults = &Options{
+	Limits: map[string]uint16{
+		TypeUserID:      1,
+		TypeAnonymousID: 100,
+	},
+	ExternalIDLimit: 5,
+}
+
+/*
+Options is the options the identity graph can take as an input to be configured.
+*/
+type Options struct {
+
+	// DB is the PostgreSQL database connection where the identity graph is stored.
+	// The tables are created by the migration "init_identity".
+	//
+	// Required.
+	DB *sql.DB
+
+	// ExternalIDs is the list of traits used as external identifiers, such as
+	// "email". They are ordered by priority: when two profiles are found for the
+	// same event, the one found with the identifier of highest priority is kept.
+	// User IDs always have the highest priority, and anonymous IDs the lowest.
+	//
+	// Example: []string{"email"}
+	ExternalIDs []string
+
+	// Limits is the maximum number of identifiers of a given type a profile can
+	// hold. When linking an identifier or merging two profiles would exceed one of
+	// the limits, the identifiers are not linked and the profiles are not merged.
+	//
+	// Defaults to 1 for "user_id" and 100 for "anonymous_id".
+	Limits map[string]uint16
+
+	// ExternalIDLimit is the limit applied to every external identifiers not
+	// present in Limits.
+	//
+	// Defaults to 5.
+	ExternalIDLimit uint16
+}
+
+/*
+validate ensures the options passed to initialize the identity graph are valid.
+*/
+func (env *Options) validate() error {
+	fail := &errors.Error{
+		Message:     "identity: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Identity"},
+		})
+
+		return fail
+	}
+
+	if env.DB == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Database connection must be set",
+			Path:    []string{"Options", "Identity", "DB"},
+		})
+	}
+
+	if env.Limits == nil {
+		env.Limits = map[string]uint16{}
+	}
+
+	for kind, limit := range Defaults.Limits {
+		if _, exists := env.Limits[kind]; !exists {
+			env.Limits[kind] = limit
+		}
+	}
+
+	if env.ExternalIDLimit == 0 {
+		env.ExternalIDLimit = Defaults.ExternalIDLimit
+	}
+
+	for _, kind := range env.ExternalIDs {
+		if kind == TypeUserID || kind == TypeAnonymousID || kind == "" {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "External ID must not be empty or be one of 'user_id' or 'anonymous_id'",
+				Path:    []string{"Options", "Identity", "ExternalIDs"},
+			})
+
+			continue
+		}
+
+		if _, exists := env.Limits[kind]; !exists {
+			env.Limits[kind] = env.ExternalIDLimit
+		}
+	}
+
+	for kind, limit := range env.Limits {
+		if limit == 0 {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Limit must be greater than 0",
+				Path:    []string{"Options", "Identity", "Limits", kind},
+			})
+		}
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package identity
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestOptions_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  *Options
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: true,
+		},
+		{
+			name: "WithDB",
+			fields: &Options{
+				DB: &sql.DB{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithReservedExternalID",
+			fields: &Options{
+				DB:          &sql.DB{},
+				ExternalIDs: []string{"user_id"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithZeroLimit",
+			fields: &Options{
+				DB: &sql.DB{},
+				Limits: map[string]uint16{
+					"email": 0,
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOptions_validateDefaults(t *testing.T) {
+	env := &Options{
+		DB:          &sql.DB{},
+		ExternalIDs: []string{"email"},
+	}
+
+	if err := env.validate(); err != nil {
+		t.Fatalf("Options.validate() error = %v", err)
+	}
+
+	want := map[string]uint16{
+		TypeUserID:      1,
+		TypeAnonymousID: 100,
+		"email":         5,
+	}
+
+	for kind, limit := range want {
+		if env.Limits[kind] != limit {
+			t.Errorf("Options.validate() limit for %s = %d, want %d", kind, env.Limits[kind], limit)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package identity
+
+import (
+	"fmt"
+	"sort"
+)
+
+/*
+merge is a merge of a profile into another one, accepted or not.
+*/
+type merge struct {
+	from     string
+	into     string
+	accepted bool
+	reason   string
+}
+
+/*
+plan holds what needs to be done in the identity graph for a set of identifiers
+received in an event. It is built without any access to the database so the merge
+rules can be applied and tested on their own.
+*/
+type plan struct {
+	merges  []merge
+	links   map[string][]Identifier
+	create  []Identifier
+	created string
+	results map[string]string
+	first   string
+}
+
+/*
+newPlan returns the plan for a set of identifiers ordered by priority, given the
+profiles already linked to some of them, the number of identifiers by type of
+these profiles, and the limits to respect.
+
+The profile of the known identifier of highest priority is the target. Other
+profiles found are merged into the target, unless it would exceed one of the
+limits. Unknown identifiers are linked to the target, unless it would exceed one
+of the limits: in this case they are linked to a new profile.
+*/
+func newPlan(ids []Identifier, known map[string]string, counts map[string]map[string]uint16, limits map[string]uint16) *plan {
+	p := &plan{
+		merges:  []merge{},
+		links:   map[string][]Identifier{},
+		create:  []Identifier{},
+		results: map[string]string{},
+	}
+
+	if len(ids) == 0 {
+		return p
+	}
+
+	p.first = ids[0].key()
+
+	var target string
+	for _, id := range ids {
+		if profileID, exists := known[id.key()]; exists {
+			target = profileID
+			break
+		}
+	}
+
+	// No profile is known for these identifiers: they are all linked to a new
+	// profile.
+	if target == "" {
+		for _, id := range ids {
+			p.create = append(p.create, id)
+			p.results[id.key()] = ""
+		}
+
+		return p
+	}
+
+	total := map[string]uint16{}
+	for kind, count := range counts[target] {
+		total[kind] = count
+	}
+
+	// Merge the other profiles found into the target if the limits allow it.
+	// Each profile is only considered once.
+	moved := map[string]string{
+		target: target,
+	}
+
+	for _, id := range ids {
+		profileID, exists := known[id.key()]
+		if !exists {
+			continue
+		}
+
+		if into, done := moved[profileID]; done {
+			p.results[id.key()] = into
+			continue
+		}
+
+		m := merge{
+			from: profileID,
+			into: target,
+		}
+
+		m.reason = exceeds(total, counts[profileID], limits)
+		if m.reason == "" {
+			m.accepted = true
+			for kind, count := range counts[profileID] {
+				total[kind] += count
+			}
+
+			moved[profileID] = target
+		} else {
+			moved[profileID] = profileID
+		}
+
+		p.results[id.key()] = moved[profileID]
+		p.merges = append(p.merges, m)
+	}
+
+	// Link the unknown identifiers to the target if the limits allow it, or to
+	// a new profile otherwise.
+	for _, id := range ids {
+		if _, exists := known[id.key()]; exists {
+			continue
+		}
+
+		if limit, exists := limits[id.Type]; exists && total[id.Type]+1 > limit {
+			p.create = append(p.create, id)
+			p.results[id.key()] = ""
+			continue
+		}
+
+		total[id.Type]++
+		p.link(target, id)
+		p.results[id.key()] = target
+	}
+
+	return p
+}
+
+/*
+link adds identifiers to link to a profile.
+*/
+func (p *plan) link(profileID string, ids ...Identifier) {
+	p.links[profileID] = append(p.links[profileID], ids...)
+}
+
+/*
+profile returns the ID of the profile linked to the identifier of highest priority
+once the plan has been applied.
+*/
+func (p *plan) profile() string {
+	profileID := p.results[p.first]
+	if profileID == "" {
+		return p.created
+	}
+
+	return profileID
+}
+
+/*
+exceeds returns the reason why two profiles can not be merged given the number of
+their identifiers by type. It returns an empty string if they can be merged.
+*/
+func exceeds(target map[string]uint16, other map[string]uint16, limits map[string]uint16) string {
+	kinds := []string{}
+	for kind := range other {
+		kinds = append(kinds, kind)
+	}
+
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		limit, exists := limits[kind]
+		if exists && target[kind]+other[kind] > limit {
+			return fmt.Sprintf("Limit of %d identifiers of type '%s' exceeded", limit, kind)
+		}
+	}
+
+	return ""
+}
+
+/*
+dedupe removes the identifiers with an empty value and the duplicates, keeping
+the order of the identifiers.
+*/
+func dedupe(ids []Identifier) []Identifier {
+	seen := map[string]bool{}
+	deduped := []Identifier{}
+	for _, id := range ids {
+		if id.Value == "" || seen[id.key()] {
+			continue
+		}
+
+		seen[id.key()] = true
+		deduped = append(deduped, id)
+	}
+
+	return deduped
+}
+
+/*
+sorted returns a copy of the identifiers sorted by type and value.
+*/
+func sorted(ids []Identifier) []Identifier {
+	s := append([]Identifier{}, ids...)
+	sort.Slice(s, func(i, j int) bool {
+		return s[i].key() < s[j].key()
+	})
+
+	return s
+}
+
+/*
+columns returns the types and values of identifiers as two slices, so they can
+be passed as arrays to PostgreSQL.
+*/
+func columns(ids []Identifier) ([]string, []string) {
+	types := make([]string, len(ids))
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		types[i] = id.Type
+		values[i] = id.Value
+	}
+
+	return types, values
+}
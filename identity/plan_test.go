@@ -0,0 +1,160 @@
+package identity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewPlan(t *testing.T) {
+	limits := map[string]uint16{
+		TypeUserID:      1,
+		TypeAnonymousID: 100,
+	}
+
+	user := func(value string) Identifier {
+		return Identifier{Type: TypeUserID, Value: value}
+	}
+
+	anonymous := func(value string) Identifier {
+		return Identifier{Type: TypeAnonymousID, Value: value}
+	}
+
+	type want struct {
+		merges  []merge
+		links   map[string][]Identifier
+		create  []Identifier
+		profile string
+	}
+
+	tests := []struct {
+		name   string
+		ids    []Identifier
+		known  map[string]string
+		counts map[string]map[string]uint16
+		want   want
+	}{
+		{
+			name:   "WithUnknownIdentifiers",
+			ids:    []Identifier{user("u1"), anonymous("a1")},
+			known:  map[string]string{},
+			counts: map[string]map[string]uint16{},
+			want: want{
+				merges:  []merge{},
+				links:   map[string][]Identifier{},
+				create:  []Identifier{user("u1"), anonymous("a1")},
+				profile: "new",
+			},
+		},
+		{
+			name: "WithKnownAnonymousProfile",
+			ids:  []Identifier{user("u1"), anonymous("a1")},
+			known: map[string]string{
+				"anonymous_id:a1": "p1",
+			},
+			counts: map[string]map[string]uint16{
+				"p1": {TypeAnonymousID: 1},
+			},
+			want: want{
+				merges: []merge{},
+				links: map[string][]Identifier{
+					"p1": {user("u1")},
+				},
+				create:  []Identifier{},
+				profile: "p1",
+			},
+		},
+		{
+			name: "WithAnonymousProfileToMerge",
+			ids:  []Identifier{user("u1"), anonymous("a1")},
+			known: map[string]string{
+				"user_id:u1":      "p1",
+				"anonymous_id:a1": "p2",
+			},
+			counts: map[string]map[string]uint16{
+				"p1": {TypeUserID: 1, TypeAnonymousID: 3},
+				"p2": {TypeAnonymousID: 1},
+			},
+			want: want{
+				merges: []merge{
+					{from: "p2", into: "p1", accepted: true},
+				},
+				links:   map[string][]Identifier{},
+				create:  []Identifier{},
+				profile: "p1",
+			},
+		},
+		{
+			name: "WithSharedDevice",
+			ids:  []Identifier{user("u2"), anonymous("a1")},
+			known: map[string]string{
+				"anonymous_id:a1": "p1",
+			},
+			counts: map[string]map[string]uint16{
+				"p1": {TypeUserID: 1, TypeAnonymousID: 1},
+			},
+			want: want{
+				merges:  []merge{},
+				links:   map[string][]Identifier{},
+				create:  []Identifier{user("u2")},
+				profile: "new",
+			},
+		},
+		{
+			name: "WithTwoKnownUsers",
+			ids:  []Identifier{user("u1"), anonymous("a1")},
+			known: map[string]string{
+				"user_id:u1":      "p1",
+				"anonymous_id:a1": "p2",
+			},
+			counts: map[string]map[string]uint16{
+				"p1": {TypeUserID: 1},
+				"p2": {TypeUserID: 1, TypeAnonymousID: 1},
+			},
+			want: want{
+				merges: []merge{
+					{from: "p2", into: "p1", reason: "Limit of 1 identifiers of type 'user_id' exceeded"},
+				},
+				links:   map[string][]Identifier{},
+				create:  []Identifier{},
+				profile: "p1",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPlan(tt.ids, tt.known, tt.counts, limits)
+			p.created = "new"
+
+			if !reflect.DeepEqual(p.merges, tt.want.merges) {
+				t.Errorf("newPlan() merges = %v, want %v", p.merges, tt.want.merges)
+			}
+			if !reflect.DeepEqual(p.links, tt.want.links) {
+				t.Errorf("newPlan() links = %v, want %v", p.links, tt.want.links)
+			}
+			if !reflect.DeepEqual(p.create, tt.want.create) {
+				t.Errorf("newPlan() create = %v, want %v", p.create, tt.want.create)
+			}
+			if got := p.profile(); got != tt.want.profile {
+				t.Errorf("plan.profile() = %v, want %v", got, tt.want.profile)
+			}
+		})
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	ids := []Identifier{
+		{Type: TypeUserID, Value: "u1"},
+		{Type: TypeAnonymousID, Value: ""},
+		{Type: TypeUserID, Value: "u1"},
+		{Type: TypeAnonymousID, Value: "a1"},
+	}
+
+	want := []Identifier{
+		{Type: TypeUserID, Value: "u1"},
+		{Type: TypeAnonymousID, Value: "a1"},
+	}
+
+	if got := dedupe(ids); !reflect.DeepEqual(got, want) {
+		t.Errorf("dedupe() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS fragment_identity.merges CASCADE;
+DROP TABLE IF EXISTS fragment_identity.identifiers CASCADE;
+DROP TABLE IF EXISTS fragment_identity.profiles CASCADE;
+
+DROP SCHEMA IF EXISTS fragment_identity;
@@ -0,0 +1,40 @@
+CREATE SCHEMA IF NOT EXISTS fragment_identity;
+
+CREATE TABLE IF NOT EXISTS fragment_identity.profiles (
+  id VARCHAR(27) PRIMARY KEY,
+  merged_into VARCHAR(27) REFERENCES fragment_identity.profiles (id)
+    ON UPDATE CASCADE ON DELETE SET NULL
+    DEFERRABLE INITIALLY DEFERRED,
+  created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS fragment_identity.identifiers (
+  type TEXT NOT NULL,
+  value TEXT NOT NULL,
+  profile_id VARCHAR(27) NOT NULL REFERENCES fragment_identity.profiles (id)
+    ON UPDATE CASCADE ON DELETE CASCADE
+    DEFERRABLE INITIALLY DEFERRED,
+  first_seen_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+  last_seen_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (type, value)
+);
+
+CREATE TABLE IF NOT EXISTS fragment_identity.merges (
+  id VARCHAR(27) PRIMARY KEY,
+  from_profile_id VARCHAR(27) NOT NULL REFERENCES fragment_identity.profiles (id)
+    ON UPDATE CASCADE ON DELETE CASCADE
+    DEFERRABLE INITIALLY DEFERRED,
+  into_profile_id VARCHAR(27) NOT NULL REFERENCES fragment_identity.profiles (id)
+    ON UPDATE CASCADE ON DELETE CASCADE
+    DEFERRABLE INITIALLY DEFERRED,
+  is_accepted BOOL NOT NULL,
+  reason TEXT,
+  created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX identifiers_profile_id
+  ON fragment_identity.identifiers (profile_id);
+
+CREATE UNIQUE INDEX merges_profiles
+  ON fragment_identity.merges (from_profile_id, into_profile_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS blacksmith_store.events_message_anonymous_id;
+DROP INDEX IF EXISTS blacksmith_store.events_message_user_id;
+
+DROP TABLE IF EXISTS fragment_profiles.traits CASCADE;
+
+DROP SCHEMA IF EXISTS fragment_profiles;
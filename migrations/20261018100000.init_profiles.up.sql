@@ -0,0 +1,17 @@
+CREATE SCHEMA IF NOT EXISTS fragment_profiles;
+
+CREATE TABLE IF NOT EXISTS fragment_profiles.traits (
+  profile_id VARCHAR(27) NOT NULL REFERENCES fragment_identity.profiles (id)
+    ON UPDATE CASCADE ON DELETE CASCADE
+    DEFERRABLE INITIALLY DEFERRED,
+  key TEXT NOT NULL,
+  value JSONB,
+  updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (profile_id, key)
+);
+
+CREATE INDEX events_message_user_id
+  ON blacksmith_store.events ((context -> 'message' ->> 'userId'));
+
+CREATE INDEX events_message_anonymous_id
+  ON blacksmith_store.events ((context -> 'message' ->> 'anonymousId'));
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS fragment_accounts.memberships CASCADE;
+DROP TABLE IF EXISTS fragment_accounts.traits CASCADE;
+DROP TABLE IF EXISTS fragment_accounts.accounts CASCADE;
+
+DROP SCHEMA IF EXISTS fragment_accounts;
@@ -0,0 +1,31 @@
+CREATE SCHEMA IF NOT EXISTS fragment_accounts;
+
+CREATE TABLE IF NOT EXISTS fragment_accounts.accounts (
+  group_id TEXT PRIMARY KEY,
+  created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS fragment_accounts.traits (
+  group_id TEXT NOT NULL REFERENCES fragment_accounts.accounts (group_id)
+    ON UPDATE CASCADE ON DELETE CASCADE
+    DEFERRABLE INITIALLY DEFERRED,
+  key TEXT NOT NULL,
+  value JSONB,
+  updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (group_id, key)
+);
+
+CREATE TABLE IF NOT EXISTS fragment_accounts.memberships (
+  group_id TEXT NOT NULL REFERENCES fragment_accounts.accounts (group_id)
+    ON UPDATE CASCADE ON DELETE CASCADE
+    DEFERRABLE INITIALLY DEFERRED,
+  type TEXT NOT NULL,
+  value TEXT NOT NULL,
+  first_seen_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+  last_seen_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (group_id, type, value)
+);
+
+CREATE INDEX memberships_member
+  ON fragment_accounts.memberships (type, value, last_seen_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS fragment_computed.buckets CASCADE;
+
+DROP SCHEMA IF EXISTS fragment_computed;
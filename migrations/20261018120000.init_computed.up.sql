@@ -0,0 +1,20 @@
+CREATE SCHEMA IF NOT EXISTS fragment_computed;
+
+CREATE TABLE IF NOT EXISTS fragment_computed.buckets (
+  profile_id VARCHAR(27) NOT NULL REFERENCES fragment_identity.profiles (id)
+    ON UPDATE CASCADE ON DELETE CASCADE
+    DEFERRABLE INITIALLY DEFERRED,
+  trait TEXT NOT NULL,
+  day DATE NOT NULL,
+  value TEXT NOT NULL DEFAULT '',
+  count INT8 NOT NULL DEFAULT 0,
+  sum FLOAT8 NOT NULL DEFAULT 0,
+  first_value JSONB,
+  first_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+  last_value JSONB,
+  last_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+  PRIMARY KEY (profile_id, trait, day, value)
+);
+
+CREATE INDEX buckets_trait_day
+  ON fragment_computed.buckets (trait, day);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS fragment_audiences.occurrences CASCADE;
+DROP TABLE IF EXISTS fragment_audiences.changes CASCADE;
+DROP TABLE IF EXISTS fragment_audiences.memberships CASCADE;
+
+DROP SCHEMA IF EXISTS fragment_audiences;
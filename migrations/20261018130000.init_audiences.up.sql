@@ -0,0 +1,42 @@
+CREATE SCHEMA IF NOT EXISTS fragment_audiences;
+
+CREATE TABLE IF NOT EXISTS fragment_audiences.memberships (
+  audience TEXT NOT NULL,
+  profile_id VARCHAR(27) NOT NULL REFERENCES fragment_identity.profiles (id)
+    ON UPDATE CASCADE ON DELETE CASCADE
+    DEFERRABLE INITIALLY DEFERRED,
+  is_member BOOL NOT NULL,
+  entered_at TIMESTAMP WITHOUT TIME ZONE,
+  exited_at TIMESTAMP WITHOUT TIME ZONE,
+  updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (audience, profile_id)
+);
+
+CREATE TABLE IF NOT EXISTS fragment_audiences.changes (
+  id VARCHAR(27) PRIMARY KEY,
+  audience TEXT NOT NULL,
+  profile_id VARCHAR(27) NOT NULL REFERENCES fragment_identity.profiles (id)
+    ON UPDATE CASCADE ON DELETE CASCADE
+    DEFERRABLE INITIALLY DEFERRED,
+  change TEXT NOT NULL,
+  created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS fragment_audiences.occurrences (
+  profile_id VARCHAR(27) NOT NULL REFERENCES fragment_identity.profiles (id)
+    ON UPDATE CASCADE ON DELETE CASCADE
+    DEFERRABLE INITIALLY DEFERRED,
+  event TEXT NOT NULL,
+  day DATE NOT NULL,
+  count INT8 NOT NULL DEFAULT 0,
+  PRIMARY KEY (profile_id, event, day)
+);
+
+CREATE INDEX memberships_profile_id
+  ON fragment_audiences.memberships (profile_id);
+
+CREATE INDEX changes_audience
+  ON fragment_audiences.changes (audience, created_at);
+
+CREATE INDEX occurrences_event_day
+  ON fragment_audiences.occurrences (event, day);
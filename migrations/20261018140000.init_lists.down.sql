@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS fragment_audiences.list_ids CASCADE;
+DROP TABLE IF EXISTS fragment_audiences.lists CASCADE;
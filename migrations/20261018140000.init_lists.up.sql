@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS fragment_audiences.lists (
+  key TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  condition JSONB,
+  created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS fragment_audiences.list_ids (
+  list TEXT NOT NULL REFERENCES fragment_audiences.lists (key)
+    ON UPDATE CASCADE ON DELETE CASCADE,
+  type TEXT NOT NULL,
+  value TEXT NOT NULL,
+  PRIMARY KEY (list, type, value)
+);
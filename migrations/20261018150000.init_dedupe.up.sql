@@ -0,0 +1,7 @@
+CREATE SCHEMA IF NOT EXISTS fragment_dedupe;
+
+CREATE TABLE IF NOT EXISTS fragment_dedupe.identifies (
+  key TEXT PRIMARY KEY,
+  traits JSONB NOT NULL DEFAULT '{}',
+  forwarded_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
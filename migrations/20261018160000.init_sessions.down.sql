@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS fragment_sessions.keys CASCADE;
+DROP TABLE IF EXISTS fragment_sessions.sessions CASCADE;
+
+DROP SCHEMA IF EXISTS fragment_sessions;
@@ -0,0 +1,21 @@
+CREATE SCHEMA IF NOT EXISTS fragment_sessions;
+
+CREATE TABLE IF NOT EXISTS fragment_sessions.sessions (
+  id VARCHAR(27) PRIMARY KEY,
+  session_id INT8 NOT NULL,
+  user_id TEXT,
+  anonymous_id TEXT,
+  started_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+  last_seen_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+  ended_at TIMESTAMP WITHOUT TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS fragment_sessions.keys (
+  key TEXT PRIMARY KEY,
+  session VARCHAR(27) NOT NULL REFERENCES fragment_sessions.sessions (id)
+    ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX sessions_active
+  ON fragment_sessions.sessions (last_seen_at)
+  WHERE ended_at IS NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS fragment_attribution.touches CASCADE;
+
+DROP SCHEMA IF EXISTS fragment_attribution;
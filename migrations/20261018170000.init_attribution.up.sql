@@ -0,0 +1,17 @@
+CREATE SCHEMA IF NOT EXISTS fragment_attribution;
+
+CREATE TABLE IF NOT EXISTS fragment_attribution.touches (
+  profile_id VARCHAR(27) NOT NULL REFERENCES fragment_identity.profiles (id)
+    ON UPDATE CASCADE ON DELETE CASCADE
+    DEFERRABLE INITIALLY DEFERRED,
+  kind TEXT NOT NULL,
+  source TEXT NOT NULL DEFAULT '',
+  medium TEXT NOT NULL DEFAULT '',
+  campaign TEXT NOT NULL DEFAULT '',
+  term TEXT NOT NULL DEFAULT '',
+  content TEXT NOT NULL DEFAULT '',
+  referrer TEXT NOT NULL DEFAULT '',
+  landing_page TEXT NOT NULL DEFAULT '',
+  touched_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+  PRIMARY KEY (profile_id, kind)
+);
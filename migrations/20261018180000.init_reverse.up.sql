@@ -0,0 +1,9 @@
+CREATE SCHEMA IF NOT EXISTS fragment_reverse;
+
+CREATE TABLE IF NOT EXISTS fragment_reverse.rows (
+  query TEXT NOT NULL,
+  key TEXT NOT NULL,
+  hash TEXT NOT NULL,
+  synced_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (query, key)
+);
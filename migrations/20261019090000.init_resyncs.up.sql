@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS fragment_audiences.resyncs (
+  key TEXT PRIMARY KEY,
+  cursor TEXT NOT NULL DEFAULT '',
+  requested_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS fragment_audiences.changes_message_id;
+
+ALTER TABLE fragment_audiences.changes
+  DROP COLUMN IF EXISTS message_id;
+
+DROP TABLE IF EXISTS fragment_audiences.messages CASCADE;
+DROP TABLE IF EXISTS fragment_computed.messages CASCADE;
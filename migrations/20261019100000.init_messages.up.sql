@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS fragment_computed.messages (
+  message_id TEXT PRIMARY KEY,
+  received_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS fragment_audiences.messages (
+  message_id TEXT PRIMARY KEY,
+  received_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE fragment_audiences.changes
+  ADD COLUMN IF NOT EXISTS message_id TEXT;
+
+CREATE INDEX changes_message_id
+  ON fragment_audiences.changes (message_id);
@@ -0,0 +1,11 @@
+CREATE SCHEMA IF NOT EXISTS fragment_webhook;
+
+CREATE TABLE IF NOT EXISTS fragment_webhook.deliveries (
+  job_id TEXT NOT NULL,
+  url TEXT NOT NULL,
+  delivered_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (job_id, url)
+);
+
+CREATE INDEX deliveries_delivered_at
+  ON fragment_webhook.deliveries (delivered_at);
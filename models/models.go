@@ -0,0 +1,100 @@
+/*
+Package models builds modeled tables on top of the raw events of the warehouse,
+using the SQL operations of Blacksmith. The operations are incremental: only the
+rows having events received since the previous run are computed again.
+*/
+package models
+
+import (
+	"strconv"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/helper/logger"
+
+	"github.com/lib/pq"
+)
+
+/*
+Runner runs the SQL operations building the modeled tables.
+*/
+type Runner struct {
+	env *Options
+}
+
+/*
+New returns a runner for the models.
+*/
+func New(env *Options) *Runner {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &Runner{
+		env: env,
+	}
+}
+
+/*
+data returns the variables passed to the SQL templates.
+*/
+func (r *Runner) data() map[string]interface{} {
+	return map[string]interface{}{
+		"schema":   r.env.Schema,
+		"models":   r.env.Models,
+		"lookback": strconv.FormatInt(int64(r.env.Lookback.Seconds()), 10) + " seconds",
+	}
+}
+
+/*
+locked returns the query of an operation preceded by a transaction-level advisory
+lock on the operation. Since the CRON task runs on every replica, the lock ensures
+an operation never runs concurrently on the same modeled tables.
+*/
+func (r *Runner) locked(operation string, query string) string {
+	key := pq.QuoteLiteral("models:" + r.env.Models + ":" + operation)
+	return `SELECT pg_advisory_xact_lock(hashtext(` + key + `));` + "\n" + query
+}
+
+/*
+Run compiles and executes the operations in order. Each operation is executed in
+its own transaction, so a failing operation does not roll back the previous ones.
+The operation is locked for the duration of its transaction, so replicas running
+it at the same time run it one after the other.
+*/
+func (r *Runner) Run() error {
+	wh, err := r.env.Warehouse.AsWarehouse()
+	if err != nil {
+		return err
+	}
+
+	for _, operation := range r.env.Operations {
+		query, err := wh.Compile(operation, r.data())
+		if err != nil {
+			return failed("Failed to compile operation "+operation, err)
+		}
+
+		if err := wh.Exec(r.locked(operation, query)); err != nil {
+			return failed("Failed to run operation "+operation, err)
+		}
+	}
+
+	return nil
+}
+
+/*
+failed returns a consistent error for the models.
+*/
+func failed(message string, err error) error {
+	return &errors.Error{
+		Message: "models: " + message,
+		Validations: []errors.Validation{
+			{
+				Message: err.Error(),
+			},
+		},
+	}
+}
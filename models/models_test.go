@@ -0,0 +1,50 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunner_compile(t *testing.T) {
+	r := New(&Options{
+		Warehouse: fakeWarehouse{},
+		Schema:    "segment",
+		Models:    "analytics",
+		Lookback:  2 * time.Hour,
+	})
+
+	wh, _ := r.env.Warehouse.AsWarehouse()
+	for _, operation := range Defaults.Operations {
+		t.Run(operation, func(t *testing.T) {
+			query, err := wh.Compile("../"+operation, r.data())
+			if err != nil {
+				t.Fatalf("Warehouse.Compile() error = %v", err)
+			}
+
+			if strings.Contains(query, "{{") || strings.Contains(query, "{%") {
+				t.Errorf("Warehouse.Compile() left template tags in %v", operation)
+			}
+
+			for _, want := range []string{"segment.", "analytics.", "INTERVAL '7200 seconds'"} {
+				if !strings.Contains(query, want) {
+					t.Errorf("Warehouse.Compile() = %v, want to contain %v", operation, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRunner_locked(t *testing.T) {
+	r := &Runner{
+		env: &Options{
+			Models: "analytics",
+		},
+	}
+
+	got := r.locked("operations/models/sessions.sql", "SELECT 1;")
+	want := "SELECT pg_advisory_xact_lock(hashtext('models:analytics:operations/models/sessions.sql'));\nSELECT 1;"
+	if got != want {
+		t.Errorf("Runner.locked() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/warehouse"
+)
+
+/*
+Defaults are the defaults options set for the models. When not set, these values
+will automatically be applied.
+*/
+var Defaults = &Options{
+	Schema:   "warehouse",
+	Models:   "warehouse_models",
+	Lookback: time.Hour,
+	Operations: []string{
+		"operations/models/sessions.sql",
+		"operations/models/users_latest.sql",
+		"operations/models/daily_active_users.sql",
+	},
+}
+
+/*
+Options is the options the models can take as an input to be configured.
+*/
+type Options struct {
+
+	// Warehouse is the destination the raw events are loaded to, and on top of
+	// which the operations run.
+	//
+	// Required.
+	Warehouse warehouse.AsWarehouse
+
+	// Schema is the PostgreSQL schema holding the raw events. It must be the one
+	// of the warehouse destination.
+	//
+	// Defaults to "warehouse".
+	Schema string
+
+	// Models is the PostgreSQL schema holding the modeled tables. It is kept apart
+	// from the raw events so it can not conflict with the table of an event.
+	//
+	// Defaults to "warehouse_models".
+	Models string
+
+	// Lookback is the duration before the latest event already modeled from which
+	// events are modeled again on every run. It allows events received late, such
+	// as the ones loaded after a retry, to still be taken into account.
+	//
+	// Defaults to 1 hour.
+	Lookback time.Duration
+
+	// Operations are the paths of the SQL templates to compile and execute on every
+	// run, relative to the working directory. They are executed in order.
+	//
+	// Defaults to the operations building the tables "sessions", "users_latest",
+	// and "daily_active_users".
+	Operations []string
+}
+
+/*
+validate ensures the options passed to initialize the models are valid.
+*/
+func (env *Options) validate() error {
+	fail := &errors.Error{
+		Message:     "models: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Models"},
+		})
+
+		return fail
+	}
+
+	if env.Warehouse == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Warehouse must be set",
+			Path:    []string{"Options", "Models", "Warehouse"},
+		})
+	}
+
+	if env.Schema == "" {
+		env.Schema = Defaults.Schema
+	}
+
+	if env.Models == "" {
+		env.Models = Defaults.Models
+	}
+
+	if env.Schema == env.Models {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Models must not be written in the schema of the raw events",
+			Path:    []string{"Options", "Models", "Models"},
+		})
+	}
+
+	if env.Lookback < 0 {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Lookback must not be negative",
+			Path:    []string{"Options", "Models", "Lookback"},
+		})
+	} else if env.Lookback == 0 {
+		env.Lookback = Defaults.Lookback
+	}
+
+	if len(env.Operations) == 0 {
+		env.Operations = Defaults.Operations
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
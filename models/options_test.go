@@ -0,0 +1,66 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/warehouse"
+)
+
+/*
+fakeWarehouse implements the warehouse.AsWarehouse interface without any database
+connection, so the templates can be compiled in tests.
+*/
+type fakeWarehouse struct{}
+
+func (w fakeWarehouse) AsWarehouse() (*warehouse.Warehouse, error) {
+	return warehouse.New(&warehouse.Options{
+		Name: "fake",
+	})
+}
+
+func TestOptions_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  *Options
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: true,
+		},
+		{
+			name: "WithWarehouse",
+			fields: &Options{
+				Warehouse: fakeWarehouse{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithSameSchemas",
+			fields: &Options{
+				Warehouse: fakeWarehouse{},
+				Schema:    "warehouse",
+				Models:    "warehouse",
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithNegativeLookback",
+			fields: &Options{
+				Warehouse: fakeWarehouse{},
+				Lookback:  -time.Hour,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package normalize
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+Case is a custom type allowing the user to only pass supported casing conventions
+when normalizing keys of properties and traits.
+*/
+type Case string
+
+/*
+CaseNone is used to leave the keys untouched.
+*/
+var CaseNone Case = ""
+
+/*
+CaseSnake is used to convert the keys to the snake case convention, such as
+"plan_name".
+*/
+var CaseSnake Case = "snake"
+
+/*
+CaseCamel is used to convert the keys to the camel case convention, such as
+"planName".
+*/
+var CaseCamel Case = "camel"
+
+/*
+apply converts a key to the casing convention.
+*/
+func (c Case) apply(key string) string {
+	switch c {
+	case CaseSnake:
+		return strings.Join(words(key), "_")
+
+	case CaseCamel:
+		w := words(key)
+		for i := 1; i < len(w); i++ {
+			r, size := utf8.DecodeRuneInString(w[i])
+			w[i] = string(unicode.ToUpper(r)) + w[i][size:]
+		}
+
+		return strings.Join(w, "")
+	}
+
+	return key
+}
+
+/*
+applyNested converts the keys of nested objects to the casing convention. Other
+values are returned untouched.
+*/
+func (c Case) applyNested(value interface{}) interface{} {
+	if c == CaseNone {
+		return value
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		nested := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			nested[c.apply(key)] = c.applyNested(value)
+		}
+
+		return nested
+
+	case []interface{}:
+		nested := make([]interface{}, len(v))
+		for i := range v {
+			nested[i] = c.applyNested(v[i])
+		}
+
+		return nested
+	}
+
+	return value
+}
+
+/*
+words splits a key into lowercased words. Words are separated by any character
+which is neither a letter nor a digit, and by changes of case such as in "planName"
+or "HTTPServer".
+*/
+func words(key string) []string {
+	runes := []rune(key)
+	w := []string{}
+
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			w = append(w, strings.ToLower(string(current)))
+			current = nil
+		}
+	}
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+
+		if unicode.IsUpper(r) && len(current) > 0 {
+			previous := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				flush()
+			}
+		}
+
+		current = append(current, r)
+	}
+
+	flush()
+	if len(w) == 0 {
+		return []string{key}
+	}
+
+	return w
+}
+
+/*
+Snake converts a key to the snake case convention, such as "order_completed" for
+"Order Completed".
+*/
+func Snake(key string) string {
+	return CaseSnake.apply(key)
+}
@@ -0,0 +1,95 @@
+package normalize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCase_apply(t *testing.T) {
+	tests := []struct {
+		name string
+		c    Case
+		key  string
+		want string
+	}{
+		{
+			name: "NoneWithCamelKey",
+			c:    CaseNone,
+			key:  "planName",
+			want: "planName",
+		},
+		{
+			name: "SnakeWithCamelKey",
+			c:    CaseSnake,
+			key:  "planName",
+			want: "plan_name",
+		},
+		{
+			name: "SnakeWithAcronym",
+			c:    CaseSnake,
+			key:  "HTTPServerURL",
+			want: "http_server_url",
+		},
+		{
+			name: "SnakeWithSpaces",
+			c:    CaseSnake,
+			key:  "Plan Name",
+			want: "plan_name",
+		},
+		{
+			name: "CamelWithSnakeKey",
+			c:    CaseCamel,
+			key:  "plan_name",
+			want: "planName",
+		},
+		{
+			name: "CamelWithDashes",
+			c:    CaseCamel,
+			key:  "billing-cycle-2",
+			want: "billingCycle2",
+		},
+		{
+			name: "CamelWithNonASCIIWord",
+			c:    CaseCamel,
+			key:  "plan_été",
+			want: "planÉté",
+		},
+		{
+			name: "SnakeWithNonASCIIWord",
+			c:    CaseSnake,
+			key:  "planÉté",
+			want: "plan_été",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.apply(tt.key); got != tt.want {
+				t.Errorf("Case.apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCase_applyNested(t *testing.T) {
+	value := map[string]interface{}{
+		"billingCycle": "monthly",
+		"addOns": []interface{}{
+			map[string]interface{}{
+				"seatCount": float64(3),
+			},
+		},
+	}
+
+	want := map[string]interface{}{
+		"billing_cycle": "monthly",
+		"add_ons": []interface{}{
+			map[string]interface{}{
+				"seat_count": float64(3),
+			},
+		},
+	}
+
+	if got := CaseSnake.applyNested(value); !reflect.DeepEqual(got, want) {
+		t.Errorf("Case.applyNested() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,42 @@
+package normalize
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+/*
+Email normalizes an email: it is trimmed and lowercased. It returns an error if
+the value is not a string or if the email is not syntactically valid.
+*/
+func Email(value interface{}) (string, error) {
+	email, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("Email must be a string")
+	}
+
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	// Only accept a bare address: a display name or comments would be parsed
+	// successfully but are not wanted in the reserved trait.
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", fmt.Errorf("Email '%s' is not valid", email)
+	}
+
+	// The domain must contain at least one dot and no empty label.
+	domain := email[strings.LastIndex(email, "@")+1:]
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return "", fmt.Errorf("Email '%s' is not valid", email)
+	}
+
+	for _, label := range labels {
+		if label == "" {
+			return "", fmt.Errorf("Email '%s' is not valid", email)
+		}
+	}
+
+	return email, nil
+}
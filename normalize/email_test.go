@@ -0,0 +1,52 @@
+package normalize
+
+import (
+	"testing"
+)
+
+func TestEmail(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "WithPaddedUppercase",
+			value: "  JohnDoe@Example.COM ",
+			want:  "johndoe@example.com",
+		},
+		{
+			name:    "WithNoDomain",
+			value:   "johndoe@",
+			wantErr: true,
+		},
+		{
+			name:    "WithNoTLD",
+			value:   "johndoe@example",
+			wantErr: true,
+		},
+		{
+			name:    "WithDisplayName",
+			value:   "John Doe <johndoe@example.com>",
+			wantErr: true,
+		},
+		{
+			name:    "WithNumber",
+			value:   float64(42),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Email(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Email() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Email() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+/*
+Package normalize offers normalizers for the traits and properties of events
+following the Segment Specification. It makes sure data is consistent before
+being loaded to destinations, such as emails and phones.
+*/
+package normalize
+
+import (
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Options is the options a source can pass to normalize the traits and properties
+of the events it receives. Every source can have its own normalization options.
+*/
+type Options struct {
+
+	// Emails enables the normalization of the reserved trait "email". The email is
+	// trimmed, lowercased, and its syntax is validated. An event with an invalid
+	// email is rejected.
+	Emails bool
+
+	// Phones enables the normalization of the reserved trait "phone" to the E.164
+	// format. Numbers written without an international prefix are considered as
+	// part of the DefaultRegion. An event with an invalid phone is rejected.
+	Phones bool
+
+	// DefaultRegion is the ISO 3166-1 alpha-2 code of the region used for phone
+	// numbers written in their national format.
+	//
+	// Example: "US"
+	// Required if Phones is enabled.
+	DefaultRegion string
+
+	// Names enables the unification of the reserved traits using the snake case
+	// convention (such as "first_name") to the one expected by the Segment
+	// Specification ("firstName"). It also trims the names and sets the trait
+	// "name" from the first and last names if not provided.
+	Names bool
+
+	// PropertyCase is the casing convention to apply to the keys of properties
+	// and custom traits. Reserved traits and properties always keep their casing
+	// since they are used by the Segment flows.
+	//
+	// Defaults to CaseNone.
+	PropertyCase Case
+}
+
+/*
+Validate ensures the normalization options are valid. The path is the one of the
+options within the application's options and is used for the validation errors.
+*/
+func (opts *Options) Validate(path []string) []errors.Validation {
+	validations := []errors.Validation{}
+
+	if opts.Phones {
+		if _, exists := regions[opts.DefaultRegion]; !exists {
+			validations = append(validations, errors.Validation{
+				Message: "Default region must be a supported ISO 3166-1 alpha-2 code",
+				Path:    at(path, "DefaultRegion"),
+			})
+		}
+	}
+
+	switch opts.PropertyCase {
+	case CaseNone, CaseSnake, CaseCamel:
+	default:
+		validations = append(validations, errors.Validation{
+			Message: "Property case must be one of '', 'snake', or 'camel'",
+			Path:    at(path, "PropertyCase"),
+		})
+	}
+
+	return validations
+}
+
+/*
+Traits normalizes the traits of an Identify or a Group event. It returns the
+validation errors encountered, if any. The path is the one of the traits within
+the event and is used for the validation errors.
+*/
+func (opts *Options) Traits(traits analytics.Traits, path []string) (analytics.Traits, []errors.Validation) {
+	validations := []errors.Validation{}
+	if traits == nil {
+		return traits, validations
+	}
+
+	normalized := analytics.Traits{}
+	for key, value := range traits {
+		// Only unify a trait if the reserved one is not also present. Otherwise
+		// it is kept as a custom trait.
+		if opts.Names {
+			if reserved, exists := aliases[key]; exists {
+				if _, taken := traits[reserved]; !taken {
+					key = reserved
+				}
+			}
+		}
+
+		if _, exists := reservedTraits[key]; !exists {
+			key = opts.PropertyCase.apply(key)
+			value = opts.PropertyCase.applyNested(value)
+		}
+
+		normalized[key] = value
+	}
+
+	if opts.Emails {
+		if value, exists := normalized["email"]; exists {
+			email, err := Email(value)
+			if err != nil {
+				validations = append(validations, errors.Validation{
+					Message: err.Error(),
+					Path:    at(path, "email"),
+				})
+			} else {
+				normalized["email"] = email
+			}
+		}
+	}
+
+	if opts.Phones {
+		if value, exists := normalized["phone"]; exists {
+			phone, err := Phone(value, opts.DefaultRegion)
+			if err != nil {
+				validations = append(validations, errors.Validation{
+					Message: err.Error(),
+					Path:    at(path, "phone"),
+				})
+			} else {
+				normalized["phone"] = phone
+			}
+		}
+	}
+
+	if opts.Names {
+		names(normalized)
+	}
+
+	return normalized, validations
+}
+
+/*
+Properties normalizes the properties of a Track, Page, or Screen event. Reserved
+properties are left untouched.
+*/
+func (opts *Options) Properties(properties analytics.Properties) analytics.Properties {
+	if properties == nil || opts.PropertyCase == CaseNone {
+		return properties
+	}
+
+	normalized := analytics.Properties{}
+	for key, value := range properties {
+		if _, exists := reservedProperties[key]; !exists {
+			key = opts.PropertyCase.apply(key)
+			value = opts.PropertyCase.applyNested(value)
+		}
+
+		normalized[key] = value
+	}
+
+	return normalized
+}
@@ -0,0 +1,143 @@
+package normalize
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+func TestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    *Options
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyOptions",
+			opts:    &Options{},
+			wantErr: false,
+		},
+		{
+			name: "WithPhonesAndNoRegion",
+			opts: &Options{
+				Phones: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithPhonesAndRegion",
+			opts: &Options{
+				Phones:        true,
+				DefaultRegion: "FR",
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithUnknownCase",
+			opts: &Options{
+				PropertyCase: "kebab",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validations := tt.opts.Validate([]string{"Options"})
+			if (len(validations) > 0) != tt.wantErr {
+				t.Errorf("Options.Validate() validations = %v, wantErr %v", validations, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOptions_Traits(t *testing.T) {
+	opts := &Options{
+		Emails:        true,
+		Phones:        true,
+		DefaultRegion: "US",
+		Names:         true,
+		PropertyCase:  CaseSnake,
+	}
+
+	tests := []struct {
+		name    string
+		traits  analytics.Traits
+		want    analytics.Traits
+		wantErr bool
+	}{
+		{
+			name: "WithReservedTraits",
+			traits: analytics.Traits{
+				"email":      " JohnDoe@Example.com",
+				"phone":      "(415) 555-0132",
+				"first_name": " John ",
+				"last_name":  "Doe",
+				"planName":   "premium",
+			},
+			want: analytics.Traits{
+				"email":     "johndoe@example.com",
+				"phone":     "+14155550132",
+				"firstName": "John",
+				"lastName":  "Doe",
+				"name":      "John Doe",
+				"plan_name": "premium",
+			},
+		},
+		{
+			name: "WithBothSpellings",
+			traits: analytics.Traits{
+				"firstName":  "John",
+				"first_name": "Johnny",
+			},
+			want: analytics.Traits{
+				"firstName":  "John",
+				"first_name": "Johnny",
+				"name":       "John",
+			},
+		},
+		{
+			name: "WithInvalidEmail",
+			traits: analytics.Traits{
+				"email": "johndoe",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, validations := opts.Traits(tt.traits, []string{"traits"})
+			if (len(validations) > 0) != tt.wantErr {
+				t.Errorf("Options.Traits() validations = %v, wantErr %v", validations, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Options.Traits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptions_Properties(t *testing.T) {
+	opts := &Options{
+		PropertyCase: CaseCamel,
+	}
+
+	properties := analytics.Properties{
+		"productId":  "p-1",
+		"order_id":   "o-1",
+		"revenue":    float64(42),
+		"promo_code": "SUMMER",
+	}
+
+	want := analytics.Properties{
+		"productId": "p-1",
+		"orderId":   "o-1",
+		"revenue":   float64(42),
+		"promoCode": "SUMMER",
+	}
+
+	if got := opts.Properties(properties); !reflect.DeepEqual(got, want) {
+		t.Errorf("Options.Properties() = %v, want %v", got, want)
+	}
+}
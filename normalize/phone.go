@@ -0,0 +1,116 @@
+package normalize
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+region holds the details needed to convert a phone number written in a national
+format to the E.164 format.
+*/
+type region struct {
+	code  string
+	trunk string
+}
+
+/*
+regions is the list of supported regions for phone numbers written in a national
+format, given their ISO 3166-1 alpha-2 code. Numbers written with an international
+prefix are supported regardless of their region.
+*/
+var regions = map[string]region{
+	"AR": {code: "54", trunk: "0"},
+	"AT": {code: "43", trunk: "0"},
+	"AU": {code: "61", trunk: "0"},
+	"BE": {code: "32", trunk: "0"},
+	"BR": {code: "55", trunk: "0"},
+	"CA": {code: "1", trunk: "1"},
+	"CH": {code: "41", trunk: "0"},
+	"CN": {code: "86", trunk: "0"},
+	"DE": {code: "49", trunk: "0"},
+	"DK": {code: "45", trunk: ""},
+	"ES": {code: "34", trunk: ""},
+	"FI": {code: "358", trunk: "0"},
+	"FR": {code: "33", trunk: "0"},
+	"GB": {code: "44", trunk: "0"},
+	"HK": {code: "852", trunk: ""},
+	"IE": {code: "353", trunk: "0"},
+	"IL": {code: "972", trunk: "0"},
+	"IN": {code: "91", trunk: "0"},
+	"IT": {code: "39", trunk: ""},
+	"JP": {code: "81", trunk: "0"},
+	"KR": {code: "82", trunk: "0"},
+	"LU": {code: "352", trunk: ""},
+	"MX": {code: "52", trunk: ""},
+	"NL": {code: "31", trunk: "0"},
+	"NO": {code: "47", trunk: ""},
+	"NZ": {code: "64", trunk: "0"},
+	"PL": {code: "48", trunk: ""},
+	"PT": {code: "351", trunk: ""},
+	"SE": {code: "46", trunk: "0"},
+	"SG": {code: "65", trunk: ""},
+	"US": {code: "1", trunk: "1"},
+	"ZA": {code: "27", trunk: "0"},
+}
+
+/*
+Phone normalizes a phone number to the E.164 format. Numbers without international
+prefix ("+" or "00") are considered as part of the default region. It returns an
+error if the value is neither a string nor a number, or if the phone number is
+not valid.
+*/
+func Phone(value interface{}, defaultRegion string) (string, error) {
+	var phone string
+	switch v := value.(type) {
+	case string:
+		phone = strings.TrimSpace(v)
+	case float64:
+		phone = strconv.FormatFloat(v, 'f', 0, 64)
+	default:
+		return "", fmt.Errorf("Phone must be a string")
+	}
+
+	// Only keep the digits, making sure no unexpected character is present.
+	var digits strings.Builder
+	for i, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+		case r == ' ', r == '-', r == '.', r == '(', r == ')', r == '/':
+		default:
+			return "", fmt.Errorf("Phone '%s' is not valid", phone)
+		}
+	}
+
+	number := digits.String()
+	switch {
+	case strings.HasPrefix(phone, "+"):
+	case strings.HasPrefix(number, "00"):
+		number = strings.TrimPrefix(number, "00")
+	default:
+		r, exists := regions[defaultRegion]
+		if !exists {
+			return "", fmt.Errorf("Phone '%s' has no international prefix and region '%s' is not supported", phone, defaultRegion)
+		}
+
+		// The trunk prefix of the North American Numbering Plan is only present
+		// when the number has 11 digits.
+		if r.trunk != "" && strings.HasPrefix(number, r.trunk) {
+			if r.code != "1" || len(number) == 11 {
+				number = strings.TrimPrefix(number, r.trunk)
+			}
+		}
+
+		number = r.code + number
+	}
+
+	// E.164 numbers have at most 15 digits and must not start with a zero.
+	if len(number) < 8 || len(number) > 15 || strings.HasPrefix(number, "0") {
+		return "", fmt.Errorf("Phone '%s' is not valid", phone)
+	}
+
+	return "+" + number, nil
+}
@@ -0,0 +1,88 @@
+package normalize
+
+import (
+	"testing"
+)
+
+func TestPhone(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         interface{}
+		defaultRegion string
+		want          string
+		wantErr       bool
+	}{
+		{
+			name:          "WithInternationalPrefix",
+			value:         "+33 6 12 34 56 78",
+			defaultRegion: "US",
+			want:          "+33612345678",
+		},
+		{
+			name:          "WithDoubleZeroPrefix",
+			value:         "0044 20 7946 0958",
+			defaultRegion: "US",
+			want:          "+442079460958",
+		},
+		{
+			name:          "WithNationalTrunkPrefix",
+			value:         "06.12.34.56.78",
+			defaultRegion: "FR",
+			want:          "+33612345678",
+		},
+		{
+			name:          "WithNorthAmericanFormat",
+			value:         "(415) 555-0132",
+			defaultRegion: "US",
+			want:          "+14155550132",
+		},
+		{
+			name:          "WithNorthAmericanTrunkPrefix",
+			value:         "1-415-555-0132",
+			defaultRegion: "US",
+			want:          "+14155550132",
+		},
+		{
+			name:          "WithNoTrunkPrefixRegion",
+			value:         "06 1234 5678",
+			defaultRegion: "IT",
+			want:          "+390612345678",
+		},
+		{
+			name:          "WithNumber",
+			value:         float64(4155550132),
+			defaultRegion: "US",
+			want:          "+14155550132",
+		},
+		{
+			name:          "WithLetters",
+			value:         "415-CALL-NOW",
+			defaultRegion: "US",
+			wantErr:       true,
+		},
+		{
+			name:          "WithTooManyDigits",
+			value:         "+33 6 12 34 56 78 90 12 34",
+			defaultRegion: "US",
+			wantErr:       true,
+		},
+		{
+			name:          "WithUnsupportedRegion",
+			value:         "612345678",
+			defaultRegion: "XX",
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Phone(tt.value, tt.defaultRegion)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Phone() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Phone() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
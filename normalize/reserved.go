@@ -0,0 +1,108 @@
+package normalize
+
+import (
+	"strings"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+reservedTraits is the list of traits reserved by the Segment Specification. They
+keep their casing since the Segment flows rely on them.
+
+Reference: https://segment.com/docs/connections/spec/identify/#traits
+*/
+var reservedTraits = map[string]bool{
+	"address":     true,
+	"age":         true,
+	"avatar":      true,
+	"birthday":    true,
+	"company":     true,
+	"createdAt":   true,
+	"description": true,
+	"email":       true,
+	"employees":   true,
+	"firstName":   true,
+	"gender":      true,
+	"id":          true,
+	"industry":    true,
+	"lastName":    true,
+	"name":        true,
+	"phone":       true,
+	"plan":        true,
+	"title":       true,
+	"username":    true,
+	"website":     true,
+}
+
+/*
+aliases maps the common spellings of reserved traits to the ones expected by the
+Segment Specification.
+*/
+var aliases = map[string]string{
+	"first_name": "firstName",
+	"firstname":  "firstName",
+	"FirstName":  "firstName",
+	"last_name":  "lastName",
+	"lastname":   "lastName",
+	"LastName":   "lastName",
+	"created_at": "createdAt",
+	"createdat":  "createdAt",
+	"CreatedAt":  "createdAt",
+	"user_name":  "username",
+	"userName":   "username",
+	"Email":      "email",
+	"Phone":      "phone",
+}
+
+/*
+reservedProperties is the list of properties reserved by the Segment Specification
+for Track, Page, and Screen events. They keep their casing since the Segment flows
+rely on them.
+
+Reference: https://segment.com/docs/connections/spec/track/#properties
+*/
+var reservedProperties = map[string]bool{
+	"category":  true,
+	"currency":  true,
+	"name":      true,
+	"path":      true,
+	"price":     true,
+	"productId": true,
+	"quantity":  true,
+	"referrer":  true,
+	"revenue":   true,
+	"search":    true,
+	"title":     true,
+	"url":       true,
+	"value":     true,
+}
+
+/*
+names trims the first and last names, and sets the full name from them if it was
+not provided.
+*/
+func names(traits analytics.Traits) {
+	for _, key := range []string{"firstName", "lastName", "name"} {
+		if value, ok := traits[key].(string); ok {
+			traits[key] = strings.Join(strings.Fields(value), " ")
+		}
+	}
+
+	if _, exists := traits["name"]; exists {
+		return
+	}
+
+	firstName, _ := traits["firstName"].(string)
+	lastName, _ := traits["lastName"].(string)
+	if name := strings.TrimSpace(firstName + " " + lastName); name != "" {
+		traits["name"] = name
+	}
+}
+
+/*
+at returns a new path for a key within the parent path.
+*/
+func at(path []string, key string) []string {
+	return append(append([]string{}, path...), key)
+}
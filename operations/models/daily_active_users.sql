@@ -0,0 +1,46 @@
+-- Build the table "daily_active_users" counting the users active every day. A
+-- user is active when at least one page, screen, or track is received for them.
+-- Only the days having events received since the last run are computed again.
+
+CREATE SCHEMA IF NOT EXISTS {{ models }};
+
+CREATE TABLE IF NOT EXISTS {{ models }}.daily_active_users (
+  day DATE PRIMARY KEY,
+  active_users INTEGER NOT NULL,
+  identified_users INTEGER NOT NULL,
+  anonymous_users INTEGER NOT NULL,
+  received_at TIMESTAMPTZ NOT NULL
+);
+
+WITH events AS (
+  SELECT user_id, anonymous_id, "timestamp", received_at FROM {{ schema }}.pages
+  UNION ALL
+  SELECT user_id, anonymous_id, "timestamp", received_at FROM {{ schema }}.screens
+  UNION ALL
+  SELECT user_id, anonymous_id, "timestamp", received_at FROM {{ schema }}.tracks
+),
+watermark AS (
+  SELECT COALESCE(MAX(received_at), '-infinity') - INTERVAL '{{ lookback }}' AS at
+  FROM {{ models }}.daily_active_users
+),
+touched AS (
+  SELECT DISTINCT DATE("timestamp" AT TIME ZONE 'UTC') AS day
+  FROM events, watermark
+  WHERE events.received_at > watermark.at
+)
+INSERT INTO {{ models }}.daily_active_users (day, active_users, identified_users,
+  anonymous_users, received_at)
+SELECT DATE(events."timestamp" AT TIME ZONE 'UTC') AS day,
+  COUNT(DISTINCT COALESCE(events.user_id, events.anonymous_id)),
+  COUNT(DISTINCT events.user_id),
+  COUNT(DISTINCT events.anonymous_id) FILTER (WHERE events.user_id IS NULL),
+  MAX(events.received_at)
+FROM events
+WHERE events."timestamp" >= (SELECT MIN(day) FROM touched)::TIMESTAMP AT TIME ZONE 'UTC'
+  AND DATE(events."timestamp" AT TIME ZONE 'UTC') IN (SELECT day FROM touched)
+GROUP BY 1
+ON CONFLICT (day) DO UPDATE SET
+  active_users = EXCLUDED.active_users,
+  identified_users = EXCLUDED.identified_users,
+  anonymous_users = EXCLUDED.anonymous_users,
+  received_at = EXCLUDED.received_at;
@@ -0,0 +1,113 @@
+-- Build the table "sessions" from the pages, screens, and tracks of the warehouse.
+-- Sessions are the ones attached by Fragment to the context of the events, where
+-- the session ID is the start time of the session in milliseconds. Only sessions
+-- having events received since the last run are computed again.
+
+CREATE SCHEMA IF NOT EXISTS {{ models }};
+
+CREATE TABLE IF NOT EXISTS {{ models }}.sessions (
+  id TEXT PRIMARY KEY,
+  session_id BIGINT NOT NULL,
+  anonymous_id TEXT,
+  user_id TEXT,
+  started_at TIMESTAMPTZ NOT NULL,
+  ended_at TIMESTAMPTZ NOT NULL,
+  duration_seconds DOUBLE PRECISION NOT NULL,
+  pages INTEGER NOT NULL,
+  screens INTEGER NOT NULL,
+  tracks INTEGER NOT NULL,
+  landing_page TEXT,
+  referrer TEXT,
+  utm_source TEXT,
+  utm_medium TEXT,
+  utm_campaign TEXT,
+  received_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS sessions_received_at
+  ON {{ models }}.sessions (received_at);
+
+WITH events AS (
+  SELECT 'page' AS type, context_session_id AS session_id, anonymous_id, user_id,
+    "timestamp", received_at, context_page_url, context_page_referrer,
+    context_campaign_source, context_campaign_medium, context_campaign_name
+  FROM {{ schema }}.pages
+  WHERE context_session_id IS NOT NULL
+  UNION ALL
+  SELECT 'screen', context_session_id, anonymous_id, user_id,
+    "timestamp", received_at, context_page_url, context_page_referrer,
+    context_campaign_source, context_campaign_medium, context_campaign_name
+  FROM {{ schema }}.screens
+  WHERE context_session_id IS NOT NULL
+  UNION ALL
+  SELECT 'track', context_session_id, anonymous_id, user_id,
+    "timestamp", received_at, context_page_url, context_page_referrer,
+    context_campaign_source, context_campaign_medium, context_campaign_name
+  FROM {{ schema }}.tracks
+  WHERE context_session_id IS NOT NULL
+),
+keyed AS (
+  SELECT session_id || ':' || COALESCE(anonymous_id, user_id) AS id, *
+  FROM events
+),
+watermark AS (
+  SELECT COALESCE(MAX(received_at), '-infinity') - INTERVAL '{{ lookback }}' AS at
+  FROM {{ models }}.sessions
+),
+touched AS (
+  SELECT DISTINCT keyed.id, keyed.session_id
+  FROM keyed, watermark
+  WHERE keyed.received_at > watermark.at
+),
+since AS (
+  SELECT TO_TIMESTAMP(MIN(session_id) / 1000.0) AS at
+  FROM touched
+),
+scoped AS (
+  SELECT keyed.*
+  FROM keyed, since
+  WHERE keyed."timestamp" >= since.at
+    AND keyed.id IN (SELECT id FROM touched)
+),
+firsts AS (
+  SELECT DISTINCT ON (id) id, context_page_url AS landing_page,
+    context_page_referrer AS referrer, context_campaign_source AS utm_source,
+    context_campaign_medium AS utm_medium, context_campaign_name AS utm_campaign
+  FROM scoped
+  ORDER BY id, "timestamp" ASC
+),
+identified AS (
+  SELECT DISTINCT ON (id) id, user_id
+  FROM scoped
+  WHERE user_id IS NOT NULL
+  ORDER BY id, "timestamp" DESC
+)
+INSERT INTO {{ models }}.sessions (id, session_id, anonymous_id, user_id,
+  started_at, ended_at, duration_seconds, pages, screens, tracks, landing_page,
+  referrer, utm_source, utm_medium, utm_campaign, received_at)
+SELECT scoped.id, MIN(scoped.session_id), MIN(scoped.anonymous_id),
+  MIN(identified.user_id), MIN(scoped."timestamp"), MAX(scoped."timestamp"),
+  EXTRACT(EPOCH FROM MAX(scoped."timestamp") - MIN(scoped."timestamp")),
+  COUNT(*) FILTER (WHERE scoped.type = 'page'),
+  COUNT(*) FILTER (WHERE scoped.type = 'screen'),
+  COUNT(*) FILTER (WHERE scoped.type = 'track'),
+  MIN(firsts.landing_page), MIN(firsts.referrer), MIN(firsts.utm_source),
+  MIN(firsts.utm_medium), MIN(firsts.utm_campaign), MAX(scoped.received_at)
+FROM scoped
+JOIN firsts ON firsts.id = scoped.id
+LEFT JOIN identified ON identified.id = scoped.id
+GROUP BY scoped.id
+ON CONFLICT (id) DO UPDATE SET
+  user_id = EXCLUDED.user_id,
+  started_at = EXCLUDED.started_at,
+  ended_at = EXCLUDED.ended_at,
+  duration_seconds = EXCLUDED.duration_seconds,
+  pages = EXCLUDED.pages,
+  screens = EXCLUDED.screens,
+  tracks = EXCLUDED.tracks,
+  landing_page = EXCLUDED.landing_page,
+  referrer = EXCLUDED.referrer,
+  utm_source = EXCLUDED.utm_source,
+  utm_medium = EXCLUDED.utm_medium,
+  utm_campaign = EXCLUDED.utm_campaign,
+  received_at = EXCLUDED.received_at;
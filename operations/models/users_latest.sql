@@ -0,0 +1,66 @@
+-- Build the table "users_latest" holding the latest state of every identified
+-- user: the traits from the table "users", the latest anonymous ID, and when the
+-- user was first and last seen. Only users having events received since the last
+-- run are updated.
+
+CREATE SCHEMA IF NOT EXISTS {{ models }};
+
+CREATE TABLE IF NOT EXISTS {{ models }}.users_latest (
+  id TEXT PRIMARY KEY,
+  anonymous_id TEXT,
+  traits JSONB NOT NULL DEFAULT '{}',
+  first_seen_at TIMESTAMPTZ NOT NULL,
+  last_seen_at TIMESTAMPTZ NOT NULL,
+  received_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS users_latest_received_at
+  ON {{ models }}.users_latest (received_at);
+
+WITH watermark AS (
+  SELECT COALESCE(MAX(received_at), '-infinity') - INTERVAL '{{ lookback }}' AS at
+  FROM {{ models }}.users_latest
+),
+events AS (
+  SELECT user_id, anonymous_id, "timestamp", received_at
+  FROM {{ schema }}.identifies, watermark
+  WHERE user_id IS NOT NULL AND received_at > watermark.at
+  UNION ALL
+  SELECT user_id, anonymous_id, "timestamp", received_at
+  FROM {{ schema }}.tracks, watermark
+  WHERE user_id IS NOT NULL AND received_at > watermark.at
+  UNION ALL
+  SELECT user_id, anonymous_id, "timestamp", received_at
+  FROM {{ schema }}.pages, watermark
+  WHERE user_id IS NOT NULL AND received_at > watermark.at
+  UNION ALL
+  SELECT user_id, anonymous_id, "timestamp", received_at
+  FROM {{ schema }}.screens, watermark
+  WHERE user_id IS NOT NULL AND received_at > watermark.at
+),
+anonymous AS (
+  SELECT DISTINCT ON (user_id) user_id, anonymous_id
+  FROM events
+  WHERE anonymous_id IS NOT NULL
+  ORDER BY user_id, "timestamp" DESC
+),
+seen AS (
+  SELECT user_id, MIN("timestamp") AS first_seen_at,
+    MAX("timestamp") AS last_seen_at, MAX(received_at) AS received_at
+  FROM events
+  GROUP BY user_id
+)
+INSERT INTO {{ models }}.users_latest (id, anonymous_id, traits, first_seen_at,
+  last_seen_at, received_at)
+SELECT seen.user_id, anonymous.anonymous_id,
+  COALESCE(JSONB_STRIP_NULLS(TO_JSONB(users) - 'id' - 'received_at' - 'uuid_ts'), '{}'),
+  seen.first_seen_at, seen.last_seen_at, seen.received_at
+FROM seen
+LEFT JOIN anonymous ON anonymous.user_id = seen.user_id
+LEFT JOIN {{ schema }}.users ON users.id = seen.user_id
+ON CONFLICT (id) DO UPDATE SET
+  anonymous_id = COALESCE(EXCLUDED.anonymous_id, users_latest.anonymous_id),
+  traits = EXCLUDED.traits,
+  first_seen_at = LEAST(EXCLUDED.first_seen_at, users_latest.first_seen_at),
+  last_seen_at = GREATEST(EXCLUDED.last_seen_at, users_latest.last_seen_at),
+  received_at = GREATEST(EXCLUDED.received_at, users_latest.received_at);
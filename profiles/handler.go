@@ -0,0 +1,188 @@
+package profiles
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/helper/rest"
+
+	"github.com/nunchistudio/fragment/auth"
+	"github.com/nunchistudio/fragment/identity"
+)
+
+/*
+response is the HTTP response of the profile API. It follows the design of the
+Blacksmith REST API.
+*/
+type response struct {
+	StatusCode int         `json:"statusCode"`
+	Message    string      `json:"message"`
+	Meta       interface{} `json:"meta,omitempty"`
+	Data       interface{} `json:"data"`
+}
+
+/*
+Handler returns the HTTP handler of the profile API, exposing the endpoints:
+
+	GET /v1/profiles/{id_type}:{id}/traits
+	GET /v1/profiles/{id_type}:{id}/events
+	GET /v1/profiles/{id_type}:{id}/external_ids
+
+Requests must be authenticated with one of the secrets of the store, passed as a
+bearer token:
+
+	Authorization: Bearer <secret>
+
+The handler is meant to be attached to the gateway, which prefixes the endpoints
+with "/api".
+*/
+func (s *Store) Handler() http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		id, resource, ok := parse(s.env.Prefix, req.URL.Path)
+		if !ok {
+			rest.ErrorNotFound(res, req)
+			return
+		}
+
+		if !auth.Valid(auth.Bearer(req), s.env.Secrets) {
+			auth.ErrorUnauthorized(res)
+			return
+		}
+
+		if req.Method != http.MethodGet {
+			rest.ErrorMethodNotAllowed(res, req)
+			return
+		}
+
+		profileID, err := s.Find(id)
+		if err != nil {
+			rest.ErrorInternal(res, req)
+			return
+		} else if profileID == "" {
+			rest.ErrorNotFound(res, req)
+			return
+		}
+
+		body := &response{
+			StatusCode: 200,
+			Message:    "Successful",
+		}
+
+		switch resource {
+		case "traits":
+			traits, err := s.Traits(profileID)
+			if err != nil {
+				rest.ErrorInternal(res, req)
+				return
+			}
+
+			body.Data = map[string]interface{}{
+				"profile_id": profileID,
+				"traits":     traits,
+			}
+
+		case "external_ids":
+			ids, err := s.ExternalIDs(profileID)
+			if err != nil {
+				rest.ErrorInternal(res, req)
+				return
+			}
+
+			body.Data = map[string]interface{}{
+				"profile_id":   profileID,
+				"external_ids": ids,
+			}
+
+		case "events":
+			var limit uint64
+			if l := req.URL.Query().Get("limit"); l != "" {
+				limit, err = strconv.ParseUint(l, 10, 16)
+				if err != nil {
+					badRequest(res, "Limit must be a positive integer", "limit")
+					return
+				}
+			}
+
+			events, err := s.Events(profileID, uint16(limit))
+			if err != nil {
+				rest.ErrorInternal(res, req)
+				return
+			}
+
+			body.Meta = map[string]interface{}{
+				"count": len(events),
+			}
+
+			body.Data = map[string]interface{}{
+				"profile_id": profileID,
+				"events":     events,
+			}
+		}
+
+		r, _ := json.Marshal(body)
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(body.StatusCode)
+		res.Write(r)
+	})
+}
+
+/*
+parse returns the identifier and the resource requested given the path of a
+request. The path can be prefixed by "/api" if the gateway did not strip it.
+*/
+func parse(prefix string, path string) (identity.Identifier, string, bool) {
+	path = strings.TrimPrefix(path, "/api")
+	if !strings.HasPrefix(path, prefix+"/v1/profiles/") {
+		return identity.Identifier{}, "", false
+	}
+
+	path = strings.TrimPrefix(path, prefix+"/v1/profiles/")
+
+	slash := strings.LastIndex(path, "/")
+	if slash < 0 {
+		return identity.Identifier{}, "", false
+	}
+
+	resource := path[slash+1:]
+	switch resource {
+	case "traits", "events", "external_ids":
+	default:
+		return identity.Identifier{}, "", false
+	}
+
+	parts := strings.SplitN(path[:slash], ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return identity.Identifier{}, "", false
+	}
+
+	id := identity.Identifier{
+		Type:  parts[0],
+		Value: parts[1],
+	}
+
+	return id, resource, true
+}
+
+/*
+badRequest handles HTTP 400 error responses for a query parameter.
+*/
+func badRequest(res http.ResponseWriter, message string, param string) {
+	body := errors.Error{
+		StatusCode: 400,
+		Message:    "Bad Request",
+		Validations: []errors.Validation{
+			{
+				Message: message,
+				Path:    []string{"query", param},
+			},
+		},
+	}
+
+	r, _ := json.Marshal(body)
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(body.StatusCode)
+	res.Write(r)
+}
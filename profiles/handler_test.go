@@ -0,0 +1,152 @@
+package profiles
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/nunchistudio/fragment/identity"
+)
+
+func TestParse(t *testing.T) {
+	type want struct {
+		id       identity.Identifier
+		resource string
+		ok       bool
+	}
+
+	tests := []struct {
+		name   string
+		prefix string
+		path   string
+		want   want
+	}{
+		{
+			name: "WithTraits",
+			path: "/v1/profiles/user_id:42/traits",
+			want: want{
+				id:       identity.Identifier{Type: "user_id", Value: "42"},
+				resource: "traits",
+				ok:       true,
+			},
+		},
+		{
+			name: "WithAPIPrefix",
+			path: "/api/v1/profiles/email:johndoe@example.com/external_ids",
+			want: want{
+				id:       identity.Identifier{Type: "email", Value: "johndoe@example.com"},
+				resource: "external_ids",
+				ok:       true,
+			},
+		},
+		{
+			name:   "WithPrefix",
+			prefix: "/cdp",
+			path:   "/api/cdp/v1/profiles/anonymous_id:abc:def/events",
+			want: want{
+				id:       identity.Identifier{Type: "anonymous_id", Value: "abc:def"},
+				resource: "events",
+				ok:       true,
+			},
+		},
+		{
+			name: "WithMissingPrefix",
+			path: "/v1/profiles/user_id:42/traits",
+			want: want{
+				ok: false,
+			},
+			prefix: "/cdp",
+		},
+		{
+			name: "WithUnknownResource",
+			path: "/v1/profiles/user_id:42/audiences",
+			want: want{
+				ok: false,
+			},
+		},
+		{
+			name: "WithoutType",
+			path: "/v1/profiles/42/traits",
+			want: want{
+				ok: false,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, resource, ok := parse(tt.prefix, tt.path)
+			if ok != tt.want.ok {
+				t.Fatalf("parse() ok = %v, want %v", ok, tt.want.ok)
+			}
+
+			if !reflect.DeepEqual(id, tt.want.id) {
+				t.Errorf("parse() id = %v, want %v", id, tt.want.id)
+			}
+
+			if resource != tt.want.resource {
+				t.Errorf("parse() resource = %v, want %v", resource, tt.want.resource)
+			}
+		})
+	}
+}
+
+func TestStore_Handler(t *testing.T) {
+	s := &Store{
+		env: &Options{
+			Secrets: []string{"secret"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{
+			name:   "WithUnknownRoute",
+			method: "GET",
+			path:   "/api/v1/users",
+			want:   404,
+		},
+		{
+			name:   "WithoutToken",
+			method: "GET",
+			path:   "/api/v1/profiles/user_id:42/traits",
+			want:   401,
+		},
+		{
+			name:   "WithUnknownToken",
+			method: "GET",
+			path:   "/api/v1/profiles/user_id:42/traits",
+			token:  "unknown",
+			want:   401,
+		},
+		{
+			name:   "WithWrongMethod",
+			method: "POST",
+			path:   "/api/v1/profiles/user_id:42/traits",
+			token:  "secret",
+			want:   405,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			res := httptest.NewRecorder()
+			s.Handler().ServeHTTP(res, req)
+
+			if res.Code != tt.want {
+				t.Errorf("Store.Handler() status = %v, want %v", res.Code, tt.want)
+			}
+		})
+	}
+}
+
+var _ http.Handler = (&Store{env: &Options{}}).Handler()
@@ -0,0 +1,132 @@
+package profiles
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/nunchistudio/fragment/identity"
+)
+
+/*
+Defaults are the defaults options set for the profile store. When not set, these
+values will automatically be applied.
+*/
+var Defaults = &Options{
+	EventsLimit: 100,
+}
+
+/*
+Options is the options the profile store can take as an input to be configured.
+*/
+type Options struct {
+
+	// DB is the PostgreSQL database connection where the profiles are stored. It
+	// must be the database of the Blacksmith store since the events of a profile
+	// are read from it. The tables are created by the migration "init_profiles".
+	//
+	// Required.
+	DB *sql.DB
+
+	// Identity is the identity graph used to find the profile of an identifier.
+	//
+	// Required.
+	Identity *identity.Graph
+
+	// EventsLimit is the maximum number of events returned at once by the profile
+	// API.
+	//
+	// Defaults to 100.
+	EventsLimit uint16
+
+	// Secrets are the tokens allowed to read the profile API. A client must pass
+	// one of them as a bearer token in the "Authorization" header, since the API
+	// exposes the traits and events of the users.
+	//
+	// Required.
+	Secrets []string
+
+	// Prefix allows to prefix the endpoints exposed by the profile API. Note that
+	// the endpoints are also prefixed with "/api" by the gateway.
+	//
+	// Example: "/cdp"
+	Prefix string
+}
+
+/*
+validate ensures the options passed to initialize the profile store are valid.
+*/
+func (env *Options) validate() error {
+	fail := &errors.Error{
+		Message:     "profiles: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Profiles"},
+		})
+
+		return fail
+	}
+
+	if env.DB == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Database connection must be set",
+			Path:    []string{"Options", "Profiles", "DB"},
+		})
+	}
+
+	if env.Identity == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Identity graph must be set",
+			Path:    []string{"Options", "Profiles", "Identity"},
+		})
+	}
+
+	if len(env.Secrets) == 0 {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Secrets must be set",
+			Path:    []string{"Options", "Profiles", "Secrets"},
+		})
+	}
+
+	for _, secret := range env.Secrets {
+		if secret == "" {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Secrets must not be empty",
+				Path:    []string{"Options", "Profiles", "Secrets"},
+			})
+
+			break
+		}
+	}
+
+	if env.EventsLimit == 0 {
+		env.EventsLimit = Defaults.EventsLimit
+	}
+
+	if env.Prefix != "" {
+		if !strings.HasPrefix(env.Prefix, "/") {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Prefix must start with a '/'",
+				Path:    []string{"Options", "Profiles", "Prefix"},
+			})
+		}
+
+		if strings.HasSuffix(env.Prefix, "/") {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Prefix must not end with a '/'",
+				Path:    []string{"Options", "Profiles", "Prefix"},
+			})
+		}
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
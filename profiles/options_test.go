@@ -0,0 +1,73 @@
+package profiles
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/nunchistudio/fragment/identity"
+)
+
+func TestOptions_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  *Options
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: true,
+		},
+		{
+			name: "WithoutIdentity",
+			fields: &Options{
+				DB: &sql.DB{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithoutSecrets",
+			fields: &Options{
+				DB:       &sql.DB{},
+				Identity: &identity.Graph{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithEmptySecret",
+			fields: &Options{
+				DB:       &sql.DB{},
+				Identity: &identity.Graph{},
+				Secrets:  []string{""},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithRequiredOptions",
+			fields: &Options{
+				DB:       &sql.DB{},
+				Identity: &identity.Graph{},
+				Secrets:  []string{"secret"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithInvalidPrefix",
+			fields: &Options{
+				DB:       &sql.DB{},
+				Identity: &identity.Graph{},
+				Secrets:  []string{"secret"},
+				Prefix:   "cdp/",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+/*
+Package profiles offers a profile store built on top of the identity graph. It
+merges the traits of Identify events over time and exposes them alongside the
+external IDs and the recent events of a profile through a REST API.
+*/
+package profiles
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/helper/logger"
+
+	"github.com/nunchistudio/fragment/identity"
+
+	"github.com/lib/pq"
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Trait is the last known value of a trait of a profile, alongside the timestamp of
+the event which set it.
+*/
+type Trait struct {
+	Value     interface{} `json:"value"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+/*
+Event is an event received for a profile, as registered in the Blacksmith store.
+*/
+type Event struct {
+	ID         string          `json:"id"`
+	Trigger    string          `json:"type"`
+	Context    json.RawMessage `json:"context,omitempty"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	SentAt     *time.Time      `json:"sent_at,omitempty"`
+	ReceivedAt *time.Time      `json:"received_at,omitempty"`
+}
+
+/*
+Store is the profile store.
+*/
+type Store struct {
+	env *Options
+}
+
+/*
+New returns a valid profile store.
+*/
+func New(env *Options) *Store {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &Store{
+		env: env,
+	}
+}
+
+/*
+Identify merges the traits of an Identify event into a profile.
+*/
+func (s *Store) Identify(profileID string, msg analytics.Identify) error {
+	return s.Set(profileID, msg.Traits, msg.Timestamp)
+}
+
+/*
+Set merges traits into a profile. A trait is only updated if the timestamp is
+more recent than the one of the event which last set it, so events received out
+of order do not override more recent values.
+*/
+func (s *Store) Set(profileID string, traits analytics.Traits, at time.Time) error {
+	if len(traits) == 0 {
+		return nil
+	}
+
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+
+	b, err := json.Marshal(traits)
+	if err != nil {
+		return failed("Failed to update traits", err)
+	}
+
+	_, err = s.env.DB.Exec(`
+		INSERT INTO fragment_profiles.traits (profile_id, key, value, updated_at)
+		SELECT $1, t.key, t.value, $3 FROM jsonb_each($2::JSONB) AS t
+		ON CONFLICT (profile_id, key) DO UPDATE
+		SET value = EXCLUDED.value, updated_at = EXCLUDED.updated_at
+		WHERE traits.updated_at <= EXCLUDED.updated_at;
+	`, profileID, string(b), at)
+	if err != nil {
+		return failed("Failed to update traits", err)
+	}
+
+	return nil
+}
+
+/*
+Update stores the traits of a profile which changed, and returns an Identify event
+for them. It returns nil if no trait changed. It is used by the features of
+Fragment computing traits on behalf of the users, such as computed traits.
+*/
+func (s *Store) Update(profileID string, userID string, anonymousID string, traits analytics.Traits, at time.Time) (*analytics.Identify, error) {
+	current, err := s.Traits(profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := analytics.Traits{}
+	for key, value := range traits {
+		if known, exists := current[key]; exists && equal(known.Value, value) {
+			continue
+		}
+
+		changed[key] = value
+	}
+
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+
+	err = s.Set(profileID, changed, at)
+	if err != nil {
+		return nil, err
+	}
+
+	return identifyWith(current, userID, anonymousID, changed, at), nil
+}
+
+/*
+Replay returns an Identify event for traits already stored, without storing them
+again. It is used when a message is retried, so the Identify event returned by
+Update for the failed attempt is not lost.
+*/
+func (s *Store) Replay(profileID string, userID string, anonymousID string, traits analytics.Traits, at time.Time) (*analytics.Identify, error) {
+	if len(traits) == 0 {
+		return nil, nil
+	}
+
+	current, err := s.Traits(profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+
+	replayed := analytics.Traits{}
+	for key, value := range traits {
+		replayed[key] = value
+	}
+
+	return identifyWith(current, userID, anonymousID, replayed, at), nil
+}
+
+/*
+identifyWith returns the Identify event for traits computed on behalf of a user.
+The name and email of the user are included so destinations requiring them, such
+as Mailchimp, can still match the user.
+*/
+func identifyWith(current map[string]*Trait, userID string, anonymousID string, traits analytics.Traits, at time.Time) *analytics.Identify {
+	identify := &analytics.Identify{
+		UserId:       userID,
+		AnonymousId:  anonymousID,
+		Timestamp:    at,
+		Traits:       traits,
+		Integrations: analytics.Integrations{},
+	}
+
+	for _, key := range []string{"email", "firstName", "lastName"} {
+		if known, exists := current[key]; exists {
+			identify.Traits[key] = known.Value
+		}
+	}
+
+	if _, exists := identify.Traits["email"]; !exists {
+		identify.Integrations["Mailchimp"] = false
+	}
+
+	return identify
+}
+
+/*
+Find returns the ID of the profile linked to an identifier. It returns an empty
+string if the identifier is not known.
+*/
+func (s *Store) Find(id identity.Identifier) (string, error) {
+	profileID, err := s.env.Identity.Lookup(id)
+	if err != nil || profileID == "" {
+		return "", err
+	}
+
+	return s.env.Identity.Root(profileID)
+}
+
+/*
+Traits returns the traits of a profile. Traits set on profiles merged into this
+one are also returned, the most recent value winning.
+*/
+func (s *Store) Traits(profileID string) (map[string]*Trait, error) {
+	members, err := s.env.Identity.Members(profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.env.DB.Query(`
+		SELECT DISTINCT ON (key) key, value, updated_at
+		FROM fragment_profiles.traits
+		WHERE profile_id = ANY($1)
+		ORDER BY key, updated_at DESC;
+	`, pq.Array(members))
+	if err != nil {
+		return nil, failed("Failed to find traits", err)
+	}
+
+	defer rows.Close()
+	traits := map[string]*Trait{}
+	for rows.Next() {
+		var key string
+		var value []byte
+		trait := &Trait{}
+		if err := rows.Scan(&key, &value, &trait.UpdatedAt); err != nil {
+			return nil, failed("Failed to find traits", err)
+		}
+
+		if err := json.Unmarshal(value, &trait.Value); err != nil {
+			return nil, failed("Failed to find traits", err)
+		}
+
+		traits[key] = trait
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, failed("Failed to find traits", err)
+	}
+
+	return traits, nil
+}
+
+/*
+ExternalIDs returns the identifiers linked to a profile.
+*/
+func (s *Store) ExternalIDs(profileID string) ([]*identity.Identifier, error) {
+	return s.env.Identity.Identifiers(profileID)
+}
+
+/*
+Events returns the most recent events of a profile, found given its user IDs and
+anonymous IDs. The limit can not exceed the one set in the options.
+*/
+func (s *Store) Events(profileID string, limit uint16) ([]*Event, error) {
+	if limit == 0 || limit > s.env.EventsLimit {
+		limit = s.env.EventsLimit
+	}
+
+	ids, err := s.env.Identity.Identifiers(profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := []string{}
+	anonymousIDs := []string{}
+	for _, id := range ids {
+		switch id.Type {
+		case identity.TypeUserID:
+			userIDs = append(userIDs, id.Value)
+		case identity.TypeAnonymousID:
+			anonymousIDs = append(anonymousIDs, id.Value)
+		}
+	}
+
+	rows, err := s.env.DB.Query(`
+		SELECT id, trigger, context, data, sent_at, received_at
+		FROM blacksmith_store.events
+		WHERE context -> 'message' ->> 'userId' = ANY($1)
+		OR context -> 'message' ->> 'anonymousId' = ANY($2)
+		ORDER BY received_at DESC
+		LIMIT $3;
+	`, pq.Array(userIDs), pq.Array(anonymousIDs), limit)
+	if err != nil {
+		return nil, failed("Failed to find events", err)
+	}
+
+	defer rows.Close()
+	events := []*Event{}
+	for rows.Next() {
+		var ctx, data []byte
+		e := &Event{}
+		if err := rows.Scan(&e.ID, &e.Trigger, &ctx, &data, &e.SentAt, &e.ReceivedAt); err != nil {
+			return nil, failed("Failed to find events", err)
+		}
+
+		e.Context = json.RawMessage(ctx)
+		e.Data = json.RawMessage(data)
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, failed("Failed to find events", err)
+	}
+
+	return events, nil
+}
+
+/*
+equal returns if two trait values are the same once encoded in JSON, so numbers
+decoded from JSON can be compared with computed ones.
+*/
+func equal(a interface{}, b interface{}) bool {
+	x, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+
+	y, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+
+	return string(x) == string(y)
+}
+
+/*
+failed returns a normalized error for the profile store.
+*/
+func failed(message string, err error) error {
+	return &errors.Error{
+		Message: "profiles: " + message,
+		Validations: []errors.Validation{
+			{
+				Message: err.Error(),
+			},
+		},
+	}
+}
@@ -0,0 +1,40 @@
+package profiles
+
+import (
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a    interface{}
+		b    interface{}
+		want bool
+	}{
+		{
+			name: "WithNumbers",
+			a:    float64(3),
+			b:    int64(3),
+			want: true,
+		},
+		{
+			name: "WithDifferentStrings",
+			a:    "shoes",
+			b:    "hats",
+			want: false,
+		},
+		{
+			name: "WithNil",
+			a:    nil,
+			b:    float64(0),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := equal(tt.a, tt.b); got != tt.want {
+				t.Errorf("equal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package sessions
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+)
+
+/*
+Defaults are the defaults options set for the sessions. When not set, these values
+will automatically be applied.
+*/
+var Defaults = &Options{
+	Timeout: 30 * time.Minute,
+}
+
+/*
+Options is the options the sessions can take as an input to be configured.
+*/
+type Options struct {
+
+	// DB is the PostgreSQL database connection where the sessions are stored. The
+	// tables are created by the migration "init_sessions". Storing the sessions in
+	// the database allows to share them across gateway replicas.
+	//
+	// Required.
+	DB *sql.DB
+
+	// Timeout is the duration of inactivity after which a session ends. The next
+	// event of the user starts a new session.
+	//
+	// Defaults to 30 minutes.
+	Timeout time.Duration
+
+	// Events allows to emit a "Session Started" event when a session starts, and a
+	// "Session Ended" event when a session times out.
+	Events bool
+}
+
+/*
+validate ensures the options passed to initialize the sessions are valid.
+*/
+func (env *Options) validate() error {
+	fail := &errors.Error{
+		Message:     "sessions: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Sessions"},
+		})
+
+		return fail
+	}
+
+	if env.DB == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Database connection must be set",
+			Path:    []string{"Options", "Sessions", "DB"},
+		})
+	}
+
+	if env.Timeout < 0 {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Timeout must not be negative",
+			Path:    []string{"Options", "Sessions", "Timeout"},
+		})
+	} else if env.Timeout == 0 {
+		env.Timeout = Defaults.Timeout
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package sessions
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestOptions_validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		fields      *Options
+		wantErr     bool
+		wantTimeout time.Duration
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: true,
+		},
+		{
+			name: "WithDefaultTimeout",
+			fields: &Options{
+				DB: &sql.DB{},
+			},
+			wantErr:     false,
+			wantTimeout: 30 * time.Minute,
+		},
+		{
+			name: "WithTimeout",
+			fields: &Options{
+				DB:      &sql.DB{},
+				Timeout: time.Hour,
+				Events:  true,
+			},
+			wantErr:     false,
+			wantTimeout: time.Hour,
+		},
+		{
+			name: "WithNegativeTimeout",
+			fields: &Options{
+				DB:      &sql.DB{},
+				Timeout: -time.Minute,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && env.Timeout != tt.wantTimeout {
+				t.Errorf("Options.validate() timeout = %v, want %v", env.Timeout, tt.wantTimeout)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+/*
+Package sessions offers server-side sessionization of the "page", "screen", and
+"track" events. Every event is assigned the session of its user, which ends after
+a duration of inactivity. Since the sessions are computed by Fragment and not by
+the clients, events sent from web and server applications share the same session.
+
+The session ID follows the convention of Amplitude: it is the time at which the
+session started, in milliseconds since the Unix epoch. It is attached to the
+context of the events under the key "session_id".
+*/
+package sessions
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/helper/logger"
+
+	"github.com/lib/pq"
+	"github.com/segmentio/ksuid"
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+EventStarted is the name of the "track" event emitted when a session starts.
+*/
+var EventStarted = "Session Started"
+
+/*
+EventEnded is the name of the "track" event emitted when a session ends.
+*/
+var EventEnded = "Session Ended"
+
+/*
+Session is the session of a user.
+*/
+type Session struct {
+	ID          int64
+	UserID      string
+	AnonymousID string
+	StartedAt   time.Time
+	LastSeenAt  time.Time
+}
+
+/*
+Store holds the sessions of the users.
+*/
+type Store struct {
+	env *Options
+}
+
+/*
+New returns a valid store of sessions.
+*/
+func New(env *Options) *Store {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &Store{
+		env: env,
+	}
+}
+
+/*
+keys returns the keys identifying a user, sorted so the locks are always acquired
+in the same order.
+*/
+func keys(userID string, anonymousID string) []string {
+	keys := []string{}
+	if userID != "" {
+		keys = append(keys, "user_id:"+userID)
+	}
+
+	if anonymousID != "" {
+		keys = append(keys, "anonymous_id:"+anonymousID)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+/*
+Attach attaches the session of a user to the context of an event received at a
+given time. A new session is started if the user has no session or if its last
+one timed out. It returns the "Session Started" event to emit if enabled.
+
+A session is shared by the user ID and the anonymous ID of the user, so events
+sent by a server with only the user ID continue the session started by the
+browser of the user.
+*/
+func (s *Store) Attach(at time.Time, ctx *analytics.Context, userID string, anonymousID string) (*analytics.Context, []analytics.Track, error) {
+	if ctx != nil {
+		if _, exists := ctx.Extra["session_id"]; exists {
+			return ctx, nil, nil
+		}
+	}
+
+	session, started, err := s.Touch(at, userID, anonymousID)
+	if err != nil || session == nil {
+		return ctx, nil, err
+	}
+
+	if ctx == nil {
+		ctx = &analytics.Context{}
+	}
+
+	extra := make(map[string]interface{}, len(ctx.Extra)+1)
+	for key, value := range ctx.Extra {
+		extra[key] = value
+	}
+
+	extra["session_id"] = session.ID
+	ctx.Extra = extra
+
+	tracks := []analytics.Track{}
+	if started && s.env.Events {
+		tracks = append(tracks, analytics.Track{
+			Event:       EventStarted,
+			UserId:      userID,
+			AnonymousId: anonymousID,
+			Timestamp:   session.StartedAt,
+			Context:     ctx,
+			Properties: analytics.Properties{
+				"session_id": session.ID,
+			},
+		})
+	}
+
+	return ctx, tracks, nil
+}
+
+/*
+Touch returns the session of a user at a given time, starting a new one if the
+user has no active session. It returns true if the session has been started.
+
+Everything is done within a single transaction. The keys of the user are locked
+so concurrent events of a user across gateway replicas share the same session.
+*/
+func (s *Store) Touch(at time.Time, userID string, anonymousID string) (*Session, bool, error) {
+	keys := keys(userID, anonymousID)
+	if len(keys) == 0 {
+		return nil, false, nil
+	}
+
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+
+	tx, err := s.env.DB.Begin()
+	if err != nil {
+		return nil, false, failed("Failed to find session", err)
+	}
+
+	defer tx.Rollback()
+
+	for _, key := range keys {
+		if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1));`, key); err != nil {
+			return nil, false, failed("Failed to lock session", err)
+		}
+	}
+
+	var id string
+	session := &Session{}
+	err = tx.QueryRow(`
+		SELECT s.id, s.session_id, s.started_at, s.last_seen_at
+		FROM fragment_sessions.keys AS k
+		INNER JOIN fragment_sessions.sessions AS s ON s.id = k.session
+		WHERE k.key = ANY($1) AND s.ended_at IS NULL AND s.last_seen_at >= $2
+		ORDER BY s.last_seen_at DESC
+		LIMIT 1;
+	`, pq.Array(keys), at.Add(-s.env.Timeout)).Scan(&id, &session.ID, &session.StartedAt, &session.LastSeenAt)
+
+	started := false
+	switch {
+	case err == sql.ErrNoRows:
+		started = true
+		id = ksuid.New().String()
+		session.ID = at.UnixNano() / int64(time.Millisecond)
+		session.StartedAt = at
+		session.LastSeenAt = at
+		_, err = tx.Exec(`
+			INSERT INTO fragment_sessions.sessions (id, session_id, user_id, anonymous_id, started_at, last_seen_at)
+			VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, $5);
+		`, id, session.ID, userID, anonymousID, at)
+
+	case err == nil:
+		if at.After(session.LastSeenAt) {
+			session.LastSeenAt = at
+		}
+
+		_, err = tx.Exec(`
+			UPDATE fragment_sessions.sessions SET
+				user_id = COALESCE(NULLIF($2, ''), user_id),
+				anonymous_id = COALESCE(NULLIF($3, ''), anonymous_id),
+				last_seen_at = $4
+			WHERE id = $1;
+		`, id, userID, anonymousID, session.LastSeenAt)
+	}
+
+	if err != nil {
+		return nil, false, failed("Failed to update session", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO fragment_sessions.keys (key, session)
+		SELECT UNNEST($1::TEXT[]), $2
+		ON CONFLICT (key) DO UPDATE SET session = EXCLUDED.session;
+	`, pq.Array(keys), id)
+	if err != nil {
+		return nil, false, failed("Failed to update session", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, false, failed("Failed to update session", err)
+	}
+
+	session.UserID = userID
+	session.AnonymousID = anonymousID
+	return session, started, nil
+}
+
+/*
+Expire ends the sessions which timed out. It returns a "Session Ended" event for
+every session ended if enabled. It is meant to be called periodically.
+*/
+func (s *Store) Expire() ([]analytics.Track, error) {
+	rows, err := s.env.DB.Query(`
+		UPDATE fragment_sessions.sessions SET ended_at = last_seen_at + make_interval(secs => $2)
+		WHERE ended_at IS NULL AND last_seen_at < $1
+		RETURNING session_id, COALESCE(user_id, ''), COALESCE(anonymous_id, ''), started_at, last_seen_at;
+	`, time.Now().UTC().Add(-s.env.Timeout), s.env.Timeout.Seconds())
+	if err != nil {
+		return nil, failed("Failed to end sessions", err)
+	}
+
+	defer rows.Close()
+	tracks := []analytics.Track{}
+	for rows.Next() {
+		session := &Session{}
+		if err := rows.Scan(&session.ID, &session.UserID, &session.AnonymousID, &session.StartedAt, &session.LastSeenAt); err != nil {
+			return nil, failed("Failed to end sessions", err)
+		}
+
+		if s.env.Events {
+			tracks = append(tracks, ended(session, s.env.Timeout))
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, failed("Failed to end sessions", err)
+	}
+
+	return tracks, nil
+}
+
+/*
+ended returns the "Session Ended" event of a session which timed out. The event
+is dated at the time the session timed out.
+*/
+func ended(session *Session, timeout time.Duration) analytics.Track {
+	return analytics.Track{
+		Event:       EventEnded,
+		UserId:      session.UserID,
+		AnonymousId: session.AnonymousID,
+		Timestamp:   session.LastSeenAt.Add(timeout),
+		Context: &analytics.Context{
+			Extra: map[string]interface{}{
+				"session_id": session.ID,
+			},
+		},
+		Properties: analytics.Properties{
+			"session_id": session.ID,
+			"duration":   session.LastSeenAt.Sub(session.StartedAt).Seconds(),
+		},
+	}
+}
+
+/*
+failed returns a normalized error for the sessions.
+*/
+func failed(message string, err error) error {
+	return &errors.Error{
+		Message: "sessions: " + message,
+		Validations: []errors.Validation{
+			{
+				Message: err.Error(),
+			},
+		},
+	}
+}
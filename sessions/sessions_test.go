@@ -0,0 +1,62 @@
+package sessions
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		userID      string
+		anonymousID string
+		want        []string
+	}{
+		{
+			name:        "WithBothIDs",
+			userID:      "42",
+			anonymousID: "a1",
+			want:        []string{"anonymous_id:a1", "user_id:42"},
+		},
+		{
+			name:   "WithUserID",
+			userID: "42",
+			want:   []string{"user_id:42"},
+		},
+		{
+			name: "WithoutIDs",
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keys(tt.userID, tt.anonymousID); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnded(t *testing.T) {
+	start := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	session := &Session{
+		ID:         start.UnixNano() / int64(time.Millisecond),
+		UserID:     "42",
+		StartedAt:  start,
+		LastSeenAt: start.Add(10 * time.Minute),
+	}
+
+	track := ended(session, 30*time.Minute)
+	if track.Event != EventEnded {
+		t.Errorf("ended() event = %v, want %v", track.Event, EventEnded)
+	}
+
+	if !track.Timestamp.Equal(start.Add(40 * time.Minute)) {
+		t.Errorf("ended() timestamp = %v, want %v", track.Timestamp, start.Add(40*time.Minute))
+	}
+
+	if track.Properties["duration"] != float64(600) {
+		t.Errorf("ended() duration = %v, want %v", track.Properties["duration"], 600)
+	}
+}
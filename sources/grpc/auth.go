@@ -0,0 +1,60 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/nunchistudio/fragment/auth"
+
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+/*
+errUnauthenticated is returned when a request or a stream does not hold a valid
+write key.
+*/
+var errUnauthenticated = status.Error(codes.Unauthenticated, "Unauthorized")
+
+/*
+authenticate returns if the metadata of a request holds a valid write key.
+*/
+func authenticate(env *Options, ctx context.Context) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		if auth.Valid(auth.Token(value), env.WriteKeys) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+unaryAuth returns the interceptor rejecting the requests without a valid write
+key.
+*/
+func unaryAuth(env *Options) grpcgo.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpcgo.UnaryServerInfo, handler grpcgo.UnaryHandler) (interface{}, error) {
+		if !authenticate(env, ctx) {
+			return nil, errUnauthenticated
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+/*
+streamAuth returns the interceptor rejecting the streams without a valid write
+key. The key is only checked when the stream is opened.
+*/
+func streamAuth(env *Options) grpcgo.StreamServerInterceptor {
+	return func(srv interface{}, stream grpcgo.ServerStream, info *grpcgo.StreamServerInfo, handler grpcgo.StreamHandler) error {
+		if !authenticate(env, stream.Context()) {
+			return errUnauthenticated
+		}
+
+		return handler(srv, stream)
+	}
+}
@@ -0,0 +1,107 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/fragment/sources/grpc/ingestpb"
+	"github.com/nunchistudio/fragment/sources/rest"
+
+	"github.com/sirupsen/logrus"
+
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestAuth(t *testing.T) {
+	env := &Options{
+		WriteKeys:      []string{"key"},
+		MaxMessageSize: Defaults.MaxMessageSize,
+		REST:           &rest.Options{},
+	}
+
+	events := make(chan *source.Event, 4)
+	listener := bufconn.Listen(1 << 20)
+	srv := grpcgo.NewServer(serverOptions(env)...)
+	ingestpb.RegisterIngestServer(srv, &server{
+		env:    env,
+		logger: logrus.New(),
+		events: events,
+	})
+
+	go srv.Serve(listener)
+	defer srv.Stop()
+
+	conn, err := grpcgo.DialContext(context.Background(), "bufconn",
+		grpcgo.WithInsecure(),
+		grpcgo.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+	)
+	if err != nil {
+		t.Fatalf("grpc.Dial() error = %v", err)
+	}
+
+	defer conn.Close()
+	client := ingestpb.NewIngestClient(conn)
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          codes.Code
+	}{
+		{
+			name:          "WithValidKey",
+			authorization: "Bearer key",
+			want:          codes.OK,
+		},
+		{
+			name:          "WithInvalidKey",
+			authorization: "Bearer other",
+			want:          codes.Unauthenticated,
+		},
+		{
+			name: "WithoutKey",
+			want: codes.Unauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", tt.authorization)
+			}
+
+			_, err := client.Track(ctx, &ingestpb.Track{
+				UserId: "user",
+				Event:  "Order Completed",
+			})
+			if status.Code(err) != tt.want {
+				t.Errorf("IngestClient.Track() error = %v, want %v", err, tt.want)
+			}
+
+			stream, err := client.Stream(ctx)
+			if err != nil {
+				t.Fatalf("IngestClient.Stream() error = %v", err)
+			}
+
+			stream.Send(&ingestpb.Message{Type: &ingestpb.Message_Track{Track: &ingestpb.Track{MessageId: "m", UserId: "user", Event: "Order Completed"}}})
+			_, err = stream.Recv()
+			if status.Code(err) != tt.want {
+				t.Errorf("IngestClient.Stream() error = %v, want %v", err, tt.want)
+			}
+
+			stream.CloseSend()
+		})
+	}
+
+	if len(events) != 2 {
+		t.Errorf("server events = %v, want %v", len(events), 2)
+	}
+}
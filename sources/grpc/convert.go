@@ -0,0 +1,211 @@
+package grpc
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/fragment/sources/grpc/ingestpb"
+	"github.com/nunchistudio/fragment/sources/rest"
+
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+convert converts a message received by the gRPC server into its sub-event, using
+the same validation and flows as the source "rest".
+*/
+func convert(env *rest.Options, msg *ingestpb.Message) (*source.SubEvent, *errors.Error) {
+	var m analytics.Message
+	var err error
+	switch {
+	case msg.GetIdentify() != nil:
+		m, err = identify(msg.GetIdentify())
+	case msg.GetTrack() != nil:
+		m, err = track(msg.GetTrack())
+	case msg.GetPage() != nil:
+		m, err = page(msg.GetPage())
+	case msg.GetScreen() != nil:
+		m, err = screen(msg.GetScreen())
+	case msg.GetGroup() != nil:
+		m, err = group(msg.GetGroup())
+	case msg.GetAlias() != nil:
+		m, err = alias(msg.GetAlias())
+	default:
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+			Validations: []errors.Validation{
+				{
+					Message: "type must be set",
+					Path:    []string{"analytics", "Message"},
+				},
+			},
+		}
+	}
+
+	if err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+			Validations: []errors.Validation{
+				{
+					Message: err.Error(),
+					Path:    []string{"analytics", "Message", "context"},
+				},
+			},
+		}
+	}
+
+	return rest.Marshal(env, m)
+}
+
+/*
+identify converts an Identify message.
+*/
+func identify(m *ingestpb.Identify) (analytics.Identify, error) {
+	ctx, err := convertContext(m.GetContext())
+	return analytics.Identify{
+		MessageId:    m.GetMessageId(),
+		AnonymousId:  m.GetAnonymousId(),
+		UserId:       m.GetUserId(),
+		Traits:       analytics.Traits(fields(m.GetTraits())),
+		Context:      ctx,
+		Integrations: analytics.Integrations(fields(m.GetIntegrations())),
+		Timestamp:    timestamp(m.GetTimestamp()),
+	}, err
+}
+
+/*
+track converts a Track message.
+*/
+func track(m *ingestpb.Track) (analytics.Track, error) {
+	ctx, err := convertContext(m.GetContext())
+	return analytics.Track{
+		MessageId:    m.GetMessageId(),
+		AnonymousId:  m.GetAnonymousId(),
+		UserId:       m.GetUserId(),
+		Event:        m.GetEvent(),
+		Properties:   analytics.Properties(fields(m.GetProperties())),
+		Context:      ctx,
+		Integrations: analytics.Integrations(fields(m.GetIntegrations())),
+		Timestamp:    timestamp(m.GetTimestamp()),
+	}, err
+}
+
+/*
+page converts a Page message.
+*/
+func page(m *ingestpb.Page) (analytics.Page, error) {
+	ctx, err := convertContext(m.GetContext())
+	return analytics.Page{
+		MessageId:    m.GetMessageId(),
+		AnonymousId:  m.GetAnonymousId(),
+		UserId:       m.GetUserId(),
+		Name:         m.GetName(),
+		Properties:   analytics.Properties(fields(m.GetProperties())),
+		Context:      ctx,
+		Integrations: analytics.Integrations(fields(m.GetIntegrations())),
+		Timestamp:    timestamp(m.GetTimestamp()),
+	}, err
+}
+
+/*
+screen converts a Screen message.
+*/
+func screen(m *ingestpb.Screen) (analytics.Screen, error) {
+	ctx, err := convertContext(m.GetContext())
+	return analytics.Screen{
+		MessageId:    m.GetMessageId(),
+		AnonymousId:  m.GetAnonymousId(),
+		UserId:       m.GetUserId(),
+		Name:         m.GetName(),
+		Properties:   analytics.Properties(fields(m.GetProperties())),
+		Context:      ctx,
+		Integrations: analytics.Integrations(fields(m.GetIntegrations())),
+		Timestamp:    timestamp(m.GetTimestamp()),
+	}, err
+}
+
+/*
+group converts a Group message.
+*/
+func group(m *ingestpb.Group) (analytics.Group, error) {
+	ctx, err := convertContext(m.GetContext())
+	return analytics.Group{
+		MessageId:    m.GetMessageId(),
+		AnonymousId:  m.GetAnonymousId(),
+		UserId:       m.GetUserId(),
+		GroupId:      m.GetGroupId(),
+		Traits:       analytics.Traits(fields(m.GetTraits())),
+		Context:      ctx,
+		Integrations: analytics.Integrations(fields(m.GetIntegrations())),
+		Timestamp:    timestamp(m.GetTimestamp()),
+	}, err
+}
+
+/*
+alias converts an Alias message.
+*/
+func alias(m *ingestpb.Alias) (analytics.Alias, error) {
+	ctx, err := convertContext(m.GetContext())
+	return analytics.Alias{
+		MessageId:    m.GetMessageId(),
+		UserId:       m.GetUserId(),
+		PreviousId:   m.GetPreviousId(),
+		Context:      ctx,
+		Integrations: analytics.Integrations(fields(m.GetIntegrations())),
+		Timestamp:    timestamp(m.GetTimestamp()),
+	}, err
+}
+
+/*
+fields returns the fields of a struct as a map, or nil if the struct is not set
+so the message is handled the same way as a JSON one without the key.
+*/
+func fields(s *structpb.Struct) map[string]interface{} {
+	if s == nil {
+		return nil
+	}
+
+	return s.AsMap()
+}
+
+/*
+convertContext converts the context of a message. It is converted through JSON so the
+keys follow the Segment spec, such as "userAgent" or "campaign".
+*/
+func convertContext(s *structpb.Struct) (*analytics.Context, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(s.AsMap())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := &analytics.Context{}
+	if err := json.Unmarshal(b, ctx); err != nil {
+		return nil, err
+	}
+
+	return ctx, nil
+}
+
+/*
+timestamp converts the timestamp of a message. The zero time is returned if not
+set, so the current time is applied when validating the message.
+*/
+func timestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+
+	return ts.AsTime()
+}
@@ -0,0 +1,95 @@
+package grpc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nunchistudio/fragment/sources/grpc/ingestpb"
+	"github.com/nunchistudio/fragment/sources/rest"
+
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestConvert(t *testing.T) {
+	properties, _ := structpb.NewStruct(map[string]interface{}{
+		"revenue": 1250,
+	})
+
+	ctx, _ := structpb.NewStruct(map[string]interface{}{
+		"ip":        "8.8.8.8",
+		"userAgent": "Mozilla/5.0",
+	})
+
+	tests := []struct {
+		name     string
+		message  *ingestpb.Message
+		wantType string
+		wantErr  bool
+	}{
+		{
+			name: "WithTrack",
+			message: &ingestpb.Message{Type: &ingestpb.Message_Track{Track: &ingestpb.Track{
+				UserId:     "user",
+				Event:      "Order Completed",
+				Properties: properties,
+				Context:    ctx,
+				Timestamp:  timestamppb.New(time.Now()),
+			}}},
+			wantType: "track",
+		},
+		{
+			name: "WithTrackWithoutEvent",
+			message: &ingestpb.Message{Type: &ingestpb.Message_Track{Track: &ingestpb.Track{
+				UserId: "user",
+			}}},
+			wantErr: true,
+		},
+		{
+			name: "WithAlias",
+			message: &ingestpb.Message{Type: &ingestpb.Message_Alias{Alias: &ingestpb.Alias{
+				UserId:     "user",
+				PreviousId: "anonymous",
+			}}},
+			wantType: "alias",
+		},
+		{
+			name:    "WithoutType",
+			message: &ingestpb.Message{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subevent, fail := convert(&rest.Options{}, tt.message)
+			if (fail != nil) != tt.wantErr {
+				t.Fatalf("convert() error = %v, wantErr %v", fail, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if subevent.Trigger != tt.wantType {
+				t.Errorf("convert() trigger = %v, want %v", subevent.Trigger, tt.wantType)
+			}
+
+			var c struct {
+				IP        string `json:"ip"`
+				UserAgent string `json:"userAgent"`
+				Message   struct {
+					Type string `json:"type"`
+				} `json:"message"`
+			}
+			json.Unmarshal(subevent.Context, &c)
+			if c.Message.Type != tt.wantType {
+				t.Errorf("convert() context message type = %v, want %v", c.Message.Type, tt.wantType)
+			}
+
+			if tt.wantType == "track" && (c.IP != "8.8.8.8" || c.UserAgent != "Mozilla/5.0") {
+				t.Errorf("convert() context = %s", subevent.Context)
+			}
+		})
+	}
+}
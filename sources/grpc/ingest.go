@@ -0,0 +1,115 @@
+package grpc
+
+import (
+	"io"
+	"net"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/fragment/sources/grpc/ingestpb"
+
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+/*
+Ingest implements the Blacksmith source.Trigger interface for the trigger
+"ingest". It runs the gRPC server for as long as the gateway is running.
+*/
+type Ingest struct {
+	env *Options
+}
+
+/*
+String returns the string representation of the trigger Ingest.
+*/
+func (t Ingest) String() string {
+	return "ingest"
+}
+
+/*
+Mode allows to register the trigger as a CDC. This means the Extract function is
+run once by the gateway, and sends events whenever a request is received by the
+gRPC server.
+*/
+func (t Ingest) Mode() *source.Mode {
+	return &source.Mode{
+		Mode: source.ModeCDC,
+	}
+}
+
+/*
+Extract is the function being run when the gateway starts. It is in charge of
+the "E" in the ETL process: Extract the data from the source.
+
+The gRPC server is gracefully stopped when the gateway is shutting down, so the
+requests being processed are not lost. Streams still open after the shutdown
+timeout are closed.
+*/
+func (t Ingest) Extract(tk *source.Toolkit, notifier *source.Notifier) {
+	listener, err := net.Listen("tcp", t.env.Address)
+	if err != nil {
+		notifier.Error <- err
+		<-notifier.IsShuttingDown
+		notifier.Done <- true
+		return
+	}
+
+	srv := grpcgo.NewServer(serverOptions(t.env)...)
+	ingestpb.RegisterIngestServer(srv, &server{
+		env:    t.env,
+		logger: tk.Logger,
+		events: notifier.Event,
+	})
+
+	go func() {
+		if err := srv.Serve(listener); err != nil {
+			notifier.Error <- err
+		}
+	}()
+
+	<-notifier.IsShuttingDown
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(t.env.ShutdownTimeout):
+		srv.Stop()
+	}
+
+	notifier.Done <- true
+}
+
+/*
+serverOptions returns the options of the gRPC server, authenticating every
+request and stream, and using TLS when configured.
+*/
+func serverOptions(env *Options) []grpcgo.ServerOption {
+	opts := []grpcgo.ServerOption{
+		grpcgo.MaxRecvMsgSize(env.MaxMessageSize),
+		grpcgo.UnaryInterceptor(unaryAuth(env)),
+		grpcgo.StreamInterceptor(streamAuth(env)),
+	}
+
+	if env.TLS != nil {
+		opts = append(opts, grpcgo.Creds(credentials.NewTLS(env.TLS)))
+	}
+
+	return opts
+}
+
+/*
+ignoreEOF returns nil if the error is the end of a stream.
+*/
+func ignoreEOF(err error) error {
+	if err == io.EOF {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,392 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package ingestpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// IngestClient is the client API for Ingest service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type IngestClient interface {
+	Identify(ctx context.Context, in *Identify, opts ...grpc.CallOption) (*Response, error)
+	Track(ctx context.Context, in *Track, opts ...grpc.CallOption) (*Response, error)
+	Page(ctx context.Context, in *Page, opts ...grpc.CallOption) (*Response, error)
+	Screen(ctx context.Context, in *Screen, opts ...grpc.CallOption) (*Response, error)
+	Group(ctx context.Context, in *Group, opts ...grpc.CallOption) (*Response, error)
+	Alias(ctx context.Context, in *Alias, opts ...grpc.CallOption) (*Response, error)
+	Batch(ctx context.Context, in *Batch, opts ...grpc.CallOption) (*Response, error)
+	// Stream receives messages on a long-lived stream. Every message is
+	// acknowledged with a Result holding its message ID, once accepted or
+	// rejected.
+	Stream(ctx context.Context, opts ...grpc.CallOption) (Ingest_StreamClient, error)
+}
+
+type ingestClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIngestClient(cc grpc.ClientConnInterface) IngestClient {
+	return &ingestClient{cc}
+}
+
+func (c *ingestClient) Identify(ctx context.Context, in *Identify, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/fragment.ingest.v1.Ingest/Identify", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingestClient) Track(ctx context.Context, in *Track, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/fragment.ingest.v1.Ingest/Track", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingestClient) Page(ctx context.Context, in *Page, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/fragment.ingest.v1.Ingest/Page", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingestClient) Screen(ctx context.Context, in *Screen, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/fragment.ingest.v1.Ingest/Screen", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingestClient) Group(ctx context.Context, in *Group, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/fragment.ingest.v1.Ingest/Group", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingestClient) Alias(ctx context.Context, in *Alias, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/fragment.ingest.v1.Ingest/Alias", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingestClient) Batch(ctx context.Context, in *Batch, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/fragment.ingest.v1.Ingest/Batch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingestClient) Stream(ctx context.Context, opts ...grpc.CallOption) (Ingest_StreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Ingest_ServiceDesc.Streams[0], "/fragment.ingest.v1.Ingest/Stream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ingestStreamClient{stream}
+	return x, nil
+}
+
+type Ingest_StreamClient interface {
+	Send(*Message) error
+	Recv() (*Result, error)
+	grpc.ClientStream
+}
+
+type ingestStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *ingestStreamClient) Send(m *Message) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *ingestStreamClient) Recv() (*Result, error) {
+	m := new(Result)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// IngestServer is the server API for Ingest service.
+// All implementations must embed UnimplementedIngestServer
+// for forward compatibility
+type IngestServer interface {
+	Identify(context.Context, *Identify) (*Response, error)
+	Track(context.Context, *Track) (*Response, error)
+	Page(context.Context, *Page) (*Response, error)
+	Screen(context.Context, *Screen) (*Response, error)
+	Group(context.Context, *Group) (*Response, error)
+	Alias(context.Context, *Alias) (*Response, error)
+	Batch(context.Context, *Batch) (*Response, error)
+	// Stream receives messages on a long-lived stream. Every message is
+	// acknowledged with a Result holding its message ID, once accepted or
+	// rejected.
+	Stream(Ingest_StreamServer) error
+	mustEmbedUnimplementedIngestServer()
+}
+
+// UnimplementedIngestServer must be embedded to have forward compatible implementations.
+type UnimplementedIngestServer struct {
+}
+
+func (UnimplementedIngestServer) Identify(context.Context, *Identify) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Identify not implemented")
+}
+func (UnimplementedIngestServer) Track(context.Context, *Track) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Track not implemented")
+}
+func (UnimplementedIngestServer) Page(context.Context, *Page) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Page not implemented")
+}
+func (UnimplementedIngestServer) Screen(context.Context, *Screen) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Screen not implemented")
+}
+func (UnimplementedIngestServer) Group(context.Context, *Group) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Group not implemented")
+}
+func (UnimplementedIngestServer) Alias(context.Context, *Alias) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Alias not implemented")
+}
+func (UnimplementedIngestServer) Batch(context.Context, *Batch) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Batch not implemented")
+}
+func (UnimplementedIngestServer) Stream(Ingest_StreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method Stream not implemented")
+}
+func (UnimplementedIngestServer) mustEmbedUnimplementedIngestServer() {}
+
+// UnsafeIngestServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IngestServer will
+// result in compilation errors.
+type UnsafeIngestServer interface {
+	mustEmbedUnimplementedIngestServer()
+}
+
+func RegisterIngestServer(s grpc.ServiceRegistrar, srv IngestServer) {
+	s.RegisterService(&Ingest_ServiceDesc, srv)
+}
+
+func _Ingest_Identify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Identify)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestServer).Identify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fragment.ingest.v1.Ingest/Identify",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestServer).Identify(ctx, req.(*Identify))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ingest_Track_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Track)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestServer).Track(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fragment.ingest.v1.Ingest/Track",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestServer).Track(ctx, req.(*Track))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ingest_Page_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Page)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestServer).Page(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fragment.ingest.v1.Ingest/Page",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestServer).Page(ctx, req.(*Page))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ingest_Screen_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Screen)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestServer).Screen(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fragment.ingest.v1.Ingest/Screen",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestServer).Screen(ctx, req.(*Screen))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ingest_Group_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Group)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestServer).Group(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fragment.ingest.v1.Ingest/Group",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestServer).Group(ctx, req.(*Group))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ingest_Alias_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Alias)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestServer).Alias(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fragment.ingest.v1.Ingest/Alias",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestServer).Alias(ctx, req.(*Alias))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ingest_Batch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Batch)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngestServer).Batch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fragment.ingest.v1.Ingest/Batch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngestServer).Batch(ctx, req.(*Batch))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ingest_Stream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IngestServer).Stream(&ingestStreamServer{stream})
+}
+
+type Ingest_StreamServer interface {
+	Send(*Result) error
+	Recv() (*Message, error)
+	grpc.ServerStream
+}
+
+type ingestStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *ingestStreamServer) Send(m *Result) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *ingestStreamServer) Recv() (*Message, error) {
+	m := new(Message)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Ingest_ServiceDesc is the grpc.ServiceDesc for Ingest service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Ingest_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fragment.ingest.v1.Ingest",
+	HandlerType: (*IngestServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Identify",
+			Handler:    _Ingest_Identify_Handler,
+		},
+		{
+			MethodName: "Track",
+			Handler:    _Ingest_Track_Handler,
+		},
+		{
+			MethodName: "Page",
+			Handler:    _Ingest_Page_Handler,
+		},
+		{
+			MethodName: "Screen",
+			Handler:    _Ingest_Screen_Handler,
+		},
+		{
+			MethodName: "Group",
+			Handler:    _Ingest_Group_Handler,
+		},
+		{
+			MethodName: "Alias",
+			Handler:    _Ingest_Alias_Handler,
+		},
+		{
+			MethodName: "Batch",
+			Handler:    _Ingest_Batch_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Stream",
+			Handler:       _Ingest_Stream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "ingest.proto",
+}
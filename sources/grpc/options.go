@@ -0,0 +1,143 @@
+package grpc
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/nunchistudio/fragment/sources/rest"
+)
+
+/*
+Defaults are the defaults options set for the source. When not set, these values
+will automatically be applied.
+*/
+var Defaults = &Options{
+	Address:         ":9092",
+	MaxMessageSize:  4 << 20,
+	ShutdownTimeout: 10 * time.Second,
+}
+
+/*
+Options is the options the source can take as an input to be configured.
+*/
+type Options struct {
+
+	// Address is the TCP address the gRPC server listens on.
+	//
+	// Defaults to ":9092", since ":9090" and ":9091" are used by the gateway and
+	// the scheduler.
+	Address string
+
+	// MaxMessageSize is the maximum size in bytes of a request, such as a batch.
+	//
+	// Defaults to 4MB.
+	MaxMessageSize int
+
+	// WriteKeys are the keys a client can authenticate with. The key is passed in
+	// the metadata "authorization" with the "Bearer" scheme, for every request and
+	// when opening a stream. They should be the same keys as the ones of the
+	// WebSocket endpoint of the source "rest".
+	//
+	// Required.
+	WriteKeys []string
+
+	// TLS is the TLS configuration of the gRPC server, holding its certificates.
+	// The write keys are sent in clear text when not set, so it should only be
+	// left empty when the TLS is terminated by a proxy in front of the server.
+	TLS *tls.Config
+
+	// ShutdownTimeout is the time given to the requests and streams to complete
+	// when the gateway is shutting down.
+	//
+	// Defaults to 10 seconds.
+	ShutdownTimeout time.Duration
+
+	// REST is the options of the source "rest" used to validate, enrich, and send
+	// the messages to the destinations. It should be the same options as the ones
+	// passed to the source "rest", so both sources share the same stores.
+	//
+	// Required.
+	REST *rest.Options
+}
+
+/*
+validate ensures the options passed to initialize the source are valid.
+*/
+func (env *Options) validate() error {
+	fail := &errors.Error{
+		Message:     "source/grpc: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Sources", "grpc"},
+		})
+
+		return fail
+	}
+
+	if env.Address == "" {
+		env.Address = Defaults.Address
+	}
+
+	if _, _, err := net.SplitHostPort(env.Address); err != nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Address must be a valid TCP address",
+			Path:    []string{"Options", "Sources", "grpc", "Address"},
+		})
+	}
+
+	if len(env.WriteKeys) == 0 {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Write keys must be set",
+			Path:    []string{"Options", "Sources", "grpc", "WriteKeys"},
+		})
+	}
+
+	for _, key := range env.WriteKeys {
+		if key == "" {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Write keys must not be empty",
+				Path:    []string{"Options", "Sources", "grpc", "WriteKeys"},
+			})
+
+			break
+		}
+	}
+
+	if env.MaxMessageSize < 0 {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Max message size must not be negative",
+			Path:    []string{"Options", "Sources", "grpc", "MaxMessageSize"},
+		})
+	} else if env.MaxMessageSize == 0 {
+		env.MaxMessageSize = Defaults.MaxMessageSize
+	}
+
+	if env.ShutdownTimeout < 0 {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Shutdown timeout must not be negative",
+			Path:    []string{"Options", "Sources", "grpc", "ShutdownTimeout"},
+		})
+	} else if env.ShutdownTimeout == 0 {
+		env.ShutdownTimeout = Defaults.ShutdownTimeout
+	}
+
+	if env.REST == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options of the source 'rest' must be set",
+			Path:    []string{"Options", "Sources", "grpc", "REST"},
+		})
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/fragment/sources/rest"
+)
+
+func TestOptions_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  *Options
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: true,
+		},
+		{
+			name: "WithREST",
+			fields: &Options{
+				WriteKeys: []string{"key"},
+				REST:      &rest.Options{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithoutWriteKeys",
+			fields: &Options{
+				REST: &rest.Options{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithEmptyWriteKey",
+			fields: &Options{
+				WriteKeys: []string{""},
+				REST:      &rest.Options{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithAddress",
+			fields: &Options{
+				Address:   "127.0.0.1:50051",
+				WriteKeys: []string{"key"},
+				REST:      &rest.Options{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithInvalidAddress",
+			fields: &Options{
+				Address:   "50051",
+				WriteKeys: []string{"key"},
+				REST:      &rest.Options{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithNegativeMaxMessageSize",
+			fields: &Options{
+				MaxMessageSize: -1,
+				WriteKeys:      []string{"key"},
+				REST:           &rest.Options{},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
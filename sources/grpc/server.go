@@ -0,0 +1,233 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/fragment/sources/grpc/ingestpb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/sirupsen/logrus"
+)
+
+/*
+server implements the gRPC service Ingest. Every request accepted is sent as an
+event to the gateway.
+*/
+type server struct {
+	ingestpb.UnimplementedIngestServer
+
+	env    *Options
+	logger *logrus.Logger
+	events chan<- *source.Event
+}
+
+/*
+Identify handles the RPC Identify.
+*/
+func (s *server) Identify(ctx context.Context, m *ingestpb.Identify) (*ingestpb.Response, error) {
+	return s.single(ctx, &ingestpb.Message{Type: &ingestpb.Message_Identify{Identify: m}})
+}
+
+/*
+Track handles the RPC Track.
+*/
+func (s *server) Track(ctx context.Context, m *ingestpb.Track) (*ingestpb.Response, error) {
+	return s.single(ctx, &ingestpb.Message{Type: &ingestpb.Message_Track{Track: m}})
+}
+
+/*
+Page handles the RPC Page.
+*/
+func (s *server) Page(ctx context.Context, m *ingestpb.Page) (*ingestpb.Response, error) {
+	return s.single(ctx, &ingestpb.Message{Type: &ingestpb.Message_Page{Page: m}})
+}
+
+/*
+Screen handles the RPC Screen.
+*/
+func (s *server) Screen(ctx context.Context, m *ingestpb.Screen) (*ingestpb.Response, error) {
+	return s.single(ctx, &ingestpb.Message{Type: &ingestpb.Message_Screen{Screen: m}})
+}
+
+/*
+Group handles the RPC Group.
+*/
+func (s *server) Group(ctx context.Context, m *ingestpb.Group) (*ingestpb.Response, error) {
+	return s.single(ctx, &ingestpb.Message{Type: &ingestpb.Message_Group{Group: m}})
+}
+
+/*
+Alias handles the RPC Alias.
+*/
+func (s *server) Alias(ctx context.Context, m *ingestpb.Alias) (*ingestpb.Response, error) {
+	return s.single(ctx, &ingestpb.Message{Type: &ingestpb.Message_Alias{Alias: m}})
+}
+
+/*
+Batch handles the RPC Batch. Invalid messages are logged and skipped, the same
+way as the source "rest" does.
+*/
+func (s *server) Batch(ctx context.Context, b *ingestpb.Batch) (*ingestpb.Response, error) {
+	subEvents := []*source.SubEvent{}
+	for _, m := range b.GetBatch() {
+		subevent, fail := convert(s.env.REST, m)
+		if fail != nil {
+			s.logger.Error(fail)
+			continue
+		}
+
+		subEvents = append(subEvents, subevent)
+	}
+
+	c, err := batchContext(b.GetContext())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.emit(ctx, c, b.GetTimestamp(), subEvents); err != nil {
+		return nil, err
+	}
+
+	return &ingestpb.Response{Success: true}, nil
+}
+
+/*
+Stream handles the RPC Stream. Every message received is sent as its own event,
+and acknowledged with its message ID. The stream ends when the client closes it.
+*/
+func (s *server) Stream(stream ingestpb.Ingest_StreamServer) error {
+	for {
+		m, err := stream.Recv()
+		if err != nil {
+			return ignoreEOF(err)
+		}
+
+		result := &ingestpb.Result{
+			MessageId: messageID(m),
+			Success:   true,
+		}
+
+		subevent, fail := convert(s.env.REST, m)
+		if fail != nil {
+			result.Success = false
+			result.Error = describe(fail)
+		} else if err := s.emit(stream.Context(), nil, nil, []*source.SubEvent{subevent}); err != nil {
+			return err
+		}
+
+		if err := stream.Send(result); err != nil {
+			return err
+		}
+	}
+}
+
+/*
+single validates a single message and sends it as an event to the gateway. An
+invalid message is rejected with its validation errors.
+*/
+func (s *server) single(ctx context.Context, m *ingestpb.Message) (*ingestpb.Response, error) {
+	subevent, fail := convert(s.env.REST, m)
+	if fail != nil {
+		return nil, toStatus(fail)
+	}
+
+	if err := s.emit(ctx, nil, nil, []*source.SubEvent{subevent}); err != nil {
+		return nil, err
+	}
+
+	return &ingestpb.Response{Success: true}, nil
+}
+
+/*
+emit sends an event holding the sub-events to the gateway. It blocks until the
+gateway received the event, so clients are slowed down when the gateway can not
+keep up.
+*/
+func (s *server) emit(ctx context.Context, c []byte, ts *timestamppb.Timestamp, subEvents []*source.SubEvent) error {
+	sentAt := time.Now().UTC()
+	if ts != nil {
+		sentAt = ts.AsTime()
+	}
+
+	event := &source.Event{
+		Version:   "v1.0",
+		Context:   c,
+		SubEvents: subEvents,
+		SentAt:    &sentAt,
+	}
+
+	select {
+	case s.events <- event:
+		return nil
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+/*
+batchContext returns the JSON encoding of the context of a batch, if any.
+*/
+func batchContext(s *structpb.Struct) ([]byte, error) {
+	ctx, err := convertContext(s)
+	if ctx == nil || err != nil {
+		return nil, err
+	}
+
+	return ctx.MarshalJSON()
+}
+
+/*
+messageID returns the message ID of a message received on a stream.
+*/
+func messageID(m *ingestpb.Message) string {
+	switch {
+	case m.GetIdentify() != nil:
+		return m.GetIdentify().GetMessageId()
+	case m.GetTrack() != nil:
+		return m.GetTrack().GetMessageId()
+	case m.GetPage() != nil:
+		return m.GetPage().GetMessageId()
+	case m.GetScreen() != nil:
+		return m.GetScreen().GetMessageId()
+	case m.GetGroup() != nil:
+		return m.GetGroup().GetMessageId()
+	case m.GetAlias() != nil:
+		return m.GetAlias().GetMessageId()
+	}
+
+	return ""
+}
+
+/*
+toStatus returns the gRPC status of a failure. Client errors are returned as
+invalid arguments, and other errors as internal ones.
+*/
+func toStatus(fail *errors.Error) error {
+	code := codes.Internal
+	if fail.StatusCode >= 400 && fail.StatusCode < 500 {
+		code = codes.InvalidArgument
+	}
+
+	return status.Error(code, describe(fail))
+}
+
+/*
+describe returns the message of a failure including its validation errors, since
+gRPC errors only hold a message.
+*/
+func describe(fail *errors.Error) string {
+	message := fail.Message
+	for _, validation := range fail.Validations {
+		message += ": " + validation.Message
+	}
+
+	return message
+}
@@ -0,0 +1,140 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/fragment/sources/grpc/ingestpb"
+	"github.com/nunchistudio/fragment/sources/rest"
+
+	"github.com/sirupsen/logrus"
+
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServer_Track(t *testing.T) {
+	events := make(chan *source.Event, 1)
+	s := &server{
+		env: &Options{
+			REST: &rest.Options{},
+		},
+		logger: logrus.New(),
+		events: events,
+	}
+
+	_, err := s.Track(context.Background(), &ingestpb.Track{
+		UserId: "user",
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("server.Track() error = %v, want %v", err, codes.InvalidArgument)
+	}
+
+	res, err := s.Track(context.Background(), &ingestpb.Track{
+		UserId: "user",
+		Event:  "Order Completed",
+	})
+	if err != nil || !res.GetSuccess() {
+		t.Fatalf("server.Track() error = %v", err)
+	}
+
+	event := <-events
+	if len(event.SubEvents) != 1 || event.SubEvents[0].Trigger != "track" {
+		t.Errorf("server.Track() event = %+v", event)
+	}
+}
+
+func TestServer_Batch(t *testing.T) {
+	events := make(chan *source.Event, 1)
+	s := &server{
+		env: &Options{
+			REST: &rest.Options{},
+		},
+		logger: logrus.New(),
+		events: events,
+	}
+
+	_, err := s.Batch(context.Background(), &ingestpb.Batch{
+		Batch: []*ingestpb.Message{
+			{Type: &ingestpb.Message_Identify{Identify: &ingestpb.Identify{UserId: "user"}}},
+			{Type: &ingestpb.Message_Track{Track: &ingestpb.Track{UserId: "user"}}},
+			{Type: &ingestpb.Message_Page{Page: &ingestpb.Page{UserId: "user", Name: "Home"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("server.Batch() error = %v", err)
+	}
+
+	if event := <-events; len(event.SubEvents) != 2 {
+		t.Errorf("server.Batch() sub-events = %v, want %v", len(event.SubEvents), 2)
+	}
+}
+
+func TestServer_Stream(t *testing.T) {
+	events := make(chan *source.Event, 2)
+	listener := bufconn.Listen(1 << 20)
+	srv := grpcgo.NewServer()
+	ingestpb.RegisterIngestServer(srv, &server{
+		env: &Options{
+			REST: &rest.Options{},
+		},
+		logger: logrus.New(),
+		events: events,
+	})
+
+	go srv.Serve(listener)
+	defer srv.Stop()
+
+	conn, err := grpcgo.DialContext(context.Background(), "bufconn",
+		grpcgo.WithInsecure(),
+		grpcgo.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+	)
+	if err != nil {
+		t.Fatalf("grpc.Dial() error = %v", err)
+	}
+
+	defer conn.Close()
+	stream, err := ingestpb.NewIngestClient(conn).Stream(context.Background())
+	if err != nil {
+		t.Fatalf("IngestClient.Stream() error = %v", err)
+	}
+
+	messages := []*ingestpb.Message{
+		{Type: &ingestpb.Message_Track{Track: &ingestpb.Track{MessageId: "valid", UserId: "user", Event: "Level Completed"}}},
+		{Type: &ingestpb.Message_Track{Track: &ingestpb.Track{MessageId: "invalid", UserId: "user"}}},
+	}
+
+	for _, m := range messages {
+		if err := stream.Send(m); err != nil {
+			t.Fatalf("Stream.Send() error = %v", err)
+		}
+	}
+
+	want := map[string]bool{
+		"valid":   true,
+		"invalid": false,
+	}
+
+	for range messages {
+		result, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Stream.Recv() error = %v", err)
+		}
+
+		if result.GetSuccess() != want[result.GetMessageId()] {
+			t.Errorf("Stream.Recv() = %+v", result)
+		}
+	}
+
+	stream.CloseSend()
+	if len(events) != 1 {
+		t.Errorf("server.Stream() events = %v, want %v", len(events), 1)
+	}
+}
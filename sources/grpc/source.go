@@ -0,0 +1,85 @@
+/*
+Package grpc provides the source "grpc", exposing a gRPC ingestion API mirroring
+the Segment HTTP API for backend services preferring gRPC over HTTP. The protobuf
+definition of the API is in the package ingestpb, so clients can be generated for
+any language.
+
+Messages are converted into the types of the Segment library, and are validated,
+enriched, and sent to the destinations the same way as the ones received by the
+source "rest". Every request creates an event whose sub-events are the messages
+received.
+
+Clients authenticate with a write key passed in the metadata "authorization" with
+the "Bearer" scheme.
+
+The code of the package ingestpb is generated from its protobuf definition:
+
+	$ protoc --go_out=. --go_opt=paths=source_relative \
+	    --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+	    ingestpb/ingest.proto
+*/
+package grpc
+
+import (
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/logger"
+	"github.com/nunchistudio/blacksmith/source"
+)
+
+/*
+GRPC implements the Blacksmith source.Source interface for the source "grpc".
+*/
+type GRPC struct {
+	env     *Options
+	options *source.Options
+}
+
+/*
+New returns a valid Blacksmith source.Source for gRPC.
+*/
+func New(env *Options) source.Source {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &GRPC{
+		env: env,
+		options: &source.Options{
+			DefaultVersion: "v1.0",
+			Versions: map[string]time.Time{
+				"v1.0": time.Time{},
+			},
+		},
+	}
+}
+
+/*
+String returns the string representation of the source GRPC.
+*/
+func (s *GRPC) String() string {
+	return "grpc"
+}
+
+/*
+Options returns common source options for gRPC. They will be shared across every
+triggers of this source, except when overridden.
+*/
+func (s *GRPC) Options() *source.Options {
+	return s.options
+}
+
+/*
+Triggers return a list of triggers the source GRPC is able to handle.
+*/
+func (s *GRPC) Triggers() map[string]source.Trigger {
+	return map[string]source.Trigger{
+		"ingest": Ingest{
+			env: s.env,
+		},
+	}
+}
@@ -0,0 +1,33 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/fragment/sources/rest"
+)
+
+var _ source.Source = &GRPC{}
+var _ source.Trigger = Ingest{}
+var _ source.TriggerCDC = Ingest{}
+
+func TestGRPC_Triggers(t *testing.T) {
+	s := New(&Options{
+		WriteKeys: []string{"key"},
+		REST:      &rest.Options{},
+	})
+
+	if s.String() != "grpc" {
+		t.Errorf("GRPC.String() = %v, want %v", s.String(), "grpc")
+	}
+
+	trigger, exists := s.Triggers()["ingest"]
+	if !exists {
+		t.Fatalf("GRPC.Triggers() is missing trigger %v", "ingest")
+	}
+
+	if trigger.Mode().Mode != source.ModeCDC {
+		t.Errorf("Ingest.Mode() = %v, want %v", trigger.Mode().Mode, source.ModeCDC)
+	}
+}
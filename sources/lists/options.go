@@ -0,0 +1,116 @@
+package lists
+
+import (
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/nunchistudio/fragment/audiences"
+)
+
+/*
+Defaults are the defaults options set for the source. When not set, these values
+will automatically be applied.
+*/
+var Defaults = &Options{
+	PageSize: 500,
+	Interval: 10 * time.Second,
+}
+
+/*
+Options is the options the source can take as an input to be configured.
+*/
+type Options struct {
+
+	// ShowMeta is used to display (or not) the metadata in the HTTP response,
+	// such as the event's context and jobs details.
+	ShowMeta bool
+
+	// ShowData is used to display (or not) the data in the HTTP response.
+	ShowData bool
+
+	// Prefix allows to prefix the endpoints exposed by the source.
+	//
+	// Example: "/cdp"
+	Prefix string
+
+	// Audiences is the store of audiences where the lists are saved.
+	//
+	// Required.
+	Audiences *audiences.Store
+
+	// Secrets are the tokens allowed to save lists and to request resyncs. A client
+	// must pass one of them as a bearer token in the "Authorization" header.
+	//
+	// Required.
+	Secrets []string
+
+	// PageSize is the number of profiles evaluated at once when synchronizing an
+	// audience or a list. Every page is sent to the destinations as its own event.
+	//
+	// Defaults to 500.
+	PageSize int
+
+	// Interval is the interval at which the resyncs requested are looked for.
+	//
+	// Defaults to 10 seconds.
+	Interval time.Duration
+}
+
+/*
+validate ensures the options passed to initialize the source are valid.
+*/
+func (env *Options) validate() error {
+	fail := &errors.Error{
+		Message:     "source/lists: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Sources", "lists"},
+		})
+
+		return fail
+	}
+
+	if env.Audiences == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Audience store must be set",
+			Path:    []string{"Options", "Sources", "lists", "Audiences"},
+		})
+	}
+
+	if len(env.Secrets) == 0 {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Secrets must be set",
+			Path:    []string{"Options", "Sources", "lists", "Secrets"},
+		})
+	}
+
+	for _, secret := range env.Secrets {
+		if secret == "" {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Secrets must not be empty",
+				Path:    []string{"Options", "Sources", "lists", "Secrets"},
+			})
+
+			break
+		}
+	}
+
+	if env.PageSize <= 0 {
+		env.PageSize = Defaults.PageSize
+	}
+
+	if env.Interval <= 0 {
+		env.Interval = Defaults.Interval
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package lists
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/fragment/audiences"
+)
+
+func TestOptions_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  *Options
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: true,
+		},
+		{
+			name: "WithoutSecrets",
+			fields: &Options{
+				Audiences: &audiences.Store{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithRequiredOptions",
+			fields: &Options{
+				Audiences: &audiences.Store{},
+				Secrets:   []string{"secret"},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && (env.PageSize != Defaults.PageSize || env.Interval != Defaults.Interval) {
+				t.Errorf("Options.validate() did not apply the defaults")
+			}
+		})
+	}
+}
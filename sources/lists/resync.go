@@ -0,0 +1,96 @@
+package lists
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/fragment/auth"
+)
+
+/*
+Resync implements the Blacksmith source.Trigger interface for the trigger
+"resync". It requests an audience or a list to be evaluated again for every
+profiles, so every membership is sent to the destinations again. It is meant to
+be used when the definition of an audience changed.
+
+The request must be authenticated with one of the secrets of the source, passed
+as a bearer token. The profiles are then evaluated page by page by the trigger
+"sync".
+
+Example of payload:
+
+	{
+	  "key": "cart_abandoners"
+	}
+*/
+type Resync struct {
+	env *Options
+
+	Key string `json:"key"`
+}
+
+/*
+String returns the string representation of the trigger Resync.
+*/
+func (t Resync) String() string {
+	return "resync"
+}
+
+/*
+Mode allows to register the trigger as a HTTP route. This means, every
+time a "POST" request is executed against the route "/v1/audiences/resync",
+the Extract function will run.
+*/
+func (t Resync) Mode() *source.Mode {
+	return &source.Mode{
+		Mode: source.ModeHTTP,
+		UsingHTTP: &source.Route{
+			Methods:  []string{"POST"},
+			Path:     t.env.Prefix + "/v1/audiences/resync",
+			ShowMeta: t.env.ShowMeta,
+			ShowData: t.env.ShowData,
+		},
+	}
+}
+
+/*
+Extract is the function being run when the HTTP route is triggered. It requests
+the resync and returns an empty event, since the memberships are sent by the
+trigger "sync".
+*/
+func (t Resync) Extract(tk *source.Toolkit, req *http.Request) (*source.Event, error) {
+	if !auth.Valid(auth.Bearer(req), t.env.Secrets) {
+		return nil, auth.Unauthorized()
+	}
+
+	// Create an empty payload, catch unwanted fields, and unmarshal it.
+	// Return an error if any occured.
+	payload := Resync{
+		env: t.env,
+	}
+	decoder := json.NewDecoder(req.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&payload)
+	if err != nil || payload.Key == "" {
+		message := "key must be set"
+		if err != nil {
+			message = err.Error()
+		}
+
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+			Validations: []errors.Validation{
+				{
+					Message: message,
+					Path:    []string{"Resync", "key"},
+				},
+			},
+		}
+	}
+
+	return resync(tk, t.env, payload.Key)
+}
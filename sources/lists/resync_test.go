@@ -0,0 +1,53 @@
+package lists
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/source"
+)
+
+func TestResync_Extract(t *testing.T) {
+	trigger := Resync{
+		env: &Options{
+			Secrets: []string{"secret"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{
+			name: "WithoutToken",
+			want: 401,
+		},
+		{
+			name:   "WithUnknownToken",
+			header: "Bearer unknown",
+			want:   401,
+		},
+		{
+			name:   "WithoutKey",
+			header: "Bearer secret",
+			want:   400,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/v1/audiences/resync", strings.NewReader(`{}`))
+			req.Header.Set("Authorization", tt.header)
+
+			_, err := trigger.Extract(&source.Toolkit{}, req)
+			if fail, ok := err.(*errors.Error); !ok || fail.StatusCode != tt.want {
+				t.Errorf("Resync.Extract() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+var _ source.Trigger = Resync{}
+var _ source.TriggerHTTP = Resync{}
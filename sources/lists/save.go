@@ -0,0 +1,104 @@
+package lists
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/fragment/audiences"
+	"github.com/nunchistudio/fragment/auth"
+)
+
+/*
+Save implements the Blacksmith source.Trigger interface for the trigger "save".
+It creates or replaces a list, either defined by a trait condition or by a list
+of uploaded identifiers, and requests its memberships to be synchronized by the
+trigger "sync". The request must be authenticated with one of the secrets of the
+source, passed as a bearer token.
+
+Example of payload:
+
+	{
+	  "key": "beta_testers",
+	  "name": "Beta Testers",
+	  "ids": [
+	    { "type": "email", "id": "john@example.com" },
+	    { "type": "user_id", "id": "42" }
+	  ]
+	}
+*/
+type Save struct {
+	env *Options
+
+	audiences.List
+}
+
+/*
+String returns the string representation of the trigger Save.
+*/
+func (t Save) String() string {
+	return "save"
+}
+
+/*
+Mode allows to register the trigger as a HTTP route. This means, every
+time a "PUT" request is executed against the route "/v1/lists", the
+Extract function will run.
+*/
+func (t Save) Mode() *source.Mode {
+	return &source.Mode{
+		Mode: source.ModeHTTP,
+		UsingHTTP: &source.Route{
+			Methods:  []string{"PUT"},
+			Path:     t.env.Prefix + "/v1/lists",
+			ShowMeta: t.env.ShowMeta,
+			ShowData: t.env.ShowData,
+		},
+	}
+}
+
+/*
+Extract is the function being run when the HTTP route is triggered. It saves
+the list and requests a resync of its memberships, so the destinations are
+synchronized with the new definition.
+*/
+func (t Save) Extract(tk *source.Toolkit, req *http.Request) (*source.Event, error) {
+	if !auth.Valid(auth.Bearer(req), t.env.Secrets) {
+		return nil, auth.Unauthorized()
+	}
+
+	// Create an empty payload, catch unwanted fields, and unmarshal it.
+	// Return an error if any occured.
+	var payload audiences.List
+	decoder := json.NewDecoder(req.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&payload)
+	if err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+			Validations: []errors.Validation{
+				{
+					Message: err.Error(),
+					Path:    []string{"List"},
+				},
+			},
+		}
+	}
+
+	if err := t.env.Audiences.SaveList(&payload); err != nil {
+		if fail, ok := err.(*errors.Error); ok && fail.StatusCode == 400 {
+			return nil, fail
+		}
+
+		tk.Logger.Error(err)
+		return nil, &errors.Error{
+			StatusCode: 500,
+			Message:    "Internal Server Error",
+		}
+	}
+
+	return resync(tk, t.env, payload.Key)
+}
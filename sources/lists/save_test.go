@@ -0,0 +1,27 @@
+package lists
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/source"
+)
+
+func TestSave_Extract(t *testing.T) {
+	trigger := Save{
+		env: &Options{
+			Secrets: []string{"secret"},
+		},
+	}
+
+	req := httptest.NewRequest("PUT", "/v1/lists", strings.NewReader(`{"key":"beta_testers"}`))
+	_, err := trigger.Extract(&source.Toolkit{}, req)
+	if fail, ok := err.(*errors.Error); !ok || fail.StatusCode != 401 {
+		t.Errorf("Save.Extract() error = %v, want %v", err, 401)
+	}
+}
+
+var _ source.Trigger = Save{}
+var _ source.TriggerHTTP = Save{}
@@ -0,0 +1,103 @@
+/*
+Package lists provides the source "lists", allowing to save named lists of users
+and to synchronize again the memberships of an audience or a list with the
+destinations. The events created by these triggers follow the same format as the
+ones received by the source "rest".
+*/
+package lists
+
+import (
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/helper/logger"
+	"github.com/nunchistudio/blacksmith/source"
+)
+
+/*
+Lists implements the Blacksmith source.Source interface for the source "lists".
+*/
+type Lists struct {
+	env     *Options
+	options *source.Options
+}
+
+/*
+New returns a valid Blacksmith source.Source for Lists.
+*/
+func New(env *Options) source.Source {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &Lists{
+		env: env,
+		options: &source.Options{
+			DefaultVersion: "v1.0",
+			Versions: map[string]time.Time{
+				"v1.0": time.Time{},
+			},
+		},
+	}
+}
+
+/*
+String returns the string representation of the source Lists.
+*/
+func (s *Lists) String() string {
+	return "lists"
+}
+
+/*
+Options returns common source options for Lists. They will be shared across
+every triggers of this source, except when overridden.
+*/
+func (s *Lists) Options() *source.Options {
+	return s.options
+}
+
+/*
+Triggers return a list of triggers the source Lists is able to handle.
+*/
+func (s *Lists) Triggers() map[string]source.Trigger {
+	return map[string]source.Trigger{
+		"save": Save{
+			env: s.env,
+		},
+		"resync": Resync{
+			env: s.env,
+		},
+		"sync": Sync{
+			env: s.env,
+		},
+	}
+}
+
+/*
+resync requests the memberships of an audience or a list to be synchronized
+again, and returns an empty event. The memberships are sent by the trigger
+"sync".
+*/
+func resync(tk *source.Toolkit, env *Options, key string) (*source.Event, error) {
+	if err := env.Audiences.Resync(key); err != nil {
+		if fail, ok := err.(*errors.Error); ok && fail.StatusCode == 404 {
+			return nil, fail
+		}
+
+		tk.Logger.Error(err)
+		return nil, &errors.Error{
+			StatusCode: 500,
+			Message:    "Internal Server Error",
+		}
+	}
+
+	// Return an empty collection of sub-events.
+	return &source.Event{
+		Version:   "v1.0",
+		SubEvents: []*source.SubEvent{},
+	}, nil
+}
@@ -0,0 +1,7 @@
+package lists
+
+import (
+	"github.com/nunchistudio/blacksmith/source"
+)
+
+var _ source.Source = &Lists{}
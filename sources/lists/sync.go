@@ -0,0 +1,102 @@
+package lists
+
+import (
+	"time"
+
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/fragment/sources/rest"
+)
+
+/*
+Sync implements the Blacksmith source.Trigger interface for the trigger "sync".
+It evaluates the audiences and lists for which a resync has been requested, page
+by page, and sends every membership to the destinations.
+*/
+type Sync struct {
+	env *Options
+}
+
+/*
+String returns the string representation of the trigger Sync.
+*/
+func (t Sync) String() string {
+	return "sync"
+}
+
+/*
+Mode allows to register the trigger as a CDC. This means the Extract function is
+run once by the gateway, and looks for the resyncs requested for as long as the
+gateway is running.
+*/
+func (t Sync) Mode() *source.Mode {
+	return &source.Mode{
+		Mode: source.ModeCDC,
+	}
+}
+
+/*
+Extract is the function being run when the gateway starts. It evaluates the
+pages of profiles as long as a resync is pending, and returns an event holding a
+"track" sub-event for every member of a page. It then waits for the interval set
+in the options before looking for resyncs again.
+
+The progress of a resync is saved once the event of a page has been sent to the
+gateway, so a page is evaluated again if the gateway stopped in the meantime.
+*/
+func (t Sync) Extract(tk *source.Toolkit, notifier *source.Notifier) {
+	ticker := time.NewTicker(t.env.Interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			select {
+			case <-notifier.IsShuttingDown:
+				notifier.Done <- true
+				return
+			default:
+			}
+
+			page, err := t.env.Audiences.ResyncNext(t.env.PageSize)
+			if err != nil {
+				notifier.Error <- err
+				break
+			} else if page == nil {
+				break
+			}
+
+			event := &source.Event{
+				Version:   "v1.0",
+				SubEvents: []*source.SubEvent{},
+			}
+
+			for _, track := range page.Tracks {
+				subevent, fail := rest.TrackEvent(track)
+				if fail != nil {
+					tk.Logger.Error(fail)
+					continue
+				}
+
+				event.SubEvents = append(event.SubEvents, subevent)
+			}
+
+			if len(event.SubEvents) > 0 {
+				now := time.Now().UTC()
+				event.SentAt = &now
+				notifier.Event <- event
+			}
+
+			if err := t.env.Audiences.ResyncSave(page); err != nil {
+				notifier.Error <- err
+				break
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-notifier.IsShuttingDown:
+			notifier.Done <- true
+			return
+		}
+	}
+}
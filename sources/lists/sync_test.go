@@ -0,0 +1,8 @@
+package lists
+
+import (
+	"github.com/nunchistudio/blacksmith/source"
+)
+
+var _ source.Trigger = Sync{}
+var _ source.TriggerCDC = Sync{}
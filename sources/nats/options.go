@@ -0,0 +1,152 @@
+package nats
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/nunchistudio/fragment/sources/rest"
+)
+
+/*
+subscriptionName is the format of the name of a subscription, used as the name
+of its trigger.
+*/
+var subscriptionName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+/*
+Defaults are the defaults options set for the source. When not set, these values
+will automatically be applied.
+*/
+var Defaults = &Options{
+	Queue: "fragment",
+}
+
+/*
+Subscription is a NATS subject the source subscribes to.
+*/
+type Subscription struct {
+
+	// Name is the unique name of the subscription, in snake case. It is used as
+	// the name of its trigger.
+	//
+	// Required.
+	Name string
+
+	// Subject is the NATS subject to subscribe to. Wildcards are supported, such
+	// as "segment.>".
+	//
+	// Required.
+	Subject string
+
+	// Queue is the queue group of the subscription, so a message is only received
+	// by one instance of the gateway.
+	//
+	// Defaults to the queue of the options.
+	Queue string
+}
+
+/*
+Options is the options the source can take as an input to be configured.
+*/
+type Options struct {
+
+	// Subscriptions are the NATS subjects the source subscribes to.
+	//
+	// Required.
+	Subscriptions []*Subscription
+
+	// Queue is the queue group of the subscriptions not having their own.
+	//
+	// Defaults to "fragment".
+	Queue string
+
+	// REST is the options of the source "rest" used to validate, enrich, and send
+	// the messages to the destinations. It should be the same options as the ones
+	// passed to the source "rest", so both sources share the same stores.
+	//
+	// Required.
+	REST *rest.Options
+}
+
+/*
+validate ensures the options passed to initialize the source are valid.
+*/
+func (env *Options) validate() error {
+	fail := &errors.Error{
+		Message:     "source/nats: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Sources", "nats"},
+		})
+
+		return fail
+	}
+
+	if env.Queue == "" {
+		env.Queue = Defaults.Queue
+	}
+
+	if env.REST == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options of the source 'rest' must be set",
+			Path:    []string{"Options", "Sources", "nats", "REST"},
+		})
+	}
+
+	if len(env.Subscriptions) == 0 {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "At least one subscription must be set",
+			Path:    []string{"Options", "Sources", "nats", "Subscriptions"},
+		})
+	}
+
+	names := map[string]bool{}
+	for i, subscription := range env.Subscriptions {
+		path := []string{"Options", "Sources", "nats", "Subscriptions", strconv.Itoa(i)}
+		if subscription == nil {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Subscription must not be nil",
+				Path:    path,
+			})
+
+			continue
+		}
+
+		if !subscriptionName.MatchString(subscription.Name) {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Subscription name must be in snake case",
+				Path:    append(path, "Name"),
+			})
+		} else if names[subscription.Name] {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Subscription name '" + subscription.Name + "' must be unique",
+				Path:    append(path, "Name"),
+			})
+		}
+
+		names[subscription.Name] = true
+		if subscription.Subject == "" || strings.ContainsAny(subscription.Subject, " \t\r\n") {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Subject must be set without whitespaces",
+				Path:    append(path, "Subject"),
+			})
+		}
+
+		if subscription.Queue == "" {
+			subscription.Queue = env.Queue
+		}
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package nats
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/fragment/sources/rest"
+)
+
+func TestOptions_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  *Options
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: true,
+		},
+		{
+			name: "WithSubscription",
+			fields: &Options{
+				REST: &rest.Options{},
+				Subscriptions: []*Subscription{
+					{Name: "backend", Subject: "segment.>"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithoutREST",
+			fields: &Options{
+				Subscriptions: []*Subscription{
+					{Name: "backend", Subject: "segment.>"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithDuplicateNames",
+			fields: &Options{
+				REST: &rest.Options{},
+				Subscriptions: []*Subscription{
+					{Name: "backend", Subject: "segment.track"},
+					{Name: "backend", Subject: "segment.identify"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithInvalidSubject",
+			fields: &Options{
+				REST: &rest.Options{},
+				Subscriptions: []*Subscription{
+					{Name: "backend", Subject: "segment events"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
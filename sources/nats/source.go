@@ -0,0 +1,78 @@
+/*
+Package nats provides the source "nats", accepting Segment messages published to
+NATS subjects so backend services can send events without HTTP. Messages are
+received through the Pub / Sub adapter of the application, and must be a single
+message with a "type" key or a batch following the Segment batch API. They are
+validated, enriched, and sent to the destinations the same way as the ones
+received by the source "rest".
+*/
+package nats
+
+import (
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/logger"
+	"github.com/nunchistudio/blacksmith/source"
+)
+
+/*
+NATS implements the Blacksmith source.Source interface for the source "nats".
+*/
+type NATS struct {
+	env     *Options
+	options *source.Options
+}
+
+/*
+New returns a valid Blacksmith source.Source for NATS.
+*/
+func New(env *Options) source.Source {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &NATS{
+		env: env,
+		options: &source.Options{
+			DefaultVersion: "v1.0",
+			Versions: map[string]time.Time{
+				"v1.0": time.Time{},
+			},
+		},
+	}
+}
+
+/*
+String returns the string representation of the source NATS.
+*/
+func (s *NATS) String() string {
+	return "nats"
+}
+
+/*
+Options returns common source options for NATS. They will be shared across every
+triggers of this source, except when overridden.
+*/
+func (s *NATS) Options() *source.Options {
+	return s.options
+}
+
+/*
+Triggers return a list of triggers the source NATS is able to handle. There is
+one trigger per subscription, named after the subscription.
+*/
+func (s *NATS) Triggers() map[string]source.Trigger {
+	triggers := map[string]source.Trigger{}
+	for _, subscription := range s.env.Subscriptions {
+		triggers[subscription.Name] = Subscribe{
+			env:          s.env,
+			subscription: subscription,
+		}
+	}
+
+	return triggers
+}
@@ -0,0 +1,39 @@
+package nats
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/fragment/sources/rest"
+)
+
+var _ source.Source = &NATS{}
+
+func TestNATS_Triggers(t *testing.T) {
+	s := New(&Options{
+		REST: &rest.Options{},
+		Subscriptions: []*Subscription{
+			{Name: "backend", Subject: "segment.>"},
+			{Name: "billing", Subject: "billing.events", Queue: "billing"},
+		},
+	})
+
+	triggers := s.Triggers()
+	if len(triggers) != 2 {
+		t.Fatalf("NATS.Triggers() = %v, want %v", len(triggers), 2)
+	}
+
+	mode := triggers["billing"].Mode()
+	if mode.Mode != source.ModeSubscription {
+		t.Errorf("Subscribe.Mode() = %v, want %v", mode.Mode, source.ModeSubscription)
+	}
+
+	if mode.UsingSubscription.Topic != "billing.events" || mode.UsingSubscription.Subscription != "billing" {
+		t.Errorf("Subscribe.Mode() = %+v", mode.UsingSubscription)
+	}
+
+	if queue := triggers["backend"].Mode().UsingSubscription.Subscription; queue != Defaults.Queue {
+		t.Errorf("Subscribe.Mode() queue = %v, want %v", queue, Defaults.Queue)
+	}
+}
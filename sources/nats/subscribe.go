@@ -0,0 +1,136 @@
+package nats
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/adapter/pubsub"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/fragment/sources/rest"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Subscribe implements the Blacksmith source.Trigger interface for the triggers of
+the subscriptions. It holds the subscription the trigger is in charge of.
+*/
+type Subscribe struct {
+	env          *Options
+	subscription *Subscription
+}
+
+/*
+payload holds the keys of a message needed to know if it is a batch, and the
+timestamp it was sent at.
+*/
+type payload struct {
+	Batch     []json.RawMessage  `json:"batch"`
+	Context   *analytics.Context `json:"context,omitempty"`
+	Timestamp time.Time          `json:"timestamp,omitempty"`
+}
+
+/*
+String returns the string representation of the trigger Subscribe.
+*/
+func (t Subscribe) String() string {
+	return t.subscription.Name
+}
+
+/*
+Mode allows to register the trigger as a subscription. This means, every time a
+message is published to the subject of the subscription, the Extract function
+will run.
+*/
+func (t Subscribe) Mode() *source.Mode {
+	return &source.Mode{
+		Mode: source.ModeSubscription,
+		UsingSubscription: &source.Subscription{
+			Topic:        t.subscription.Subject,
+			Subscription: t.subscription.Queue,
+		},
+	}
+}
+
+/*
+Extract is the function being run when a message is received by the subscription.
+It is in charge of the "E" in the ETL process: Extract the data from the source.
+
+A batch returns one sub-event per valid message, and invalid messages within the
+batch are logged and skipped, the same way as the source "rest" does.
+*/
+func (t Subscribe) Extract(tk *source.Toolkit, msg *pubsub.Message) (*source.Event, error) {
+	var p payload
+	if err := json.Unmarshal(msg.Body, &p); err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+			Validations: []errors.Validation{
+				{
+					Message: err.Error(),
+					Path:    []string{"analytics", "Message"},
+				},
+			},
+		}
+	}
+
+	// Add the current timestamp if none was provided.
+	if p.Timestamp.IsZero() {
+		p.Timestamp = time.Now().UTC()
+	}
+
+	// A single message is validated and returned with its flows to run.
+	if p.Batch == nil {
+		subevent, fail := rest.Decode(t.env.REST, msg.Body)
+		if fail != nil {
+			return nil, fail
+		}
+
+		return &source.Event{
+			Version: "v1.0",
+			Context: subevent.Context,
+			Data:    subevent.Data,
+			SentAt:  &p.Timestamp,
+			Flows:   subevent.Flows,
+		}, nil
+	}
+
+	// Go through each message of the batch. By returning sub-events, these said
+	// sub-events will have this batch event as their parent event.
+	subEvents := []*source.SubEvent{}
+	for _, b := range p.Batch {
+		subevent, fail := rest.Decode(t.env.REST, b)
+		if fail != nil {
+			if tk != nil && tk.Logger != nil {
+				tk.Logger.Error(fail)
+			}
+
+			continue
+		}
+
+		subEvents = append(subEvents, subevent)
+	}
+
+	// Try to marshal the context of the batch.
+	var ctx []byte
+	if p.Context != nil {
+		var err error
+		ctx, err = p.Context.MarshalJSON()
+		if err != nil {
+			return nil, &errors.Error{
+				StatusCode: 400,
+				Message:    "Bad Request",
+			}
+		}
+	}
+
+	// Return the context and a collection of sub-events to process.
+	return &source.Event{
+		Version:   "v1.0",
+		Context:   ctx,
+		SubEvents: subEvents,
+		SentAt:    &p.Timestamp,
+	}, nil
+}
@@ -0,0 +1,85 @@
+package nats
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/adapter/pubsub"
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/fragment/sources/rest"
+)
+
+var _ source.Trigger = Subscribe{}
+var _ source.TriggerSubscription = Subscribe{}
+
+func TestSubscribe_Extract(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		wantFlows     int
+		wantSubEvents int
+		wantErr       bool
+	}{
+		{
+			name:      "WithTrack",
+			body:      `{"type":"track","userId":"user","event":"Order Completed","properties":{"revenue":1250}}`,
+			wantFlows: 1,
+		},
+		{
+			name:    "WithInvalidTrack",
+			body:    `{"type":"track","userId":"user"}`,
+			wantErr: true,
+		},
+		{
+			name:    "WithUnsupportedType",
+			body:    `{"type":"unknown","userId":"user"}`,
+			wantErr: true,
+		},
+		{
+			name:    "WithInvalidJSON",
+			body:    `{"type":`,
+			wantErr: true,
+		},
+		{
+			name: "WithBatch",
+			body: `{"batch":[
+				{"type":"identify","userId":"user","traits":{"plan":"pro"}},
+				{"type":"track","userId":"user","event":"Order Completed"},
+				{"type":"track","userId":"user"}
+			]}`,
+			wantSubEvents: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trigger := Subscribe{
+				env: &Options{
+					REST: &rest.Options{},
+				},
+				subscription: &Subscription{
+					Name:    "backend",
+					Subject: "segment.>",
+				},
+			}
+
+			event, err := trigger.Extract(nil, &pubsub.Message{
+				Body: []byte(tt.body),
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Subscribe.Extract() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if len(event.Flows) != tt.wantFlows {
+				t.Errorf("Subscribe.Extract() flows = %v, want %v", len(event.Flows), tt.wantFlows)
+			}
+
+			if len(event.SubEvents) != tt.wantSubEvents {
+				t.Errorf("Subscribe.Extract() sub-events = %v, want %v", len(event.SubEvents), tt.wantSubEvents)
+			}
+		})
+	}
+}
@@ -12,6 +12,8 @@ import (
 
 	"github.com/nunchistudio/blacksmith-modules/segment/segmentflow"
 
+	"github.com/nunchistudio/fragment/flows/fragmentflow"
+
 	"gopkg.in/segmentio/analytics-go.v3"
 )
 
@@ -62,7 +64,9 @@ func (t Alias) Extract(tk *source.Toolkit, req *http.Request) (*source.Event, er
 
 	// Create an empty payload, catch unwanted fields, and unmarshal it.
 	// Return an error if any occured.
-	var payload Alias
+	payload := Alias{
+		env: t.env,
+	}
 	decoder := json.NewDecoder(req.Body)
 	decoder.DisallowUnknownFields()
 	err := decoder.Decode(&payload)
@@ -124,26 +128,58 @@ func (t Alias) marshal() (*source.SubEvent, *errors.Error) {
 		}
 	}
 
-	// Try to marshal the context from the request payload.
-	var ctx []byte
-	if t.Context != nil {
-		ctx, err = t.Context.MarshalJSON()
+	// Link the previous ID to the user ID in the identity graph if enabled for the
+	// source, and refresh the attribution traits of the user since the touches of
+	// the previous ID may now be part of the user's profile.
+	var attributed *analytics.Identify
+	if t.env.Identity != nil {
+		profileID, err := t.env.Identity.Alias(t.Alias)
+		if err == nil && t.env.Attribution != nil {
+			attributed, err = t.env.Attribution.Refresh(profileID, t.UserId, "")
+		}
+
 		if err != nil {
-			return nil, &errors.Error{
-				StatusCode: 400,
-				Message:    "Bad Request",
-			}
+			return nil, internal(err)
+		}
+	}
+
+	// Try to marshal the context from the request payload, including the message
+	// details.
+	ctx, err := MarshalContext(t.Context, Message{
+		Type:       "alias",
+		MessageId:  t.MessageId,
+		UserId:     t.UserId,
+		PreviousId: t.PreviousId,
+	})
+	if err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
 		}
 	}
 
+	// Create the flows to run. Attribution traits which changed are sent to the
+	// destinations using the Identify flow.
+	flows := []flow.Flow{
+		&fragmentflow.Alias{
+			Alias: segmentflow.Alias{
+				Alias: t.Alias,
+			},
+		},
+	}
+
+	if attributed != nil {
+		flows = append(flows, &fragmentflow.Identify{
+			Identify: segmentflow.Identify{
+				Identify: *attributed,
+			},
+		})
+	}
+
 	// Return the context, data, and a collection of flows to run.
 	return &source.SubEvent{
 		Trigger: "alias",
 		Context: ctx,
-		Flows: []flow.Flow{
-			&segmentflow.Alias{
-				Alias: t.Alias,
-			},
-		},
+		Flows:   flows,
 	}, nil
 }
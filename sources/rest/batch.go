@@ -112,58 +112,10 @@ func (t Batch) Extract(tk *source.Toolkit, req *http.Request) (*source.Event, er
 		}
 
 		// Unmarshal the event with the appropriate struct and create the
-		// flow for the corresponding event.
-		switch eventType {
-		case "identify":
-			var e Identify
-			json.Unmarshal(b, &e)
-			subevent, fail = e.marshal()
-			if fail != nil {
-				tk.Logger.Error(fail)
-			}
-
-		case "track":
-			var e Track
-			json.Unmarshal(b, &e)
-			subevent, fail = e.marshal()
-			if fail != nil {
-				tk.Logger.Error(fail)
-			}
-
-		case "group":
-			var e Group
-			json.Unmarshal(b, &e)
-			subevent, fail = e.marshal()
-			if fail != nil {
-				tk.Logger.Error(fail)
-			}
-
-		case "alias":
-			var e Alias
-			json.Unmarshal(b, &e)
-			subevent, fail = e.marshal()
-			if fail != nil {
-				tk.Logger.Error(fail)
-			}
-
-		case "page":
-			var e Page
-			json.Unmarshal(b, &e)
-			subevent, fail = e.marshal()
-			if fail != nil {
-				tk.Logger.Error(fail)
-			}
-
-		case "screen":
-			var e Screen
-			json.Unmarshal(b, &e)
-			subevent, fail = e.marshal()
-			if fail != nil {
-				tk.Logger.Error(fail)
-			}
-
-		default:
-			continue
+		// flow for the corresponding event. Unsupported types are skipped.
+		subevent, fail = decode(t.env, eventType, b, false)
+		if fail != nil {
+			tk.Logger.Error(fail)
 		}
 
 		// If a sub-event is present, add it to the slice of sub-events to process.
@@ -0,0 +1,323 @@
+package rest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	helper "github.com/nunchistudio/blacksmith/helper/rest"
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/fragment/auth"
+)
+
+/*
+BulkOptions is the options of the NDJSON endpoint of the source, for uploading
+large amount of messages such as exports or backfills.
+*/
+type BulkOptions struct {
+
+	// WriteKeys are the keys a client can authenticate with when uploading
+	// messages. The key is passed in the "Authorization" header with the "Bearer"
+	// scheme.
+	//
+	// Required.
+	WriteKeys []string
+
+	// ImportKeys are the keys a client can authenticate with when importing
+	// historical messages, such as the command "fragment import". The messages
+	// uploaded with an import key are not attached to sessions, do not change the
+	// audiences of the users, and are never sent to Slack.
+	ImportKeys []string
+
+	// ChunkSize is the maximum number of sub-events of an event sent to the gateway.
+	// Lines are read and validated as they are received, and every chunk is sent
+	// once full so an upload is never held in memory.
+	//
+	// Defaults to 100.
+	ChunkSize int
+
+	// MaxLineSize is the maximum size in bytes of a line. Larger lines are rejected
+	// without stopping the upload.
+	//
+	// Defaults to 32KB, as for the Segment HTTP API.
+	MaxLineSize int
+
+	// MaxResults is the maximum number of rejected lines listed in the response,
+	// so an upload with many invalid lines does not hold them all in memory. The
+	// lines rejected after are still counted.
+	//
+	// Defaults to 1000.
+	MaxResults int
+}
+
+/*
+bulkResponse is the HTTP response of the NDJSON endpoint. It follows the design
+of the Blacksmith REST API.
+*/
+type bulkResponse struct {
+	StatusCode int       `json:"statusCode"`
+	Message    string    `json:"message"`
+	Data       *bulkData `json:"data"`
+}
+
+/*
+bulkData holds the results of an upload. Lines is the number of lines handled,
+so a client can resume an interrupted upload from the next one. Only the lines
+rejected are listed in the results, the others have been accepted. Truncated is
+true when more lines have been rejected than the results can list.
+*/
+type bulkData struct {
+	Lines     int           `json:"lines"`
+	Accepted  int           `json:"accepted"`
+	Rejected  int           `json:"rejected"`
+	Results   []*bulkResult `json:"results"`
+	Truncated bool          `json:"truncated"`
+}
+
+/*
+bulkResult is the result of a rejected line, given its number starting at 1.
+*/
+type bulkResult struct {
+	Line      int           `json:"line"`
+	MessageID string        `json:"messageId,omitempty"`
+	Error     *errors.Error `json:"error"`
+}
+
+/*
+BulkHandler returns the HTTP handler of the NDJSON endpoint of the source,
+exposing the endpoint:
+
+	POST /v1/bulk
+
+The request must be authenticated with one of the write keys, or one of the import
+keys for historical messages. The request body
+holds messages following the Segment spec, one per line, with
+a "type" key as for the batch endpoint. The content type must be
+"application/x-ndjson". Every line is validated and sent to the destinations the
+same way as the POST endpoints, in events holding at most ChunkSize sub-events.
+Empty lines are ignored.
+
+Requests to other endpoints are passed to the next handler, so the handler can be
+chained with others. It is meant to be attached to the gateway, which prefixes
+the endpoints with "/api".
+*/
+func BulkHandler(env *Options, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		path := strings.TrimPrefix(req.URL.Path, "/api")
+		if env.Bulk == nil || path != env.Prefix+"/v1/bulk" {
+			if next == nil {
+				helper.ErrorNotFound(res, req)
+				return
+			}
+
+			next.ServeHTTP(res, req)
+			return
+		}
+
+		if req.Method != http.MethodPost {
+			helper.ErrorMethodNotAllowed(res, req)
+			return
+		}
+
+		key := auth.Bearer(req)
+		imported := auth.Valid(key, env.Bulk.ImportKeys)
+		if !imported && !auth.Valid(key, env.Bulk.WriteKeys) {
+			auth.ErrorUnauthorized(res)
+			return
+		}
+
+		mediatype, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if mediatype != "application/x-ndjson" {
+			body, _ := json.Marshal(&errors.Error{
+				StatusCode: 415,
+				Message:    "Unsupported Media Type",
+			})
+
+			res.Header().Set("Content-Type", "application/json")
+			res.WriteHeader(http.StatusUnsupportedMediaType)
+			res.Write(body)
+			return
+		}
+
+		body := env.Bulk.upload(env, req, imported)
+		b, _ := json.Marshal(body)
+
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(body.StatusCode)
+		res.Write(b)
+	})
+}
+
+/*
+upload reads the lines of a request body until its end, and sends the valid
+messages to the gateway in chunks. The upload stops if the gateway is shutting
+down or the client went away, in which case the lines of the pending chunk are
+not counted as handled. Imported is true when the request is authenticated with an
+import key.
+*/
+func (bulk *BulkOptions) upload(env *Options, req *http.Request, imported bool) *bulkResponse {
+	body := &bulkResponse{
+		StatusCode: 200,
+		Message:    "Successful",
+		Data: &bulkData{
+			Results: []*bulkResult{},
+		},
+	}
+
+	reader := bufio.NewReader(req.Body)
+	chunk := []*source.SubEvent{}
+	line := 0
+
+	// flush sends the pending chunk to the gateway. It returns false if the event
+	// could not be sent.
+	flush := func() bool {
+		if len(chunk) == 0 {
+			return true
+		}
+
+		now := time.Now().UTC()
+		event := &source.Event{
+			Version:   "v1.0",
+			SubEvents: chunk,
+			SentAt:    &now,
+		}
+
+		select {
+		case env.events <- event:
+			body.Data.Accepted += len(chunk)
+			body.Data.Lines = line
+			chunk = []*source.SubEvent{}
+			return true
+
+		case <-env.closing:
+			body.StatusCode = 503
+			body.Message = "Service Unavailable"
+
+		case <-req.Context().Done():
+			body.StatusCode = 499
+			body.Message = "Client Closed Request"
+		}
+
+		return false
+	}
+
+	for {
+		b, tooLong, err := readLine(reader, bulk.MaxLineSize)
+		if err != nil {
+			if err != io.EOF {
+				body.StatusCode = 400
+				body.Message = "Bad Request"
+				return body
+			}
+
+			break
+		}
+
+		line++
+		b = bytes.TrimSpace(b)
+		if len(b) == 0 && !tooLong {
+			if len(chunk) == 0 {
+				body.Data.Lines = line
+			}
+
+			continue
+		}
+
+		var fail *errors.Error
+		var subevent *source.SubEvent
+		if tooLong {
+			fail = &errors.Error{
+				StatusCode: 413,
+				Message:    "Request Entity Too Large",
+				Validations: []errors.Validation{
+					{
+						Message: "line exceeds " + strconv.Itoa(bulk.MaxLineSize) + " bytes",
+						Path:    []string{"analytics", "Message"},
+					},
+				},
+			}
+		} else {
+			subevent, fail = decodeMessage(env, b, imported)
+		}
+
+		if fail != nil {
+			var message struct {
+				MessageId string `json:"messageId"`
+			}
+
+			body.Data.Rejected++
+			if len(body.Data.Results) < bulk.MaxResults {
+				json.Unmarshal(b, &message)
+				body.Data.Results = append(body.Data.Results, &bulkResult{
+					Line:      line,
+					MessageID: message.MessageId,
+					Error:     fail,
+				})
+			} else {
+				body.Data.Truncated = true
+			}
+
+			if len(chunk) == 0 {
+				body.Data.Lines = line
+			}
+
+			continue
+		}
+
+		chunk = append(chunk, subevent)
+		if len(chunk) >= bulk.ChunkSize && !flush() {
+			return body
+		}
+	}
+
+	if flush() {
+		body.Data.Lines = line
+	}
+
+	return body
+}
+
+/*
+readLine reads a line from a reader without its line feed. When a line is larger
+than max bytes, the rest of the line is discarded and tooLong is true. io.EOF is
+only returned once there is no more line to read.
+*/
+func readLine(reader *bufio.Reader, max int) (line []byte, tooLong bool, err error) {
+	for {
+		b, err := reader.ReadSlice('\n')
+		if !tooLong {
+			if len(line)+len(b) > max+1 {
+				tooLong = true
+				line = nil
+			} else {
+				line = append(line, b...)
+			}
+		}
+
+		switch err {
+		case nil:
+			return bytes.TrimSuffix(line, []byte("\n")), tooLong, nil
+
+		case bufio.ErrBufferFull:
+			continue
+
+		case io.EOF:
+			if len(line) == 0 && !tooLong {
+				return nil, false, io.EOF
+			}
+
+			return line, tooLong, nil
+
+		default:
+			return nil, false, err
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package rest
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/source"
+)
+
+func TestBulkHandler(t *testing.T) {
+	env := &Options{
+		Bulk: &BulkOptions{
+			WriteKeys:   []string{"key"},
+			ImportKeys:  []string{"import"},
+			ChunkSize:   2,
+			MaxLineSize: 128,
+		},
+	}
+
+	if err := env.validate(); err != nil {
+		t.Fatalf("Options.validate() error = %v", err)
+	}
+
+	events := make(chan *source.Event, 10)
+	go func() {
+		for event := range env.events {
+			events <- event
+		}
+	}()
+
+	next := http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusTeapot)
+	})
+
+	server := httptest.NewServer(BulkHandler(env, next))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/api/v1/profiles/user_id:user/traits")
+	if err != nil || res.StatusCode != http.StatusTeapot {
+		t.Errorf("BulkHandler() did not pass the request to the next handler")
+	}
+
+	res, err = post(server.URL+"/api/v1/bulk", "application/x-ndjson", "", "{}")
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("BulkHandler() accepted a request without write key")
+	}
+
+	res, err = post(server.URL+"/api/v1/bulk", "application/x-ndjson", "invalid", "{}")
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("BulkHandler() accepted a request with an invalid write key")
+	}
+
+	res, err = post(server.URL+"/api/v1/bulk", "application/x-ndjson", "import", "")
+	if err != nil || res.StatusCode != http.StatusOK {
+		t.Errorf("BulkHandler() rejected a request with an import key")
+	}
+
+	res, err = post(server.URL+"/api/v1/bulk", "application/json", "key", "{}")
+	if err != nil || res.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("BulkHandler() accepted a content type other than NDJSON")
+	}
+
+	lines := []string{
+		`{"type":"track","messageId":"1","userId":"user","event":"Order Completed"}`,
+		`{"type":"track","messageId":"2","userId":"user"}`,
+		``,
+		`{"type":"page","messageId":"3","anonymousId":"anonymous","name":"Home"}`,
+		`{"type":"track","messageId":"4","userId":"user","event":"` + strings.Repeat("a", 128) + `"}`,
+		`not json`,
+		`{"type":"identify","messageId":"5","userId":"user"}`,
+	}
+
+	res, err = post(server.URL+"/api/v1/bulk", "application/x-ndjson", "key", strings.Join(lines, "\n"))
+	if err != nil {
+		t.Fatalf("http.Post() error = %v", err)
+	}
+
+	defer res.Body.Close()
+	var body bulkResponse
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("json.Decode() error = %v", err)
+	}
+
+	if body.StatusCode != 200 || body.Data.Lines != 7 || body.Data.Accepted != 3 || body.Data.Rejected != 3 {
+		t.Errorf("BulkHandler() data = %+v", body.Data)
+	}
+
+	if body.Data.Truncated {
+		t.Errorf("BulkHandler() truncated = %v, want %v", body.Data.Truncated, false)
+	}
+
+	want := []int{2, 5, 6}
+	for i, result := range body.Data.Results {
+		if result.Line != want[i] {
+			t.Errorf("BulkHandler() result line = %v, want %v", result.Line, want[i])
+		}
+	}
+
+	chunks := []int{2, 1}
+	for i, size := range chunks {
+		select {
+		case event := <-events:
+			if len(event.SubEvents) != size {
+				t.Errorf("BulkHandler() sub-events = %v, want %v", len(event.SubEvents), size)
+			}
+
+		case <-time.After(time.Second):
+			t.Fatalf("BulkHandler() events = %v, want %v", i, len(chunks))
+		}
+	}
+}
+
+func TestBulkHandlerTruncated(t *testing.T) {
+	env := &Options{
+		Bulk: &BulkOptions{
+			WriteKeys:  []string{"key"},
+			MaxResults: 2,
+		},
+	}
+
+	if err := env.validate(); err != nil {
+		t.Fatalf("Options.validate() error = %v", err)
+	}
+
+	server := httptest.NewServer(BulkHandler(env, nil))
+	defer server.Close()
+
+	lines := strings.Repeat("not json\n", 5)
+	res, err := post(server.URL+"/api/v1/bulk", "application/x-ndjson", "key", lines)
+	if err != nil {
+		t.Fatalf("http.Post() error = %v", err)
+	}
+
+	defer res.Body.Close()
+	var body bulkResponse
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("json.Decode() error = %v", err)
+	}
+
+	if body.Data.Rejected != 5 || len(body.Data.Results) != 2 || !body.Data.Truncated {
+		t.Errorf("BulkHandler() data = %+v", body.Data)
+	}
+}
+
+/*
+post sends a request to the NDJSON endpoint, authenticated with a write key if
+not empty.
+*/
+func post(url string, contentType string, key string, body string) (*http.Response, error) {
+	req, _ := http.NewRequest("POST", url, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+
+	return http.DefaultClient.Do(req)
+}
+
+func TestReadLine(t *testing.T) {
+	reader := bufio.NewReaderSize(strings.NewReader("short\n"+strings.Repeat("a", 64)+"\nlast"), 16)
+	tests := []struct {
+		line    string
+		tooLong bool
+	}{
+		{line: "short"},
+		{tooLong: true},
+		{line: "last"},
+	}
+
+	for _, tt := range tests {
+		line, tooLong, err := readLine(reader, 32)
+		if err != nil {
+			t.Fatalf("readLine() error = %v", err)
+		}
+
+		if string(line) != tt.line || tooLong != tt.tooLong {
+			t.Errorf("readLine() = %q, %v, want %q, %v", line, tooLong, tt.line, tt.tooLong)
+		}
+	}
+
+	if _, _, err := readLine(reader, 32); err != io.EOF {
+		t.Errorf("readLine() error = %v, want %v", err, io.EOF)
+	}
+}
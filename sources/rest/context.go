@@ -0,0 +1,190 @@
+package rest
+
+import (
+	"encoding/json"
+
+	"github.com/nunchistudio/blacksmith/flow"
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/helper/logger"
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/blacksmith-modules/segment/segmentflow"
+
+	"github.com/nunchistudio/fragment/flows/fragmentflow"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Message holds the details of a message which are not part of its context nor its
+data. It is stored within the context of the event under the key "message", so
+the events can later be found for a given user, such as in the profile API.
+*/
+type Message struct {
+	Type        string `json:"type"`
+	MessageId   string `json:"messageId,omitempty"`
+	UserId      string `json:"userId,omitempty"`
+	AnonymousId string `json:"anonymousId,omitempty"`
+	Event       string `json:"event,omitempty"`
+	Name        string `json:"name,omitempty"`
+	GroupId     string `json:"groupId,omitempty"`
+	PreviousId  string `json:"previousId,omitempty"`
+}
+
+/*
+MarshalContext returns the JSON encoding of the context of a message, including
+the message details. The context of the message itself is left untouched since
+it is also used by the flows. It is exported so events created by other sources
+follow the same format.
+*/
+func MarshalContext(ctx *analytics.Context, msg Message) ([]byte, error) {
+	c := analytics.Context{}
+	if ctx != nil {
+		c = *ctx
+	}
+
+	extra := make(map[string]interface{}, len(c.Extra)+1)
+	for key, value := range c.Extra {
+		extra[key] = value
+	}
+
+	extra["message"] = msg
+	c.Extra = extra
+
+	return c.MarshalJSON()
+}
+
+/*
+TrackEvent returns the sub-event for a Track created by Fragment, such as when a
+profile entered or exited an audience. It is exported so the sources of Fragment
+creating events on behalf of users share the same format.
+*/
+func TrackEvent(track analytics.Track) (*source.SubEvent, *errors.Error) {
+	ctx, err := MarshalContext(track.Context, Message{
+		Type:        "track",
+		MessageId:   track.MessageId,
+		UserId:      track.UserId,
+		AnonymousId: track.AnonymousId,
+		Event:       track.Event,
+	})
+	if err != nil {
+		return nil, &errors.Error{
+			Message: "source/rest: Failed to marshal context",
+		}
+	}
+
+	data, err := json.Marshal(&track.Properties)
+	if err != nil {
+		return nil, &errors.Error{
+			Message: "source/rest: Failed to marshal properties",
+		}
+	}
+
+	return &source.SubEvent{
+		Trigger: "track",
+		Context: ctx,
+		Data:    data,
+		Flows: []flow.Flow{
+			&fragmentflow.Track{
+				Track: segmentflow.Track{
+					Track: track,
+				},
+			},
+		},
+	}, nil
+}
+
+/*
+IdentifyEvent returns the sub-event for an Identify created by Fragment, such as
+when computed traits changed or when traits are synced from the warehouse.
+*/
+func IdentifyEvent(identify analytics.Identify) (*source.SubEvent, *errors.Error) {
+	ctx, err := MarshalContext(identify.Context, Message{
+		Type:        "identify",
+		MessageId:   identify.MessageId,
+		UserId:      identify.UserId,
+		AnonymousId: identify.AnonymousId,
+	})
+	if err != nil {
+		return nil, &errors.Error{
+			Message: "source/rest: Failed to marshal context",
+		}
+	}
+
+	data, err := json.Marshal(&identify.Traits)
+	if err != nil {
+		return nil, &errors.Error{
+			Message: "source/rest: Failed to marshal traits",
+		}
+	}
+
+	return &source.SubEvent{
+		Trigger: "identify",
+		Context: ctx,
+		Data:    data,
+		Flows: []flow.Flow{
+			&fragmentflow.Identify{
+				Identify: segmentflow.Identify{
+					Identify: identify,
+				},
+			},
+		},
+	}, nil
+}
+
+/*
+GroupEvent returns the sub-event for a Group created by Fragment, such as when
+traits of an account are synced from the warehouse.
+*/
+func GroupEvent(group analytics.Group) (*source.SubEvent, *errors.Error) {
+	ctx, err := MarshalContext(group.Context, Message{
+		Type:        "group",
+		MessageId:   group.MessageId,
+		UserId:      group.UserId,
+		AnonymousId: group.AnonymousId,
+		GroupId:     group.GroupId,
+	})
+	if err != nil {
+		return nil, &errors.Error{
+			Message: "source/rest: Failed to marshal context",
+		}
+	}
+
+	data, err := json.Marshal(&group.Traits)
+	if err != nil {
+		return nil, &errors.Error{
+			Message: "source/rest: Failed to marshal traits",
+		}
+	}
+
+	return &source.SubEvent{
+		Trigger: "group",
+		Context: ctx,
+		Data:    data,
+		Flows: []flow.Flow{
+			&fragmentflow.Group{
+				Group: segmentflow.Group{
+					Group: group,
+				},
+			},
+		},
+	}, nil
+}
+
+/*
+internal returns the error returned to the client when a store failed while
+enriching a message. The underlying error is only logged, so the details of the
+stores are never exposed to the client.
+
+The stores are updated one after the other before the event is persisted by the
+gateway, so the writes which succeeded are not rolled back. They are idempotent
+given the message ID, so a client can safely retry the message.
+*/
+func internal(err error) *errors.Error {
+	logger.Default.Error(err)
+
+	return &errors.Error{
+		StatusCode: 500,
+		Message:    "Internal Server Error",
+	}
+}
@@ -0,0 +1,27 @@
+package rest
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+)
+
+func TestInternal(t *testing.T) {
+	err := &errors.Error{
+		Message: "identity: Failed to link identifiers",
+		Validations: []errors.Validation{
+			{
+				Message: "connection refused",
+			},
+		},
+	}
+
+	fail := internal(err)
+	if fail.StatusCode != 500 {
+		t.Errorf("internal() status = %v, want %v", fail.StatusCode, 500)
+	}
+
+	if fail.Message != "Internal Server Error" || len(fail.Validations) != 0 {
+		t.Errorf("internal() validations = %v", fail.Validations)
+	}
+}
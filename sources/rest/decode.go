@@ -0,0 +1,158 @@
+package rest
+
+import (
+	"encoding/json"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/source"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Decode validates a single message following the Segment spec, given its "type"
+key, and returns its sub-event alongside the flows to run. It is exported so the
+sources receiving Segment messages from other transports than HTTP share the same
+validation, enrichment, and flows as the source "rest".
+*/
+func Decode(env *Options, b []byte) (*source.SubEvent, *errors.Error) {
+	return decodeMessage(env, b, false)
+}
+
+/*
+decodeMessage validates a single message given its "type" key, as Decode does.
+Imported is true for the messages uploaded with an import key, and is never set
+from the message itself so a client can not claim its messages are imported.
+*/
+func decodeMessage(env *Options, b []byte, imported bool) (*source.SubEvent, *errors.Error) {
+	var message struct {
+		Type string `json:"type"`
+	}
+
+	if err := json.Unmarshal(b, &message); err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+			Validations: []errors.Validation{
+				{
+					Message: err.Error(),
+					Path:    []string{"analytics", "Message"},
+				},
+			},
+		}
+	}
+
+	subevent, fail := decode(env, message.Type, b, imported)
+	if fail != nil {
+		return nil, fail
+	}
+
+	if subevent == nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+			Validations: []errors.Validation{
+				{
+					Message: "type '" + message.Type + "' is not supported",
+					Path:    []string{"analytics", "Message", "type"},
+				},
+			},
+		}
+	}
+
+	return subevent, nil
+}
+
+/*
+decode unmarshals a message with the appropriate struct given its type, and
+returns its sub-event. No sub-event is returned if the type is not supported.
+*/
+func decode(env *Options, typ string, b []byte, imported bool) (*source.SubEvent, *errors.Error) {
+	switch typ {
+	case "identify":
+		e := Identify{
+			env:      env,
+			imported: imported,
+		}
+		json.Unmarshal(b, &e)
+		return e.marshal()
+
+	case "track":
+		e := Track{
+			env:      env,
+			imported: imported,
+		}
+		json.Unmarshal(b, &e)
+		return e.marshal()
+
+	case "group":
+		e := Group{
+			env: env,
+		}
+		json.Unmarshal(b, &e)
+		return e.marshal()
+
+	case "alias":
+		e := Alias{
+			env: env,
+		}
+		json.Unmarshal(b, &e)
+		return e.marshal()
+
+	case "page":
+		e := Page{
+			env:      env,
+			imported: imported,
+		}
+		json.Unmarshal(b, &e)
+		return e.marshal()
+
+	case "screen":
+		e := Screen{
+			env:      env,
+			imported: imported,
+		}
+		json.Unmarshal(b, &e)
+		return e.marshal()
+	}
+
+	return nil, nil
+}
+
+/*
+Marshal validates a message already decoded into one of the types of the Segment
+library, and returns its sub-event alongside the flows to run. It is exported for
+the sources receiving messages in another format than JSON.
+*/
+func Marshal(env *Options, msg analytics.Message) (*source.SubEvent, *errors.Error) {
+	switch m := msg.(type) {
+	case analytics.Identify:
+		return Identify{env: env, Identify: m}.marshal()
+
+	case analytics.Track:
+		return Track{env: env, Track: m}.marshal()
+
+	case analytics.Group:
+		return Group{env: env, Group: m}.marshal()
+
+	case analytics.Alias:
+		return Alias{env: env, Alias: m}.marshal()
+
+	case analytics.Page:
+		return Page{env: env, Page: m}.marshal()
+
+	case analytics.Screen:
+		return Screen{env: env, Screen: m}.marshal()
+	}
+
+	return nil, &errors.Error{
+		StatusCode: 400,
+		Message:    "Bad Request",
+		Validations: []errors.Validation{
+			{
+				Message: "type of message is not supported",
+				Path:    []string{"analytics", "Message"},
+			},
+		},
+	}
+}
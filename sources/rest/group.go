@@ -12,6 +12,8 @@ import (
 
 	"github.com/nunchistudio/blacksmith-modules/segment/segmentflow"
 
+	"github.com/nunchistudio/fragment/flows/fragmentflow"
+
 	"gopkg.in/segmentio/analytics-go.v3"
 )
 
@@ -62,7 +64,9 @@ func (t Group) Extract(tk *source.Toolkit, req *http.Request) (*source.Event, er
 
 	// Create an empty payload, catch unwanted fields, and unmarshal it.
 	// Return an error if any occured.
-	var payload Group
+	payload := Group{
+		env: t.env,
+	}
 	decoder := json.NewDecoder(req.Body)
 	decoder.DisallowUnknownFields()
 	err := decoder.Decode(&payload)
@@ -124,16 +128,43 @@ func (t Group) marshal() (*source.SubEvent, *errors.Error) {
 		}
 	}
 
-	// Try to marshal the context from the request payload.
-	var ctx []byte
-	if t.Context != nil {
-		ctx, err = t.Context.MarshalJSON()
-		if err != nil {
+	// Normalize the traits if enabled for the source. Return the validation
+	// errors if any occurred.
+	if t.env.Normalize != nil {
+		traits, validations := t.env.Normalize.Traits(t.Traits, []string{"analytics", "Group", "Traits"})
+		if len(validations) > 0 {
 			return nil, &errors.Error{
-				StatusCode: 400,
-				Message:    "Bad Request",
+				StatusCode:  400,
+				Message:     "Bad Request",
+				Validations: validations,
 			}
 		}
+
+		t.Traits = traits
+	}
+
+	// Update the account and its memberships if enabled for the source.
+	if t.env.Accounts != nil {
+		err = t.env.Accounts.Group(t.Group)
+		if err != nil {
+			return nil, internal(err)
+		}
+	}
+
+	// Try to marshal the context from the request payload, including the message
+	// details.
+	ctx, err := MarshalContext(t.Context, Message{
+		Type:        "group",
+		MessageId:   t.MessageId,
+		UserId:      t.UserId,
+		AnonymousId: t.AnonymousId,
+		GroupId:     t.GroupId,
+	})
+	if err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+		}
 	}
 
 	// Try to marshal the data from the request payload.
@@ -154,8 +185,10 @@ func (t Group) marshal() (*source.SubEvent, *errors.Error) {
 		Context: ctx,
 		Data:    data,
 		Flows: []flow.Flow{
-			&segmentflow.Group{
-				Group: t.Group,
+			&fragmentflow.Group{
+				Group: segmentflow.Group{
+					Group: t.Group,
+				},
 			},
 		},
 	}, nil
@@ -12,6 +12,8 @@ import (
 
 	"github.com/nunchistudio/blacksmith-modules/segment/segmentflow"
 
+	"github.com/nunchistudio/fragment/flows/fragmentflow"
+
 	"gopkg.in/segmentio/analytics-go.v3"
 )
 
@@ -23,6 +25,10 @@ will be received by the gateway.
 type Identify struct {
 	env *Options
 
+	// imported is true for the messages uploaded to the NDJSON endpoint with an
+	// import key.
+	imported bool
+
 	analytics.Identify
 }
 
@@ -62,7 +68,9 @@ func (t Identify) Extract(tk *source.Toolkit, req *http.Request) (*source.Event,
 
 	// Create an empty payload, catch unwanted fields, and unmarshal it.
 	// Return an error if any occured.
-	var payload Identify
+	payload := Identify{
+		env: t.env,
+	}
 	decoder := json.NewDecoder(req.Body)
 	decoder.DisallowUnknownFields()
 	err := decoder.Decode(&payload)
@@ -124,16 +132,78 @@ func (t Identify) marshal() (*source.SubEvent, *errors.Error) {
 		}
 	}
 
-	// Try to marshal the context from the request payload.
-	var ctx []byte
-	if t.Context != nil {
-		ctx, err = t.Context.MarshalJSON()
-		if err != nil {
+	// Normalize the traits if enabled for the source. Return the validation
+	// errors if any occurred.
+	if t.env.Normalize != nil {
+		traits, validations := t.env.Normalize.Traits(t.Traits, []string{"analytics", "Identify", "Traits"})
+		if len(validations) > 0 {
 			return nil, &errors.Error{
-				StatusCode: 400,
-				Message:    "Bad Request",
+				StatusCode:  400,
+				Message:     "Bad Request",
+				Validations: validations,
+			}
+		}
+
+		t.Traits = traits
+	}
+
+	// Link the identifiers of the user in the identity graph if enabled for the
+	// source, merge the traits into the user's profile, refresh the attribution
+	// traits since profiles may have been merged, and evaluate the audiences which
+	// may have changed with these traits, unless the message has been imported.
+	var attributed *analytics.Identify
+	var memberships []analytics.Track
+	if t.env.Identity != nil {
+		profileID, err := t.env.Identity.Identify(t.Identify)
+		if err != nil {
+			return nil, internal(err)
+		}
+
+		if t.env.Profiles != nil {
+			err = t.env.Profiles.Identify(profileID, t.Identify)
+			if err != nil {
+				return nil, internal(err)
+			}
+		}
+
+		if t.env.Attribution != nil {
+			attributed, err = t.env.Attribution.Refresh(profileID, t.UserId, t.AnonymousId)
+			if err != nil {
+				return nil, internal(err)
 			}
 		}
+
+		if t.env.Audiences != nil && !t.imported {
+			memberships, err = t.env.Audiences.Identify(profileID, t.Identify)
+			if err != nil {
+				return nil, internal(err)
+			}
+		}
+	}
+
+	// Only send the event to the destinations if the traits of the user changed
+	// since the last time they were sent, if enabled for the source.
+	forward := true
+	if t.env.Dedupe != nil {
+		forward, err = t.env.Dedupe.Changed(t.Identify)
+		if err != nil {
+			return nil, internal(err)
+		}
+	}
+
+	// Try to marshal the context from the request payload, including the message
+	// details.
+	ctx, err := MarshalContext(t.Context, Message{
+		Type:        "identify",
+		MessageId:   t.MessageId,
+		UserId:      t.UserId,
+		AnonymousId: t.AnonymousId,
+	})
+	if err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
+		}
 	}
 
 	// Try to marshal the data from the request payload.
@@ -148,15 +218,40 @@ func (t Identify) marshal() (*source.SubEvent, *errors.Error) {
 		}
 	}
 
+	// Create the flows to run. The event is still stored when its traits did not
+	// change, but no jobs are created for the destinations. Attribution traits
+	// which changed are sent to the destinations using the Identify flow, and
+	// audience memberships which changed using the Track flow.
+	flows := []flow.Flow{}
+	if forward {
+		flows = append(flows, &fragmentflow.Identify{
+			Identify: segmentflow.Identify{
+				Identify: t.Identify,
+			},
+		})
+	}
+
+	if attributed != nil {
+		flows = append(flows, &fragmentflow.Identify{
+			Identify: segmentflow.Identify{
+				Identify: *attributed,
+			},
+		})
+	}
+
+	for _, membership := range memberships {
+		flows = append(flows, &fragmentflow.Track{
+			Track: segmentflow.Track{
+				Track: membership,
+			},
+		})
+	}
+
 	// Return the context, data, and a collection of flows to run.
 	return &source.SubEvent{
 		Trigger: "identify",
 		Context: ctx,
 		Data:    data,
-		Flows: []flow.Flow{
-			&segmentflow.Identify{
-				Identify: t.Identify,
-			},
-		},
+		Flows:   flows,
 	}, nil
 }
@@ -0,0 +1,29 @@
+package rest
+
+import (
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+ImportLibrary is the name of the library set in the context of the messages
+imported with the command "fragment import".
+
+Messages are only considered as imported when uploaded to the NDJSON endpoint with
+one of the import keys, never given their library which can be set by any client.
+Imported messages are historical ones, so they are not attached to sessions, do
+not change the audiences of the users, and are never sent to Slack.
+*/
+const ImportLibrary = "fragment-import"
+
+/*
+withoutAlerts returns the integrations of an imported message with the alerting
+destinations disabled, so historical events do not trigger alerts.
+*/
+func withoutAlerts(integrations analytics.Integrations) analytics.Integrations {
+	disabled := analytics.Integrations{}
+	for name, value := range integrations {
+		disabled[name] = value
+	}
+
+	return disabled.Disable("Slack")
+}
@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"testing"
+
+	"github.com/nunchistudio/fragment/flows/fragmentflow"
+)
+
+func TestDecode_Imported(t *testing.T) {
+	message := []byte(`{
+		"type": "track",
+		"userId": "user",
+		"event": "Order Completed",
+		"integrations": {"Mixpanel": true},
+		"context": {"library": {"name": "fragment-import", "version": "1"}}
+	}`)
+
+	tests := []struct {
+		name      string
+		imported  bool
+		wantSlack interface{}
+	}{
+		{
+			name:      "WithImportLibraryOnly",
+			imported:  false,
+			wantSlack: nil,
+		},
+		{
+			name:      "WithImportKey",
+			imported:  true,
+			wantSlack: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subevent, fail := decodeMessage(&Options{}, message, tt.imported)
+			if fail != nil {
+				t.Fatalf("decodeMessage() error = %v", fail)
+			}
+
+			f := subevent.Flows[0].(*fragmentflow.Track)
+			if f.Track.Track.Integrations["Slack"] != tt.wantSlack || f.Track.Track.Integrations["Mixpanel"] != true {
+				t.Errorf("decodeMessage() integrations = %v", f.Track.Track.Integrations)
+			}
+		})
+	}
+
+	// Messages decoded for other transports are never imported.
+	subevent, fail := Decode(&Options{}, message)
+	if fail != nil {
+		t.Fatalf("Decode() error = %v", fail)
+	}
+
+	f := subevent.Flows[0].(*fragmentflow.Track)
+	if _, exists := f.Track.Track.Integrations["Slack"]; exists {
+		t.Errorf("Decode() integrations = %v", f.Track.Track.Integrations)
+	}
+}
@@ -2,12 +2,47 @@ package rest
 
 import (
 	"strings"
+	"time"
 
 	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/fragment/accounts"
+	"github.com/nunchistudio/fragment/attribution"
+	"github.com/nunchistudio/fragment/audiences"
+	"github.com/nunchistudio/fragment/computed"
+	"github.com/nunchistudio/fragment/dedupe"
+	"github.com/nunchistudio/fragment/identity"
+	"github.com/nunchistudio/fragment/normalize"
+	"github.com/nunchistudio/fragment/profiles"
+	"github.com/nunchistudio/fragment/sessions"
 )
 
+/*
+Defaults are the defaults options set for the source. When not set, these values
+will automatically be applied.
+*/
+var Defaults = &Options{
+	WebSocket: &WebSocketOptions{
+		MaxMessageSize: 32 << 10,
+		PingInterval:   30 * time.Second,
+	},
+	Bulk: &BulkOptions{
+		ChunkSize:   100,
+		MaxLineSize: 32 << 10,
+		MaxResults:  1000,
+	},
+}
+
 /*
 Options is the options the source can take as an input to be configured.
+
+The stores set in the options are updated while a message is validated, before
+its event is persisted by the gateway. When a store fails, the message is
+rejected with a 500 error but the writes of the previous stores are kept. Linking
+identifiers and merging traits are idempotent, and the computed traits and the
+audiences record the message ID so a retried message is not counted twice.
+Messages without a message ID are not deduplicated.
 */
 type Options struct {
 
@@ -23,6 +58,62 @@ type Options struct {
 	//
 	// Example: "/cdp"
 	Prefix string
+
+	// Normalize is the normalization options applied to the traits and properties
+	// of every events received by the source. When nil, events are not normalized.
+	Normalize *normalize.Options
+
+	// Identity is the identity graph updated every time an "identify" or "alias"
+	// event is received by the source. When nil, the identity graph is disabled.
+	Identity *identity.Graph
+
+	// Profiles is the profile store updated with the traits of every "identify"
+	// event received by the source. It requires the identity graph to be enabled.
+	Profiles *profiles.Store
+
+	// Accounts is the account store updated with every "group" event received by
+	// the source. It is also used to attach the traits of the current account of
+	// a user to its "track" and "page" events, if enabled in the account store.
+	Accounts *accounts.Store
+
+	// Computed is the store of computed traits evaluated every time a "track"
+	// event is received by the source.
+	Computed *computed.Store
+
+	// Audiences is the store of audiences evaluated every time an "identify" or a
+	// "track" event is received by the source.
+	Audiences *audiences.Store
+
+	// Attribution is the store of first and last touches updated every time a
+	// "page" or "track" event is received by the source, and refreshed when
+	// profiles are merged on "identify" and "alias" events. It requires the
+	// identity graph to be enabled.
+	Attribution *attribution.Store
+
+	// Sessions is the store of sessions attached to the context of every "page",
+	// "screen", and "track" event received by the source. When nil, events are not
+	// sessionized.
+	Sessions *sessions.Store
+
+	// Dedupe is used to only send "identify" events to the destinations when the
+	// traits of the user changed. When nil, every "identify" event is sent. The
+	// traits are recorded when the event is received, so a destination failing to
+	// load them only receives them again once the refresh interval elapsed.
+	Dedupe *dedupe.Store
+
+	// WebSocket is the options of the WebSocket endpoint "/v1/stream", exposed by
+	// the handler returned by WebSocketHandler. When nil, the endpoint is disabled.
+	WebSocket *WebSocketOptions
+
+	// Bulk is the options of the NDJSON endpoint "/v1/bulk", exposed by the handler
+	// returned by BulkHandler. When nil, the endpoint is disabled.
+	Bulk *BulkOptions
+
+	// events and closing are shared by the endpoints not registered as triggers,
+	// such as the WebSocket and NDJSON endpoints. Their events are forwarded to the
+	// gateway by the trigger "stream".
+	events  chan *source.Event
+	closing chan struct{}
 }
 
 /*
@@ -50,6 +141,60 @@ func (env *Options) validate() error {
 		}
 	}
 
+	if env.Normalize != nil {
+		fail.Validations = append(fail.Validations, env.Normalize.Validate([]string{"Options", "Sources", "rest", "Normalize"})...)
+	}
+
+	if env.Identity == nil && (env.Profiles != nil || env.Computed != nil || env.Audiences != nil || env.Attribution != nil) {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Identity graph must be set when using profiles, computed traits, audiences, or attribution",
+			Path:    []string{"Options", "Sources", "rest", "Identity"},
+		})
+	}
+
+	if env.WebSocket != nil {
+		if len(env.WebSocket.WriteKeys) == 0 {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "At least one write key must be set",
+				Path:    []string{"Options", "Sources", "rest", "WebSocket", "WriteKeys"},
+			})
+		}
+
+		if env.WebSocket.MaxMessageSize <= 0 {
+			env.WebSocket.MaxMessageSize = Defaults.WebSocket.MaxMessageSize
+		}
+
+		if env.WebSocket.PingInterval <= 0 {
+			env.WebSocket.PingInterval = Defaults.WebSocket.PingInterval
+		}
+	}
+
+	if env.Bulk != nil {
+		if len(env.Bulk.WriteKeys) == 0 {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "At least one write key must be set",
+				Path:    []string{"Options", "Sources", "rest", "Bulk", "WriteKeys"},
+			})
+		}
+
+		if env.Bulk.ChunkSize <= 0 {
+			env.Bulk.ChunkSize = Defaults.Bulk.ChunkSize
+		}
+
+		if env.Bulk.MaxLineSize <= 0 {
+			env.Bulk.MaxLineSize = Defaults.Bulk.MaxLineSize
+		}
+
+		if env.Bulk.MaxResults <= 0 {
+			env.Bulk.MaxResults = Defaults.Bulk.MaxResults
+		}
+	}
+
+	if env.WebSocket != nil || env.Bulk != nil {
+		env.events = make(chan *source.Event)
+		env.closing = make(chan struct{})
+	}
+
 	if len(fail.Validations) > 0 {
 		return fail
 	}
@@ -12,6 +12,8 @@ import (
 
 	"github.com/nunchistudio/blacksmith-modules/segment/segmentflow"
 
+	"github.com/nunchistudio/fragment/flows/fragmentflow"
+
 	"gopkg.in/segmentio/analytics-go.v3"
 )
 
@@ -23,6 +25,10 @@ will be received by the gateway.
 type Page struct {
 	env *Options
 
+	// imported is true for the messages uploaded to the NDJSON endpoint with an
+	// import key.
+	imported bool
+
 	analytics.Page
 }
 
@@ -62,7 +68,9 @@ func (t Page) Extract(tk *source.Toolkit, req *http.Request) (*source.Event, err
 
 	// Create an empty payload, catch unwanted fields, and unmarshal it.
 	// Return an error if any occured.
-	var payload Page
+	payload := Page{
+		env: t.env,
+	}
 	decoder := json.NewDecoder(req.Body)
 	decoder.DisallowUnknownFields()
 	err := decoder.Decode(&payload)
@@ -124,15 +132,58 @@ func (t Page) marshal() (*source.SubEvent, *errors.Error) {
 		}
 	}
 
-	// Try to marshal the context from the request payload.
-	var ctx []byte
-	if t.Context != nil {
-		ctx, err = t.Context.MarshalJSON()
+	// Normalize the properties if enabled for the source.
+	if t.env.Normalize != nil {
+		t.Properties = t.env.Normalize.Properties(t.Properties)
+	}
+
+	// Attach the traits of the user's current account to the context if enabled
+	// for the source.
+	if t.env.Accounts != nil {
+		t.Context, err = t.env.Accounts.Attach(t.Context, t.UserId, t.AnonymousId)
 		if err != nil {
-			return nil, &errors.Error{
-				StatusCode: 400,
-				Message:    "Bad Request",
-			}
+			return nil, internal(err)
+		}
+	}
+
+	// Attach the session of the user to the context if enabled for the source. A
+	// "Session Started" event is returned when a new session started. Imported
+	// messages are not part of the current sessions.
+	var started []analytics.Track
+	if t.env.Sessions != nil && !t.imported {
+		t.Context, started, err = t.env.Sessions.Attach(t.Timestamp, t.Context, t.UserId, t.AnonymousId)
+		if err != nil {
+			return nil, internal(err)
+		}
+	}
+
+	// Record the touch of the user found in the context if enabled for the source.
+	// An Identify is returned when attribution traits changed.
+	var attributed *analytics.Identify
+	if t.env.Attribution != nil {
+		profileID, err := t.env.Identity.User(t.Timestamp, t.UserId, t.AnonymousId)
+		if err == nil {
+			attributed, err = t.env.Attribution.Touch(profileID, t.UserId, t.AnonymousId, t.Timestamp, t.Context)
+		}
+
+		if err != nil {
+			return nil, internal(err)
+		}
+	}
+
+	// Try to marshal the context from the request payload, including the message
+	// details.
+	ctx, err := MarshalContext(t.Context, Message{
+		Type:        "page",
+		MessageId:   t.MessageId,
+		UserId:      t.UserId,
+		AnonymousId: t.AnonymousId,
+		Name:        t.Name,
+	})
+	if err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
 		}
 	}
 
@@ -148,15 +199,38 @@ func (t Page) marshal() (*source.SubEvent, *errors.Error) {
 		}
 	}
 
+	// Create the flows to run. Attribution traits which changed are sent to the
+	// destinations using the Identify flow, and started sessions using the Track
+	// flow.
+	flows := []flow.Flow{
+		&fragmentflow.Page{
+			Page: segmentflow.Page{
+				Page: t.Page,
+			},
+		},
+	}
+
+	if attributed != nil {
+		flows = append(flows, &fragmentflow.Identify{
+			Identify: segmentflow.Identify{
+				Identify: *attributed,
+			},
+		})
+	}
+
+	for _, session := range started {
+		flows = append(flows, &fragmentflow.Track{
+			Track: segmentflow.Track{
+				Track: session,
+			},
+		})
+	}
+
 	// Return the context, data, and a collection of flows to run.
 	return &source.SubEvent{
 		Trigger: "page",
 		Context: ctx,
 		Data:    data,
-		Flows: []flow.Flow{
-			&segmentflow.Page{
-				Page: t.Page,
-			},
-		},
+		Flows:   flows,
 	}, nil
 }
@@ -12,6 +12,8 @@ import (
 
 	"github.com/nunchistudio/blacksmith-modules/segment/segmentflow"
 
+	"github.com/nunchistudio/fragment/flows/fragmentflow"
+
 	"gopkg.in/segmentio/analytics-go.v3"
 )
 
@@ -23,6 +25,10 @@ will be received by the gateway.
 type Screen struct {
 	env *Options
 
+	// imported is true for the messages uploaded to the NDJSON endpoint with an
+	// import key.
+	imported bool
+
 	analytics.Screen
 }
 
@@ -62,7 +68,9 @@ func (t Screen) Extract(tk *source.Toolkit, req *http.Request) (*source.Event, e
 
 	// Create an empty payload, catch unwanted fields, and unmarshal it.
 	// Return an error if any occured.
-	var payload Screen
+	payload := Screen{
+		env: t.env,
+	}
 	decoder := json.NewDecoder(req.Body)
 	decoder.DisallowUnknownFields()
 	err := decoder.Decode(&payload)
@@ -124,15 +132,35 @@ func (t Screen) marshal() (*source.SubEvent, *errors.Error) {
 		}
 	}
 
-	// Try to marshal the context from the request payload.
-	var ctx []byte
-	if t.Context != nil {
-		ctx, err = t.Context.MarshalJSON()
+	// Normalize the properties if enabled for the source.
+	if t.env.Normalize != nil {
+		t.Properties = t.env.Normalize.Properties(t.Properties)
+	}
+
+	// Attach the session of the user to the context if enabled for the source. A
+	// "Session Started" event is returned when a new session started. Imported
+	// messages are not part of the current sessions.
+	var started []analytics.Track
+	if t.env.Sessions != nil && !t.imported {
+		t.Context, started, err = t.env.Sessions.Attach(t.Timestamp, t.Context, t.UserId, t.AnonymousId)
 		if err != nil {
-			return nil, &errors.Error{
-				StatusCode: 400,
-				Message:    "Bad Request",
-			}
+			return nil, internal(err)
+		}
+	}
+
+	// Try to marshal the context from the request payload, including the message
+	// details.
+	ctx, err := MarshalContext(t.Context, Message{
+		Type:        "screen",
+		MessageId:   t.MessageId,
+		UserId:      t.UserId,
+		AnonymousId: t.AnonymousId,
+		Name:        t.Name,
+	})
+	if err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
 		}
 	}
 
@@ -148,15 +176,29 @@ func (t Screen) marshal() (*source.SubEvent, *errors.Error) {
 		}
 	}
 
+	// Create the flows to run. Started sessions are sent to the destinations
+	// using the Track flow.
+	flows := []flow.Flow{
+		&fragmentflow.Screen{
+			Screen: segmentflow.Screen{
+				Screen: t.Screen,
+			},
+		},
+	}
+
+	for _, session := range started {
+		flows = append(flows, &fragmentflow.Track{
+			Track: segmentflow.Track{
+				Track: session,
+			},
+		})
+	}
+
 	// Return the context, data, and a collection of flows to run.
 	return &source.SubEvent{
 		Trigger: "screen",
 		Context: ctx,
 		Data:    data,
-		Flows: []flow.Flow{
-			&segmentflow.Screen{
-				Screen: t.Screen,
-			},
-		},
+		Flows:   flows,
 	}, nil
 }
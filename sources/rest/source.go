@@ -54,10 +54,11 @@ func (s *REST) Options() *source.Options {
 }
 
 /*
-Triggers return a list of triggers the source REST is able to handle.
+Triggers return a list of triggers the source REST is able to handle. The trigger
+"stream" is only registered when the WebSocket or NDJSON endpoint is enabled.
 */
 func (s *REST) Triggers() map[string]source.Trigger {
-	return map[string]source.Trigger{
+	triggers := map[string]source.Trigger{
 		"identify": Identify{
 			env: s.env,
 		},
@@ -80,4 +81,12 @@ func (s *REST) Triggers() map[string]source.Trigger {
 			env: s.env,
 		},
 	}
+
+	if s.env.WebSocket != nil || s.env.Bulk != nil {
+		triggers["stream"] = Stream{
+			env: s.env,
+		}
+	}
+
+	return triggers
 }
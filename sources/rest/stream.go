@@ -0,0 +1,53 @@
+package rest
+
+import (
+	"github.com/nunchistudio/blacksmith/source"
+)
+
+/*
+Stream implements the Blacksmith source.Trigger interface for the trigger
+"stream". It forwards the events received by the WebSocket and NDJSON
+endpoints to the gateway.
+*/
+type Stream struct {
+	env *Options
+}
+
+/*
+String returns the string representation of the trigger Stream.
+*/
+func (t Stream) String() string {
+	return "stream"
+}
+
+/*
+Mode allows to register the trigger as a CDC. This means the Extract function is
+run once by the gateway, and forwards the events of the WebSocket and NDJSON
+endpoints for as long as the gateway is running.
+*/
+func (t Stream) Mode() *source.Mode {
+	return &source.Mode{
+		Mode: source.ModeCDC,
+	}
+}
+
+/*
+Extract is the function being run when the gateway starts. It is in charge of
+the "E" in the ETL process: Extract the data from the source.
+
+The WebSocket connections are closed and the NDJSON uploads are stopped when the
+gateway is shutting down, so clients can resume with another instance.
+*/
+func (t Stream) Extract(tk *source.Toolkit, notifier *source.Notifier) {
+	for {
+		select {
+		case event := <-t.env.events:
+			notifier.Event <- event
+
+		case <-notifier.IsShuttingDown:
+			close(t.env.closing)
+			notifier.Done <- true
+			return
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package rest
+
+import (
+	"github.com/nunchistudio/blacksmith/source"
+)
+
+var _ source.Trigger = Stream{}
+var _ source.TriggerCDC = Stream{}
@@ -12,6 +12,8 @@ import (
 
 	"github.com/nunchistudio/blacksmith-modules/segment/segmentflow"
 
+	"github.com/nunchistudio/fragment/flows/fragmentflow"
+
 	"gopkg.in/segmentio/analytics-go.v3"
 )
 
@@ -23,6 +25,10 @@ will be received by the gateway.
 type Track struct {
 	env *Options
 
+	// imported is true for the messages uploaded to the NDJSON endpoint with an
+	// import key.
+	imported bool
+
 	analytics.Track
 }
 
@@ -62,7 +68,9 @@ func (t Track) Extract(tk *source.Toolkit, req *http.Request) (*source.Event, er
 
 	// Create an empty payload, catch unwanted fields, and unmarshal it.
 	// Return an error if any occured.
-	var payload Track
+	payload := Track{
+		env: t.env,
+	}
 	decoder := json.NewDecoder(req.Body)
 	decoder.DisallowUnknownFields()
 	err := decoder.Decode(&payload)
@@ -124,15 +132,76 @@ func (t Track) marshal() (*source.SubEvent, *errors.Error) {
 		}
 	}
 
-	// Try to marshal the context from the request payload.
-	var ctx []byte
-	if t.Context != nil {
-		ctx, err = t.Context.MarshalJSON()
+	// Never send imported events to the alerting destinations.
+	if t.imported {
+		t.Integrations = withoutAlerts(t.Integrations)
+	}
+
+	// Normalize the properties if enabled for the source.
+	if t.env.Normalize != nil {
+		t.Properties = t.env.Normalize.Properties(t.Properties)
+	}
+
+	// Attach the traits of the user's current account to the context if enabled
+	// for the source.
+	if t.env.Accounts != nil {
+		t.Context, err = t.env.Accounts.Attach(t.Context, t.UserId, t.AnonymousId)
 		if err != nil {
-			return nil, &errors.Error{
-				StatusCode: 400,
-				Message:    "Bad Request",
-			}
+			return nil, internal(err)
+		}
+	}
+
+	// Attach the session of the user to the context if enabled for the source. A
+	// "Session Started" event is returned when a new session started. Imported
+	// messages are not part of the current sessions.
+	var started []analytics.Track
+	if t.env.Sessions != nil && !t.imported {
+		t.Context, started, err = t.env.Sessions.Attach(t.Timestamp, t.Context, t.UserId, t.AnonymousId)
+		if err != nil {
+			return nil, internal(err)
+		}
+	}
+
+	// Evaluate the computed traits, the attribution, and the audiences for the
+	// user's profile if enabled for the source. An Identify is returned when
+	// computed or attribution traits changed, and tracks are returned when the
+	// user entered or exited audiences. Audiences are not evaluated for imported
+	// messages.
+	var computed *analytics.Identify
+	var attributed *analytics.Identify
+	var memberships []analytics.Track
+	if t.env.Computed != nil || t.env.Audiences != nil || t.env.Attribution != nil {
+		profileID, err := t.env.Identity.User(t.Timestamp, t.UserId, t.AnonymousId)
+		if err == nil && t.env.Computed != nil {
+			computed, err = t.env.Computed.Track(profileID, t.Track)
+		}
+
+		if err == nil && t.env.Attribution != nil {
+			attributed, err = t.env.Attribution.Touch(profileID, t.UserId, t.AnonymousId, t.Timestamp, t.Context)
+		}
+
+		if err == nil && t.env.Audiences != nil && !t.imported {
+			memberships, err = t.env.Audiences.Track(profileID, t.Track)
+		}
+
+		if err != nil {
+			return nil, internal(err)
+		}
+	}
+
+	// Try to marshal the context from the request payload, including the message
+	// details.
+	ctx, err := MarshalContext(t.Context, Message{
+		Type:        "track",
+		MessageId:   t.MessageId,
+		UserId:      t.UserId,
+		AnonymousId: t.AnonymousId,
+		Event:       t.Event,
+	})
+	if err != nil {
+		return nil, &errors.Error{
+			StatusCode: 400,
+			Message:    "Bad Request",
 		}
 	}
 
@@ -148,15 +217,54 @@ func (t Track) marshal() (*source.SubEvent, *errors.Error) {
 		}
 	}
 
+	// Create the flows to run. Computed and attribution traits which changed are
+	// sent to the destinations using the Identify flow, and audience memberships
+	// which changed as well as started sessions using the Track flow.
+	flows := []flow.Flow{
+		&fragmentflow.Track{
+			Track: segmentflow.Track{
+				Track: t.Track,
+			},
+		},
+	}
+
+	if computed != nil {
+		flows = append(flows, &fragmentflow.Identify{
+			Identify: segmentflow.Identify{
+				Identify: *computed,
+			},
+		})
+	}
+
+	if attributed != nil {
+		flows = append(flows, &fragmentflow.Identify{
+			Identify: segmentflow.Identify{
+				Identify: *attributed,
+			},
+		})
+	}
+
+	for _, membership := range memberships {
+		flows = append(flows, &fragmentflow.Track{
+			Track: segmentflow.Track{
+				Track: membership,
+			},
+		})
+	}
+
+	for _, session := range started {
+		flows = append(flows, &fragmentflow.Track{
+			Track: segmentflow.Track{
+				Track: session,
+			},
+		})
+	}
+
 	// Return the context, data, and a collection of flows to run.
 	return &source.SubEvent{
 		Trigger: "track",
 		Context: ctx,
 		Data:    data,
-		Flows: []flow.Flow{
-			&segmentflow.Track{
-				Track: t.Track,
-			},
-		},
+		Flows:   flows,
 	}, nil
 }
@@ -0,0 +1,224 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	helper "github.com/nunchistudio/blacksmith/helper/rest"
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/fragment/auth"
+
+	"github.com/gorilla/websocket"
+)
+
+/*
+WebSocketOptions is the options of the WebSocket endpoint of the source, for
+long-lived clients sending a stream of messages such as kiosks or games.
+*/
+type WebSocketOptions struct {
+
+	// WriteKeys are the keys a client can authenticate with when opening the
+	// connection. The key is passed as the username of the HTTP basic auth, as for
+	// the Segment HTTP API, or with the query parameter "writeKey" for clients not
+	// able to set headers such as browsers.
+	//
+	// Required.
+	WriteKeys []string
+
+	// MaxMessageSize is the maximum size in bytes of a message. The connection is
+	// closed when a client sends a larger message.
+	//
+	// Defaults to 32KB, as for the Segment HTTP API.
+	MaxMessageSize int64
+
+	// PingInterval is the interval at which the server pings the clients. The
+	// connection of a client is closed when it does not answer within the interval.
+	//
+	// Defaults to 30 seconds.
+	PingInterval time.Duration
+}
+
+/*
+ack is the acknowledgment sent to the client for every message received.
+*/
+type ack struct {
+	MessageID string        `json:"messageId"`
+	Success   bool          `json:"success"`
+	Error     *errors.Error `json:"error,omitempty"`
+}
+
+/*
+upgrader upgrades the HTTP connections to the WebSocket protocol. Origins are not
+checked since clients authenticate with a write key.
+*/
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(req *http.Request) bool {
+		return true
+	},
+}
+
+/*
+WebSocketHandler returns the HTTP handler of the WebSocket endpoint of the source,
+exposing the endpoint:
+
+	GET /v1/stream
+
+Once connected, a client sends messages following the Segment spec, one per text
+frame, with a "type" key as for the batch endpoint. Every message is validated and
+sent to the destinations the same way as the POST endpoints, and is acknowledged
+with its message ID:
+
+	{"messageId": "...", "success": true}
+
+Requests to other endpoints are passed to the next handler, so the handler can be
+chained with others. It is meant to be attached to the gateway, which prefixes
+the endpoints with "/api".
+*/
+func WebSocketHandler(env *Options, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		path := strings.TrimPrefix(req.URL.Path, "/api")
+		if env.WebSocket == nil || path != env.Prefix+"/v1/stream" {
+			if next == nil {
+				helper.ErrorNotFound(res, req)
+				return
+			}
+
+			next.ServeHTTP(res, req)
+			return
+		}
+
+		if req.Method != http.MethodGet {
+			helper.ErrorMethodNotAllowed(res, req)
+			return
+		}
+
+		if !env.WebSocket.authenticate(req) {
+			body, _ := json.Marshal(&errors.Error{
+				StatusCode: 401,
+				Message:    "Unauthorized",
+			})
+
+			res.Header().Set("Content-Type", "application/json")
+			res.WriteHeader(http.StatusUnauthorized)
+			res.Write(body)
+			return
+		}
+
+		// The upgrader replies to the client itself if the upgrade failed.
+		conn, err := upgrader.Upgrade(res, req, nil)
+		if err != nil {
+			return
+		}
+
+		env.WebSocket.serve(env, conn)
+	})
+}
+
+/*
+authenticate returns if the request opening a connection holds a valid write key.
+*/
+func (ws *WebSocketOptions) authenticate(req *http.Request) bool {
+	key, _, ok := req.BasicAuth()
+	if !ok {
+		key = req.URL.Query().Get("writeKey")
+	}
+
+	return auth.Valid(key, ws.WriteKeys)
+}
+
+/*
+serve reads the messages of a client until the connection is closed, and sends
+an event to the gateway for every valid message. Messages are acknowledged in the
+order they are received.
+*/
+func (ws *WebSocketOptions) serve(env *Options, conn *websocket.Conn) {
+	done := make(chan struct{})
+	defer close(done)
+	defer conn.Close()
+
+	conn.SetReadLimit(ws.MaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(2 * ws.PingInterval))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * ws.PingInterval))
+	})
+
+	// Ping the client at regular interval, and close the connection when the
+	// gateway is shutting down. Control frames can be written concurrently with
+	// the acknowledgments.
+	go func() {
+		ticker := time.NewTicker(ws.PingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(ws.PingInterval))
+
+			case <-env.closing:
+				message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "shutting down")
+				conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+				conn.Close()
+				return
+
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		_, b, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		a := ws.receive(env, b)
+		if err := conn.WriteJSON(a); err != nil {
+			return
+		}
+	}
+}
+
+/*
+receive validates a message and sends its event to the gateway. It returns the
+acknowledgment to send to the client.
+*/
+func (ws *WebSocketOptions) receive(env *Options, b []byte) *ack {
+	var message struct {
+		MessageId string `json:"messageId"`
+	}
+
+	json.Unmarshal(b, &message)
+	a := &ack{
+		MessageID: message.MessageId,
+	}
+
+	subevent, fail := Decode(env, b)
+	if fail != nil {
+		a.Error = fail
+		return a
+	}
+
+	now := time.Now().UTC()
+	event := &source.Event{
+		Version:   "v1.0",
+		SubEvents: []*source.SubEvent{subevent},
+		SentAt:    &now,
+	}
+
+	select {
+	case env.events <- event:
+		a.Success = true
+	case <-env.closing:
+		a.Error = &errors.Error{
+			StatusCode: 503,
+			Message:    "Service Unavailable",
+		}
+	}
+
+	return a
+}
@@ -0,0 +1,94 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWebSocketHandler(t *testing.T) {
+	env := &Options{
+		WebSocket: &WebSocketOptions{
+			WriteKeys: []string{"key"},
+		},
+	}
+
+	if err := env.validate(); err != nil {
+		t.Fatalf("Options.validate() error = %v", err)
+	}
+
+	events := make(chan *source.Event, 2)
+	go func() {
+		for event := range env.events {
+			events <- event
+		}
+	}()
+
+	next := http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusTeapot)
+	})
+
+	server := httptest.NewServer(WebSocketHandler(env, next))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/api/v1/profiles/user_id:user/traits")
+	if err != nil || res.StatusCode != http.StatusTeapot {
+		t.Errorf("WebSocketHandler() did not pass the request to the next handler")
+	}
+
+	endpoint := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/stream"
+	if _, res, err := websocket.DefaultDialer.Dial(endpoint+"?writeKey=unknown", nil); err == nil || res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("WebSocketHandler() accepted an unknown write key")
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(endpoint+"?writeKey=key", nil)
+	if err != nil {
+		t.Fatalf("websocket.Dial() error = %v", err)
+	}
+
+	defer conn.Close()
+	messages := []string{
+		`{"type":"track","messageId":"valid","userId":"user","event":"Level Completed"}`,
+		`{"type":"track","messageId":"invalid","userId":"user"}`,
+		`{"type":"page","messageId":"page","anonymousId":"anonymous","name":"Home"}`,
+	}
+
+	want := map[string]bool{
+		"valid":   true,
+		"invalid": false,
+		"page":    true,
+	}
+
+	for _, m := range messages {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(m)); err != nil {
+			t.Fatalf("Conn.WriteMessage() error = %v", err)
+		}
+
+		var a ack
+		if err := conn.ReadJSON(&a); err != nil {
+			t.Fatalf("Conn.ReadJSON() error = %v", err)
+		}
+
+		if a.Success != want[a.MessageID] {
+			t.Errorf("WebSocketHandler() ack = %+v", a)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-events:
+			if len(event.SubEvents) != 1 {
+				t.Errorf("WebSocketHandler() sub-events = %v, want %v", len(event.SubEvents), 1)
+			}
+
+		case <-time.After(time.Second):
+			t.Fatalf("WebSocketHandler() events = %v, want %v", i, 2)
+		}
+	}
+}
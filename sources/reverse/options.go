@@ -0,0 +1,160 @@
+package reverse
+
+import (
+	"database/sql"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+)
+
+/*
+TypeIdentify is used to sync the rows of a query as "identify" events. The query
+must return a column "user_id". Every other column is a trait of the user.
+*/
+var TypeIdentify = "identify"
+
+/*
+TypeGroup is used to sync the rows of a query as "group" events. The query must
+return the columns "group_id" and "user_id". Every other column is a trait of the
+group.
+*/
+var TypeGroup = "group"
+
+/*
+queryName is the format of the name of a query, used as the name of its trigger.
+*/
+var queryName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+/*
+Defaults are the defaults options set for the source. When not set, these values
+will automatically be applied.
+*/
+var Defaults = &Options{
+	Interval: "@every 1h",
+}
+
+/*
+Query is a SQL query whose rows are synced back to the destinations.
+*/
+type Query struct {
+
+	// Name is the unique name of the query. It is used as the name of the trigger
+	// running the query.
+	//
+	// Required.
+	Name string
+
+	// Type is the type of events created from the rows. It must be one of
+	// TypeIdentify or TypeGroup.
+	//
+	// Required.
+	Type string
+
+	// SQL is the query to run. It must return the columns expected by its type.
+	//
+	// Required.
+	SQL string
+
+	// Interval represents an interval or a CRON string at which the query shall
+	// run.
+	//
+	// Defaults to the interval of the source.
+	Interval string
+}
+
+/*
+Options is the options the source can take as an input to be configured.
+*/
+type Options struct {
+
+	// Interval represents an interval or a CRON string at which the queries shall
+	// run, unless overridden by a query.
+	//
+	// Defaults to "@every 1h".
+	Interval string
+
+	// DB is the PostgreSQL database connection the queries run against. It is also
+	// where the results of the previous runs are stored. The tables are created by
+	// the migration "init_reverse".
+	//
+	// Required.
+	DB *sql.DB
+
+	// Queries are the queries to run. Each query is registered as a trigger.
+	Queries []*Query
+}
+
+/*
+validate ensures the options passed to initialize the source are valid.
+*/
+func (env *Options) validate() error {
+	fail := &errors.Error{
+		Message:     "source/reverse: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Sources", "reverse"},
+		})
+
+		return fail
+	}
+
+	if env.Interval == "" {
+		env.Interval = Defaults.Interval
+	}
+
+	if env.DB == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Database connection must be set",
+			Path:    []string{"Options", "Sources", "reverse", "DB"},
+		})
+	}
+
+	names := map[string]bool{}
+	for i, query := range env.Queries {
+		path := []string{"Options", "Sources", "reverse", "Queries", strconv.Itoa(i)}
+		if !queryName.MatchString(query.Name) {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Query name must only contain lowercase letters, digits, and underscores",
+				Path:    append(path, "Name"),
+			})
+		}
+
+		if names[query.Name] {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Query name must be unique",
+				Path:    append(path, "Name"),
+			})
+		}
+
+		names[query.Name] = true
+		if query.Type != TypeIdentify && query.Type != TypeGroup {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Query type must be one of 'identify' or 'group'",
+				Path:    append(path, "Type"),
+			})
+		}
+
+		if strings.TrimSpace(query.SQL) == "" {
+			fail.Validations = append(fail.Validations, errors.Validation{
+				Message: "Query SQL must be set",
+				Path:    append(path, "SQL"),
+			})
+		}
+
+		if query.Interval == "" {
+			query.Interval = env.Interval
+		}
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
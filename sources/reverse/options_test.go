@@ -0,0 +1,69 @@
+package reverse
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestOptions_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  *Options
+		wantErr bool
+	}{
+		{
+			name:    "WithEmptyOptions",
+			fields:  &Options{},
+			wantErr: true,
+		},
+		{
+			name: "WithQuery",
+			fields: &Options{
+				DB: &sql.DB{},
+				Queries: []*Query{
+					{Name: "lifetime_value", Type: TypeIdentify, SQL: "SELECT user_id, ltv FROM ltv"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "WithInvalidType",
+			fields: &Options{
+				DB: &sql.DB{},
+				Queries: []*Query{
+					{Name: "lifetime_value", Type: "track", SQL: "SELECT user_id, ltv FROM ltv"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithDuplicateName",
+			fields: &Options{
+				DB: &sql.DB{},
+				Queries: []*Query{
+					{Name: "plans", Type: TypeGroup, SQL: "SELECT group_id, user_id, plan FROM plans"},
+					{Name: "plans", Type: TypeGroup, SQL: "SELECT group_id, user_id, plan FROM plans"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "WithoutSQL",
+			fields: &Options{
+				DB: &sql.DB{},
+				Queries: []*Query{
+					{Name: "plans", Type: TypeGroup},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.fields
+			if err := env.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
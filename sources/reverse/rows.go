@@ -0,0 +1,133 @@
+package reverse
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+)
+
+/*
+row is a row returned by a query, with the identifiers of the user and the group
+apart from the traits.
+*/
+type row struct {
+	UserID  string
+	GroupID string
+	Traits  map[string]interface{}
+}
+
+/*
+key returns the key identifying the row across runs.
+*/
+func (r *row) key() string {
+	if r.GroupID != "" {
+		return r.GroupID + ":" + r.UserID
+	}
+
+	return r.UserID
+}
+
+/*
+hash returns the hash of the traits of the row. The JSON encoding of a map has
+sorted keys, so the hash is the same for the same traits.
+*/
+func (r *row) hash() string {
+	b, _ := json.Marshal(r.Traits)
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+/*
+value converts a value scanned from the database to a value of a trait. The
+PostgreSQL driver returns numerics and JSON as raw bytes, which are decoded so the
+traits keep their type.
+*/
+func value(databaseType string, scanned interface{}) interface{} {
+	b, ok := scanned.([]byte)
+	if !ok {
+		return scanned
+	}
+
+	switch databaseType {
+	case "NUMERIC":
+		if f, err := strconv.ParseFloat(string(b), 64); err == nil {
+			return f
+		}
+
+	case "JSON", "JSONB":
+		var decoded interface{}
+		if err := json.Unmarshal(b, &decoded); err == nil {
+			return decoded
+		}
+	}
+
+	return string(b)
+}
+
+/*
+scan reads the rows returned by a query. Rows without the identifiers expected by
+the type of the query are skipped.
+*/
+func scan(rows *sql.Rows, typ string) ([]*row, error) {
+	columns, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	scanned := []*row{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		r := &row{
+			Traits: map[string]interface{}{},
+		}
+
+		for i, column := range columns {
+			v := value(column.DatabaseTypeName(), values[i])
+			switch column.Name() {
+			case "user_id":
+				r.UserID = identifier(v)
+
+			case "group_id":
+				r.GroupID = identifier(v)
+
+			default:
+				r.Traits[column.Name()] = v
+			}
+		}
+
+		if r.UserID == "" || (typ == TypeGroup && r.GroupID == "") {
+			continue
+		}
+
+		scanned = append(scanned, r)
+	}
+
+	return scanned, rows.Err()
+}
+
+/*
+identifier returns the string representation of an identifier, which can be
+stored as a number in the database.
+*/
+func identifier(v interface{}) string {
+	switch id := v.(type) {
+	case string:
+		return id
+
+	case int64:
+		return strconv.FormatInt(id, 10)
+	}
+
+	return ""
+}
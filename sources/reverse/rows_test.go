@@ -0,0 +1,73 @@
+package reverse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		name         string
+		databaseType string
+		scanned      interface{}
+		want         interface{}
+	}{
+		{
+			name:         "WithNumeric",
+			databaseType: "NUMERIC",
+			scanned:      []byte("129.90"),
+			want:         129.9,
+		},
+		{
+			name:         "WithJSONB",
+			databaseType: "JSONB",
+			scanned:      []byte(`{"plan":"pro"}`),
+			want:         map[string]interface{}{"plan": "pro"},
+		},
+		{
+			name:         "WithBytes",
+			databaseType: "BPCHAR",
+			scanned:      []byte("pro"),
+			want:         "pro",
+		},
+		{
+			name:         "WithInteger",
+			databaseType: "INT8",
+			scanned:      int64(42),
+			want:         int64(42),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := value(tt.databaseType, tt.scanned); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("value() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRow_key(t *testing.T) {
+	tests := []struct {
+		name string
+		row  *row
+		want string
+	}{
+		{
+			name: "WithUser",
+			row:  &row{UserID: "u1"},
+			want: "u1",
+		},
+		{
+			name: "WithGroup",
+			row:  &row{UserID: "u1", GroupID: "g1"},
+			want: "g1:u1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.row.key(); got != tt.want {
+				t.Errorf("row.key() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package reverse
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/lib/pq"
+)
+
+/*
+snapshot holds the rows of a run of a query which are new or which changed since
+the previous run. The previous results of the query stay locked until the
+snapshot is saved or closed, so two runs of the same query can not emit the same
+changes twice.
+*/
+type snapshot struct {
+	tx      *sql.Tx
+	query   string
+	keys    []string
+	hashes  map[string]string
+	changed []*row
+}
+
+/*
+diff returns the snapshot of the rows which are new or which changed since the
+previous run of a query. Nothing is saved until save is called with the rows
+actually emitted, so a row whose sub-event could not be created is synced again
+on the next run. The snapshot must be closed once done.
+*/
+func diff(env *Options, query string, rows []*row) (*snapshot, error) {
+	tx, err := env.DB.Begin()
+	if err != nil {
+		return nil, failed("Failed to diff rows", err)
+	}
+
+	// Lock the previous results of the query, so two runs of the same query can
+	// not emit the same changes twice.
+	_, err = tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1));`, "reverse:"+query)
+	if err != nil {
+		tx.Rollback()
+		return nil, failed("Failed to diff rows", err)
+	}
+
+	previous, err := tx.Query(`
+		SELECT key, hash FROM fragment_reverse.rows
+		WHERE query = $1;
+	`, query)
+	if err != nil {
+		tx.Rollback()
+		return nil, failed("Failed to diff rows", err)
+	}
+
+	hashes := map[string]string{}
+	for previous.Next() {
+		var key, hash string
+		if err := previous.Scan(&key, &hash); err != nil {
+			previous.Close()
+			tx.Rollback()
+			return nil, failed("Failed to diff rows", err)
+		}
+
+		hashes[key] = hash
+	}
+
+	previous.Close()
+	if err := previous.Err(); err != nil {
+		tx.Rollback()
+		return nil, failed("Failed to diff rows", err)
+	}
+
+	changed, keys, values := changes(rows, hashes)
+	return &snapshot{
+		tx:      tx,
+		query:   query,
+		keys:    keys,
+		hashes:  values,
+		changed: changed,
+	}, nil
+}
+
+/*
+save saves the hashes of the rows emitted as the result of the run, and releases
+the lock. Rows which are not returned anymore are forgotten, so they are synced
+again if they come back.
+*/
+func (s *snapshot) save(emitted []*row) error {
+	_, err := s.tx.Exec(`
+		DELETE FROM fragment_reverse.rows
+		WHERE query = $1 AND NOT (key = ANY($2::TEXT[]));
+	`, s.query, pq.Array(s.keys))
+	if err != nil {
+		return failed("Failed to save rows", err)
+	}
+
+	keys, hashes := s.pending(emitted)
+	_, err = s.tx.Exec(`
+		INSERT INTO fragment_reverse.rows (query, key, hash, synced_at)
+		SELECT $1, UNNEST($2::TEXT[]), UNNEST($3::TEXT[]), $4
+		ON CONFLICT (query, key) DO UPDATE SET
+			hash = EXCLUDED.hash,
+			synced_at = EXCLUDED.synced_at;
+	`, s.query, pq.Array(keys), pq.Array(hashes), time.Now().UTC())
+	if err != nil {
+		return failed("Failed to save rows", err)
+	}
+
+	if err := s.tx.Commit(); err != nil {
+		return failed("Failed to save rows", err)
+	}
+
+	return nil
+}
+
+/*
+pending returns the keys and the hashes to save given the rows emitted.
+*/
+func (s *snapshot) pending(emitted []*row) ([]string, []string) {
+	keys := make([]string, len(emitted))
+	hashes := make([]string, len(emitted))
+	for i, r := range emitted {
+		keys[i] = r.key()
+		hashes[i] = s.hashes[r.key()]
+	}
+
+	return keys, hashes
+}
+
+/*
+close releases the lock of the snapshot without saving it, if it has not been
+saved yet.
+*/
+func (s *snapshot) close() {
+	s.tx.Rollback()
+}
+
+/*
+changes returns the rows whose hash differs from the previous one, alongside the
+keys of every rows and their current hashes. When a key is returned by several
+rows, only the last one is kept.
+*/
+func changes(rows []*row, previous map[string]string) ([]*row, []string, map[string]string) {
+	current := map[string]*row{}
+	hashes := map[string]string{}
+	keys := []string{}
+	for _, r := range rows {
+		key := r.key()
+		if _, exists := current[key]; !exists {
+			keys = append(keys, key)
+		}
+
+		current[key] = r
+		hashes[key] = r.hash()
+	}
+
+	changed := []*row{}
+	for _, key := range keys {
+		if previous[key] != hashes[key] {
+			changed = append(changed, current[key])
+		}
+	}
+
+	return changed, keys, hashes
+}
+
+/*
+failed returns a consistent error for the source.
+*/
+func failed(message string, err error) error {
+	return &errors.Error{
+		Message: "source/reverse: " + message,
+		Validations: []errors.Validation{
+			{
+				Message: err.Error(),
+			},
+		},
+	}
+}
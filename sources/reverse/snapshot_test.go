@@ -0,0 +1,48 @@
+package reverse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChanges(t *testing.T) {
+	unchanged := &row{UserID: "u1", Traits: map[string]interface{}{"ltv": 42.0}}
+	updated := &row{UserID: "u2", Traits: map[string]interface{}{"ltv": 100.0}}
+	added := &row{UserID: "u3", Traits: map[string]interface{}{"ltv": 7.0}}
+
+	previous := map[string]string{
+		"u1": unchanged.hash(),
+		"u2": (&row{UserID: "u2", Traits: map[string]interface{}{"ltv": 90.0}}).hash(),
+		"u4": "removed",
+	}
+
+	changed, keys, _ := changes([]*row{unchanged, updated, added}, previous)
+	if !reflect.DeepEqual(changed, []*row{updated, added}) {
+		t.Errorf("changes() changed = %v, want %v", changed, []*row{updated, added})
+	}
+
+	if !reflect.DeepEqual(keys, []string{"u1", "u2", "u3"}) {
+		t.Errorf("changes() keys = %v, want %v", keys, []string{"u1", "u2", "u3"})
+	}
+}
+
+func TestSnapshot_Pending(t *testing.T) {
+	emitted := &row{UserID: "u1", Traits: map[string]interface{}{"ltv": 42.0}}
+	rejected := &row{UserID: "u2", Traits: map[string]interface{}{"ltv": 100.0}}
+
+	changed, keys, hashes := changes([]*row{emitted, rejected}, map[string]string{})
+	s := &snapshot{
+		keys:    keys,
+		hashes:  hashes,
+		changed: changed,
+	}
+
+	gotKeys, gotHashes := s.pending([]*row{emitted})
+	if !reflect.DeepEqual(gotKeys, []string{"u1"}) {
+		t.Errorf("snapshot.pending() keys = %v, want %v", gotKeys, []string{"u1"})
+	}
+
+	if !reflect.DeepEqual(gotHashes, []string{emitted.hash()}) {
+		t.Errorf("snapshot.pending() hashes = %v, want %v", gotHashes, []string{emitted.hash()})
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Package reverse provides the source "reverse", syncing the results of SQL queries
+back to the destinations, such as traits computed in the warehouse. The rows which
+changed since the previous run are sent as "identify" or "group" events, following
+the same format as the ones received by the source "rest".
+*/
+package reverse
+
+import (
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/logger"
+	"github.com/nunchistudio/blacksmith/source"
+)
+
+/*
+Reverse implements the Blacksmith source.Source interface for the source
+"reverse".
+*/
+type Reverse struct {
+	env     *Options
+	options *source.Options
+}
+
+/*
+New returns a valid Blacksmith source.Source for Reverse.
+*/
+func New(env *Options) source.Source {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &Reverse{
+		env: env,
+		options: &source.Options{
+			DefaultVersion: "v1.0",
+			Versions: map[string]time.Time{
+				"v1.0": time.Time{},
+			},
+			DefaultSchedule: &source.Schedule{
+				Interval: env.Interval,
+			},
+		},
+	}
+}
+
+/*
+String returns the string representation of the source Reverse.
+*/
+func (s *Reverse) String() string {
+	return "reverse"
+}
+
+/*
+Options returns common source options for Reverse. They will be shared across
+every triggers of this source, except when overridden.
+*/
+func (s *Reverse) Options() *source.Options {
+	return s.options
+}
+
+/*
+Triggers return a list of triggers the source Reverse is able to handle. There is
+one trigger per query, named after the query.
+*/
+func (s *Reverse) Triggers() map[string]source.Trigger {
+	triggers := map[string]source.Trigger{}
+	for _, query := range s.env.Queries {
+		triggers[query.Name] = Sync{
+			env:   s.env,
+			query: query,
+		}
+	}
+
+	return triggers
+}
@@ -0,0 +1,7 @@
+package reverse
+
+import (
+	"github.com/nunchistudio/blacksmith/source"
+)
+
+var _ source.Source = &Reverse{}
@@ -0,0 +1,113 @@
+package reverse
+
+import (
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/errors"
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/fragment/sources/rest"
+
+	"gopkg.in/segmentio/analytics-go.v3"
+)
+
+/*
+Sync implements the Blacksmith source.Trigger interface for the triggers of the
+source "reverse". It runs a query and syncs the rows which changed since the
+previous run.
+*/
+type Sync struct {
+	env   *Options
+	query *Query
+}
+
+/*
+String returns the string representation of the trigger Sync, which is the name
+of its query.
+*/
+func (t Sync) String() string {
+	return t.query.Name
+}
+
+/*
+Mode allows to register the trigger as a CRON task. This means, the Extract
+function will run at the interval set for the query.
+*/
+func (t Sync) Mode() *source.Mode {
+	return &source.Mode{
+		Mode: source.ModeCRON,
+		UsingCRON: &source.Schedule{
+			Interval: t.query.Interval,
+		},
+	}
+}
+
+/*
+Extract is the function being run when the CRON task is triggered. It runs the
+query, and returns an "identify" or "group" sub-event for every row which is new
+or changed since the previous run. The hashes of the rows are saved once their
+sub-events are created and before the event is returned, so a row is not synced
+again if the gateway fails to persist the event, unless it changes.
+*/
+func (t Sync) Extract(tk *source.Toolkit) (*source.Event, error) {
+	rows, err := t.env.DB.Query(t.query.SQL)
+	if err != nil {
+		return nil, failed("Failed to run query "+t.query.Name, err)
+	}
+
+	scanned, err := scan(rows, t.query.Type)
+	rows.Close()
+	if err != nil {
+		return nil, failed("Failed to read rows of query "+t.query.Name, err)
+	}
+
+	snap, err := diff(t.env, t.query.Name, scanned)
+	if err != nil {
+		return nil, err
+	}
+
+	defer snap.close()
+	now := time.Now().UTC()
+	subEvents := []*source.SubEvent{}
+	emitted := []*row{}
+	for _, r := range snap.changed {
+		var subevent *source.SubEvent
+		var fail *errors.Error
+		switch t.query.Type {
+		case TypeGroup:
+			subevent, fail = rest.GroupEvent(analytics.Group{
+				GroupId:   r.GroupID,
+				UserId:    r.UserID,
+				Traits:    r.Traits,
+				Timestamp: now,
+			})
+
+		default:
+			subevent, fail = rest.IdentifyEvent(analytics.Identify{
+				UserId:    r.UserID,
+				Traits:    r.Traits,
+				Timestamp: now,
+			})
+		}
+
+		if fail != nil {
+			tk.Logger.Error(fail)
+			continue
+		}
+
+		subEvents = append(subEvents, subevent)
+		emitted = append(emitted, r)
+	}
+
+	// Only save the rows emitted, so the ones which failed are synced again on
+	// the next run.
+	if err := snap.save(emitted); err != nil {
+		return nil, err
+	}
+
+	// Return the collection of sub-events to process.
+	return &source.Event{
+		Version:   "v1.0",
+		SubEvents: subEvents,
+	}, nil
+}
@@ -0,0 +1,8 @@
+package reverse
+
+import (
+	"github.com/nunchistudio/blacksmith/source"
+)
+
+var _ source.Trigger = Sync{}
+var _ source.TriggerCRON = Sync{}
@@ -0,0 +1,65 @@
+package scheduled
+
+import (
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/fragment/sources/rest"
+)
+
+/*
+Audiences implements the Blacksmith source.Trigger interface for the trigger
+"audiences". It evaluates the audiences of the profiles so conditions over
+rolling windows stay accurate.
+*/
+type Audiences struct {
+	env *Options
+}
+
+/*
+String returns the string representation of the trigger Audiences.
+*/
+func (t Audiences) String() string {
+	return "audiences"
+}
+
+/*
+Mode allows to register the trigger as a CRON task. This means, the Extract
+function will run at the interval set in the options.
+*/
+func (t Audiences) Mode() *source.Mode {
+	return &source.Mode{
+		Mode: source.ModeCRON,
+		UsingCRON: &source.Schedule{
+			Interval: t.env.Interval,
+		},
+	}
+}
+
+/*
+Extract is the function being run when the CRON task is triggered. It evaluates
+the audiences and returns a "track" sub-event for every profile which entered or
+exited an audience.
+*/
+func (t Audiences) Extract(tk *source.Toolkit) (*source.Event, error) {
+	tracks, err := t.env.Audiences.Refresh()
+	if err != nil {
+		return nil, err
+	}
+
+	subEvents := []*source.SubEvent{}
+	for _, track := range tracks {
+		subevent, fail := rest.TrackEvent(track)
+		if fail != nil {
+			tk.Logger.Error(fail)
+			continue
+		}
+
+		subEvents = append(subEvents, subevent)
+	}
+
+	// Return the collection of sub-events to process.
+	return &source.Event{
+		Version:   "v1.0",
+		SubEvents: subEvents,
+	}, nil
+}
@@ -0,0 +1,64 @@
+package scheduled
+
+import (
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/fragment/sources/rest"
+)
+
+/*
+ComputedTraits implements the Blacksmith source.Trigger interface for the trigger
+"computed_traits". It refreshes the computed traits over a rolling window.
+*/
+type ComputedTraits struct {
+	env *Options
+}
+
+/*
+String returns the string representation of the trigger ComputedTraits.
+*/
+func (t ComputedTraits) String() string {
+	return "computed_traits"
+}
+
+/*
+Mode allows to register the trigger as a CRON task. This means, the Extract
+function will run at the interval set in the options.
+*/
+func (t ComputedTraits) Mode() *source.Mode {
+	return &source.Mode{
+		Mode: source.ModeCRON,
+		UsingCRON: &source.Schedule{
+			Interval: t.env.Interval,
+		},
+	}
+}
+
+/*
+Extract is the function being run when the CRON task is triggered. It refreshes
+the computed traits and returns an "identify" sub-event for every profile with
+traits which changed.
+*/
+func (t ComputedTraits) Extract(tk *source.Toolkit) (*source.Event, error) {
+	identifies, err := t.env.Computed.Refresh()
+	if err != nil {
+		return nil, err
+	}
+
+	subEvents := []*source.SubEvent{}
+	for _, identify := range identifies {
+		subevent, fail := rest.IdentifyEvent(*identify)
+		if fail != nil {
+			tk.Logger.Error(fail)
+			continue
+		}
+
+		subEvents = append(subEvents, subevent)
+	}
+
+	// Return the collection of sub-events to process.
+	return &source.Event{
+		Version:   "v1.0",
+		SubEvents: subEvents,
+	}, nil
+}
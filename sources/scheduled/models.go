@@ -0,0 +1,50 @@
+package scheduled
+
+import (
+	"github.com/nunchistudio/blacksmith/source"
+)
+
+/*
+Models implements the Blacksmith source.Trigger interface for the trigger
+"models". It builds the modeled tables on top of the raw events of the warehouse.
+*/
+type Models struct {
+	env *Options
+}
+
+/*
+String returns the string representation of the trigger Models.
+*/
+func (t Models) String() string {
+	return "models"
+}
+
+/*
+Mode allows to register the trigger as a CRON task. This means, the Extract
+function will run at the interval set in the options.
+*/
+func (t Models) Mode() *source.Mode {
+	return &source.Mode{
+		Mode: source.ModeCRON,
+		UsingCRON: &source.Schedule{
+			Interval: t.env.Interval,
+		},
+	}
+}
+
+/*
+Extract is the function being run when the CRON task is triggered. It runs the
+SQL operations building the modeled tables. Since the task is triggered on every
+replica, each operation holds an advisory lock while it runs. No event is returned.
+*/
+func (t Models) Extract(tk *source.Toolkit) (*source.Event, error) {
+	if err := t.env.Models.Run(); err != nil {
+		return nil, err
+	}
+
+	// Return an empty collection of sub-events.
+	return &source.Event{
+		Version:   "v1.0",
+		SubEvents: []*source.SubEvent{},
+	}, nil
+}
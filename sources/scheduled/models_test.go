@@ -0,0 +1,8 @@
+package scheduled
+
+import (
+	"github.com/nunchistudio/blacksmith/source"
+)
+
+var _ source.Trigger = Models{}
+var _ source.TriggerCRON = Models{}
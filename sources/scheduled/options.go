@@ -0,0 +1,77 @@
+package scheduled
+
+import (
+	"github.com/nunchistudio/blacksmith/helper/errors"
+
+	"github.com/nunchistudio/fragment/audiences"
+	"github.com/nunchistudio/fragment/computed"
+	"github.com/nunchistudio/fragment/models"
+	"github.com/nunchistudio/fragment/sessions"
+)
+
+/*
+Defaults are the defaults options set for the source. When not set, these values
+will automatically be applied.
+*/
+var Defaults = &Options{
+	Interval: "@every 1h",
+}
+
+/*
+Options is the options the source can take as an input to be configured.
+*/
+type Options struct {
+
+	// Interval represents an interval or a CRON string at which the triggers of
+	// the source shall run.
+	//
+	// Defaults to "@every 1h".
+	Interval string
+
+	// Computed is the store of computed traits to refresh, so traits computed over
+	// a rolling window stay accurate even for inactive users. When nil, the
+	// trigger "computed_traits" is disabled.
+	Computed *computed.Store
+
+	// Audiences is the store of audiences to evaluate, so conditions over rolling
+	// windows stay accurate even for inactive users. When nil, the trigger
+	// "audiences" is disabled.
+	Audiences *audiences.Store
+
+	// Sessions is the store of sessions to end once they timed out. When nil, the
+	// trigger "sessions" is disabled.
+	Sessions *sessions.Store
+
+	// Models is the runner of the SQL operations building the modeled tables of
+	// the warehouse. When nil, the trigger "models" is disabled.
+	Models *models.Runner
+}
+
+/*
+validate ensures the options passed to initialize the source are valid.
+*/
+func (env *Options) validate() error {
+	fail := &errors.Error{
+		Message:     "source/scheduled: Failed to load",
+		Validations: []errors.Validation{},
+	}
+
+	if env == nil {
+		fail.Validations = append(fail.Validations, errors.Validation{
+			Message: "Options must not be nil",
+			Path:    []string{"Options", "Sources", "scheduled"},
+		})
+
+		return fail
+	}
+
+	if env.Interval == "" {
+		env.Interval = Defaults.Interval
+	}
+
+	if len(fail.Validations) > 0 {
+		return fail
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package scheduled
+
+import (
+	"github.com/nunchistudio/blacksmith/source"
+
+	"github.com/nunchistudio/fragment/sources/rest"
+)
+
+/*
+Sessions implements the Blacksmith source.Trigger interface for the trigger
+"sessions". It ends the sessions which timed out.
+*/
+type Sessions struct {
+	env *Options
+}
+
+/*
+String returns the string representation of the trigger Sessions.
+*/
+func (t Sessions) String() string {
+	return "sessions"
+}
+
+/*
+Mode allows to register the trigger as a CRON task. This means, the Extract
+function will run at the interval set in the options.
+*/
+func (t Sessions) Mode() *source.Mode {
+	return &source.Mode{
+		Mode: source.ModeCRON,
+		UsingCRON: &source.Schedule{
+			Interval: t.env.Interval,
+		},
+	}
+}
+
+/*
+Extract is the function being run when the CRON task is triggered. It ends the
+sessions which timed out and returns a "track" sub-event for every session ended,
+if enabled in the store of sessions.
+*/
+func (t Sessions) Extract(tk *source.Toolkit) (*source.Event, error) {
+	tracks, err := t.env.Sessions.Expire()
+	if err != nil {
+		return nil, err
+	}
+
+	subEvents := []*source.SubEvent{}
+	for _, track := range tracks {
+		subevent, fail := rest.TrackEvent(track)
+		if fail != nil {
+			tk.Logger.Error(fail)
+			continue
+		}
+
+		subEvents = append(subEvents, subevent)
+	}
+
+	// Return the collection of sub-events to process.
+	return &source.Event{
+		Version:   "v1.0",
+		SubEvents: subEvents,
+	}, nil
+}
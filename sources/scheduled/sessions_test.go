@@ -0,0 +1,8 @@
+package scheduled
+
+import (
+	"github.com/nunchistudio/blacksmith/source"
+)
+
+var _ source.Trigger = Sessions{}
+var _ source.TriggerCRON = Sessions{}
@@ -0,0 +1,96 @@
+/*
+Package scheduled provides the source "scheduled", running the periodic tasks of
+Fragment as CRON triggers. The events created by these triggers follow the same
+format as the ones received by the source "rest".
+*/
+package scheduled
+
+import (
+	"time"
+
+	"github.com/nunchistudio/blacksmith/helper/logger"
+	"github.com/nunchistudio/blacksmith/source"
+)
+
+/*
+Scheduled implements the Blacksmith source.Source interface for the source
+"scheduled".
+*/
+type Scheduled struct {
+	env     *Options
+	options *source.Options
+}
+
+/*
+New returns a valid Blacksmith source.Source for Scheduled.
+*/
+func New(env *Options) source.Source {
+
+	// Validate the environment options passed by the application.
+	// Stop the process if any error is returned.
+	if err := env.validate(); err != nil {
+		logger.Default.Fatal(err)
+		return nil
+	}
+
+	return &Scheduled{
+		env: env,
+		options: &source.Options{
+			DefaultVersion: "v1.0",
+			Versions: map[string]time.Time{
+				"v1.0": time.Time{},
+			},
+			DefaultSchedule: &source.Schedule{
+				Interval: env.Interval,
+			},
+		},
+	}
+}
+
+/*
+String returns the string representation of the source Scheduled.
+*/
+func (s *Scheduled) String() string {
+	return "scheduled"
+}
+
+/*
+Options returns common source options for Scheduled. They will be shared across
+every triggers of this source, except when overridden.
+*/
+func (s *Scheduled) Options() *source.Options {
+	return s.options
+}
+
+/*
+Triggers return a list of triggers the source Scheduled is able to handle. Only
+the triggers of the features enabled are returned.
+*/
+func (s *Scheduled) Triggers() map[string]source.Trigger {
+	triggers := map[string]source.Trigger{}
+	if s.env.Computed != nil {
+		triggers["computed_traits"] = ComputedTraits{
+			env: s.env,
+		}
+	}
+
+	if s.env.Audiences != nil {
+		triggers["audiences"] = Audiences{
+			env: s.env,
+		}
+	}
+
+	if s.env.Sessions != nil {
+		triggers["sessions"] = Sessions{
+			env: s.env,
+		}
+	}
+
+	if s.env.Models != nil {
+		triggers["models"] = Models{
+			env: s.env,
+		}
+	}
+
+	return triggers
+}
@@ -0,0 +1,7 @@
+package scheduled
+
+import (
+	"github.com/nunchistudio/blacksmith/source"
+)
+
+var _ source.Source = &Scheduled{}